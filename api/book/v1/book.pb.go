@@ -101,6 +101,456 @@ func (x *TellMeResponse) GetMessage() string {
 	return ""
 }
 
+// CountBooksRequest 图书计数请求
+type CountBooksRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CountBooksRequest) Reset() {
+	*x = CountBooksRequest{}
+	mi := &file_book_v1_book_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CountBooksRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CountBooksRequest) ProtoMessage() {}
+
+func (x *CountBooksRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_book_v1_book_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CountBooksRequest.ProtoReflect.Descriptor instead.
+func (*CountBooksRequest) Descriptor() ([]byte, []int) {
+	return file_book_v1_book_proto_rawDescGZIP(), []int{2}
+}
+
+// CountBooksResponse 图书计数响应
+type CountBooksResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// count 图书总数
+	Count         int64 `protobuf:"varint,1,opt,name=count,proto3" json:"count,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CountBooksResponse) Reset() {
+	*x = CountBooksResponse{}
+	mi := &file_book_v1_book_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CountBooksResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CountBooksResponse) ProtoMessage() {}
+
+func (x *CountBooksResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_book_v1_book_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CountBooksResponse.ProtoReflect.Descriptor instead.
+func (*CountBooksResponse) Descriptor() ([]byte, []int) {
+	return file_book_v1_book_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *CountBooksResponse) GetCount() int64 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+// BookInfo 图书信息
+type BookInfo struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// id 图书 ID
+	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// bookname 书名
+	Bookname string `protobuf:"bytes,2,opt,name=bookname,proto3" json:"bookname,omitempty"`
+	// email 联系邮箱
+	Email         string `protobuf:"bytes,3,opt,name=email,proto3" json:"email,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BookInfo) Reset() {
+	*x = BookInfo{}
+	mi := &file_book_v1_book_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BookInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BookInfo) ProtoMessage() {}
+
+func (x *BookInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_book_v1_book_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BookInfo.ProtoReflect.Descriptor instead.
+func (*BookInfo) Descriptor() ([]byte, []int) {
+	return file_book_v1_book_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *BookInfo) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *BookInfo) GetBookname() string {
+	if x != nil {
+		return x.Bookname
+	}
+	return ""
+}
+
+func (x *BookInfo) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+// CreateBookRequest 创建图书请求
+type CreateBookRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// bookname 书名，不能为空
+	Bookname string `protobuf:"bytes,1,opt,name=bookname,proto3" json:"bookname,omitempty"`
+	// email 联系邮箱，不能为空
+	Email         string `protobuf:"bytes,2,opt,name=email,proto3" json:"email,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateBookRequest) Reset() {
+	*x = CreateBookRequest{}
+	mi := &file_book_v1_book_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateBookRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateBookRequest) ProtoMessage() {}
+
+func (x *CreateBookRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_book_v1_book_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateBookRequest.ProtoReflect.Descriptor instead.
+func (*CreateBookRequest) Descriptor() ([]byte, []int) {
+	return file_book_v1_book_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *CreateBookRequest) GetBookname() string {
+	if x != nil {
+		return x.Bookname
+	}
+	return ""
+}
+
+func (x *CreateBookRequest) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+// CreateBookResponse 创建图书响应
+type CreateBookResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// book 创建后的图书
+	Book          *BookInfo `protobuf:"bytes,1,opt,name=book,proto3" json:"book,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateBookResponse) Reset() {
+	*x = CreateBookResponse{}
+	mi := &file_book_v1_book_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateBookResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateBookResponse) ProtoMessage() {}
+
+func (x *CreateBookResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_book_v1_book_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateBookResponse.ProtoReflect.Descriptor instead.
+func (*CreateBookResponse) Descriptor() ([]byte, []int) {
+	return file_book_v1_book_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *CreateBookResponse) GetBook() *BookInfo {
+	if x != nil {
+		return x.Book
+	}
+	return nil
+}
+
+// GetBookRequest 获取图书请求
+type GetBookRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// id 图书 ID
+	Id            string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetBookRequest) Reset() {
+	*x = GetBookRequest{}
+	mi := &file_book_v1_book_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetBookRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetBookRequest) ProtoMessage() {}
+
+func (x *GetBookRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_book_v1_book_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetBookRequest.ProtoReflect.Descriptor instead.
+func (*GetBookRequest) Descriptor() ([]byte, []int) {
+	return file_book_v1_book_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *GetBookRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+// GetBookResponse 获取图书响应
+type GetBookResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// book 图书信息
+	Book          *BookInfo `protobuf:"bytes,1,opt,name=book,proto3" json:"book,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetBookResponse) Reset() {
+	*x = GetBookResponse{}
+	mi := &file_book_v1_book_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetBookResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetBookResponse) ProtoMessage() {}
+
+func (x *GetBookResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_book_v1_book_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetBookResponse.ProtoReflect.Descriptor instead.
+func (*GetBookResponse) Descriptor() ([]byte, []int) {
+	return file_book_v1_book_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *GetBookResponse) GetBook() *BookInfo {
+	if x != nil {
+		return x.Book
+	}
+	return nil
+}
+
+// ListBooksRequest 图书列表请求
+type ListBooksRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// page_size 每页数量，为 0 时使用默认值，超过上限时截断
+	PageSize int32 `protobuf:"varint,1,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	// page_token 上一页返回的游标，为空表示第一页
+	PageToken     string `protobuf:"bytes,2,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListBooksRequest) Reset() {
+	*x = ListBooksRequest{}
+	mi := &file_book_v1_book_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListBooksRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListBooksRequest) ProtoMessage() {}
+
+func (x *ListBooksRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_book_v1_book_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListBooksRequest.ProtoReflect.Descriptor instead.
+func (*ListBooksRequest) Descriptor() ([]byte, []int) {
+	return file_book_v1_book_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *ListBooksRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListBooksRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+// ListBooksResponse 图书列表响应
+type ListBooksResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// books 当前页图书
+	Books []*BookInfo `protobuf:"bytes,1,rep,name=books,proto3" json:"books,omitempty"`
+	// next_page_token 下一页游标，为空表示没有更多数据
+	NextPageToken string `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListBooksResponse) Reset() {
+	*x = ListBooksResponse{}
+	mi := &file_book_v1_book_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListBooksResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListBooksResponse) ProtoMessage() {}
+
+func (x *ListBooksResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_book_v1_book_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListBooksResponse.ProtoReflect.Descriptor instead.
+func (*ListBooksResponse) Descriptor() ([]byte, []int) {
+	return file_book_v1_book_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *ListBooksResponse) GetBooks() []*BookInfo {
+	if x != nil {
+		return x.Books
+	}
+	return nil
+}
+
+func (x *ListBooksResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
 var File_book_v1_book_proto protoreflect.FileDescriptor
 
 const file_book_v1_book_proto_rawDesc = "" +
@@ -108,10 +558,39 @@ const file_book_v1_book_proto_rawDesc = "" +
 	"\x12book/v1/book.proto\x12\abook.v1\"\x0f\n" +
 	"\rTellMeRequest\"*\n" +
 	"\x0eTellMeResponse\x12\x18\n" +
-	"\amessage\x18\x01 \x01(\tR\amessage2N\n" +
+	"\amessage\x18\x01 \x01(\tR\amessage\"\x13\n" +
+	"\x11CountBooksRequest\"*\n" +
+	"\x12CountBooksResponse\x12\x14\n" +
+	"\x05count\x18\x01 \x01(\x03R\x05count\"L\n" +
+	"\bBookInfo\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1a\n" +
+	"\bbookname\x18\x02 \x01(\tR\bbookname\x12\x14\n" +
+	"\x05email\x18\x03 \x01(\tR\x05email\"E\n" +
+	"\x11CreateBookRequest\x12\x1a\n" +
+	"\bbookname\x18\x01 \x01(\tR\bbookname\x12\x14\n" +
+	"\x05email\x18\x02 \x01(\tR\x05email\";\n" +
+	"\x12CreateBookResponse\x12%\n" +
+	"\x04book\x18\x01 \x01(\v2\x11.book.v1.BookInfoR\x04book\" \n" +
+	"\x0eGetBookRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"8\n" +
+	"\x0fGetBookResponse\x12%\n" +
+	"\x04book\x18\x01 \x01(\v2\x11.book.v1.BookInfoR\x04book\"N\n" +
+	"\x10ListBooksRequest\x12\x1b\n" +
+	"\tpage_size\x18\x01 \x01(\x05R\bpageSize\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\x02 \x01(\tR\tpageToken\"d\n" +
+	"\x11ListBooksResponse\x12'\n" +
+	"\x05books\x18\x01 \x03(\v2\x11.book.v1.BookInfoR\x05books\x12&\n" +
+	"\x0fnext_page_token\x18\x02 \x01(\tR\rnextPageToken2\xe6\x02\n" +
 	"\vBookService\x12?\n" +
 	"\n" +
-	"JustTellMe\x12\x16.book.v1.TellMeRequest\x1a\x17.book.v1.TellMeResponse\"\x00B0Z.github.com/alfredchaos/demo/api/book/v1;bookv1b\x06proto3"
+	"JustTellMe\x12\x16.book.v1.TellMeRequest\x1a\x17.book.v1.TellMeResponse\"\x00\x12G\n" +
+	"\n" +
+	"CountBooks\x12\x1a.book.v1.CountBooksRequest\x1a\x1b.book.v1.CountBooksResponse\"\x00\x12G\n" +
+	"\n" +
+	"CreateBook\x12\x1a.book.v1.CreateBookRequest\x1a\x1b.book.v1.CreateBookResponse\"\x00\x12>\n" +
+	"\aGetBook\x12\x17.book.v1.GetBookRequest\x1a\x18.book.v1.GetBookResponse\"\x00\x12D\n" +
+	"\tListBooks\x12\x19.book.v1.ListBooksRequest\x1a\x1a.book.v1.ListBooksResponse\"\x00B0Z.github.com/alfredchaos/demo/api/book/v1;bookv1b\x06proto3"
 
 var (
 	file_book_v1_book_proto_rawDescOnce sync.Once
@@ -125,19 +604,39 @@ func file_book_v1_book_proto_rawDescGZIP() []byte {
 	return file_book_v1_book_proto_rawDescData
 }
 
-var file_book_v1_book_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_book_v1_book_proto_msgTypes = make([]protoimpl.MessageInfo, 11)
 var file_book_v1_book_proto_goTypes = []any{
-	(*TellMeRequest)(nil),  // 0: book.v1.TellMeRequest
-	(*TellMeResponse)(nil), // 1: book.v1.TellMeResponse
+	(*TellMeRequest)(nil),      // 0: book.v1.TellMeRequest
+	(*TellMeResponse)(nil),     // 1: book.v1.TellMeResponse
+	(*CountBooksRequest)(nil),  // 2: book.v1.CountBooksRequest
+	(*CountBooksResponse)(nil), // 3: book.v1.CountBooksResponse
+	(*BookInfo)(nil),           // 4: book.v1.BookInfo
+	(*CreateBookRequest)(nil),  // 5: book.v1.CreateBookRequest
+	(*CreateBookResponse)(nil), // 6: book.v1.CreateBookResponse
+	(*GetBookRequest)(nil),     // 7: book.v1.GetBookRequest
+	(*GetBookResponse)(nil),    // 8: book.v1.GetBookResponse
+	(*ListBooksRequest)(nil),   // 9: book.v1.ListBooksRequest
+	(*ListBooksResponse)(nil),  // 10: book.v1.ListBooksResponse
 }
 var file_book_v1_book_proto_depIdxs = []int32{
-	0, // 0: book.v1.BookService.JustTellMe:input_type -> book.v1.TellMeRequest
-	1, // 1: book.v1.BookService.JustTellMe:output_type -> book.v1.TellMeResponse
-	1, // [1:2] is the sub-list for method output_type
-	0, // [0:1] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	4,  // 0: book.v1.CreateBookResponse.book:type_name -> book.v1.BookInfo
+	4,  // 1: book.v1.GetBookResponse.book:type_name -> book.v1.BookInfo
+	4,  // 2: book.v1.ListBooksResponse.books:type_name -> book.v1.BookInfo
+	0,  // 3: book.v1.BookService.JustTellMe:input_type -> book.v1.TellMeRequest
+	2,  // 4: book.v1.BookService.CountBooks:input_type -> book.v1.CountBooksRequest
+	5,  // 5: book.v1.BookService.CreateBook:input_type -> book.v1.CreateBookRequest
+	7,  // 6: book.v1.BookService.GetBook:input_type -> book.v1.GetBookRequest
+	9,  // 7: book.v1.BookService.ListBooks:input_type -> book.v1.ListBooksRequest
+	1,  // 8: book.v1.BookService.JustTellMe:output_type -> book.v1.TellMeResponse
+	3,  // 9: book.v1.BookService.CountBooks:output_type -> book.v1.CountBooksResponse
+	6,  // 10: book.v1.BookService.CreateBook:output_type -> book.v1.CreateBookResponse
+	8,  // 11: book.v1.BookService.GetBook:output_type -> book.v1.GetBookResponse
+	10, // 12: book.v1.BookService.ListBooks:output_type -> book.v1.ListBooksResponse
+	8,  // [8:13] is the sub-list for method output_type
+	3,  // [3:8] is the sub-list for method input_type
+	3,  // [3:3] is the sub-list for extension type_name
+	3,  // [3:3] is the sub-list for extension extendee
+	0,  // [0:3] is the sub-list for field type_name
 }
 
 func init() { file_book_v1_book_proto_init() }
@@ -151,7 +650,7 @@ func file_book_v1_book_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_book_v1_book_proto_rawDesc), len(file_book_v1_book_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   2,
+			NumMessages:   11,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
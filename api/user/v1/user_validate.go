@@ -0,0 +1,23 @@
+package userv1
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// MaxHelloNameLength HelloRequest.name 的最大长度（字符数）
+const MaxHelloNameLength = 64
+
+// Validate 校验问候请求，由服务端校验拦截器在调用处理函数前执行
+func (x *HelloRequest) Validate() error {
+	name := x.GetName()
+	if strings.TrimSpace(name) == "" {
+		return errors.New("name is required")
+	}
+	if n := utf8.RuneCountInString(name); n > MaxHelloNameLength {
+		return fmt.Errorf("name must be at most %d characters, got %d", MaxHelloNameLength, n)
+	}
+	return nil
+}
@@ -1,21 +1,31 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"net"
+	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
-	userv1 "github.com/alfredchaos/demo/api/user/v1"
 	_ "github.com/alfredchaos/demo/docs"
 	"github.com/alfredchaos/demo/internal/api-gateway/dependencies"
+	"github.com/alfredchaos/demo/internal/api-gateway/dto"
 	"github.com/alfredchaos/demo/internal/api-gateway/router"
+	"github.com/alfredchaos/demo/internal/clients"
+	"github.com/alfredchaos/demo/pkg/buildinfo"
+	"github.com/alfredchaos/demo/pkg/cache"
 	"github.com/alfredchaos/demo/pkg/config"
+	"github.com/alfredchaos/demo/pkg/db"
+	"github.com/alfredchaos/demo/pkg/featureflag"
 	"github.com/alfredchaos/demo/pkg/grpcclient"
+	"github.com/alfredchaos/demo/pkg/health"
 	"github.com/alfredchaos/demo/pkg/log"
 	"github.com/alfredchaos/demo/pkg/mq"
 	"go.uber.org/zap"
-	"google.golang.org/grpc"
 )
 
 // Config api-gateway 配置结构
@@ -25,6 +35,16 @@ type Config struct {
 	Services    ServicesConfig    `yaml:"services" mapstructure:"services"`         // 后端服务配置（保持向后兼容）
 	GRPCClients grpcclient.Config `yaml:"grpc_clients" mapstructure:"grpc_clients"` // gRPC客户端配置
 	RabbitMQ    mq.RabbitMQConfig `yaml:"rabbitmq" mapstructure:"rabbitmq"`         // RabbitMQ 配置
+	Degradation map[string]bool   `yaml:"degradation" mapstructure:"degradation"`   // 按接口开启降级响应（如 hello: true）
+
+	FeatureFlags map[string]bool    `yaml:"feature_flags" mapstructure:"feature_flags"` // 功能开关，可通过 /api/v1/admin/flags 查看
+	Health       HealthConfig       `yaml:"health" mapstructure:"health"`               // 健康检查配置
+	Admin        AdminConfig        `yaml:"admin" mapstructure:"admin"`                 // 运维管理接口配置
+	Probe        health.ProbeConfig `yaml:"probe" mapstructure:"probe"`                 // 探针服务配置（/livez、/readyz）
+
+	// 网关自身不读写存储；启用后只用于在 /api/v1/admin/stats 中汇总共享 PostgreSQL / Redis 的连接池统计
+	Database db.PostgresConfig `yaml:"database" mapstructure:"database"`
+	Redis    cache.RedisConfig `yaml:"redis" mapstructure:"redis"`
 }
 
 // ServerConfig 服务器配置
@@ -32,6 +52,19 @@ type ServerConfig struct {
 	Name string `yaml:"name" mapstructure:"name"` // 服务名称
 	Host string `yaml:"host" mapstructure:"host"` // 监听地址
 	Port int    `yaml:"port" mapstructure:"port"` // 监听端口
+
+	JSONNaming string `yaml:"json_naming" mapstructure:"json_naming"` // 响应 JSON 字段命名: snake_case（默认）, camelCase
+}
+
+// HealthConfig 健康检查配置
+type HealthConfig struct {
+	CriticalServices []string `yaml:"critical_services" mapstructure:"critical_services"` // 关键下游，熔断时 /health 返回 503；其余下游熔断只标记 degraded
+}
+
+// AdminConfig 运维管理接口配置
+type AdminConfig struct {
+	AllowedIPs []string `yaml:"allowed_ips" mapstructure:"allowed_ips"` // 允许访问 /api/v1/admin 的来源 IP 或 CIDR，为空时拒绝所有请求
+	Pprof      bool     `yaml:"pprof" mapstructure:"pprof"`             // 是否挂载 /api/v1/admin/debug/pprof/*，默认关闭
 }
 
 // ServicesConfig 后端服务配置
@@ -40,13 +73,6 @@ type ServicesConfig struct {
 	BookService string `yaml:"book_service" mapstructure:"book_service"` // book-service 地址
 }
 
-func init() {
-	// 注册 gRPC 客户端工厂
-	grpcclient.GlobalRegistry.Register("user-service", func(conn *grpc.ClientConn) interface{} {
-		return userv1.NewUserServiceClient(conn)
-	})
-}
-
 // @title Demo API Gateway
 // @version 1.0
 // @description 微服务架构演示项目的 API 网关
@@ -61,33 +87,64 @@ func main() {
 	log.MustInitLogger(&cfg.Log, cfg.Server.Name)
 	defer log.Sync()
 
-	log.Info("starting api-gateway", zap.String("name", cfg.Server.Name))
+	buildinfo.LogStartup("api-gateway", zap.String("name", cfg.Server.Name))
+
+	// 响应 JSON 字段命名策略
+	if err := dto.SetNamingStrategy(cfg.Server.JSONNaming); err != nil {
+		log.Fatal("invalid json naming strategy", zap.Error(err))
+	}
 
 	// 初始化 gRPC 客户端管理器
-	clientManager := grpcclient.InitGRPCClientManager(&cfg.GRPCClients)
+	clientManager := grpcclient.InitGRPCClientManager(&cfg.GRPCClients, clients.Factories())
 	defer func() {
 		if err := clientManager.Close(); err != nil {
 			log.Error("failed to close grpc client manager", zap.Error(err))
 		}
 	}()
 
+	// 连接池统计来源
+	statsSources, closeStats := connectStatsSources(&cfg)
+	defer closeStats()
+
 	// 依赖注入
-	deps := &dependencies.Dependencies{ClientManager: clientManager}
+	deps := &dependencies.Dependencies{
+		ClientManager: clientManager,
+		Degradation:   cfg.Degradation,
+		Flags:         featureflag.New(cfg.FeatureFlags),
+
+		CriticalServices: cfg.Health.CriticalServices,
+		AdminAllowedIPs:  cfg.Admin.AllowedIPs,
+		AdminPprof:       cfg.Admin.Pprof,
+		StatsSources:     statsSources,
+	}
 	appCtx := dependencies.InjectDependencies(deps)
 	log.Info("dependencies injected successfully")
 
 	// 设置路由
 	r := router.SetupRouter(appCtx)
 
+	// 探针服务，HTTP 端口绑定成功前 /readyz 返回 503
+	readiness := cfg.Probe.NewReadiness()
+	probeServer, err := health.StartProbeServer(&cfg.Probe, readiness)
+	if err != nil {
+		log.Fatal("failed to start probe server", zap.Error(err))
+	}
+
 	// 启动 HTTP 服务器
 	addr := fmt.Sprintf("%s:%d", cfg.Server.Host, cfg.Server.Port)
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		log.Fatal("failed to listen http server", zap.Error(err))
+	}
 	log.Info("http server starting", zap.String("addr", addr))
 
+	httpServer := &http.Server{Handler: r}
 	go func() {
-		if err := r.Run(addr); err != nil {
+		if err := httpServer.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
 			log.Fatal("failed to start http server", zap.Error(err))
 		}
 	}()
+	readiness.Complete()
 
 	// 等待中断信号
 	quit := make(chan os.Signal, 1)
@@ -95,5 +152,51 @@ func main() {
 	<-quit
 
 	log.Info("shutting down api-gateway")
+
+	// 先让 /readyz 返回 503 并等待负载均衡摘除实例，再停止接收请求
+	readiness.Drain(cfg.Probe.DrainDelay)
+
+	stopCtx, cancelStop := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancelStop()
+	if err := httpServer.Shutdown(stopCtx); err != nil {
+		log.Error("failed to stop http server", zap.Error(err))
+	}
+	if err := probeServer.Stop(stopCtx); err != nil {
+		log.Error("failed to stop probe server", zap.Error(err))
+	}
 	log.Info("api-gateway stopped")
 }
+
+// connectStatsSources 按 enabled 开关连接需要汇总统计的存储，返回统计来源及关闭函数
+// 统计只用于排查问题，连接失败时记录警告并跳过，不影响网关启动
+func connectStatsSources(cfg *Config) ([]health.StatsSource, func()) {
+	var sources []health.StatsSource
+	var closers []func() error
+
+	if cfg.Database.Enabled {
+		client, err := db.NewPostgresClient(&cfg.Database)
+		if err != nil {
+			log.Warn("failed to connect postgres for admin stats", zap.Error(err))
+		} else {
+			sources = append(sources, health.NamedStats("postgres", client))
+			closers = append(closers, client.Close)
+		}
+	}
+	if cfg.Redis.IsEnabled() {
+		client, err := cache.NewRedisClient(&cfg.Redis)
+		if err != nil {
+			log.Warn("failed to connect redis for admin stats", zap.Error(err))
+		} else {
+			sources = append(sources, health.NamedStats("redis", client))
+			closers = append(closers, client.Close)
+		}
+	}
+
+	return sources, func() {
+		for _, closeFn := range closers {
+			if err := closeFn(); err != nil {
+				log.Warn("failed to close stats source", zap.Error(err))
+			}
+		}
+	}
+}
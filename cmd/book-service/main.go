@@ -1,15 +1,22 @@
 package main
 
 import (
+	"context"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/alfredchaos/demo/internal/book-service/conf"
 	"github.com/alfredchaos/demo/internal/book-service/dependencies"
 	"github.com/alfredchaos/demo/internal/book-service/server"
+	"github.com/alfredchaos/demo/internal/clients"
+	"github.com/alfredchaos/demo/pkg/buildinfo"
 	"github.com/alfredchaos/demo/pkg/config"
+	"github.com/alfredchaos/demo/pkg/db"
+	"github.com/alfredchaos/demo/pkg/debugserver"
 	"github.com/alfredchaos/demo/pkg/grpcclient"
+	"github.com/alfredchaos/demo/pkg/health"
 	"github.com/alfredchaos/demo/pkg/log"
 	"go.uber.org/zap"
 )
@@ -21,38 +28,78 @@ func main() {
 	log.MustInitLogger(&cfg.Log, cfg.Server.Name)
 	defer log.Sync()
 
-	log.Info("starting book-service",
+	buildinfo.LogStartup("book-service",
 		zap.String("name", cfg.Server.Name),
 		zap.String("addr", cfg.Server.GetAddr()))
 
 	// 初始化 gRPC 客户端管理器
-	clientManager := grpcclient.InitGRPCClientManager(&cfg.GRPCClients)
+	clientManager := grpcclient.InitGRPCClientManager(&cfg.GRPCClients, clients.Factories())
 	defer func() {
 		if err := clientManager.Close(); err != nil {
 			log.Error("failed to close grpc client manager", zap.Error(err))
 		}
 	}()
 
+	// 探针服务先于依赖连接启动，连接期间 /readyz 返回 503
+	readiness := cfg.Probe.NewReadiness()
+	probeServer, err := health.StartProbeServer(&cfg.Probe, readiness)
+	if err != nil {
+		log.Fatal("failed to start probe server", zap.Error(err))
+	}
+	startupCtx, stopWatch := context.WithCancel(context.Background())
+	defer stopWatch()
+	go readiness.WatchStartup(startupCtx, 5*time.Second)
+
 	// 依赖注入
 	deps := &dependencies.Dependencies{
 		ClientManager: clientManager,
 		Cfg:           &cfg,
+		Readiness:     readiness,
 	}
 	appCtx, err := dependencies.InjectDependencies(deps)
 	if err != nil {
+		// os.Exit 不会执行 defer，先手动释放已启动的资源，再以非零状态退出
 		log.Error("failed to inject dependencies", zap.Error(err))
-		return
+		stopWatch()
+		if err := probeServer.Stop(context.Background()); err != nil {
+			log.Error("failed to stop probe server", zap.Error(err))
+		}
+		if err := clientManager.Close(); err != nil {
+			log.Error("failed to close grpc client manager", zap.Error(err))
+		}
+		log.Sync()
+		os.Exit(1)
 	}
 	log.Info("dependencies injected successfully")
 
+	// 周期性上报图书数量指标
+	reportCtx, stopReport := context.WithCancel(context.Background())
+	defer stopReport()
+	if appCtx.Data.BookRepo != nil && cfg.Database.CountReportInterval > 0 {
+		interval := time.Duration(cfg.Database.CountReportInterval) * time.Second
+		go db.ReportRowCount(reportCtx, "books", interval, appCtx.Data.BookRepo.Count)
+	}
+
 	grpcServer := server.NewGRPCServerBuilder(&cfg.Server).
+		WithMiddleware(cfg.Middleware).
 		WithBookService(appCtx.BookService).Build()
 	log.Info("grpc server initialized")
+	// 端口绑定成功后才标记就绪，避免 /readyz 先于 gRPC 端口可用返回 200
+	if err := grpcServer.Listen(); err != nil {
+		log.Fatal("failed to listen grpc server", zap.Error(err))
+	}
 	go func() {
 		if err := grpcServer.Start(); err != nil {
 			log.Fatal("failed to start grpc server", zap.Error(err))
 		}
 	}()
+	readiness.Complete()
+
+	// 调试服务（pprof），默认关闭
+	debugServer, err := debugserver.Start(&cfg.Debug)
+	if err != nil {
+		log.Fatal("failed to start debug server", zap.Error(err))
+	}
 
 	// ============================================================
 	// 优雅关闭
@@ -61,7 +108,20 @@ func main() {
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 
-	log.Info("shutting down user-service...")
+	log.Info("shutting down book-service...")
+
+	// 先让 /readyz 返回 503 并等待负载均衡摘除实例，再停止接收请求
+	readiness.Drain(cfg.Probe.DrainDelay)
+
+	stopCtx, cancelStop := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancelStop()
+	if err := debugServer.Stop(stopCtx); err != nil {
+		log.Error("failed to stop debug server", zap.Error(err))
+	}
+	if err := probeServer.Stop(stopCtx); err != nil {
+		log.Error("failed to stop probe server", zap.Error(err))
+	}
+
 	grpcServer.Stop()
 	log.Info("user-service stopped gracefully")
 }
@@ -0,0 +1,29 @@
+package main
+
+import "github.com/alfredchaos/demo/migrations"
+
+// 进程退出码
+// 默认只区分成功与失败；-detailed-exit-code 时区分是否执行了迁移，-fail-if-pending 时 status 发现待执行迁移返回 exitPending
+const (
+	exitOK       = 0 // 执行成功（-detailed-exit-code 时表示执行了迁移）
+	exitError    = 1 // 执行失败
+	exitNoChange = 2 // 没有需要执行的迁移，仅 -detailed-exit-code 时使用
+	exitPending  = 3 // status 发现待执行的迁移，仅 -fail-if-pending 时使用
+)
+
+// exitCode 根据执行结果分类返回进程退出码
+func exitCode(outcome string, detailed, failIfPending bool) int {
+	switch outcome {
+	case migrations.OutcomeFailed:
+		return exitError
+	case migrations.OutcomePending:
+		if failIfPending {
+			return exitPending
+		}
+	case migrations.OutcomeNoChange:
+		if detailed {
+			return exitNoChange
+		}
+	}
+	return exitOK
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/alfredchaos/demo/migrations"
+	"github.com/pressly/goose/v3"
+)
+
+func TestExitCode(t *testing.T) {
+	applied := &migrations.Result{Applied: []*goose.MigrationResult{{Direction: "up"}}}
+	upToDate := &migrations.Result{Status: []*goose.MigrationStatus{{State: goose.StateApplied}}}
+	pending := &migrations.Result{Status: []*goose.MigrationStatus{{State: goose.StateApplied}, {State: goose.StatePending}}}
+
+	cases := []struct {
+		name          string
+		result        *migrations.Result
+		err           error
+		detailed      bool
+		failIfPending bool
+		want          int
+	}{
+		{"applied", applied, nil, false, false, exitOK},
+		{"applied detailed", applied, nil, true, false, exitOK},
+		{"up to date", &migrations.Result{}, nil, false, false, exitOK},
+		{"up to date detailed", &migrations.Result{}, nil, true, false, exitNoChange},
+		{"error", nil, errors.New("connection refused"), false, false, exitError},
+		{"partial failure detailed", applied, errors.New("syntax error"), true, false, exitError},
+		{"status pending", pending, nil, false, false, exitOK},
+		{"status pending fail-if-pending", pending, nil, false, true, exitPending},
+		{"status pending fail-if-pending detailed", pending, nil, true, true, exitPending},
+		{"status up to date fail-if-pending", upToDate, nil, false, true, exitOK},
+		{"status up to date fail-if-pending detailed", upToDate, nil, true, true, exitNoChange},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := exitCode(migrations.Outcome(c.result, c.err), c.detailed, c.failIfPending); got != c.want {
+				t.Fatalf("want exit code %d, got %d", c.want, got)
+			}
+		})
+	}
+}
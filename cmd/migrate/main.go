@@ -1,9 +1,12 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"os"
+	"path/filepath"
+	"time"
 
 	"github.com/alfredchaos/demo/internal/user-service/conf"
 	"github.com/alfredchaos/demo/migrations"
@@ -19,25 +22,66 @@ func main() {
 		command = flag.String("cmd", "up", "Migration command: up, up-to, down, down-to, status, version, reset")
 		version = flag.Int64("version", 0, "Target version (for up-to/down-to commands)")
 		cfgPath = flag.String("config", "configs/user-service.yaml", "Configuration file path")
+		name    = flag.String("name", migrations.SharedDB, "Registered migrations name")
+		output  = flag.String("output", "text", "Output format: text, json (json prints a run summary to stdout)")
+
+		detailedExitCode = flag.Bool("detailed-exit-code", false, "Exit 0 when migrations were applied, 2 when there was nothing to migrate, 1 on error")
+		failIfPending    = flag.Bool("fail-if-pending", false, "With -cmd=status, exit 3 when there are pending migrations")
 	)
 	flag.Parse()
 
+	if *output != "text" && *output != "json" {
+		fmt.Fprintf(os.Stderr, "Unknown output format: %s\n", *output)
+		os.Exit(exitError)
+	}
+	if *failIfPending && *command != migrations.ActionStatus {
+		fmt.Fprintln(os.Stderr, "-fail-if-pending can only be used with -cmd=status")
+		os.Exit(exitError)
+	}
+
 	// 加载配置
 	var cfg conf.Config
 	if err := config.LoadConfigFromPath(*cfgPath, &cfg); err != nil {
-		fmt.Printf("Failed to load config: %v\n", err)
-		os.Exit(1)
+		fmt.Fprintf(os.Stderr, "Failed to load config: %v\n", err)
+		os.Exit(exitError)
+	}
+
+	// json 模式下标准输出只保留执行摘要，日志改写到标准错误
+	if *output == "json" {
+		cfg.Log.OutputPaths = []string{"stderr"}
+		cfg.Log.EnableConsoleWriter = false
 	}
 
 	// 初始化日志
 	log.MustInitLogger(&cfg.Log, cfg.Server.Name)
 	defer log.Sync()
 
-	log.Info("Starting database migration", zap.String("command", *command))
+	log.Info("Starting database migration", zap.String("command", *command), zap.String("name", *name))
+
+	migrateCmd := migrations.Command{Action: *command, Version: *version}
+	start := time.Now()
+	// writeSummary json 模式下向标准输出写入执行摘要，执行迁移之前失败时同样输出失败摘要
+	writeSummary := func(result *migrations.Result, err error) {
+		if *output != "json" {
+			return
+		}
+		summary := migrations.NewSummary(*name, migrateCmd, result, err, time.Since(start))
+		if werr := summary.WriteJSON(os.Stdout); werr != nil {
+			log.Error("Failed to write migration summary", zap.Error(werr))
+		}
+	}
+
+	// up-to / down-to 必须指定目标版本
+	if (*command == migrations.ActionUpTo || *command == migrations.ActionDownTo) && *version == 0 {
+		err := fmt.Errorf("%s command requires -version parameter", *command)
+		writeSummary(nil, err)
+		log.Fatal("Invalid migration command", zap.Error(err))
+	}
 
 	// 创建数据库客户端（不执行迁移）
 	client, err := db.NewPostgresClient(&cfg.Database)
 	if err != nil {
+		writeSummary(nil, fmt.Errorf("failed to create database client: %w", err))
 		log.Fatal("Failed to create database client", zap.Error(err))
 	}
 	defer client.Close()
@@ -45,64 +89,39 @@ func main() {
 	// 获取底层的 sql.DB 对象
 	sqlDB, err := client.GetDB().DB()
 	if err != nil {
+		writeSummary(nil, fmt.Errorf("failed to get sql.DB: %w", err))
 		log.Fatal("Failed to get sql.DB", zap.Error(err))
 	}
 
-	// 执行迁移命令
-	switch *command {
-	case "up":
-		if err := migrations.MigrateUp(sqlDB); err != nil {
-			log.Fatal("Failed to execute migration", zap.Error(err))
-		}
-		log.Info("Migration completed successfully")
-
-	case "down":
-		if err := migrations.MigrateDown(sqlDB); err != nil {
-			log.Fatal("Failed to rollback migration", zap.Error(err))
-		}
-		log.Info("Rollback completed successfully")
-
-	case "status":
-		if err := migrations.MigrateStatus(sqlDB); err != nil {
-			log.Fatal("Failed to query migration status", zap.Error(err))
-		}
-
-	case "up-to":
-		if *version == 0 {
-			log.Fatal("up-to command requires -version parameter")
-		}
-		if err := migrations.MigrateUpTo(sqlDB, *version); err != nil {
-			log.Fatal("Failed to migrate up to version", zap.Error(err))
-		}
-		log.Info("Migrated up to version successfully", zap.Int64("version", *version))
+	if *command == migrations.ActionReset {
+		log.Warn("WARNING: About to reset database (will delete all data)")
+	}
 
-	case "down-to":
-		if *version == 0 {
-			log.Fatal("down-to command requires -version parameter")
-		}
-		if err := migrations.MigrateDownTo(sqlDB, *version); err != nil {
-			log.Fatal("Failed to migrate down to version", zap.Error(err))
+	// 执行迁移命令
+	result, err := migrations.Migrate(context.Background(), sqlDB, *name, migrateCmd)
+	writeSummary(result, err)
+	if result != nil {
+		for _, applied := range result.Applied {
+			log.Info("Migration applied", zap.String("result", applied.String()))
 		}
-		log.Info("Migrated down to version successfully", zap.Int64("version", *version))
-
-	case "version":
-		// 查询当前版本
-		currentVersion, err := migrations.GetCurrentVersion(sqlDB)
-		if err != nil {
-			log.Fatal("Failed to get current version", zap.Error(err))
+		for _, status := range result.Status {
+			log.Info("Migration status",
+				zap.String("migration", filepath.Base(status.Source.Path)),
+				zap.String("state", string(status.State)),
+				zap.Time("applied_at", status.AppliedAt))
 		}
-		log.Info("Current database version", zap.Int64("version", currentVersion))
+	}
+	if err != nil {
+		log.Fatal("Failed to execute migration", zap.Error(err))
+	}
+	log.Info("Current database version", zap.Int64("version", result.Version))
 
-	case "reset":
-		log.Warn("WARNING: About to reset database (will delete all data)")
-		if err := migrations.MigrateReset(sqlDB); err != nil {
-			log.Fatal("Failed to reset database", zap.Error(err))
-		}
-		log.Info("Database reset successfully")
+	outcome := migrations.Outcome(result, nil)
+	log.Info("Migration operation completed", zap.String("outcome", outcome), zap.Int("pending", result.Pending()))
 
-	default:
-		log.Fatal(fmt.Sprintf("Unknown command: %s", *command))
+	if code := exitCode(outcome, *detailedExitCode, *failIfPending); code != exitOK {
+		log.Sync()
+		client.Close()
+		os.Exit(code)
 	}
-
-	log.Info("Migration operation completed")
 }
@@ -5,23 +5,21 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
+	"github.com/alfredchaos/demo/internal/clients"
 	"github.com/alfredchaos/demo/internal/nice-service/conf"
 	"github.com/alfredchaos/demo/internal/nice-service/dependencies"
 	// "github.com/alfredchaos/demo/internal/nice-service/server"
+	"github.com/alfredchaos/demo/pkg/buildinfo"
 	"github.com/alfredchaos/demo/pkg/config"
+	"github.com/alfredchaos/demo/pkg/debugserver"
 	"github.com/alfredchaos/demo/pkg/grpcclient"
+	"github.com/alfredchaos/demo/pkg/health"
 	"github.com/alfredchaos/demo/pkg/log"
 	"go.uber.org/zap"
 )
 
-// func init() {
-// 	// 注册 gRPC 客户端工厂
-// 	grpcclient.GlobalRegistry.Register("user-service", func(conn *grpc.ClientConn) interface{} {
-// 		return userv1.NewUserServiceClient(conn)
-// 	})
-// }
-
 func main() {
 	var cfg conf.Config
 	config.MustLoadConfig("nice-service", &cfg)
@@ -29,32 +27,59 @@ func main() {
 	log.MustInitLogger(&cfg.Log, cfg.Server.Name)
 	defer log.Sync()
 
-	log.Info("starting nice-service", zap.String("name", cfg.Server.Name))
+	buildinfo.LogStartup("nice-service", zap.String("name", cfg.Server.Name))
 
 	// 初始化 gRPC 客户端管理器（未来可能需要调用其他服务）
-	clientManager := grpcclient.InitGRPCClientManager(&cfg.GRPCClients)
+	clientManager := grpcclient.InitGRPCClientManager(&cfg.GRPCClients, clients.Factories())
 	defer func() {
 		if err := clientManager.Close(); err != nil {
 			log.Error("failed to close grpc client manager", zap.Error(err))
 		}
 	}()
 
+	// 探针服务先于依赖连接启动，连接期间 /readyz 返回 503
+	readiness := cfg.Probe.NewReadiness()
+	probeServer, err := health.StartProbeServer(&cfg.Probe, readiness)
+	if err != nil {
+		log.Fatal("failed to start probe server", zap.Error(err))
+	}
+	startupCtx, stopWatch := context.WithCancel(context.Background())
+	defer stopWatch()
+	go readiness.WatchStartup(startupCtx, 5*time.Second)
+
 	// 依赖注入
 	deps := &dependencies.Dependencies{
 		ClientManager: clientManager,
 		Cfg:           &cfg,
+		Readiness:     readiness,
 	}
 	appCtx, err := dependencies.InjectDependencies(deps)
 	if err != nil {
+		// os.Exit 不会执行 defer，先手动释放已启动的资源，再以非零状态退出
 		log.Error("failed to inject dependencies", zap.Error(err))
-		return
+		stopWatch()
+		if err := probeServer.Stop(context.Background()); err != nil {
+			log.Error("failed to stop probe server", zap.Error(err))
+		}
+		if err := clientManager.Close(); err != nil {
+			log.Error("failed to close grpc client manager", zap.Error(err))
+		}
+		log.Sync()
+		os.Exit(1)
 	}
 	log.Info("dependencies injected successfully")
 
+	// 调试服务（pprof），默认关闭
+	debugServer, err := debugserver.Start(&cfg.Debug)
+	if err != nil {
+		log.Fatal("failed to start debug server", zap.Error(err))
+	}
+
 	// ============================================================
 	// gRPC 服务器（暂时注释，未来可能需要同时支持同步和异步通信）
 	// ============================================================
 	// grpcServer := server.NewGRPCServerBuilder(&cfg.Server).
+	// 	WithMiddleware(cfg.Middleware).
 	// 	WithNiceService(appCtx.NiceService).Build()
 	// log.Info("grpc server initialized")
 	// go func() {
@@ -66,22 +91,25 @@ func main() {
 	// ============================================================
 	// RabbitMQ 消费者启动
 	// ============================================================
-	if appCtx.Consumer != nil && appCtx.HandleService != nil {
-		ctx, cancel := context.WithCancel(context.Background())
-		defer cancel()
+	// 取消 consumeCtx 即停止拉取新消息，处理中的消息在 drain_timeout 内继续完成
+	consumeCtx, stopConsuming := context.WithCancel(context.Background())
+	defer stopConsuming()
 
+	if appCtx.Consumer != nil && appCtx.HandleService != nil {
 		// 启动消费者
 		go func() {
 			log.Info("starting rabbitmq consumer",
 				zap.String("queue", cfg.RabbitMQ.Queue),
-				zap.String("routing_key", cfg.RabbitMQ.RoutingKey))
+				zap.String("routing_key", cfg.RabbitMQ.RoutingKey),
+				zap.Bool("auto_ack", cfg.RabbitMQ.AutoAck))
 
-			// 使用 HandleService.HandleMessage 作为消息处理器
-			if err := appCtx.Consumer.Consume(ctx, appCtx.HandleService.HandleMessage); err != nil {
+			// 按路由键分发到 HandleService 中注册的处理器，确认模式由 rabbitmq.auto_ack 决定
+			if err := appCtx.Consumer.ConsumeDeliveries(consumeCtx, appCtx.HandleService.Dispatcher().Dispatch); err != nil {
 				log.Error("consumer stopped with error", zap.Error(err))
 			}
 		}()
 		log.Info("rabbitmq consumer started successfully")
+		readiness.Complete()
 	} else {
 		log.Warn("consumer or handle service is not initialized, skipping consumer startup")
 	}
@@ -95,12 +123,25 @@ func main() {
 
 	log.Info("shutting down nice-service...")
 
-	// 关闭消费者
+	// 先让 /readyz 返回 503 并等待，再停止拉取新消息
+	readiness.Drain(cfg.Probe.DrainDelay)
+
+	stopCtx, cancelStop := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancelStop()
+	if err := debugServer.Stop(stopCtx); err != nil {
+		log.Error("failed to stop debug server", zap.Error(err))
+	}
+	if err := probeServer.Stop(stopCtx); err != nil {
+		log.Error("failed to stop probe server", zap.Error(err))
+	}
+
+	// 停止拉取新消息，等待处理中的消息排空后关闭消费者
+	stopConsuming()
 	if appCtx.Consumer != nil {
 		if err := appCtx.Consumer.Close(); err != nil {
 			log.Error("failed to close consumer", zap.Error(err))
 		} else {
-			log.Info("consumer closed successfully")
+			log.Info("consumer drained and closed successfully")
 		}
 	}
 
@@ -113,6 +154,12 @@ func main() {
 		}
 	}
 
+	if appCtx.Redis != nil {
+		if err := appCtx.Redis.Close(); err != nil {
+			log.Error("failed to close redis client", zap.Error(err))
+		}
+	}
+
 	// 未来如果启用 gRPC 服务器
 	// grpcServer.Stop()
 
@@ -0,0 +1,38 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/alfredchaos/demo/internal/api-gateway/dto"
+	"github.com/alfredchaos/demo/pkg/featureflag"
+	"github.com/gin-gonic/gin"
+)
+
+// IFeatureFlagController 功能开关控制器接口
+type IFeatureFlagController interface {
+	List(c *gin.Context)
+}
+
+// featureFlagController 功能开关控制器实现
+// 只读查看网关加载的功能开关；开关由各服务从自身配置加载，网关无法修改 user-service 等后端的开关，因此不提供运行时修改接口
+type featureFlagController struct {
+	flags *featureflag.Flags
+}
+
+// NewFeatureFlagController 创建功能开关控制器
+func NewFeatureFlagController(flags *featureflag.Flags) IFeatureFlagController {
+	return &featureFlagController{
+		flags: flags,
+	}
+}
+
+// List 获取所有功能开关
+// @Summary 功能开关列表
+// @Description 返回网关当前的功能开关状态
+// @Tags Admin
+// @Produce json
+// @Success 200 {object} dto.Response{data=map[string]bool} "成功响应"
+// @Router /api/v1/admin/flags [get]
+func (ctrl *featureFlagController) List(c *gin.Context) {
+	c.JSON(http.StatusOK, dto.NewSuccessResponse(ctrl.flags.All()))
+}
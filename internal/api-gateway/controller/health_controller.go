@@ -0,0 +1,80 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/alfredchaos/demo/internal/api-gateway/dto"
+	"github.com/alfredchaos/demo/pkg/grpcclient"
+	"github.com/gin-gonic/gin"
+)
+
+// 健康状态
+const (
+	HealthStatusOK        = "ok"        // 所有下游可用
+	HealthStatusDegraded  = "degraded"  // 非关键下游熔断，网关仍可部分服务
+	HealthStatusUnhealthy = "unhealthy" // 关键下游熔断，网关无法正常服务
+)
+
+// BreakerStateSource 熔断状态来源，由 grpcclient.Manager 实现
+type BreakerStateSource interface {
+	BreakerStates() map[string]grpcclient.BreakerState
+}
+
+// IHealthController 健康检查控制器接口
+type IHealthController interface {
+	Health(c *gin.Context)
+}
+
+// healthController 健康检查控制器实现
+// 根据下游 gRPC 客户端的熔断状态判断网关是否可用
+type healthController struct {
+	breakers BreakerStateSource
+	critical map[string]bool
+}
+
+// NewHealthController 创建健康检查控制器
+// criticalServices 为关键下游，其熔断时返回 unhealthy；其余下游熔断只标记 degraded
+func NewHealthController(breakers BreakerStateSource, criticalServices []string) IHealthController {
+	critical := make(map[string]bool, len(criticalServices))
+	for _, name := range criticalServices {
+		critical[name] = true
+	}
+	return &healthController{
+		breakers: breakers,
+		critical: critical,
+	}
+}
+
+// Health 健康检查
+// @Summary 健康检查
+// @Description 返回网关及下游熔断状态；关键下游熔断时返回 503，非关键下游熔断时返回 degraded
+// @Tags System
+// @Produce json
+// @Success 200 {object} dto.HealthResponse "健康或降级"
+// @Failure 503 {object} dto.HealthResponse "关键下游不可用"
+// @Router /health [get]
+func (ctrl *healthController) Health(c *gin.Context) {
+	resp := dto.HealthResponse{Status: HealthStatusOK}
+	httpStatus := http.StatusOK
+
+	states := ctrl.breakers.BreakerStates()
+	if len(states) > 0 {
+		resp.Dependencies = make(map[string]string, len(states))
+	}
+	for serviceName, state := range states {
+		resp.Dependencies[serviceName] = state.String()
+		if state == grpcclient.BreakerClosed {
+			continue
+		}
+
+		// 半开状态下探测尚未成功，同样视为不可用
+		if ctrl.critical[serviceName] {
+			resp.Status = HealthStatusUnhealthy
+			httpStatus = http.StatusServiceUnavailable
+		} else if resp.Status == HealthStatusOK {
+			resp.Status = HealthStatusDegraded
+		}
+	}
+
+	c.JSON(httpStatus, resp)
+}
@@ -0,0 +1,58 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/alfredchaos/demo/internal/api-gateway/dto"
+	"github.com/alfredchaos/demo/pkg/grpcclient"
+	"github.com/gin-gonic/gin"
+)
+
+// fakeBreakers 返回固定熔断状态
+type fakeBreakers map[string]grpcclient.BreakerState
+
+func (f fakeBreakers) BreakerStates() map[string]grpcclient.BreakerState { return f }
+
+func TestHealth_ReflectsBreakerState(t *testing.T) {
+	cases := []struct {
+		name       string
+		states     fakeBreakers
+		wantCode   int
+		wantStatus string
+	}{
+		{"all closed", fakeBreakers{"user-service": grpcclient.BreakerClosed, "book-service": grpcclient.BreakerClosed}, http.StatusOK, HealthStatusOK},
+		{"no breakers", fakeBreakers{}, http.StatusOK, HealthStatusOK},
+		{"non-critical open", fakeBreakers{"user-service": grpcclient.BreakerClosed, "book-service": grpcclient.BreakerOpen}, http.StatusOK, HealthStatusDegraded},
+		{"non-critical half open", fakeBreakers{"book-service": grpcclient.BreakerHalfOpen}, http.StatusOK, HealthStatusDegraded},
+		{"critical open", fakeBreakers{"user-service": grpcclient.BreakerOpen, "book-service": grpcclient.BreakerOpen}, http.StatusServiceUnavailable, HealthStatusUnhealthy},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			router := gin.New()
+			router.GET("/health", NewHealthController(c.states, []string{"user-service"}).Health)
+
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health", nil))
+
+			if rec.Code != c.wantCode {
+				t.Fatalf("want %d, got %d", c.wantCode, rec.Code)
+			}
+			var body dto.HealthResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+				t.Fatalf("decode body: %v", err)
+			}
+			if body.Status != c.wantStatus {
+				t.Fatalf("want status %q, got %q", c.wantStatus, body.Status)
+			}
+			for name, state := range c.states {
+				if body.Dependencies[name] != state.String() {
+					t.Fatalf("want %s=%s, got %v", name, state, body.Dependencies)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/alfredchaos/demo/internal/api-gateway/domain"
+	"github.com/alfredchaos/demo/internal/api-gateway/dto"
+	"github.com/alfredchaos/demo/pkg/log"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// IHelloController 聚合问候控制器接口
+type IHelloController interface {
+	Hello(c *gin.Context)
+}
+
+// helloController 聚合问候控制器实现
+// 并发调用 user-service 和 book-service 并合并结果
+type helloController struct {
+	userService   domain.IUserService
+	bookService   domain.IBookService
+	allowDegraded bool
+}
+
+// NewHelloController 创建聚合问候控制器
+// allowDegraded: 一个后端失败时是否返回降级的部分响应，false 时任一后端失败即返回 500
+func NewHelloController(userService domain.IUserService, bookService domain.IBookService, allowDegraded bool) IHelloController {
+	return &helloController{
+		userService:   userService,
+		bookService:   bookService,
+		allowDegraded: allowDegraded,
+	}
+}
+
+// Hello 处理聚合问候请求
+// @Summary 聚合问候接口
+// @Description 并发调用 user-service 和 book-service；开启降级时单个后端失败仍返回可用部分并标记 degraded
+// @Tags Hello
+// @Accept json
+// @Produce json
+// @Param name query string true "问候对象的名称，最长 64 个字符"
+// @Success 200 {object} dto.Response{data=dto.AggregatedHelloResponse} "成功响应（可能为降级响应）"
+// @Failure 400 {object} dto.Response "参数错误"
+// @Failure 500 {object} dto.Response "服务器错误（状态码按下游 gRPC 错误映射）"
+// @Router /api/v1/hello [get]
+func (ctrl *helloController) Hello(c *gin.Context) {
+	ctx := c.Request.Context()
+	name := c.Query("name")
+
+	// 未开启降级时任一后端失败即整体失败，errgroup 在首个错误时取消另一个调用，避免继续占用下游资源；
+	// 开启降级时需要保留另一个后端的结果，因此失败不会取消兄弟调用
+	var (
+		g                        *errgroup.Group
+		callCtx                  = ctx
+		userMessage, bookMessage string
+		userErr, bookErr         error
+	)
+	if ctrl.allowDegraded {
+		g = &errgroup.Group{}
+	} else {
+		g, callCtx = errgroup.WithContext(ctx)
+	}
+
+	g.Go(func() error {
+		userMessage, userErr = ctrl.userService.SayHello(callCtx, name)
+		return userErr
+	})
+	g.Go(func() error {
+		bookMessage, bookErr = ctrl.bookService.JustTellMe(callCtx)
+		return bookErr
+	})
+	// firstErr 为最先失败的调用返回的错误，被取消的兄弟调用的错误不会覆盖它
+	firstErr := g.Wait()
+
+	resp := dto.AggregatedHelloResponse{
+		UserMessage: userMessage,
+		BookMessage: bookMessage,
+	}
+	if userErr != nil {
+		resp.Unavailable = append(resp.Unavailable, "user-service")
+	}
+	if bookErr != nil {
+		resp.Unavailable = append(resp.Unavailable, "book-service")
+	}
+
+	switch {
+	case status.Code(userErr) == codes.InvalidArgument:
+		// 参数错误是调用方的问题，不视为后端不可用，也不降级
+		httpStatus, body := dto.FromGRPCError(userErr)
+		c.JSON(httpStatus, body)
+
+	case len(resp.Unavailable) == 0:
+		c.JSON(http.StatusOK, dto.NewSuccessResponse(resp))
+
+	case ctrl.allowDegraded && len(resp.Unavailable) == 1:
+		resp.Degraded = true
+		log.WithContext(ctx).Warn("hello request degraded",
+			zap.Strings("unavailable", resp.Unavailable),
+			zap.NamedError("user_error", userErr),
+			zap.NamedError("book_error", bookErr))
+		c.JSON(http.StatusOK, dto.NewSuccessResponse(resp))
+
+	default:
+		log.WithContext(ctx).Error("hello request failed",
+			zap.Strings("unavailable", resp.Unavailable),
+			zap.NamedError("user_error", userErr),
+			zap.NamedError("book_error", bookErr))
+		// 返回最先失败的后端的 gRPC 状态对应的 HTTP 响应；
+		// 开启降级时两个调用互不取消，两个错误均为真实失败，按固定顺序选择以保证响应稳定
+		err := firstErr
+		if ctrl.allowDegraded {
+			err = userErr
+			if err == nil {
+				err = bookErr
+			}
+		}
+		httpStatus, body := dto.FromGRPCError(err)
+		c.JSON(httpStatus, body)
+	}
+}
@@ -0,0 +1,213 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alfredchaos/demo/internal/api-gateway/domain"
+	"github.com/alfredchaos/demo/internal/api-gateway/dto"
+	"github.com/alfredchaos/demo/pkg/log"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// useNopLogger 测试期间使用空日志，结束后恢复原日志
+func useNopLogger(t *testing.T) {
+	t.Helper()
+	original := log.Logger
+	log.Logger = zap.NewNop()
+	t.Cleanup(func() { log.Logger = original })
+}
+
+// fakeUserService 返回固定问候语的用户服务
+type fakeUserService struct{}
+
+func (fakeUserService) SayHello(ctx context.Context, name string) (string, error) {
+	return "hello from users", nil
+}
+
+func (fakeUserService) CountUsers(ctx context.Context) (int64, error) { return 0, nil }
+
+func (fakeUserService) ListUsers(ctx context.Context, cursor string, pageSize int) ([]*domain.User, string, error) {
+	return nil, "", nil
+}
+
+// failingBookService 始终失败的图书服务
+type failingBookService struct{}
+
+func (failingBookService) JustTellMe(ctx context.Context) (string, error) {
+	return "", errors.New("book-service unavailable")
+}
+
+// fakeBookService 返回固定消息的图书服务
+type fakeBookService struct{}
+
+func (fakeBookService) JustTellMe(ctx context.Context) (string, error) {
+	return "hello from books", nil
+}
+
+// serveHello 调用聚合问候接口
+func serveHello(t *testing.T, ctrl IHelloController) *httptest.ResponseRecorder {
+	t.Helper()
+	router := gin.New()
+	router.GET("/api/v1/hello", ctrl.Hello)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/hello", nil))
+	return rec
+}
+
+func TestHello_DegradesWhenBookServiceFails(t *testing.T) {
+	useNopLogger(t)
+	rec := serveHello(t, NewHelloController(fakeUserService{}, failingBookService{}, true))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("want 200, got %d", rec.Code)
+	}
+
+	var body struct {
+		Code int                         `json:"code"`
+		Data dto.AggregatedHelloResponse `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if !body.Data.Degraded {
+		t.Fatalf("want degraded flag, got %+v", body.Data)
+	}
+	if body.Data.UserMessage != "hello from users" {
+		t.Fatalf("want user-service part, got %+v", body.Data)
+	}
+	if len(body.Data.Unavailable) != 1 || body.Data.Unavailable[0] != "book-service" {
+		t.Fatalf("want book-service unavailable, got %v", body.Data.Unavailable)
+	}
+}
+
+func TestHello_FailsWhenDegradationDisabled(t *testing.T) {
+	useNopLogger(t)
+	rec := serveHello(t, NewHelloController(fakeUserService{}, failingBookService{}, false))
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("want 500, got %d", rec.Code)
+	}
+}
+
+// unavailableUserService 返回 gRPC Unavailable 的用户服务
+type unavailableUserService struct{ fakeUserService }
+
+func (unavailableUserService) SayHello(ctx context.Context, name string) (string, error) {
+	return "", fmt.Errorf("failed to call user service: %w", status.Error(codes.Unavailable, "user-service is down"))
+}
+
+func TestHello_MapsGRPCErrorWhenAllBackendsFail(t *testing.T) {
+	useNopLogger(t)
+	rec := serveHello(t, NewHelloController(unavailableUserService{}, failingBookService{}, true))
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("want 503, got %d", rec.Code)
+	}
+
+	var body dto.Response
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if body.Message != "user-service is down" {
+		t.Fatalf("want original gRPC message, got %q", body.Message)
+	}
+}
+
+// invalidNameUserService 校验失败返回 gRPC InvalidArgument 的用户服务
+type invalidNameUserService struct{ fakeUserService }
+
+func (invalidNameUserService) SayHello(ctx context.Context, name string) (string, error) {
+	return "", fmt.Errorf("failed to call user service: %w", status.Error(codes.InvalidArgument, "name is required"))
+}
+
+func TestHello_InvalidNameIsNotDegraded(t *testing.T) {
+	useNopLogger(t)
+	rec := serveHello(t, NewHelloController(invalidNameUserService{}, fakeBookService{}, true))
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("want 400, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+// blockingUserService 阻塞到上下文取消的用户服务，记录是否被取消
+type blockingUserService struct {
+	fakeUserService
+	cancelled chan struct{}
+}
+
+func (s *blockingUserService) SayHello(ctx context.Context, name string) (string, error) {
+	select {
+	case <-ctx.Done():
+		close(s.cancelled)
+		return "", ctx.Err()
+	case <-time.After(5 * time.Second):
+		return "hello from users", nil
+	}
+}
+
+func TestHello_FailureCancelsSiblingCall(t *testing.T) {
+	useNopLogger(t)
+	userService := &blockingUserService{cancelled: make(chan struct{})}
+	rec := serveHello(t, NewHelloController(userService, failingBookService{}, false))
+
+	select {
+	case <-userService.cancelled:
+	default:
+		t.Fatal("want user-service call cancelled after book-service failed")
+	}
+	// 响应取决于真正失败的 book-service，而不是被取消的 user-service
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("want 500, got %d", rec.Code)
+	}
+	var body dto.Response
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if body.Message == context.Canceled.Error() {
+		t.Fatalf("want root cause reported, got %q", body.Message)
+	}
+}
+
+// slowUserService 延迟返回的用户服务，返回时上下文已被取消则失败
+type slowUserService struct{ fakeUserService }
+
+func (slowUserService) SayHello(ctx context.Context, name string) (string, error) {
+	time.Sleep(20 * time.Millisecond)
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	return "hello from users", nil
+}
+
+func TestHello_DegradedDoesNotCancelSiblingCall(t *testing.T) {
+	useNopLogger(t)
+	rec := serveHello(t, NewHelloController(slowUserService{}, failingBookService{}, true))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("want 200, got %d", rec.Code)
+	}
+	var body struct {
+		Data dto.AggregatedHelloResponse `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if body.Data.UserMessage != "hello from users" {
+		t.Fatalf("want user-service call kept running after book-service failed, got %+v", body.Data)
+	}
+}
@@ -0,0 +1,39 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/alfredchaos/demo/internal/api-gateway/dto"
+	"github.com/alfredchaos/demo/pkg/health"
+	"github.com/gin-gonic/gin"
+)
+
+// IStatsController 运行状态统计控制器接口
+type IStatsController interface {
+	Stats(c *gin.Context)
+}
+
+// statsController 运行状态统计控制器实现
+// 汇总连接池统计与 goroutine、内存等运行时统计，供值班排查时快速查看，无需接入 pprof
+type statsController struct {
+	sources []health.StatsSource
+}
+
+// NewStatsController 创建运行状态统计控制器
+// sources 为需要汇总连接池统计的客户端（db.PostgresClient、cache.RedisClient、db.MongoClient 等）
+func NewStatsController(sources ...health.StatsSource) IStatsController {
+	return &statsController{
+		sources: sources,
+	}
+}
+
+// Stats 获取连接池及运行时统计
+// @Summary 运行状态统计
+// @Description 返回各连接池统计以及 goroutine 数、内存统计；会短暂暂停运行时，不要高频调用
+// @Tags Admin
+// @Produce json
+// @Success 200 {object} dto.Response{data=health.StatsSnapshot} "成功响应"
+// @Router /api/v1/admin/stats [get]
+func (ctrl *statsController) Stats(c *gin.Context) {
+	c.JSON(http.StatusOK, dto.NewSuccessResponse(health.CollectStats(ctrl.sources...)))
+}
@@ -0,0 +1,53 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/alfredchaos/demo/pkg/health"
+	"github.com/gin-gonic/gin"
+)
+
+// fakePool 返回固定连接池统计
+type fakePool map[string]interface{}
+
+func (f fakePool) Stats() (map[string]interface{}, error) { return f, nil }
+
+func TestStats_IncludesPoolAndRuntimeFields(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/admin/stats", NewStatsController(
+		health.NamedStats("postgres", fakePool{"open_connections": 4, "in_use": 2}),
+		health.NamedStats("redis", fakePool{"total_conns": 10, "idle_conns": 8}),
+	).Stats)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/stats", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("want 200, got %d", rec.Code)
+	}
+
+	var body struct {
+		Data struct {
+			Pools   map[string]map[string]float64 `json:"pools"`
+			Runtime map[string]interface{}        `json:"runtime"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+
+	if body.Data.Pools["postgres"]["in_use"] != 2 || body.Data.Pools["redis"]["total_conns"] != 10 {
+		t.Fatalf("want pool stats in response, got %v", body.Data.Pools)
+	}
+	for _, field := range []string{"goroutines", "heap_alloc", "sys", "num_gc"} {
+		if _, ok := body.Data.Runtime[field]; !ok {
+			t.Fatalf("want runtime field %q, got %v", field, body.Data.Runtime)
+		}
+	}
+	if goroutines, _ := body.Data.Runtime["goroutines"].(float64); goroutines <= 0 {
+		t.Fatalf("want positive goroutine count, got %v", body.Data.Runtime["goroutines"])
+	}
+}
@@ -2,10 +2,12 @@ package controller
 
 import (
 	"net/http"
+	"strconv"
 
 	"github.com/alfredchaos/demo/internal/api-gateway/domain"
 	"github.com/alfredchaos/demo/internal/api-gateway/dto"
 	"github.com/alfredchaos/demo/pkg/log"
+	"github.com/alfredchaos/demo/pkg/pagination"
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 )
@@ -13,8 +15,17 @@ import (
 // IUserController 用户控制器接口
 type IUserController interface {
 	SayHello(c *gin.Context)
+	CountUsers(c *gin.Context)
+	ListUsers(c *gin.Context)
 }
 
+const (
+	// defaultUserListLimit 未指定 limit 时最多返回的用户数
+	defaultUserListLimit = 1000
+	// maxUserListLimit 单次请求最多返回的用户数
+	maxUserListLimit = 100000
+)
+
 // userController 用户控制器实现
 type userController struct {
 	userService domain.IUserService
@@ -34,8 +45,10 @@ func NewUserController(userService domain.IUserService) IUserController {
 // @Tags User
 // @Accept json
 // @Produce json
+// @Param name query string true "问候对象的名称，最长 64 个字符"
 // @Success 200 {object} dto.Response{data=dto.HelloResponse} "成功响应"
-// @Failure 500 {object} dto.Response "服务器错误"
+// @Failure 400 {object} dto.Response "参数错误"
+// @Failure 500 {object} dto.Response "服务器错误（状态码按下游 gRPC 错误映射）"
 // @Router /api/v1/user/hello [get]
 func (ctrl *userController) SayHello(c *gin.Context) {
 	ctx := c.Request.Context()
@@ -43,11 +56,13 @@ func (ctrl *userController) SayHello(c *gin.Context) {
 	// 使用 WithContext 自动附加请求上下文信息
 	log.WithContext(ctx).Info("received user hello request")
 
-	// 调用用户服务
-	message, err := ctrl.userService.SayHello(ctx)
+	// 调用用户服务，name 由 user-service 校验
+	message, err := ctrl.userService.SayHello(ctx, c.Query("name"))
 	if err != nil {
 		log.WithContext(ctx).Error("failed to call user service", zap.Error(err))
-		c.JSON(http.StatusInternalServerError, dto.NewErrorResponse(10001, "failed to call user service"))
+		// 按 gRPC 状态映射，参数非法时返回 400
+		httpStatus, body := dto.FromGRPCError(err)
+		c.JSON(httpStatus, body)
 		return
 	}
 
@@ -58,3 +73,99 @@ func (ctrl *userController) SayHello(c *gin.Context) {
 		Message: message,
 	}))
 }
+
+// CountUsers 处理用户计数请求
+// @Summary 用户计数
+// @Description 返回用户总数，结果可能被短暂缓存或为估算值
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Success 200 {object} dto.Response{data=dto.CountResponse} "成功响应"
+// @Failure 500 {object} dto.Response "服务器错误"
+// @Router /api/v1/admin/users/count [get]
+func (ctrl *userController) CountUsers(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	count, err := ctrl.userService.CountUsers(ctx)
+	if err != nil {
+		log.WithContext(ctx).Error("failed to count users", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, dto.NewErrorResponse(10001, "failed to count users"))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.NewSuccessResponse(dto.CountResponse{
+		Count: count,
+	}))
+}
+
+// ListUsers 流式返回用户列表
+// @Summary 用户列表
+// @Description 按创建时间倒序返回用户。结果按页从 user-service 拉取并逐项写出，不会在网关中缓存完整列表；
+// @Description 开始输出后如果拉取失败，响应会被截断为不完整的 JSON，客户端应将解析失败视为错误
+// @Tags User
+// @Accept json
+// @Produce json
+// @Param limit query int false "最多返回的用户数，默认 1000，最大 100000"
+// @Param cursor query string false "起始游标，为空表示从头开始"
+// @Success 200 {object} dto.Response{data=dto.CursorPageResponse{items=[]dto.UserResponse}} "成功响应"
+// @Failure 400 {object} dto.Response "参数错误"
+// @Failure 500 {object} dto.Response "服务器错误"
+// @Router /api/v1/user/list [get]
+func (ctrl *userController) ListUsers(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	limit := defaultUserListLimit
+	if raw := c.Query("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 || n > maxUserListLimit {
+			c.JSON(http.StatusBadRequest, dto.NewErrorResponse(10002, "invalid limit"))
+			return
+		}
+		limit = n
+	}
+
+	stream := dto.NewListStreamWriter(c.Writer)
+	cursor := c.Query("cursor")
+	for remaining := limit; remaining > 0; {
+		pageSize := remaining
+		if pageSize > pagination.MaxPageSize {
+			pageSize = pagination.MaxPageSize
+		}
+
+		users, next, err := ctrl.userService.ListUsers(ctx, cursor, pageSize)
+		if err != nil {
+			log.WithContext(ctx).Error("failed to list users", zap.Error(err), zap.Int("written", stream.Count()))
+			if !stream.Started() {
+				c.JSON(http.StatusInternalServerError, dto.NewErrorResponse(10001, "failed to list users"))
+			}
+			// 已开始输出时无法再修改状态码，直接结束响应，客户端收到的是被截断的 JSON
+			return
+		}
+
+		if !stream.Started() {
+			c.Header("Content-Type", "application/json; charset=utf-8")
+			c.Status(http.StatusOK)
+		}
+		for _, user := range users {
+			if err := stream.Write(dto.UserResponse{
+				ID:       user.ID,
+				Username: user.Username,
+				Email:    user.Email,
+			}); err != nil {
+				log.WithContext(ctx).Warn("failed to write user list", zap.Error(err))
+				return
+			}
+		}
+		stream.Flush()
+
+		remaining -= len(users)
+		if next == "" || len(users) == 0 {
+			break
+		}
+		cursor = next
+	}
+
+	if err := stream.Close(); err != nil {
+		log.WithContext(ctx).Warn("failed to write user list", zap.Error(err))
+	}
+}
@@ -0,0 +1,289 @@
+package controller
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	userv1 "github.com/alfredchaos/demo/api/user/v1"
+	"github.com/alfredchaos/demo/internal/api-gateway/domain"
+	"github.com/alfredchaos/demo/internal/api-gateway/dto"
+	"github.com/alfredchaos/demo/internal/api-gateway/service"
+	"github.com/alfredchaos/demo/pkg/middleware"
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// pagedUserService 按页返回用户的用户服务
+// 第一页之后的页需要等待 release 关闭才返回，用于验证响应在全部数据加载前已开始输出
+type pagedUserService struct {
+	fakeUserService
+	total   int
+	release chan struct{}
+	failAt  int // 第几页（从 1 开始）返回错误，0 表示不失败
+}
+
+func (s *pagedUserService) ListUsers(ctx context.Context, cursor string, pageSize int) ([]*domain.User, string, error) {
+	start := 0
+	if cursor != "" {
+		fmt.Sscanf(cursor, "%d", &start)
+	}
+	page := start/pageSize + 1
+
+	if page > 1 && s.release != nil {
+		select {
+		case <-s.release:
+		case <-time.After(5 * time.Second):
+			return nil, "", errors.New("page was requested before the first page was streamed")
+		}
+	}
+	if page == s.failAt {
+		return nil, "", errors.New("user-service unavailable")
+	}
+
+	var users []*domain.User
+	for i := start; i < start+pageSize && i < s.total; i++ {
+		users = append(users, &domain.User{ID: fmt.Sprintf("u%d", i), Username: fmt.Sprintf("user%d", i)})
+	}
+	next := ""
+	if start+len(users) < s.total {
+		next = fmt.Sprintf("%d", start+len(users))
+	}
+	return users, next, nil
+}
+
+func newUserListServer(t *testing.T, svc domain.IUserService) *httptest.Server {
+	t.Helper()
+	router := gin.New()
+	router.GET("/api/v1/user/list", NewUserController(svc).ListUsers)
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestListUsers_StreamsBeforeAllPagesLoaded(t *testing.T) {
+	svc := &pagedUserService{total: 250, release: make(chan struct{})}
+	server := newUserListServer(t, svc)
+
+	resp, err := http.Get(server.URL + "/api/v1/user/list?limit=250")
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("want 200, got %d", resp.StatusCode)
+	}
+
+	// 后续页被阻塞时，第一页的数据必须已经到达客户端
+	reader := bufio.NewReader(resp.Body)
+	head := make([]byte, 64)
+	if _, err := io.ReadFull(reader, head); err != nil {
+		t.Fatalf("read streamed head: %v", err)
+	}
+	if !strings.Contains(string(head), `"id":"u0"`) {
+		t.Fatalf("want first user streamed, got %q", head)
+	}
+	close(svc.release)
+
+	rest, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("read rest: %v", err)
+	}
+
+	var body struct {
+		Code int `json:"code"`
+		Data struct {
+			Items []dto.UserResponse `json:"items"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(append(head, rest...), &body); err != nil {
+		t.Fatalf("decode streamed response: %v", err)
+	}
+	if len(body.Data.Items) != 250 {
+		t.Fatalf("want 250 users, got %d", len(body.Data.Items))
+	}
+	if body.Data.Items[249].ID != "u249" {
+		t.Fatalf("want users in order, last is %q", body.Data.Items[249].ID)
+	}
+}
+
+func TestListUsers_Limit(t *testing.T) {
+	server := newUserListServer(t, &pagedUserService{total: 250})
+
+	cases := []struct {
+		query     string
+		wantCode  int
+		wantItems int
+	}{
+		{"limit=150", http.StatusOK, 150},
+		{"", http.StatusOK, 250},
+		{"limit=0", http.StatusBadRequest, 0},
+		{"limit=abc", http.StatusBadRequest, 0},
+	}
+
+	for _, c := range cases {
+		t.Run(c.query, func(t *testing.T) {
+			resp, err := http.Get(server.URL + "/api/v1/user/list?" + c.query)
+			if err != nil {
+				t.Fatalf("get: %v", err)
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != c.wantCode {
+				t.Fatalf("want %d, got %d", c.wantCode, resp.StatusCode)
+			}
+			if c.wantCode != http.StatusOK {
+				return
+			}
+
+			var body struct {
+				Data dto.CursorPageResponse `json:"data"`
+			}
+			if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if items, _ := body.Data.Items.([]interface{}); len(items) != c.wantItems {
+				t.Fatalf("want %d items, got %d", c.wantItems, len(items))
+			}
+		})
+	}
+}
+
+func TestListUsers_Errors(t *testing.T) {
+	useNopLogger(t)
+	t.Run("first page fails", func(t *testing.T) {
+		server := newUserListServer(t, &pagedUserService{total: 250, failAt: 1})
+
+		resp, err := http.Get(server.URL + "/api/v1/user/list")
+		if err != nil {
+			t.Fatalf("get: %v", err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusInternalServerError {
+			t.Fatalf("want 500, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("later page fails", func(t *testing.T) {
+		server := newUserListServer(t, &pagedUserService{total: 250, failAt: 2})
+
+		resp, err := http.Get(server.URL + "/api/v1/user/list")
+		if err != nil {
+			t.Fatalf("get: %v", err)
+		}
+		defer resp.Body.Close()
+
+		// 已开始输出，响应被截断，客户端无法解析出完整结果
+		var body map[string]interface{}
+		if err := json.NewDecoder(resp.Body).Decode(&body); err == nil {
+			t.Fatal("want truncated response to fail decoding")
+		}
+	})
+}
+
+func TestListUsers_Empty(t *testing.T) {
+	server := newUserListServer(t, &pagedUserService{})
+
+	resp, err := http.Get(server.URL + "/api/v1/user/list")
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	defer resp.Body.Close()
+	raw, _ := io.ReadAll(resp.Body)
+	if want := `{"code":0,"message":"success","data":{"items":[]}}`; string(raw) != want {
+		t.Fatalf("want %s, got %s", want, raw)
+	}
+}
+
+// echoUserServer 回显 name 的 user-service，记录处理函数是否被调用
+type echoUserServer struct {
+	userv1.UnimplementedUserServiceServer
+	calls int
+}
+
+func (s *echoUserServer) SayHello(ctx context.Context, req *userv1.HelloRequest) (*userv1.HelloResponse, error) {
+	s.calls++
+	return &userv1.HelloResponse{Message: "Hello " + req.GetName()}, nil
+}
+
+// newHelloGateway 启动带校验拦截器的 bufconn user-service，返回经真实 gRPC 客户端访问它的网关路由
+func newHelloGateway(t *testing.T, srv *echoUserServer) *gin.Engine {
+	t.Helper()
+
+	lis := bufconn.Listen(1 << 20)
+	server := grpc.NewServer(grpc.ChainUnaryInterceptor(middleware.UnaryServerValidation()))
+	userv1.RegisterUserServiceServer(server, srv)
+	go func() { _ = server.Serve(lis) }()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+
+	router := gin.New()
+	router.GET("/api/v1/user/hello", NewUserController(service.NewUserService(userv1.NewUserServiceClient(conn))).SayHello)
+	return router
+}
+
+func TestSayHello_ValidatesNameEndToEnd(t *testing.T) {
+	useNopLogger(t)
+	cases := []struct {
+		name       string
+		query      string
+		wantStatus int
+		wantCalled bool
+	}{
+		{"valid name", "alice", http.StatusOK, true},
+		{"missing name", "", http.StatusBadRequest, false},
+		{"blank name", "   ", http.StatusBadRequest, false},
+		{"name too long", strings.Repeat("a", userv1.MaxHelloNameLength+1), http.StatusBadRequest, false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			srv := &echoUserServer{}
+			router := newHelloGateway(t, srv)
+
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/user/hello?name="+url.QueryEscape(c.query), nil))
+
+			if rec.Code != c.wantStatus {
+				t.Fatalf("want status %d, got %d: %s", c.wantStatus, rec.Code, rec.Body.String())
+			}
+			if called := srv.calls > 0; called != c.wantCalled {
+				t.Fatalf("want handler called=%v, got %v", c.wantCalled, called)
+			}
+
+			var body dto.Response
+			if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+				t.Fatalf("decode response: %v", err)
+			}
+			if c.wantStatus == http.StatusOK {
+				data, _ := json.Marshal(body.Data)
+				if !strings.Contains(string(data), "Hello "+c.query) {
+					t.Fatalf("want greeting for %q, got %s", c.query, data)
+				}
+				return
+			}
+			if !strings.Contains(body.Message, "name") {
+				t.Fatalf("want validation message about name, got %q", body.Message)
+			}
+		})
+	}
+}
@@ -1,41 +1,96 @@
 package dependencies
 
 import (
+	bookv1 "github.com/alfredchaos/demo/api/book/v1"
 	userv1 "github.com/alfredchaos/demo/api/user/v1"
 	"github.com/alfredchaos/demo/internal/api-gateway/controller"
+	"github.com/alfredchaos/demo/internal/api-gateway/middleware"
 	"github.com/alfredchaos/demo/internal/api-gateway/service"
+	"github.com/alfredchaos/demo/pkg/featureflag"
 	"github.com/alfredchaos/demo/pkg/grpcclient"
+	"github.com/alfredchaos/demo/pkg/health"
 	"github.com/alfredchaos/demo/pkg/log"
+	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 )
 
 // AppContext 应用上下文
 // 持有所有控制器实例
 type AppContext struct {
-	UserController controller.IUserController
+	UserController        controller.IUserController
+	HelloController       controller.IHelloController
+	FeatureFlagController controller.IFeatureFlagController
+	HealthController      controller.IHealthController
+	StatsController       controller.IStatsController
+
+	// AdminIPFilter 运维管理路由的来源 IP 白名单中间件
+	AdminIPFilter gin.HandlerFunc
+	// AdminPprof 是否在运维管理路由组下挂载 pprof
+	AdminPprof bool
 }
 
 // Dependencies 依赖项
 type Dependencies struct {
 	ClientManager *grpcclient.Manager
+	// Degradation 按接口开启降级：键为接口名（如 hello），值为 true 时单个后端失败仍返回部分响应
+	Degradation map[string]bool
+	// Flags 功能开关，可通过运维接口查看
+	Flags *featureflag.Flags
+	// CriticalServices 关键下游服务，其熔断时健康检查返回 unhealthy，其余下游熔断只标记 degraded
+	CriticalServices []string
+	// AdminAllowedIPs 允许访问运维管理路由的来源 IP 或 CIDR，为空时拒绝所有请求
+	AdminAllowedIPs []string
+	// AdminPprof 是否在运维管理路由组下挂载 pprof，默认关闭；AdminAllowedIPs 为空时不挂载
+	AdminPprof bool
+	// StatsSources 运维统计接口汇总的连接池，由配置中启用的 database / redis 创建；都未启用时只返回运行时统计
+	StatsSources []health.StatsSource
 }
 
 // InjectDependencies 依赖注入函数
 func InjectDependencies(deps *Dependencies) *AppContext {
-	// 获取 gRPC 客户端（使用 GetClient 自动创建类型化客户端）
-	userClientRaw, err := deps.ClientManager.GetClient("user-service")
+	// 获取类型化的 gRPC 客户端，客户端注册错误时记录原因后退出，而不是类型断言 panic
+	userClient, err := grpcclient.Typed[userv1.UserServiceClient](deps.ClientManager, "user-service")
 	if err != nil {
 		log.Fatal("failed to get user service client", zap.Error(err))
 	}
-	userClient := userClientRaw.(userv1.UserServiceClient)
+
+	bookClient, err := grpcclient.Typed[bookv1.BookServiceClient](deps.ClientManager, "book-service")
+	if err != nil {
+		log.Fatal("failed to get book service client", zap.Error(err))
+	}
 
 	// 创建 Service 层（实现 Domain 接口）
 	userService := service.NewUserService(userClient)
+	bookService := service.NewBookService(bookClient)
 
 	// 创建 Controller 层（依赖 Domain 接口）
 	userController := controller.NewUserController(userService)
+	helloController := controller.NewHelloController(userService, bookService, deps.Degradation["hello"])
+	featureFlagController := controller.NewFeatureFlagController(deps.Flags)
+	healthController := controller.NewHealthController(deps.ClientManager, deps.CriticalServices)
+	statsController := controller.NewStatsController(deps.StatsSources...)
+
+	adminIPFilter, err := middleware.IPFilter(deps.AdminAllowedIPs)
+	if err != nil {
+		log.Fatal("invalid admin ip filter", zap.Error(err))
+	}
 
 	return &AppContext{
-		UserController: userController,
+		UserController:        userController,
+		HelloController:       helloController,
+		FeatureFlagController: featureFlagController,
+		HealthController:      healthController,
+		StatsController:       statsController,
+		AdminIPFilter:         adminIPFilter,
+		AdminPprof:            adminPprofEnabled(deps.AdminPprof, deps.AdminAllowedIPs),
+	}
+}
+
+// adminPprofEnabled pprof 会暴露堆和 goroutine 信息，只在配置了非空来源白名单时挂载
+func adminPprofEnabled(enabled bool, allowedIPs []string) bool {
+	if enabled && len(allowedIPs) == 0 {
+		log.Warn("admin pprof disabled because admin.allowed_ips is empty")
+		return false
 	}
+	return enabled
 }
@@ -0,0 +1,24 @@
+package dependencies
+
+import (
+	"testing"
+
+	"github.com/alfredchaos/demo/pkg/log"
+	"go.uber.org/zap"
+)
+
+func TestAdminPprofEnabled_RequiresAllowList(t *testing.T) {
+	original := log.Logger
+	log.Logger = zap.NewNop()
+	t.Cleanup(func() { log.Logger = original })
+
+	if adminPprofEnabled(true, nil) {
+		t.Fatal("want pprof disabled without allow list")
+	}
+	if !adminPprofEnabled(true, []string{"127.0.0.1"}) {
+		t.Fatal("want pprof enabled with allow list")
+	}
+	if adminPprofEnabled(false, []string{"127.0.0.1"}) {
+		t.Fatal("want pprof disabled when not enabled")
+	}
+}
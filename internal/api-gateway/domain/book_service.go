@@ -0,0 +1,13 @@
+package domain
+
+import (
+	"context"
+)
+
+// IBookService 图书服务领域接口
+// 定义图书相关的业务能力
+type IBookService interface {
+	// JustTellMe 问候接口
+	// 返回 book-service 的问候消息
+	JustTellMe(ctx context.Context) (string, error)
+}
@@ -4,10 +4,25 @@ import (
 	"context"
 )
 
+// User 用户信息
+type User struct {
+	ID       string
+	Username string
+	Email    string
+}
+
 // IUserService 用户服务领域接口
 // 定义用户相关的业务能力
 type IUserService interface {
 	// SayHello 问候接口
-	// 返回问候消息
-	SayHello(ctx context.Context) (string, error)
+	// 返回问候 name 的消息，name 由 user-service 校验，非法时返回 InvalidArgument
+	SayHello(ctx context.Context, name string) (string, error)
+
+	// CountUsers 用户计数接口
+	// 返回用户总数
+	CountUsers(ctx context.Context) (int64, error)
+
+	// ListUsers 用户列表接口
+	// 按游标获取一页用户，next 为空表示没有更多数据
+	ListUsers(ctx context.Context, cursor string, pageSize int) (users []*User, next string, err error)
 }
@@ -0,0 +1,50 @@
+package dto
+
+import (
+	"context"
+	"errors"
+
+	apperrors "github.com/alfredchaos/demo/pkg/errors"
+	"google.golang.org/grpc/status"
+)
+
+// FromGRPCError 将下游 gRPC 调用的错误转换为 HTTP 状态码和响应体
+// 保留 gRPC status 的原始消息和 details；被 fmt.Errorf 包装过的错误同样能取到原始 status，
+// 上下文超时映射为 504，其余非 gRPC 错误返回 500 且不暴露内部错误信息
+func FromGRPCError(err error) (int, Response) {
+	st := grpcStatus(err)
+	code := apperrors.FromGRPCCode(st.Code())
+
+	message := st.Message()
+	if code == apperrors.ErrInternalServer || message == "" {
+		message = apperrors.GetErrorMessage(code)
+	}
+
+	resp := Response{
+		Code:    int(code),
+		Message: message,
+	}
+	if details := st.Details(); len(details) > 0 {
+		resp.Data = details
+	}
+
+	return apperrors.HTTPStatus(code), resp
+}
+
+// grpcStatus 提取错误链中的 gRPC status
+func grpcStatus(err error) *status.Status {
+	var grpcErr interface{ GRPCStatus() *status.Status }
+	if errors.As(err, &grpcErr) {
+		if st, ok := status.FromError(grpcErr.(error)); ok {
+			return st
+		}
+	}
+
+	// 上下文错误只保留状态码，消息使用默认文案，避免暴露包装链上的内部信息
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		return status.New(status.FromContextError(err).Code(), "")
+	}
+
+	st, _ := status.FromError(err)
+	return st
+}
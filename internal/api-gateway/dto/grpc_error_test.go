@@ -0,0 +1,72 @@
+package dto
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	apperrors "github.com/alfredchaos/demo/pkg/errors"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestFromGRPCError(t *testing.T) {
+	cases := []struct {
+		name        string
+		err         error
+		wantStatus  int
+		wantCode    apperrors.ErrorCode
+		wantMessage string
+	}{
+		{"invalid argument", status.Error(codes.InvalidArgument, "name is required"), http.StatusBadRequest, apperrors.ErrInvalidParams, "name is required"},
+		{"not found", status.Error(codes.NotFound, "user not found"), http.StatusNotFound, apperrors.ErrNotFound, "user not found"},
+		{"already exists", status.Error(codes.AlreadyExists, "username taken"), http.StatusConflict, apperrors.ErrConflict, "username taken"},
+		{"unauthenticated", status.Error(codes.Unauthenticated, "token expired"), http.StatusUnauthorized, apperrors.ErrUnauthorized, "token expired"},
+		{"permission denied", status.Error(codes.PermissionDenied, "admin only"), http.StatusForbidden, apperrors.ErrForbidden, "admin only"},
+		{"resource exhausted", status.Error(codes.ResourceExhausted, "rate limited"), http.StatusTooManyRequests, apperrors.ErrTooManyRequests, "rate limited"},
+		{"unavailable", status.Error(codes.Unavailable, "connection refused"), http.StatusServiceUnavailable, apperrors.ErrServiceUnavailable, "connection refused"},
+		{"deadline exceeded", status.Error(codes.DeadlineExceeded, "deadline exceeded"), http.StatusGatewayTimeout, apperrors.ErrTimeout, "deadline exceeded"},
+		{"internal hides message", status.Error(codes.Internal, "pq: connection reset"), http.StatusInternalServerError, apperrors.ErrInternalServer, "internal server error"},
+		{"wrapped keeps original message", fmt.Errorf("failed to call user service: %w", status.Error(codes.NotFound, "user not found")), http.StatusNotFound, apperrors.ErrNotFound, "user not found"},
+		{"context deadline", fmt.Errorf("call: %w", context.DeadlineExceeded), http.StatusGatewayTimeout, apperrors.ErrTimeout, "request timeout"},
+		{"plain error", errors.New("boom"), http.StatusInternalServerError, apperrors.ErrInternalServer, "internal server error"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			httpStatus, body := FromGRPCError(c.err)
+			if httpStatus != c.wantStatus {
+				t.Errorf("status = %d, want %d", httpStatus, c.wantStatus)
+			}
+			if body.Code != int(c.wantCode) {
+				t.Errorf("code = %d, want %d", body.Code, c.wantCode)
+			}
+			if body.Message != c.wantMessage {
+				t.Errorf("message = %q, want %q", body.Message, c.wantMessage)
+			}
+		})
+	}
+}
+
+func TestFromGRPCError_PreservesDetails(t *testing.T) {
+	st, err := status.New(codes.InvalidArgument, "invalid user").WithDetails(&errdetails.BadRequest{
+		FieldViolations: []*errdetails.BadRequest_FieldViolation{{Field: "email", Description: "invalid format"}},
+	})
+	if err != nil {
+		t.Fatalf("attach details: %v", err)
+	}
+
+	_, body := FromGRPCError(st.Err())
+
+	details, ok := body.Data.([]interface{})
+	if !ok || len(details) != 1 {
+		t.Fatalf("want 1 detail, got %#v", body.Data)
+	}
+	badRequest, ok := details[0].(*errdetails.BadRequest)
+	if !ok || badRequest.FieldViolations[0].Field != "email" {
+		t.Fatalf("want BadRequest detail for email, got %#v", details[0])
+	}
+}
@@ -0,0 +1,222 @@
+package dto
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync/atomic"
+)
+
+// NamingStrategy 响应 JSON 字段命名策略
+type NamingStrategy string
+
+const (
+	// SnakeCase 使用 DTO json 标签中的原始名称（默认）
+	SnakeCase NamingStrategy = "snake_case"
+	// CamelCase 将 json 标签中的 snake_case 名称转换为 camelCase
+	CamelCase NamingStrategy = "camelCase"
+)
+
+// namingStrategy 当前生效的命名策略，启动时由配置设置
+var namingStrategy atomic.Value
+
+func init() {
+	namingStrategy.Store(SnakeCase)
+}
+
+// SetNamingStrategy 设置响应 JSON 字段命名策略，为空时使用 snake_case
+func SetNamingStrategy(name string) error {
+	switch NamingStrategy(name) {
+	case "", SnakeCase:
+		namingStrategy.Store(SnakeCase)
+	case CamelCase:
+		namingStrategy.Store(CamelCase)
+	default:
+		return fmt.Errorf("unsupported json naming strategy: %s", name)
+	}
+	return nil
+}
+
+// CurrentNamingStrategy 获取当前生效的命名策略
+func CurrentNamingStrategy() NamingStrategy {
+	return namingStrategy.Load().(NamingStrategy)
+}
+
+// MarshalJSON 按当前命名策略序列化响应信封及其中的 DTO
+func (r Response) MarshalJSON() ([]byte, error) {
+	type plain Response
+	return MarshalWithNaming(plain(r), CurrentNamingStrategy())
+}
+
+// MarshalWithNaming 按指定命名策略序列化 v
+// 只转换结构体字段名（来自 json 标签），map 的键属于数据，保持不变
+func MarshalWithNaming(v interface{}, strategy NamingStrategy) ([]byte, error) {
+	if strategy != CamelCase {
+		return json.Marshal(v)
+	}
+	return json.Marshal(renameFields(reflect.ValueOf(v)))
+}
+
+var (
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// renameFields 将结构体转换为以 camelCase 为键、保持字段声明顺序的对象，递归处理嵌套的结构体、切片和 map 值
+// 自定义了序列化方式的类型（如 time.Time）原样交给 encoding/json
+func renameFields(v reflect.Value) interface{} {
+	if !v.IsValid() {
+		return nil
+	}
+	if v.Type().Implements(jsonMarshalerType) || v.Type().Implements(textMarshalerType) {
+		return v.Interface()
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return renameFields(v.Elem())
+
+	case reflect.Struct:
+		out := make(orderedObject, 0, v.NumField())
+		renameStructFields(v, &out)
+		return out
+
+	case reflect.Map:
+		if v.IsNil() || v.Type().Key().Kind() != reflect.String {
+			return v.Interface()
+		}
+		out := make(map[string]interface{}, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			out[iter.Key().String()] = renameFields(iter.Value())
+		}
+		return out
+
+	case reflect.Slice:
+		if v.IsNil() {
+			return nil
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			// []byte 保持 base64 编码
+			return v.Interface()
+		}
+		fallthrough
+	case reflect.Array:
+		out := make([]interface{}, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			out[i] = renameFields(v.Index(i))
+		}
+		return out
+
+	default:
+		return v.Interface()
+	}
+}
+
+// renameStructFields 按 json 标签收集结构体字段，匿名嵌入的结构体字段提升到外层
+func renameStructFields(v reflect.Value, out *orderedObject) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+
+		fv := v.Field(i)
+		if field.Anonymous && name == "" {
+			if fv.Kind() == reflect.Ptr {
+				if fv.IsNil() {
+					continue
+				}
+				fv = fv.Elem()
+			}
+			if fv.Kind() == reflect.Struct {
+				renameStructFields(fv, out)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if strings.Contains(opts, "omitempty") && isEmptyValue(fv) {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+		*out = append(*out, objectField{name: snakeToCamel(name), value: renameFields(fv)})
+	}
+}
+
+// objectField 对象中的一个字段
+type objectField struct {
+	name  string
+	value interface{}
+}
+
+// orderedObject 按字段声明顺序序列化的 JSON 对象
+type orderedObject []objectField
+
+// MarshalJSON 按顺序输出字段
+func (o orderedObject) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, field := range o {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(field.name)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(field.value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// isEmptyValue 与 encoding/json 的 omitempty 规则一致：结构体零值不视为空
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Struct:
+		return false
+	default:
+		return v.IsZero()
+	}
+}
+
+// snakeToCamel 将 snake_case 转换为 camelCase，例如 next_cursor -> nextCursor
+func snakeToCamel(name string) string {
+	parts := strings.Split(name, "_")
+	var b strings.Builder
+	b.Grow(len(name))
+	for i, part := range parts {
+		if part == "" {
+			continue
+		}
+		if i == 0 || b.Len() == 0 {
+			b.WriteString(part)
+			continue
+		}
+		b.WriteString(strings.ToUpper(part[:1]))
+		b.WriteString(part[1:])
+	}
+	return b.String()
+}
@@ -0,0 +1,75 @@
+package dto
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+// namingTestDTO 覆盖嵌套结构体、切片、map 和 time.Time 的测试 DTO
+type namingTestDTO struct {
+	UserMessage string            `json:"user_message"`
+	NextCursor  string            `json:"next_cursor,omitempty"`
+	CreatedAt   time.Time         `json:"created_at"`
+	Items       []CountResponse   `json:"items"`
+	Labels      map[string]string `json:"labels"`
+}
+
+func TestResponse_MarshalsWithConfiguredNaming(t *testing.T) {
+	resp := NewSuccessResponse(namingTestDTO{
+		UserMessage: "hi",
+		CreatedAt:   time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC),
+		Items:       []CountResponse{{Count: 1}},
+		Labels:      map[string]string{"feature_flag": "on"},
+	})
+
+	cases := []struct {
+		strategy string
+		want     string
+	}{
+		{"", `{"code":0,"message":"success","data":{"user_message":"hi","created_at":"2024-05-01T00:00:00Z","items":[{"count":1}],"labels":{"feature_flag":"on"}}}`},
+		// map 的键属于数据，不随命名策略转换；omitempty 的空字段被省略
+		{"camelCase", `{"code":0,"message":"success","data":{"userMessage":"hi","createdAt":"2024-05-01T00:00:00Z","items":[{"count":1}],"labels":{"feature_flag":"on"}}}`},
+	}
+
+	for _, c := range cases {
+		t.Run(c.strategy, func(t *testing.T) {
+			if err := SetNamingStrategy(c.strategy); err != nil {
+				t.Fatalf("set naming strategy: %v", err)
+			}
+			defer SetNamingStrategy("")
+
+			got, err := json.Marshal(resp)
+			if err != nil {
+				t.Fatalf("marshal: %v", err)
+			}
+			if string(got) != c.want {
+				t.Fatalf("want %s, got %s", c.want, got)
+			}
+		})
+	}
+}
+
+func TestSetNamingStrategy_RejectsUnknown(t *testing.T) {
+	if err := SetNamingStrategy("PascalCase"); err == nil {
+		t.Fatal("want error for unsupported strategy")
+	}
+	if got := CurrentNamingStrategy(); got != SnakeCase {
+		t.Fatalf("want strategy unchanged, got %s", got)
+	}
+}
+
+func TestSnakeToCamel(t *testing.T) {
+	cases := map[string]string{
+		"count":        "count",
+		"next_cursor":  "nextCursor",
+		"user_message": "userMessage",
+		"_private":     "private",
+		"a__b":         "aB",
+	}
+	for in, want := range cases {
+		if got := snakeToCamel(in); got != want {
+			t.Errorf("snakeToCamel(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package dto
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/alfredchaos/demo/pkg/pagination"
+	"github.com/gin-gonic/gin"
+)
+
+// SortQueryKey 排序查询参数名，格式为 sort=field[:asc|desc][,field[:asc|desc]]
+const SortQueryKey = "sort"
+
+// SortField 排序字段，与仓储层使用同一结构
+type SortField = pagination.SortField
+
+// Filters 过滤条件，与仓储层使用同一结构
+type Filters = pagination.Filters
+
+// ParseSort 解析 sort 查询参数
+// 字段必须在 allowed 白名单内，防止通过排序列进行 SQL 注入；未提供 sort 时返回 nil
+// 支持逗号分隔或重复传参，例如 sort=created_at:desc,name 或 sort=created_at:desc&sort=name
+func ParseSort(c *gin.Context, allowed []string) ([]SortField, error) {
+	var fields []SortField
+	seen := make(map[string]struct{})
+	for _, raw := range c.QueryArray(SortQueryKey) {
+		for _, item := range strings.Split(raw, ",") {
+			item = strings.TrimSpace(item)
+			if item == "" {
+				continue
+			}
+
+			name, direction, _ := strings.Cut(item, ":")
+			if !contains(allowed, name) {
+				return nil, fmt.Errorf("sort field %q is not allowed", name)
+			}
+			if _, ok := seen[name]; ok {
+				return nil, fmt.Errorf("duplicate sort field %q", name)
+			}
+			seen[name] = struct{}{}
+
+			field := SortField{Field: name}
+			switch strings.ToLower(direction) {
+			case "", "asc":
+			case "desc":
+				field.Desc = true
+			default:
+				return nil, fmt.Errorf("invalid sort direction %q for field %q", direction, name)
+			}
+			fields = append(fields, field)
+		}
+	}
+	return fields, nil
+}
+
+// ParseFilters 解析过滤查询参数
+// 仅提取 allowed 白名单内的字段，支持逗号分隔或重复传参形成多值过滤；空值会被忽略
+// 分页、排序等非过滤参数不会出现在结果中，因此调用方只需传入可过滤字段
+func ParseFilters(c *gin.Context, allowed []string) Filters {
+	filters := make(Filters)
+	for _, name := range allowed {
+		for _, raw := range c.QueryArray(name) {
+			for _, value := range strings.Split(raw, ",") {
+				if value = strings.TrimSpace(value); value != "" {
+					filters[name] = append(filters[name], value)
+				}
+			}
+		}
+	}
+	return filters
+}
+
+// contains 判断 name 是否在 list 中
+func contains(list []string, name string) bool {
+	for _, item := range list {
+		if item == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,76 @@
+package dto
+
+import (
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+var sortableFields = []string{"created_at", "name"}
+
+func newQueryContext(rawQuery string) *gin.Context {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("GET", "/items?"+rawQuery, nil)
+	return c
+}
+
+func TestParseSort(t *testing.T) {
+	cases := []struct {
+		name  string
+		query string
+		want  []SortField
+	}{
+		{"empty", "", nil},
+		{"single desc", "sort=created_at:desc", []SortField{{Field: "created_at", Desc: true}}},
+		{"default asc", "sort=name", []SortField{{Field: "name"}}},
+		{"comma separated", "sort=created_at:DESC,name:asc", []SortField{{Field: "created_at", Desc: true}, {Field: "name"}}},
+		{"repeated param", "sort=name&sort=created_at:desc", []SortField{{Field: "name"}, {Field: "created_at", Desc: true}}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := ParseSort(newQueryContext(c.query), sortableFields)
+			if err != nil {
+				t.Fatalf("ParseSort() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, c.want) {
+				t.Errorf("ParseSort() = %+v, want %+v", got, c.want)
+			}
+		})
+	}
+}
+
+func TestParseSortRejectsInvalidInput(t *testing.T) {
+	cases := []struct {
+		name  string
+		query string
+	}{
+		{"disallowed field", "sort=password:asc"},
+		{"injection attempt", "sort=name%3B%20DROP%20TABLE%20users"},
+		{"bad direction", "sort=name:sideways"},
+		{"duplicate field", "sort=name,name:desc"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if _, err := ParseSort(newQueryContext(c.query), sortableFields); err == nil {
+				t.Errorf("ParseSort(%q) expected error", c.query)
+			}
+		})
+	}
+}
+
+func TestParseFilters(t *testing.T) {
+	c := newQueryContext("status=active,pending&status=banned&role=admin&page_size=10&secret=x&role=")
+
+	got := ParseFilters(c, []string{"status", "role", "author"})
+	want := Filters{
+		"status": {"active", "pending", "banned"},
+		"role":   {"admin"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseFilters() = %+v, want %+v", got, want)
+	}
+}
@@ -35,3 +35,45 @@ type HelloRequest struct {
 type HelloResponse struct {
 	Message string `json:"message" example:"Hello World"` // 问候消息
 }
+
+// AggregatedHelloResponse 聚合问候响应数据
+// 允许降级时，某个后端不可用仍返回其余部分，并标记 degraded
+type AggregatedHelloResponse struct {
+	UserMessage string   `json:"user_message,omitempty" example:"Hello World"`      // user-service 问候消息
+	BookMessage string   `json:"book_message,omitempty" example:"Hello from books"` // book-service 问候消息
+	Degraded    bool     `json:"degraded" example:"false"`                          // 是否为降级响应（部分后端不可用）
+	Unavailable []string `json:"unavailable,omitempty" example:"book-service"`      // 不可用的后端服务
+}
+
+// CountResponse 计数响应数据
+type CountResponse struct {
+	Count int64 `json:"count" example:"42"` // 数量（可能为短暂缓存或估算值）
+}
+
+// UserResponse 用户信息响应数据
+type UserResponse struct {
+	ID       string `json:"id" example:"c9875b7e-458e-465b-8d34-3c05204d6957"` // 用户 ID
+	Username string `json:"username" example:"alice"`                          // 用户名
+	Email    string `json:"email" example:"alice@example.com"`                 // 邮箱
+}
+
+// CursorPageResponse 游标分页响应数据
+// NextCursor 为 pagination 包编码的不透明游标，为空表示没有更多数据
+type CursorPageResponse struct {
+	Items      interface{} `json:"items" swaggertype:"array,object"`                // 当前页数据
+	NextCursor string      `json:"next_cursor,omitempty" example:"eyJpZCI6InUxIn0"` // 下一页游标
+}
+
+// NewCursorPageResponse 创建游标分页响应
+func NewCursorPageResponse(items interface{}, nextCursor string) *Response {
+	return NewSuccessResponse(CursorPageResponse{
+		Items:      items,
+		NextCursor: nextCursor,
+	})
+}
+
+// HealthResponse 健康检查响应
+type HealthResponse struct {
+	Status       string            `json:"status" example:"ok"`                                // 健康状态: ok, degraded, unhealthy
+	Dependencies map[string]string `json:"dependencies,omitempty" example:"user-service:open"` // 下游熔断状态: closed, open, half_open
+}
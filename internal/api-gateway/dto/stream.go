@@ -0,0 +1,85 @@
+package dto
+
+import (
+	"io"
+	"net/http"
+)
+
+// 流式列表响应的固定前后缀，与 NewCursorPageResponse 的信封结构一致（各键名在两种命名策略下相同）
+const (
+	listStreamPrefix = `{"code":0,"message":"success","data":{"items":[`
+	listStreamSuffix = `]}}`
+)
+
+// ListStreamWriter 以流式方式输出统一响应格式的列表
+// 每项数据生成后立即序列化写出，调用方无需在内存中保留完整列表；
+// 写出的 JSON 与 NewSuccessResponse(CursorPageResponse{Items: ...}) 相同，单项按当前命名策略序列化
+type ListStreamWriter struct {
+	w       io.Writer
+	started bool
+	count   int
+}
+
+// NewListStreamWriter 创建流式列表写入器
+func NewListStreamWriter(w io.Writer) *ListStreamWriter {
+	return &ListStreamWriter{w: w}
+}
+
+// Write 序列化并写出一项数据，首次调用时先写出响应信封的前缀
+func (s *ListStreamWriter) Write(item interface{}) error {
+	data, err := MarshalWithNaming(item, CurrentNamingStrategy())
+	if err != nil {
+		return err
+	}
+
+	if err := s.start(); err != nil {
+		return err
+	}
+	if s.count > 0 {
+		if _, err := io.WriteString(s.w, ","); err != nil {
+			return err
+		}
+	}
+	if _, err := s.w.Write(data); err != nil {
+		return err
+	}
+	s.count++
+	return nil
+}
+
+// Flush 将已写出的数据推送给客户端，底层 Writer 不支持刷新时为空操作
+func (s *ListStreamWriter) Flush() {
+	if f, ok := s.w.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Close 写出响应信封的后缀，使输出成为完整的 JSON
+// 列表为空时同样输出完整的空列表响应
+func (s *ListStreamWriter) Close() error {
+	if err := s.start(); err != nil {
+		return err
+	}
+	_, err := io.WriteString(s.w, listStreamSuffix)
+	return err
+}
+
+// Started 是否已开始写出响应，开始后无法再返回错误响应
+func (s *ListStreamWriter) Started() bool {
+	return s.started
+}
+
+// Count 已写出的数据项数量
+func (s *ListStreamWriter) Count() int {
+	return s.count
+}
+
+// start 写出响应信封的前缀（仅一次）
+func (s *ListStreamWriter) start() error {
+	if s.started {
+		return nil
+	}
+	s.started = true
+	_, err := io.WriteString(s.w, listStreamPrefix)
+	return err
+}
@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"context"
+
+	"github.com/alfredchaos/demo/pkg/reqctx"
+	"github.com/gin-gonic/gin"
+)
+
+// DeadlineBudget 时间预算中间件
+// 客户端可通过 X-Deadline-Budget-Ms 请求头声明剩余的时间预算，
+// 网关据此收紧请求上下文的截止时间，下游 gRPC 调用会继续扣减该预算
+func DeadlineBudget() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		budget, ok := reqctx.ParseBudget(c.GetHeader(reqctx.DeadlineBudgetKey))
+		if !ok {
+			c.Next()
+			return
+		}
+
+		// 只会收紧截止时间，不会超过 Timeout 中间件设置的上限
+		ctx, cancel := context.WithTimeout(c.Request.Context(), budget)
+		defer cancel()
+
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
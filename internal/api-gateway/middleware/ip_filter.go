@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/alfredchaos/demo/internal/api-gateway/dto"
+	"github.com/alfredchaos/demo/pkg/log"
+	"github.com/alfredchaos/demo/pkg/netutil"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// IPFilter 来源 IP 白名单中间件，用于保护运维管理路由
+// allowed 支持单个 IP（10.0.0.1）和 CIDR（10.0.0.0/8），为空时拒绝所有来源；
+// 来源 IP 取 TCP 连接的对端地址（RemoteIP），不信任 X-Forwarded-For 等可伪造的请求头，
+// 经过代理部署时应把代理地址加入白名单，或只在内网直连访问运维接口
+func IPFilter(allowed []string) (gin.HandlerFunc, error) {
+	allowList, err := netutil.ParseIPAllowList(allowed)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(c *gin.Context) {
+		clientIP := c.RemoteIP()
+		if !allowList.Empty() && allowList.Allows(clientIP) {
+			c.Next()
+			return
+		}
+
+		log.WithContext(c.Request.Context()).Warn("request rejected by ip filter",
+			zap.String("client_ip", clientIP),
+			zap.String("path", c.Request.URL.Path),
+		)
+		c.AbortWithStatusJSON(http.StatusForbidden, dto.NewErrorResponse(10005, "forbidden"))
+	}, nil
+}
@@ -0,0 +1,97 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/alfredchaos/demo/pkg/log"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+func TestIPFilter_AllowsOnlyListedSources(t *testing.T) {
+	restoreLogger(t)
+	gin.SetMode(gin.TestMode)
+
+	filter, err := IPFilter([]string{"10.0.0.0/8", "192.168.1.7"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	router := gin.New()
+	router.GET("/admin", filter, func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	cases := []struct {
+		remoteAddr string
+		want       int
+	}{
+		{"10.1.2.3:5000", http.StatusOK},
+		{"192.168.1.7:5000", http.StatusOK},
+		{"192.168.1.8:5000", http.StatusForbidden},
+		{"[::1]:5000", http.StatusForbidden},
+	}
+	for _, c := range cases {
+		req := httptest.NewRequest(http.MethodGet, "/admin", nil)
+		req.RemoteAddr = c.remoteAddr
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		if rec.Code != c.want {
+			t.Fatalf("%s: want %d, got %d", c.remoteAddr, c.want, rec.Code)
+		}
+	}
+}
+
+func TestIPFilter_EmptyListDeniesAll(t *testing.T) {
+	restoreLogger(t)
+	gin.SetMode(gin.TestMode)
+	filter, err := IPFilter(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	router := gin.New()
+	router.GET("/admin", filter, func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	for _, remoteAddr := range []string{"127.0.0.1:5000", "203.0.113.9:5000"} {
+		req := httptest.NewRequest(http.MethodGet, "/admin", nil)
+		req.RemoteAddr = remoteAddr
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		if rec.Code != http.StatusForbidden {
+			t.Fatalf("%s: want 403 without allow list, got %d", remoteAddr, rec.Code)
+		}
+	}
+
+	if _, err := IPFilter([]string{"not-an-ip"}); err == nil {
+		t.Fatal("want error for invalid entry")
+	}
+}
+
+func TestIPFilter_IgnoresForwardedHeaders(t *testing.T) {
+	restoreLogger(t)
+	gin.SetMode(gin.TestMode)
+	filter, err := IPFilter([]string{"127.0.0.1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// 与网关一致：未调用 SetTrustedProxies，gin 默认信任所有代理
+	router := gin.New()
+	router.GET("/admin", filter, func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	req := httptest.NewRequest(http.MethodGet, "/admin", nil)
+	req.RemoteAddr = "203.0.113.9:5000"
+	req.Header.Set("X-Forwarded-For", "127.0.0.1")
+	req.Header.Set("X-Real-IP", "127.0.0.1")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("want spoofed X-Forwarded-For rejected with 403, got %d", rec.Code)
+	}
+}
+
+// restoreLogger 测试期间使用空日志，结束后恢复原日志
+func restoreLogger(t *testing.T) {
+	t.Helper()
+	original := log.Logger
+	log.Logger = zap.NewNop()
+	t.Cleanup(func() { log.Logger = original })
+}
@@ -30,6 +30,8 @@ func Logger() gin.HandlerFunc {
 		ctx := c.Request.Context()
 		ctx = reqctx.WithRequestID(ctx, requestID)
 		ctx = reqctx.WithRequestInfo(ctx, method, path, clientIP)
+		// 绑定请求级 logger，下游通过 log.FromContext 复用，无需重复提取字段
+		ctx = log.IntoContext(ctx, log.WithContext(ctx))
 		
 		// 更新 request 的 context
 		c.Request = c.Request.WithContext(ctx)
@@ -37,8 +39,11 @@ func Logger() gin.HandlerFunc {
 		// 处理请求
 		c.Next()
 
-		// 计算请求耗时
-		latency := time.Since(startTime)
+		// 计算请求耗时，优先使用 StartTime 中间件记录的开始时间（涵盖前置中间件）
+		latency := reqctx.Elapsed(c.Request.Context())
+		if latency == 0 {
+			latency = time.Since(startTime)
+		}
 
 		// 获取响应状态码
 		statusCode := c.Writer.Status()
@@ -63,12 +68,13 @@ func Logger() gin.HandlerFunc {
 		}
 
 		// 根据状态码选择日志级别
+		logger := log.WithContext(ctx)
 		if statusCode >= 500 {
-			log.Error("HTTP request error", fields...)
+			logger.Error("HTTP request error", fields...)
 		} else if statusCode >= 400 {
-			log.Warn("HTTP request warning", fields...)
+			logger.Warn("HTTP request warning", fields...)
 		} else {
-			log.Info("HTTP request", fields...)
+			logger.Info("HTTP request", fields...)
 		}
 	}
 }
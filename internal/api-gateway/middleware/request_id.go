@@ -27,7 +27,9 @@ func RequestID() gin.HandlerFunc {
 		c.Set(RequestIDKey, requestID)
 
 		// 将请求ID添加到 request.Context 中
+		// 同时作为 trace_id，由 gRPC 客户端拦截器传递给下游服务，串联整条调用链
 		ctx := reqctx.WithRequestID(c.Request.Context(), requestID)
+		ctx = reqctx.WithTraceID(ctx, requestID)
 		c.Request = c.Request.WithContext(ctx)
 
 		// 将请求ID设置到响应头中
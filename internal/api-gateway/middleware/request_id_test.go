@@ -0,0 +1,84 @@
+package middleware
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/alfredchaos/demo/pkg/grpcclient"
+	"github.com/alfredchaos/demo/pkg/log"
+	grpcmiddleware "github.com/alfredchaos/demo/pkg/middleware"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// TestRequestID_PropagatesAsTraceIDToGRPC 网关请求ID应作为 trace_id 出现在网关和下游服务的日志中
+func TestRequestID_PropagatesAsTraceIDToGRPC(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	prev := log.Logger
+	log.Logger = zap.New(core)
+	t.Cleanup(func() { log.Logger = prev })
+	gin.SetMode(gin.TestMode)
+
+	// 下游 gRPC 服务：与各服务 builder 相同的追踪 + 日志拦截器
+	lis := bufconn.Listen(1 << 20)
+	server := grpc.NewServer(grpc.ChainUnaryInterceptor(
+		grpcmiddleware.UnaryServerTracing(),
+		grpcmiddleware.UnaryServerLogging(),
+	))
+	healthpb.RegisterHealthServer(server, health.NewServer())
+	go func() { _ = server.Serve(lis) }()
+	defer server.Stop()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(grpcclient.TracingInterceptor()),
+	)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.Close()
+	client := healthpb.NewHealthClient(conn)
+
+	// 网关：RequestID + Logger 中间件，处理函数调用下游
+	router := gin.New()
+	router.Use(RequestID(), Logger())
+	router.GET("/check", func(c *gin.Context) {
+		if _, err := client.Check(c.Request.Context(), &healthpb.HealthCheckRequest{}); err != nil {
+			c.Status(http.StatusBadGateway)
+			return
+		}
+		c.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/check", nil)
+	req.Header.Set(RequestIDKey, "req-724")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("want 200, got %d", rec.Code)
+	}
+
+	gatewayLogs := logs.FilterMessage("HTTP request").All()
+	downstreamLogs := logs.FilterMessage("gRPC request").All()
+	if len(gatewayLogs) != 1 || len(downstreamLogs) != 1 {
+		t.Fatalf("want 1 gateway and 1 downstream log, got %d and %d", len(gatewayLogs), len(downstreamLogs))
+	}
+	if got := gatewayLogs[0].ContextMap()["trace_id"]; got != "req-724" {
+		t.Fatalf("want gateway trace_id req-724, got %v", got)
+	}
+	if got := downstreamLogs[0].ContextMap()["trace_id"]; got != "req-724" {
+		t.Fatalf("want downstream trace_id req-724, got %v", got)
+	}
+}
@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"errors"
+
+	"github.com/alfredchaos/demo/pkg/log"
+	"github.com/alfredchaos/demo/pkg/reqctx"
+	"github.com/alfredchaos/demo/pkg/session"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// SessionCookieName 会话 ID 所在的 Cookie 名称
+const SessionCookieName = "session_id"
+
+// Session 会话中间件
+// 从 Cookie 读取会话 ID 并加载用户 ID 到 request.Context，处理函数通过 reqctx.GetUserID 获取；
+// 未登录或会话失效时不中断请求，由需要登录的路由自行判断
+func Session(store *session.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		sessionID, err := c.Cookie(SessionCookieName)
+		if err != nil || sessionID == "" {
+			c.Next()
+			return
+		}
+
+		userID, err := store.Get(c.Request.Context(), sessionID)
+		if err != nil {
+			if !errors.Is(err, session.ErrNotFound) {
+				log.WithContext(c.Request.Context()).Warn("failed to load session", zap.Error(err))
+			}
+			c.Next()
+			return
+		}
+
+		// 在已绑定的请求级 logger 上追加 user_id
+		ctx := reqctx.WithUserID(c.Request.Context(), userID)
+		ctx = log.IntoContext(ctx, log.FromContext(ctx).With(zap.String("user_id", userID)))
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
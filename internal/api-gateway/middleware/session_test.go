@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alfredchaos/demo/pkg/cache"
+	"github.com/alfredchaos/demo/pkg/log"
+	"github.com/alfredchaos/demo/pkg/reqctx"
+	"github.com/alfredchaos/demo/pkg/session"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+func TestSession_PopulatesUserID(t *testing.T) {
+	original := log.Logger
+	log.Logger = zap.NewNop()
+	t.Cleanup(func() { log.Logger = original })
+
+	gin.SetMode(gin.TestMode)
+
+	mr := miniredis.RunT(t)
+	rc, err := cache.NewRedisClient(&cache.RedisConfig{Addr: mr.Addr()})
+	if err != nil {
+		t.Fatalf("new redis client: %v", err)
+	}
+	defer rc.Close()
+	store := session.NewStore(rc)
+
+	sessionID, err := store.Create(context.Background(), "user-1", time.Hour)
+	if err != nil {
+		t.Fatalf("create session: %v", err)
+	}
+
+	router := gin.New()
+	router.Use(Session(store))
+	router.GET("/me", func(c *gin.Context) {
+		c.String(http.StatusOK, reqctx.GetUserID(c.Request.Context()))
+	})
+
+	cases := []struct {
+		name   string
+		cookie string
+		want   string
+	}{
+		{"valid session", sessionID, "user-1"},
+		{"unknown session", "unknown", ""},
+		{"no cookie", "", ""},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/me", nil)
+			if c.cookie != "" {
+				req.AddCookie(&http.Cookie{Name: SessionCookieName, Value: c.cookie})
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("want status 200, got %d", w.Code)
+			}
+			if got := w.Body.String(); got != c.want {
+				t.Fatalf("want user id %q, got %q", c.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,18 @@
+package middleware
+
+import (
+	"time"
+
+	"github.com/alfredchaos/demo/pkg/reqctx"
+	"github.com/gin-gonic/gin"
+)
+
+// StartTime 请求开始时间中间件
+// 将请求开始时间存入 request.Context，下游日志和处理函数可通过 reqctx.Elapsed 获取总耗时
+func StartTime() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := reqctx.WithStartTime(c.Request.Context(), time.Now())
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
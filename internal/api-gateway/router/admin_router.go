@@ -0,0 +1,39 @@
+package router
+
+import (
+	"net/http"
+
+	"github.com/alfredchaos/demo/internal/api-gateway/controller"
+	"github.com/alfredchaos/demo/pkg/debugserver"
+	"github.com/gin-gonic/gin"
+)
+
+// AdminRouter 运维管理路由组
+// ipFilter 限制可访问运维接口的来源 IP；pprof 为 true 时挂载 /debug/pprof/*
+func AdminRouter(
+	router *gin.RouterGroup,
+	ipFilter gin.HandlerFunc,
+	pprof bool,
+	userController controller.IUserController,
+	featureFlagController controller.IFeatureFlagController,
+	statsController controller.IStatsController,
+) {
+	adminGroup := router.Group("/admin", ipFilter)
+	{
+		adminGroup.GET("/users/count", userController.CountUsers)
+		adminGroup.GET("/flags", featureFlagController.List)
+		adminGroup.GET("/stats", statsController.Stats)
+	}
+
+	if pprof {
+		PprofRouter(adminGroup)
+	}
+}
+
+// PprofRouter 在路由组下挂载 pprof 处理器（/debug/pprof/*）
+// 去掉路由组前缀后交给 debugserver.PprofHandler，使 pprof.Index 能按名称分发 profile
+func PprofRouter(router *gin.RouterGroup) {
+	handler := gin.WrapH(http.StripPrefix(router.BasePath(), debugserver.PprofHandler()))
+	router.GET("/debug/pprof/*profile", handler)
+	router.POST("/debug/pprof/symbol", handler)
+}
@@ -0,0 +1,102 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/alfredchaos/demo/internal/api-gateway/controller"
+	"github.com/alfredchaos/demo/internal/api-gateway/middleware"
+	"github.com/alfredchaos/demo/pkg/featureflag"
+	"github.com/alfredchaos/demo/pkg/log"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// newAdminEngine 创建只挂载运维路由的引擎
+func newAdminEngine(t *testing.T, allowedIPs []string, pprof bool) *gin.Engine {
+	t.Helper()
+	ipFilter, err := middleware.IPFilter(allowedIPs)
+	if err != nil {
+		t.Fatalf("ip filter: %v", err)
+	}
+
+	engine := gin.New()
+	AdminRouter(engine.Group("/api/v1"), ipFilter, pprof,
+		controller.NewUserController(nil),
+		controller.NewFeatureFlagController(featureflag.New(nil)),
+		controller.NewStatsController(),
+	)
+	return engine
+}
+
+// get 以指定来源地址发起 GET 请求
+func get(engine *gin.Engine, path, remoteAddr string) int {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	req.RemoteAddr = remoteAddr
+	rec := httptest.NewRecorder()
+	engine.ServeHTTP(rec, req)
+	return rec.Code
+}
+
+// useNopLogger 测试期间使用空日志，结束后恢复原日志
+func useNopLogger(t *testing.T) {
+	t.Helper()
+	original := log.Logger
+	log.Logger = zap.NewNop()
+	t.Cleanup(func() { log.Logger = original })
+}
+
+func TestAdminRouter_PprofOnlyWhenEnabled(t *testing.T) {
+	useNopLogger(t)
+	gin.SetMode(gin.TestMode)
+	paths := []string{
+		"/api/v1/admin/debug/pprof/",
+		"/api/v1/admin/debug/pprof/cmdline",
+		"/api/v1/admin/debug/pprof/heap?debug=1",
+	}
+
+	disabled := newAdminEngine(t, []string{"127.0.0.1"}, false)
+	for _, path := range paths {
+		if code := get(disabled, path, "127.0.0.1:5000"); code != http.StatusNotFound {
+			t.Fatalf("disabled %s: want 404, got %d", path, code)
+		}
+	}
+
+	enabled := newAdminEngine(t, []string{"127.0.0.1"}, true)
+	for _, path := range paths {
+		if code := get(enabled, path, "127.0.0.1:5000"); code != http.StatusOK {
+			t.Fatalf("enabled %s: want 200, got %d", path, code)
+		}
+	}
+}
+
+func TestAdminRouter_PprofBehindIPFilter(t *testing.T) {
+	useNopLogger(t)
+	gin.SetMode(gin.TestMode)
+	engine := newAdminEngine(t, []string{"127.0.0.1"}, true)
+
+	if code := get(engine, "/api/v1/admin/debug/pprof/cmdline", "127.0.0.1:5000"); code != http.StatusOK {
+		t.Fatalf("allowed source: want 200, got %d", code)
+	}
+	if code := get(engine, "/api/v1/admin/debug/pprof/cmdline", "203.0.113.9:5000"); code != http.StatusForbidden {
+		t.Fatalf("other source: want 403, got %d", code)
+	}
+}
+
+func TestAdminRouter_PprofIgnoresForwardedFor(t *testing.T) {
+	useNopLogger(t)
+	gin.SetMode(gin.TestMode)
+	engine := newAdminEngine(t, []string{"127.0.0.1"}, true)
+
+	for _, path := range []string{"/api/v1/admin/debug/pprof/heap", "/api/v1/admin/debug/pprof/goroutine"} {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.RemoteAddr = "203.0.113.9:5000"
+		req.Header.Set("X-Forwarded-For", "127.0.0.1")
+		rec := httptest.NewRecorder()
+		engine.ServeHTTP(rec, req)
+		if rec.Code != http.StatusForbidden {
+			t.Fatalf("%s: want spoofed source rejected with 403, got %d", path, rec.Code)
+		}
+	}
+}
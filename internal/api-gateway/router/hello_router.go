@@ -0,0 +1,11 @@
+package router
+
+import (
+	"github.com/alfredchaos/demo/internal/api-gateway/controller"
+	"github.com/gin-gonic/gin"
+)
+
+// HelloRouter 聚合问候路由
+func HelloRouter(router *gin.RouterGroup, controller controller.IHelloController) {
+	router.GET("/hello", controller.Hello)
+}
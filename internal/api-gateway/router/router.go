@@ -16,10 +16,12 @@ func SetupRouter(appCtx *dependencies.AppContext) *gin.Engine {
 	// 应用全局中间件（顺序很重要）
 	router.Use(
 		middleware.Recovery(),              // 1. Panic恢复（最先执行，确保能捕获所有panic）
-		middleware.RequestID(),             // 2. 请求ID生成（用于后续日志追踪）
-		middleware.Logger(),                // 3. 请求日志记录
-		middleware.CORS(),                  // 4. 跨域处理
-		middleware.Timeout(30*time.Second), // 5. 请求超时（30秒）
+		middleware.StartTime(),             // 2. 记录请求开始时间（供 reqctx.Elapsed 计算总耗时）
+		middleware.RequestID(),             // 3. 请求ID生成（用于后续日志追踪）
+		middleware.Logger(),                // 4. 请求日志记录
+		middleware.CORS(),                  // 5. 跨域处理
+		middleware.Timeout(30*time.Second), // 6. 请求超时（30秒）
+		middleware.DeadlineBudget(),        // 7. 按客户端声明的时间预算收紧截止时间
 	)
 
 	// API 路由组
@@ -27,12 +29,16 @@ func SetupRouter(appCtx *dependencies.AppContext) *gin.Engine {
 	{
 		// 用户路由
 		UserRouter(apiV1, appCtx.UserController)
+		// 聚合问候路由
+		HelloRouter(apiV1, appCtx.HelloController)
+		// 运维管理路由
+		AdminRouter(apiV1, appCtx.AdminIPFilter, appCtx.AdminPprof, appCtx.UserController, appCtx.FeatureFlagController, appCtx.StatsController)
 		// 可以继续添加更多路由
 		// OrderRouter(apiV1, appCtx.OrderController)
 	}
 
 	// 系统路由组
-	SystemRouter(router)
+	SystemRouter(router, appCtx.HealthController)
 
 	return router
 }
@@ -1,20 +1,21 @@
 package router
 
 import (
+	"github.com/alfredchaos/demo/internal/api-gateway/controller"
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 )
 
 // SystemRouter 系统路由组
-func SystemRouter(router *gin.Engine) {
+func SystemRouter(router *gin.Engine, healthController controller.IHealthController) {
 	// Swagger 文档
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
-	// 健康检查
-	router.GET("/health", func(c *gin.Context) {
-		c.JSON(200, gin.H{
-			"status": "ok",
-		})
-	})
+	// Prometheus 指标
+	router.GET("/metrics", gin.WrapH(promhttp.Handler()))
+
+	// 健康检查（包含下游熔断状态）
+	router.GET("/health", healthController.Health)
 }
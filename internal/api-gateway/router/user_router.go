@@ -10,6 +10,7 @@ func UserRouter(router *gin.RouterGroup, controller controller.IUserController)
 	userGroup := router.Group("/user")
 	{
 		userGroup.GET("/hello", controller.SayHello)
+		userGroup.GET("/list", controller.ListUsers)
 		// 可以添加更多用户相关路由
 		// userGroup.GET("/:id", controller.GetUser)
 		// userGroup.POST("", controller.CreateUser)
@@ -0,0 +1,41 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	bookv1 "github.com/alfredchaos/demo/api/book/v1"
+	"github.com/alfredchaos/demo/internal/api-gateway/domain"
+	"github.com/alfredchaos/demo/pkg/log"
+	"github.com/alfredchaos/demo/pkg/metrics"
+	"go.uber.org/zap"
+)
+
+// bookService 图书服务实现
+// 封装对 book-service 的 gRPC 调用
+type bookService struct {
+	baseService
+	bookClient bookv1.BookServiceClient
+}
+
+// NewBookService 创建图书服务实例
+// 注入 gRPC 客户端依赖
+func NewBookService(bookClient bookv1.BookServiceClient) domain.IBookService {
+	return &bookService{
+		baseService: baseService{},
+		bookClient:  bookClient,
+	}
+}
+
+// JustTellMe 调用 book-service 的 JustTellMe 接口
+func (s *bookService) JustTellMe(ctx context.Context) (string, error) {
+	stop := metrics.Timer("book_service.just_tell_me", metrics.WithLog(ctx))
+	resp, err := s.bookClient.JustTellMe(ctx, &bookv1.TellMeRequest{})
+	stop()
+	if err != nil {
+		log.WithContext(ctx).Error("failed to call book service", zap.Error(err))
+		return "", fmt.Errorf("failed to call book service: %w", err)
+	}
+
+	return resp.Message, nil
+}
@@ -7,6 +7,7 @@ import (
 	userv1 "github.com/alfredchaos/demo/api/user/v1"
 	"github.com/alfredchaos/demo/internal/api-gateway/domain"
 	"github.com/alfredchaos/demo/pkg/log"
+	"github.com/alfredchaos/demo/pkg/metrics"
 	"go.uber.org/zap"
 )
 
@@ -27,12 +28,11 @@ func NewUserService(userClient userv1.UserServiceClient) domain.IUserService {
 }
 
 // SayHello 调用 user-service 的 SayHello 接口
-func (s *userService) SayHello(ctx context.Context) (string, error) {
-	// 传递 trace ID 到 gRPC metadata
-	ctx = s.withTraceID(ctx)
-
-	// 调用 user-service
-	resp, err := s.userClient.SayHello(ctx, &userv1.HelloRequest{})
+func (s *userService) SayHello(ctx context.Context, name string) (string, error) {
+	// 调用 user-service（trace ID 由 grpcclient.TracingInterceptor 传递）
+	stop := metrics.Timer("user_service.say_hello", metrics.WithLog(ctx))
+	resp, err := s.userClient.SayHello(ctx, &userv1.HelloRequest{Name: name})
+	stop()
 	if err != nil {
 		log.WithContext(ctx).Error("failed to call user service", zap.Error(err))
 		return "", fmt.Errorf("failed to call user service: %w", err)
@@ -41,3 +41,40 @@ func (s *userService) SayHello(ctx context.Context) (string, error) {
 	log.WithContext(ctx).Info("user service SayHello success", zap.String("message", resp.Message))
 	return resp.Message, nil
 }
+
+// CountUsers 调用 user-service 的 CountUsers 接口
+func (s *userService) CountUsers(ctx context.Context) (int64, error) {
+	stop := metrics.Timer("user_service.count_users", metrics.WithLog(ctx))
+	resp, err := s.userClient.CountUsers(ctx, &userv1.CountUsersRequest{})
+	stop()
+	if err != nil {
+		log.WithContext(ctx).Error("failed to count users", zap.Error(err))
+		return 0, fmt.Errorf("failed to count users: %w", err)
+	}
+
+	return resp.Count, nil
+}
+
+// ListUsers 调用 user-service 的 ListUsers 接口获取一页用户
+func (s *userService) ListUsers(ctx context.Context, cursor string, pageSize int) ([]*domain.User, string, error) {
+	stop := metrics.Timer("user_service.list_users", metrics.WithLog(ctx))
+	resp, err := s.userClient.ListUsers(ctx, &userv1.ListUsersRequest{
+		PageSize:  int32(pageSize),
+		PageToken: cursor,
+	})
+	stop()
+	if err != nil {
+		log.WithContext(ctx).Error("failed to list users", zap.Error(err))
+		return nil, "", fmt.Errorf("failed to list users: %w", err)
+	}
+
+	users := make([]*domain.User, 0, len(resp.Users))
+	for _, u := range resp.Users {
+		users = append(users, &domain.User{
+			ID:       u.Id,
+			Username: u.Username,
+			Email:    u.Email,
+		})
+	}
+	return users, resp.NextPageToken, nil
+}
@@ -2,7 +2,11 @@ package biz
 
 import (
 	"context"
+	"fmt"
 
+	"github.com/alfredchaos/demo/internal/book-service/domain"
+	"github.com/alfredchaos/demo/internal/book-service/repository"
+	apperrors "github.com/alfredchaos/demo/pkg/errors"
 	"github.com/alfredchaos/demo/pkg/log"
 	"go.uber.org/zap"
 )
@@ -10,15 +14,22 @@ import (
 // BookUseCase 用户业务逻辑用例接口
 type IBookUseCase interface {
 	JustTellMe(ctx context.Context, name string) (string, error)
+	CountBooks(ctx context.Context) (int64, error)
+	CreateBook(ctx context.Context, bookname, email string) (*domain.Book, error)
+	GetBook(ctx context.Context, id domain.BookID) (*domain.Book, error)
+	ListBooks(ctx context.Context, cursor string, limit int) ([]*domain.Book, string, error)
 }
 
 // BookUseCase Book业务逻辑用例实现
 type BookUseCase struct {
+	bookRepo repository.BookRepository
 }
 
 // NewBookUseCase 创建新的Book业务逻辑用例
-func NewBookUseCase() *BookUseCase {
-	return &BookUseCase{}
+func NewBookUseCase(bookRepo repository.BookRepository) *BookUseCase {
+	return &BookUseCase{
+		bookRepo: bookRepo,
+	}
 }
 
 func (uc *BookUseCase) JustTellMe(ctx context.Context, name string) (string, error) {
@@ -32,3 +43,68 @@ func (uc *BookUseCase) JustTellMe(ctx context.Context, name string) (string, err
 
 	return BookMessage, nil
 }
+
+// CountBooks 统计图书总数
+func (uc *BookUseCase) CountBooks(ctx context.Context) (int64, error) {
+	if uc.bookRepo == nil {
+		return 0, fmt.Errorf("book repository is not configured")
+	}
+
+	count, err := uc.bookRepo.Count(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count books: %w", err)
+	}
+
+	return count, nil
+}
+
+// CreateBook 创建图书
+// 书名或邮箱为空时返回 *domain.ValidationError，书名重复时返回 domain.ErrBooknameTaken
+func (uc *BookUseCase) CreateBook(ctx context.Context, bookname, email string) (*domain.Book, error) {
+	if uc.bookRepo == nil {
+		return nil, fmt.Errorf("book repository is not configured")
+	}
+
+	book := domain.NewBook(bookname, email)
+	if err := book.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := uc.bookRepo.Create(ctx, book); err != nil {
+		return nil, fmt.Errorf("failed to create book: %w", err)
+	}
+
+	log.WithContext(ctx).Info("book created", zap.String("book_id", book.ID.String()))
+	return book, nil
+}
+
+// GetBook 根据ID获取图书
+func (uc *BookUseCase) GetBook(ctx context.Context, id domain.BookID) (*domain.Book, error) {
+	if id == "" {
+		return nil, apperrors.New(apperrors.ErrInvalidParams, "book id is required")
+	}
+	if uc.bookRepo == nil {
+		return nil, fmt.Errorf("book repository is not configured")
+	}
+
+	book, err := uc.bookRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get book: %w", err)
+	}
+
+	return book, nil
+}
+
+// ListBooks 基于游标分页列出图书
+func (uc *BookUseCase) ListBooks(ctx context.Context, cursor string, limit int) ([]*domain.Book, string, error) {
+	if uc.bookRepo == nil {
+		return nil, "", fmt.Errorf("book repository is not configured")
+	}
+
+	books, next, err := uc.bookRepo.ListAfter(ctx, cursor, limit)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to list books: %w", err)
+	}
+
+	return books, next, nil
+}
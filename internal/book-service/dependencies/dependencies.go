@@ -10,8 +10,9 @@ import (
 	"github.com/alfredchaos/demo/internal/book-service/repository/mongo"
 	"github.com/alfredchaos/demo/internal/book-service/repository/psql"
 	"github.com/alfredchaos/demo/internal/book-service/service"
-	"github.com/alfredchaos/demo/pkg/db"
 	"github.com/alfredchaos/demo/pkg/grpcclient"
+	"github.com/alfredchaos/demo/pkg/health"
+	"github.com/alfredchaos/demo/pkg/startup"
 )
 
 type AppContext struct {
@@ -25,6 +26,7 @@ type AppContext struct {
 type Dependencies struct {
 	ClientManager *grpcclient.Manager
 	Cfg           *conf.Config
+	Readiness     *health.Readiness // 就绪状态，依赖连接成功后在其中标记，可为 nil
 }
 
 func InjectDependencies(deps *Dependencies) (*AppContext, error) {
@@ -36,33 +38,42 @@ func InjectDependencies(deps *Dependencies) (*AppContext, error) {
 	// }
 	// bookClient := client.(bookv1.BookServiceClient)
 
-	var pgClient *db.PostgresClient
+	// 按 enabled 开关连接各存储后端
+	stores, err := connectStores(deps.Cfg, defaultStoreConnectors, deps.Readiness)
+	if err != nil {
+		return nil, err
+	}
+
 	var bookRepo repository.BookRepository
-	if deps.Cfg.Database.Enabled {
-		pgClient = psql.MustInitPostgresClient(&deps.Cfg.Database)
-		bookRepo = psql.NewBookPgRepository(pgClient.GetDB())
+	if stores.postgres != nil {
+		bookRepo = psql.NewBookPgRepository(stores.postgres.GetDB(), &deps.Cfg.Database)
 	}
 
-	var mongoClient *db.MongoClient
 	var bookDocumentRepo repository.BookDocumentRepository
-	if deps.Cfg.MongoDB.URI != "" {
-		mongoCfg := mongo.FromMongoConfig(&deps.Cfg.MongoDB)
-		mongoClient = mongo.MustInitMongoClient(mongoCfg)
-		bookDocumentRepo = mongo.NewBookMongoDocumentRepository(mongoClient)
+	if stores.mongo != nil {
+		bookDocumentRepo = mongo.NewBookMongoDocumentRepository(stores.mongo)
 	}
 
-	data := repository.NewData(pgClient, mongoClient, bookRepo, bookDocumentRepo)
+	data := repository.NewData(stores.postgres, stores.mongo, bookRepo, bookDocumentRepo)
 	// bookCache := cache.NewBookRedisCache(&deps.Cfg.Redis)
 
 	// 初始化 RabbitMQ，book-service 仅作为消息发布者
-	messageQueue := rabbitmq.MustInitRabbitMQ(&deps.Cfg.RabbitMQ)
+	var messageQueue *rabbitmq.MessageQueue
+	deps.Readiness.Require("rabbitmq")
+	if err := startup.RetryConnect("rabbitmq", func() (err error) {
+		messageQueue, err = rabbitmq.InitRabbitMQ(&deps.Cfg.RabbitMQ)
+		return err
+	}, deps.Cfg.StartupRetry); err != nil {
+		return nil, err
+	}
+	deps.Readiness.MarkReady("rabbitmq", messageQueue)
 	// publisher, err := messageQueue.NewPublisher()
 	// if err != nil {
 	// 	log.Fatal("failed to create publisher", zap.Error(err))
 	// 	return nil, err
 	// }
 
-	bookUseCase := biz.NewBookUseCase()
+	bookUseCase := biz.NewBookUseCase(data.BookRepo)
 	bookService := service.NewBookService(bookUseCase)
 
 	return &AppContext{
@@ -0,0 +1,61 @@
+package dependencies
+
+import (
+	"github.com/alfredchaos/demo/internal/book-service/conf"
+	"github.com/alfredchaos/demo/internal/book-service/repository/mongo"
+	"github.com/alfredchaos/demo/internal/book-service/repository/psql"
+	"github.com/alfredchaos/demo/pkg/db"
+	"github.com/alfredchaos/demo/pkg/health"
+	"github.com/alfredchaos/demo/pkg/startup"
+)
+
+// storeConnectors 各存储后端的连接函数，测试中替换为假实现
+type storeConnectors struct {
+	postgres func(cfg *db.PostgresConfig) (*db.PostgresClient, error)
+	mongo    func(cfg *mongo.Config) (*db.MongoClient, error)
+}
+
+// defaultStoreConnectors 连接真实后端
+var defaultStoreConnectors = storeConnectors{
+	postgres: psql.InitPostgresClient,
+	mongo:    mongo.InitMongoClient,
+}
+
+// storeClients 已连接的存储后端，未启用的后端为 nil
+type storeClients struct {
+	postgres *db.PostgresClient
+	mongo    *db.MongoClient
+}
+
+// connectStores 按各后端配置的 enabled 开关连接存储
+// book-service 暂未使用缓存，redis.enabled 不生效
+// 依赖可能晚于本服务就绪，连接失败时按 startup_retry 重试；连接成功后在 readiness 中标记就绪
+func connectStores(cfg *conf.Config, connectors storeConnectors, readiness *health.Readiness) (*storeClients, error) {
+	var clients storeClients
+	retry := cfg.StartupRetry
+
+	if cfg.Database.Enabled {
+		readiness.Require("postgres")
+		if err := startup.RetryConnect("postgres", func() (err error) {
+			clients.postgres, err = connectors.postgres(&cfg.Database)
+			return err
+		}, retry); err != nil {
+			return nil, err
+		}
+		readiness.MarkReady("postgres", clients.postgres)
+	}
+
+	if cfg.MongoDB.IsEnabled() {
+		mongoCfg := mongo.FromMongoConfig(&cfg.MongoDB)
+		readiness.Require("mongodb")
+		if err := startup.RetryConnect("mongodb", func() (err error) {
+			clients.mongo, err = connectors.mongo(mongoCfg)
+			return err
+		}, retry); err != nil {
+			return nil, err
+		}
+		readiness.MarkReady("mongodb", clients.mongo)
+	}
+
+	return &clients, nil
+}
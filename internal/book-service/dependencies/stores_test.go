@@ -0,0 +1,74 @@
+package dependencies
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/alfredchaos/demo/internal/book-service/conf"
+	"github.com/alfredchaos/demo/internal/book-service/repository/mongo"
+	"github.com/alfredchaos/demo/pkg/db"
+	"github.com/alfredchaos/demo/pkg/log"
+	"github.com/alfredchaos/demo/pkg/startup"
+	"go.uber.org/zap"
+)
+
+func TestConnectStores_EnabledCombinations(t *testing.T) {
+	useNopLogger(t)
+
+	for mask := 0; mask < 4; mask++ {
+		pg, mg := mask&1 != 0, mask&2 != 0
+		t.Run(fmt.Sprintf("postgres=%t,mongodb=%t", pg, mg), func(t *testing.T) {
+			cfg := &conf.Config{}
+			cfg.Database.Enabled = pg
+			cfg.MongoDB.Enabled = &mg
+
+			var pgCalls, mongoCalls int
+			clients, err := connectStores(cfg, storeConnectors{
+				postgres: func(cfg *db.PostgresConfig) (*db.PostgresClient, error) {
+					pgCalls++
+					return &db.PostgresClient{}, nil
+				},
+				mongo: func(cfg *mongo.Config) (*db.MongoClient, error) {
+					mongoCalls++
+					return &db.MongoClient{}, nil
+				},
+			}, nil)
+			if err != nil {
+				t.Fatalf("connectStores: %v", err)
+			}
+
+			if (clients.postgres != nil) != pg || (pgCalls == 1) != pg {
+				t.Errorf("postgres: want connected=%t, got client=%v calls=%d", pg, clients.postgres, pgCalls)
+			}
+			if (clients.mongo != nil) != mg || (mongoCalls == 1) != mg {
+				t.Errorf("mongodb: want connected=%t, got client=%v calls=%d", mg, clients.mongo, mongoCalls)
+			}
+		})
+	}
+}
+
+func TestConnectStores_EnabledBackendFailureIsReturned(t *testing.T) {
+	useNopLogger(t)
+
+	cfg := &conf.Config{StartupRetry: startup.RetryPolicy{MaxAttempts: 1}}
+	cfg.MongoDB.URI = "mongodb://localhost:27017"
+
+	connErr := errors.New("server selection timeout")
+	_, err := connectStores(cfg, storeConnectors{
+		mongo: func(cfg *mongo.Config) (*db.MongoClient, error) {
+			return nil, connErr
+		},
+	}, nil)
+	if !errors.Is(err, connErr) {
+		t.Fatalf("want mongodb connection error, got %v", err)
+	}
+}
+
+// useNopLogger 测试期间使用空日志，结束后恢复原日志
+func useNopLogger(t *testing.T) {
+	t.Helper()
+	original := log.Logger
+	log.Logger = zap.NewNop()
+	t.Cleanup(func() { log.Logger = original })
+}
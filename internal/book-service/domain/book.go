@@ -4,7 +4,7 @@ import "time"
 
 // Book book领域模型
 type Book struct {
-	ID        string    // 用户ID
+	ID        BookID    // 图书ID
 	Bookname  string    // 用户名
 	Email     string    // 邮箱
 	CreatedAt time.Time // 创建时间
@@ -22,13 +22,13 @@ func NewBook(Bookname, email string) *Book {
 	}
 }
 
-// Validate 验证book数据
+// Validate 验证book数据，校验失败时返回 *ValidationError
 func (u *Book) Validate() error {
 	if u.Bookname == "" {
-		return ErrInvalidBookname
+		return &ValidationError{Field: "bookname", Err: ErrInvalidBookname}
 	}
 	if u.Email == "" {
-		return ErrInvalidEmail
+		return &ValidationError{Field: "email", Err: ErrInvalidEmail}
 	}
 	return nil
 }
@@ -1,17 +1,45 @@
 package domain
 
-import "errors"
+import (
+	"fmt"
 
+	"github.com/alfredchaos/demo/pkg/errors"
+)
+
+// 领域哨兵错误携带错误码，可通过 errors.HTTPStatusOf / errors.GRPCCodeOf 统一映射
 var (
+	// ErrInvalidBook 图书数据无效，所有 ValidationError 都可通过 errors.Is 匹配该错误
+	ErrInvalidBook = errors.NewCoded(errors.ErrInvalidParams, "invalid book")
+
 	// ErrInvalidBookname 无效的书名
-	ErrInvalidBookname = errors.New("invalid Bookname")
+	ErrInvalidBookname = errors.NewCoded(errors.ErrInvalidParams, "invalid Bookname")
 
 	// ErrInvalidEmail 无效的邮箱
-	ErrInvalidEmail = errors.New("invalid email")
+	ErrInvalidEmail = errors.NewCoded(errors.ErrInvalidParams, "invalid email")
+
+	// ErrBookNotFound 图书不存在
+	ErrBookNotFound = errors.NewCoded(errors.ErrNotFound, "Book not found")
 
-	// ErrBookNotFound 用户不存在
-	ErrBookNotFound = errors.New("Book not found")
+	// ErrBookAlreadyExists 图书已存在（ID 冲突）
+	ErrBookAlreadyExists = errors.NewCoded(errors.ErrConflict, "Book already exists")
 
-	// ErrBookAlreadyExists 用户已存在
-	ErrBookAlreadyExists = errors.New("Book already exists")
+	// ErrBooknameTaken 书名已被占用
+	ErrBooknameTaken = errors.NewCoded(errors.ErrConflict, "bookname already taken")
 )
+
+// ValidationError 图书字段校验失败
+// 可通过 errors.Is 同时匹配具体字段的哨兵错误（如 ErrInvalidBookname）和 ErrInvalidBook
+type ValidationError struct {
+	Field string // 校验失败的字段
+	Err   error  // 字段对应的哨兵错误
+}
+
+// Error 实现 error 接口
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%s: %s", ErrInvalidBook.Message, e.Err)
+}
+
+// Unwrap 返回字段错误和 ErrInvalidBook
+func (e *ValidationError) Unwrap() []error {
+	return []error{e.Err, ErrInvalidBook}
+}
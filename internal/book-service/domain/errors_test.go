@@ -0,0 +1,55 @@
+package domain
+
+import (
+	stderrors "errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/alfredchaos/demo/pkg/errors"
+	"google.golang.org/grpc/codes"
+)
+
+func TestValidationError_MatchesFieldAndInvalidBook(t *testing.T) {
+	tests := []struct {
+		name  string
+		book  *Book
+		field string
+		want  error
+	}{
+		{name: "missing bookname", book: &Book{Email: "a@example.com"}, field: "bookname", want: ErrInvalidBookname},
+		{name: "missing email", book: &Book{Bookname: "golang"}, field: "email", want: ErrInvalidEmail},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 上层通常会包装一层错误
+			err := fmt.Errorf("failed to create book: %w", tt.book.Validate())
+
+			var verr *ValidationError
+			if !stderrors.As(err, &verr) || verr.Field != tt.field {
+				t.Fatalf("want ValidationError on %s, got %v", tt.field, err)
+			}
+			if !stderrors.Is(err, tt.want) || !stderrors.Is(err, ErrInvalidBook) {
+				t.Fatalf("want %v and ErrInvalidBook, got %v", tt.want, err)
+			}
+			if got := errors.HTTPStatusOf(err); got != http.StatusBadRequest {
+				t.Fatalf("want http 400, got %d", got)
+			}
+			if got := errors.GRPCCodeOf(err); got != codes.InvalidArgument {
+				t.Fatalf("want codes.InvalidArgument, got %s", got)
+			}
+		})
+	}
+}
+
+func TestErrBooknameTaken_MapsToAlreadyExists(t *testing.T) {
+	err := fmt.Errorf("failed to create book: %w", ErrBooknameTaken)
+
+	if got := errors.HTTPStatusOf(err); got != http.StatusConflict {
+		t.Fatalf("want http 409, got %d", got)
+	}
+	if got := errors.GRPCCodeOf(err); got != codes.AlreadyExists {
+		t.Fatalf("want codes.AlreadyExists, got %s", got)
+	}
+}
@@ -0,0 +1,31 @@
+package domain
+
+import "github.com/alfredchaos/demo/pkg/idgen"
+
+// BookID 图书 ID
+// 与其他实体的 ID 区分类型，避免在编译期无法发现的 ID 混用；JSON / BSON 中仍序列化为普通字符串
+type BookID string
+
+// NewBookID 生成新的图书 ID（UUID）
+func NewBookID() BookID {
+	return BookID(idgen.UUID.New())
+}
+
+// ParseBookID 解析并校验外部传入的图书 ID，格式不合法时返回包装了 idgen.ErrInvalidID 的错误
+func ParseBookID(s string) (BookID, error) {
+	id := BookID(s)
+	if err := id.Validate(); err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+// Validate 校验 ID 是否为标准格式的 UUID
+func (id BookID) Validate() error {
+	return idgen.UUID.Validate(string(id))
+}
+
+// String 实现 fmt.Stringer 接口
+func (id BookID) String() string {
+	return string(id)
+}
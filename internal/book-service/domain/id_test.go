@@ -0,0 +1,24 @@
+package domain
+
+import (
+	stderrors "errors"
+	"testing"
+
+	"github.com/alfredchaos/demo/pkg/idgen"
+)
+
+func TestParseBookID(t *testing.T) {
+	id := NewBookID()
+	if got, err := ParseBookID(id.String()); err != nil || got != id {
+		t.Fatalf("want %q to parse, got %q (err=%v)", id, got, err)
+	}
+
+	for _, raw := range []string{"", "b1", "7c9e6679742540de944be07fc1f90ae7", "7c9e6679-7425-40de-944b-e07fc1f90ae7 "} {
+		if _, err := ParseBookID(raw); !stderrors.Is(err, idgen.ErrInvalidID) {
+			t.Errorf("%q: want ErrInvalidID, got %v", raw, err)
+		}
+		if err := BookID(raw).Validate(); !stderrors.Is(err, idgen.ErrInvalidID) {
+			t.Errorf("%q: want Validate to reject, got %v", raw, err)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package mongo
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -31,8 +32,8 @@ func NewBookMongoDocumentRepository(client *db.MongoClient) *BookMongoDocumentRe
 }
 
 // SaveDocument 保存Book文档（JSON 格式）
-func (r *BookMongoDocumentRepository) SaveDocument(ctx context.Context, BookID string, document map[string]interface{}) error {
-	document["_id"] = BookID
+func (r *BookMongoDocumentRepository) SaveDocument(ctx context.Context, BookID domain.BookID, document map[string]interface{}) error {
+	document["_id"] = string(BookID)
 
 	// 自动添加/更新时间戳
 	now := time.Now()
@@ -42,38 +43,49 @@ func (r *BookMongoDocumentRepository) SaveDocument(ctx context.Context, BookID s
 	document["updated_at"] = now
 
 	// Upsert 操作
-	filter := bson.M{"_id": BookID}
+	filter := bson.M{"_id": string(BookID)}
 	update := bson.M{"$set": document}
 	opts := options.Update().SetUpsert(true)
 
 	_, err := r.collection.UpdateOne(ctx, filter, update, opts)
 	if err != nil {
-		return fmt.Errorf("failed to save document: %w", err)
+		return mapBookDocumentError(err, "failed to save document")
 	}
 
 	return nil
 }
 
 // GetDocument 根据ID获取Book文档（JSON 格式）
-func (r *BookMongoDocumentRepository) GetDocument(ctx context.Context, BookID string) (map[string]interface{}, error) {
+func (r *BookMongoDocumentRepository) GetDocument(ctx context.Context, BookID domain.BookID) (map[string]interface{}, error) {
 	var document map[string]interface{}
 
-	err := r.collection.FindOne(ctx, bson.M{"_id": BookID}).Decode(&document)
+	err := r.collection.FindOne(ctx, bson.M{"_id": string(BookID)}).Decode(&document)
 	if err != nil {
-		if err == mongo.ErrNoDocuments {
-			return nil, domain.ErrBookNotFound
-		}
-		return nil, fmt.Errorf("failed to get document: %w", err)
+		return nil, mapBookDocumentError(err, "failed to get document")
 	}
 
 	return document, nil
 }
 
+// Exists 判断Book文档是否存在
+// 只投影 _id 字段，避免传输整个文档
+func (r *BookMongoDocumentRepository) Exists(ctx context.Context, BookID domain.BookID) (bool, error) {
+	opts := options.FindOne().SetProjection(bson.M{"_id": 1})
+	err := r.collection.FindOne(ctx, bson.M{"_id": string(BookID)}, opts).Err()
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to check document existence: %w", err)
+	}
+	return true, nil
+}
+
 // DeleteDocument 删除Book文档
-func (r *BookMongoDocumentRepository) DeleteDocument(ctx context.Context, BookID string) error {
-	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": BookID})
+func (r *BookMongoDocumentRepository) DeleteDocument(ctx context.Context, BookID domain.BookID) error {
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": string(BookID)})
 	if err != nil {
-		return fmt.Errorf("failed to delete document: %w", err)
+		return mapBookDocumentError(err, "failed to delete document")
 	}
 
 	if result.DeletedCount == 0 {
@@ -113,15 +125,15 @@ func (r *BookMongoDocumentRepository) FindDocuments(ctx context.Context, filter
 }
 
 // UpdateDocumentFields 更新文档的部分字段
-func (r *BookMongoDocumentRepository) UpdateDocumentFields(ctx context.Context, BookID string, fields map[string]interface{}) error {
+func (r *BookMongoDocumentRepository) UpdateDocumentFields(ctx context.Context, BookID domain.BookID, fields map[string]interface{}) error {
 	fields["updated_at"] = time.Now()
 
-	filter := bson.M{"_id": BookID}
+	filter := bson.M{"_id": string(BookID)}
 	update := bson.M{"$set": fields}
 
 	result, err := r.collection.UpdateOne(ctx, filter, update)
 	if err != nil {
-		return fmt.Errorf("failed to update document fields: %w", err)
+		return mapBookDocumentError(err, "failed to update document fields")
 	}
 
 	if result.MatchedCount == 0 {
@@ -130,3 +142,16 @@ func (r *BookMongoDocumentRepository) UpdateDocumentFields(ctx context.Context,
 
 	return nil
 }
+
+// mapBookDocumentError 将 MongoDB 错误映射为领域错误，与 PostgreSQL 仓库保持一致
+// 文档不存在映射为 domain.ErrBookNotFound，唯一索引冲突映射为 domain.ErrBookAlreadyExists
+func mapBookDocumentError(err error, message string) error {
+	switch {
+	case errors.Is(err, mongo.ErrNoDocuments):
+		return domain.ErrBookNotFound
+	case mongo.IsDuplicateKeyError(err):
+		return domain.ErrBookAlreadyExists
+	default:
+		return fmt.Errorf("%s: %w", message, err)
+	}
+}
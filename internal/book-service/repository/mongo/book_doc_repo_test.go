@@ -0,0 +1,106 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/alfredchaos/demo/internal/book-service/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestBookMongoDocumentRepository_Exists(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("existing", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "test.Books", mtest.FirstBatch, bson.D{{Key: "_id", Value: "b1"}}))
+		repo := &BookMongoDocumentRepository{collection: mt.Coll}
+
+		got, err := repo.Exists(context.Background(), "b1")
+		if err != nil {
+			mt.Fatalf("unexpected error: %v", err)
+		}
+		if !got {
+			mt.Fatalf("want existing document")
+		}
+	})
+
+	mt.Run("missing", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "test.Books", mtest.FirstBatch))
+		repo := &BookMongoDocumentRepository{collection: mt.Coll}
+
+		got, err := repo.Exists(context.Background(), "b1")
+		if err != nil {
+			mt.Fatalf("unexpected error: %v", err)
+		}
+		if got {
+			mt.Fatalf("want missing document")
+		}
+	})
+
+	mt.Run("driver error", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 2, Message: "bad value"}))
+		repo := &BookMongoDocumentRepository{collection: mt.Coll}
+
+		if got, err := repo.Exists(context.Background(), "b1"); err == nil || got {
+			mt.Fatalf("want driver error, got %v, %v", got, err)
+		}
+	})
+}
+
+func TestBookMongoDocumentRepository_MapsErrors(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("get missing", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "test.Books", mtest.FirstBatch))
+		repo := &BookMongoDocumentRepository{collection: mt.Coll}
+
+		if _, err := repo.GetDocument(context.Background(), "b1"); !errors.Is(err, domain.ErrBookNotFound) {
+			mt.Fatalf("want ErrBookNotFound, got %v", err)
+		}
+	})
+
+	mt.Run("delete missing", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 0}))
+		repo := &BookMongoDocumentRepository{collection: mt.Coll}
+
+		if err := repo.DeleteDocument(context.Background(), "b1"); !errors.Is(err, domain.ErrBookNotFound) {
+			mt.Fatalf("want ErrBookNotFound, got %v", err)
+		}
+	})
+
+	mt.Run("update missing", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 0}, bson.E{Key: "nModified", Value: 0}))
+		repo := &BookMongoDocumentRepository{collection: mt.Coll}
+
+		err := repo.UpdateDocumentFields(context.Background(), "b1", map[string]interface{}{"title": "golang"})
+		if !errors.Is(err, domain.ErrBookNotFound) {
+			mt.Fatalf("want ErrBookNotFound, got %v", err)
+		}
+	})
+
+	mt.Run("duplicate key", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateWriteErrorsResponse(mtest.WriteError{
+			Index:   0,
+			Code:    11000,
+			Message: "duplicate key error",
+		}))
+		repo := &BookMongoDocumentRepository{collection: mt.Coll}
+
+		err := repo.SaveDocument(context.Background(), "b1", map[string]interface{}{"title": "golang"})
+		if !errors.Is(err, domain.ErrBookAlreadyExists) {
+			mt.Fatalf("want ErrBookAlreadyExists, got %v", err)
+		}
+	})
+
+	mt.Run("other error", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 2, Message: "bad value"}))
+		repo := &BookMongoDocumentRepository{collection: mt.Coll}
+
+		err := repo.SaveDocument(context.Background(), "b1", map[string]interface{}{"title": "golang"})
+		if err == nil || errors.Is(err, domain.ErrBookAlreadyExists) || errors.Is(err, domain.ErrBookNotFound) {
+			mt.Fatalf("want wrapped driver error, got %v", err)
+		}
+	})
+}
@@ -2,29 +2,31 @@ package psql
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/alfredchaos/demo/internal/book-service/domain"
-	"github.com/google/uuid"
+	"github.com/alfredchaos/demo/internal/book-service/repository"
+	"github.com/alfredchaos/demo/pkg/db"
+	"github.com/alfredchaos/demo/pkg/idgen"
+	"github.com/alfredchaos/demo/pkg/metrics"
+	"github.com/alfredchaos/demo/pkg/pagination"
+	"github.com/jackc/pgx/v5/pgconn"
 	"gorm.io/gorm"
 )
 
 // BookPgPO Book持久化对象（PostgreSQL）
 // 负责与PostgreSQL交互的数据结构
+// 表名由 db.NewNamingStrategy 统一生成（默认 books）
 type BookPgPO struct {
 	ID        string    `gorm:"column:id;primaryKey"`
-	Bookname  string    `gorm:"column:Bookname;uniqueIndex;not null"`
+	Bookname  string    `gorm:"column:bookname;uniqueIndex;not null"`
 	Email     string    `gorm:"column:email;not null"`
 	CreatedAt time.Time `gorm:"column:created_at"`
 	UpdatedAt time.Time `gorm:"column:updated_at"`
 }
 
-// TableName 指定表名
-func (BookPgPO) TableName() string {
-	return "Books"
-}
-
 // BeforeCreate GORM 钩子：创建前自动设置时间戳
 func (po *BookPgPO) BeforeCreate(tx *gorm.DB) error {
 	now := time.Now()
@@ -46,7 +48,7 @@ func (po *BookPgPO) BeforeUpdate(tx *gorm.DB) error {
 // ToDomain 将持久化对象转换为领域对象
 func (po *BookPgPO) ToDomain() *domain.Book {
 	return &domain.Book{
-		ID:        po.ID,
+		ID:        domain.BookID(po.ID),
 		Bookname:  po.Bookname,
 		Email:     po.Email,
 		CreatedAt: po.CreatedAt,
@@ -57,7 +59,7 @@ func (po *BookPgPO) ToDomain() *domain.Book {
 // FromDomainBook 从领域对象创建持久化对象
 func FromDomainBook(Book *domain.Book) *BookPgPO {
 	return &BookPgPO{
-		ID:        Book.ID,
+		ID:        string(Book.ID),
 		Bookname:  Book.Bookname,
 		Email:     Book.Email,
 		CreatedAt: Book.CreatedAt,
@@ -67,32 +69,68 @@ func FromDomainBook(Book *domain.Book) *BookPgPO {
 
 // BookPgRepository PostgreSQL仓库实现
 type BookPgRepository struct {
-	db *gorm.DB
+	db           *gorm.DB
+	counter      *db.RowCounter
+	ids          idgen.Generator
+	queryTimeout time.Duration // 单次调用的默认超时，ctx 中的 reqctx.WithDBTimeout 优先
 }
 
 // NewBookPgRepository 创建PostgreSQL Book仓库
-func NewBookPgRepository(db *gorm.DB) *BookPgRepository {
-	return &BookPgRepository{db: db}
+// cfg 用于行数统计的缓存和估算策略以及默认查询超时，可为 nil
+func NewBookPgRepository(gormDB *gorm.DB, cfg *db.PostgresConfig) *BookPgRepository {
+	return &BookPgRepository{
+		db:           gormDB,
+		counter:      db.NewRowCounter(gormDB, &BookPgPO{}, cfg),
+		ids:          idgen.UUID,
+		queryTimeout: cfg.GetQueryTimeout(),
+	}
+}
+
+// WithIDGenerator 设置 ID 生成器，ID 格式校验随之改变，默认使用 UUID
+func (r *BookPgRepository) WithIDGenerator(gen idgen.Generator) *BookPgRepository {
+	r.ids = gen
+	return r
+}
+
+// WithTx 返回使用事务连接 tx 的仓库副本
+// 行数统计同样在事务连接上执行（见 db.RowCounter.WithTx），写操作仍使原仓库的行数缓存失效
+func (r *BookPgRepository) WithTx(tx *gorm.DB) repository.BookRepository {
+	return &BookPgRepository{
+		db:           tx,
+		counter:      r.counter.WithTx(tx),
+		ids:          r.ids,
+		queryTimeout: r.queryTimeout,
+	}
 }
 
 // Create 创建Book
 func (r *BookPgRepository) Create(ctx context.Context, Book *domain.Book) error {
-	// 生成UUID作为ID
+	// 未指定ID时生成新ID，指定时校验格式
 	if Book.ID == "" {
-		Book.ID = uuid.New().String()
+		Book.ID = domain.BookID(r.ids.New())
+	} else if err := r.ids.Validate(string(Book.ID)); err != nil {
+		return err
 	}
 
-	// 验证Book数据
+	// 验证Book数据，直接返回 *domain.ValidationError
 	if err := Book.Validate(); err != nil {
-		return fmt.Errorf("invalid Book data: %w", err)
+		return err
 	}
 
 	po := FromDomainBook(Book)
+
+	ctx, cancel := db.QueryContext(ctx, r.queryTimeout)
+	defer cancel()
+
+	defer metrics.Timer("book_pg_repo.create", metrics.WithLog(ctx))()
+
 	// GORM 会自动设置 CreatedAt 和 UpdatedAt
 	if err := r.db.WithContext(ctx).Create(po).Error; err != nil {
-		return fmt.Errorf("failed to create Book: %w", err)
+		return mapBookError(err, "failed to create Book")
 	}
 
+	r.counter.Invalidate()
+
 	// 将 GORM 自动生成的时间戳同步回领域对象
 	Book.CreatedAt = po.CreatedAt
 	Book.UpdatedAt = po.UpdatedAt
@@ -101,51 +139,90 @@ func (r *BookPgRepository) Create(ctx context.Context, Book *domain.Book) error
 }
 
 // GetByID 根据ID获取Book
-func (r *BookPgRepository) GetByID(ctx context.Context, id string) (*domain.Book, error) {
+func (r *BookPgRepository) GetByID(ctx context.Context, id domain.BookID) (*domain.Book, error) {
+	if err := r.ids.Validate(string(id)); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := db.QueryContext(ctx, r.queryTimeout)
+	defer cancel()
+
+	defer metrics.Timer("book_pg_repo.get_by_id", metrics.WithLog(ctx))()
+
 	var po BookPgPO
-	err := r.db.WithContext(ctx).Where("id = ?", id).First(&po).Error
+	err := r.db.WithContext(ctx).Where("id = ?", string(id)).First(&po).Error
 	if err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return nil, domain.ErrBookNotFound
-		}
-		return nil, fmt.Errorf("failed to get Book by id: %w", err)
+		return nil, mapBookError(err, "failed to get Book by id")
 	}
 	return po.ToDomain(), nil
 }
 
+// Exists 判断Book是否存在
+// 只查询常量列，避免加载整行数据
+func (r *BookPgRepository) Exists(ctx context.Context, id domain.BookID) (bool, error) {
+	if err := r.ids.Validate(string(id)); err != nil {
+		return false, err
+	}
+
+	ctx, cancel := db.QueryContext(ctx, r.queryTimeout)
+	defer cancel()
+
+	defer metrics.Timer("book_pg_repo.exists", metrics.WithLog(ctx))()
+
+	var found int
+	result := r.db.WithContext(ctx).
+		Model(&BookPgPO{}).
+		Select("1").
+		Where("id = ?", string(id)).
+		Limit(1).
+		Scan(&found)
+	if result.Error != nil {
+		return false, mapBookError(result.Error, "failed to check book existence")
+	}
+	return result.RowsAffected > 0, nil
+}
+
 // GetByBookname 根据书名获取Book
 func (r *BookPgRepository) GetByBookname(ctx context.Context, bookname string) (*domain.Book, error) {
+	ctx, cancel := db.QueryContext(ctx, r.queryTimeout)
+	defer cancel()
+
+	defer metrics.Timer("book_pg_repo.get_by_bookname", metrics.WithLog(ctx))()
+
 	var po BookPgPO
 	err := r.db.WithContext(ctx).Where("bookname = ?", bookname).First(&po).Error
 	if err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return nil, domain.ErrBookNotFound
-		}
-		return nil, fmt.Errorf("failed to get Book by Bookname: %w", err)
+		return nil, mapBookError(err, "failed to get Book by Bookname")
 	}
 	return po.ToDomain(), nil
 }
 
 // Update 更新Book
 func (r *BookPgRepository) Update(ctx context.Context, book *domain.Book) error {
-	if book.ID == "" {
-		return fmt.Errorf("book id is required for update")
+	if err := r.ids.Validate(string(book.ID)); err != nil {
+		return err
 	}
 
-	// 验证Book数据
+	// 验证Book数据，直接返回 *domain.ValidationError
 	if err := book.Validate(); err != nil {
-		return fmt.Errorf("invalid book data: %w", err)
+		return err
 	}
 
 	po := FromDomainBook(book)
+
+	ctx, cancel := db.QueryContext(ctx, r.queryTimeout)
+	defer cancel()
+
+	defer metrics.Timer("book_pg_repo.update", metrics.WithLog(ctx))()
+
 	result := r.db.WithContext(ctx).
 		Model(&BookPgPO{}).
-		Where("id = ?", book.ID).
+		Where("id = ?", string(book.ID)).
 		Select("bookname", "email", "updated_at").
 		Updates(po)
 
 	if result.Error != nil {
-		return fmt.Errorf("failed to update Book: %w", result.Error)
+		return mapBookError(result.Error, "failed to update Book")
 	}
 
 	if result.RowsAffected == 0 {
@@ -159,27 +236,54 @@ func (r *BookPgRepository) Update(ctx context.Context, book *domain.Book) error
 }
 
 // Delete 删除Book
-func (r *BookPgRepository) Delete(ctx context.Context, id string) error {
-	if id == "" {
-		return fmt.Errorf("Book id is required for delete")
+func (r *BookPgRepository) Delete(ctx context.Context, id domain.BookID) error {
+	if err := r.ids.Validate(string(id)); err != nil {
+		return err
 	}
 
-	result := r.db.WithContext(ctx).Where("id = ?", id).Delete(&BookPgPO{})
+	ctx, cancel := db.QueryContext(ctx, r.queryTimeout)
+	defer cancel()
+
+	defer metrics.Timer("book_pg_repo.delete", metrics.WithLog(ctx))()
+
+	result := r.db.WithContext(ctx).Where("id = ?", string(id)).Delete(&BookPgPO{})
 	if result.Error != nil {
-		return fmt.Errorf("failed to delete Book: %w", result.Error)
+		return mapBookError(result.Error, "failed to delete Book")
 	}
 
 	if result.RowsAffected == 0 {
 		return domain.ErrBookNotFound
 	}
 
+	r.counter.Invalidate()
+
 	return nil
 }
 
+// Count 统计Book数量
+// 结果会被短暂缓存，大表可配置为返回估算值
+func (r *BookPgRepository) Count(ctx context.Context) (int64, error) {
+	ctx, cancel := db.QueryContext(ctx, r.queryTimeout)
+	defer cancel()
+
+	defer metrics.Timer("book_pg_repo.count", metrics.WithLog(ctx))()
+
+	count, err := r.counter.Count(ctx)
+	if err != nil {
+		return 0, mapBookError(err, "failed to count books")
+	}
+	return count, nil
+}
+
 // List 列出Book
 func (r *BookPgRepository) List(ctx context.Context, offset, limit int) ([]*domain.Book, error) {
 	var pos []BookPgPO
 
+	ctx, cancel := db.QueryContext(ctx, r.queryTimeout)
+	defer cancel()
+
+	defer metrics.Timer("book_pg_repo.list", metrics.WithLog(ctx))()
+
 	query := r.db.WithContext(ctx)
 
 	// 设置分页参数
@@ -192,7 +296,7 @@ func (r *BookPgRepository) List(ctx context.Context, offset, limit int) ([]*doma
 
 	// 按创建时间倒序排列
 	if err := query.Order("created_at DESC").Find(&pos).Error; err != nil {
-		return nil, fmt.Errorf("failed to list Books: %w", err)
+		return nil, mapBookError(err, "failed to list Books")
 	}
 
 	// 转换为领域对象
@@ -203,3 +307,69 @@ func (r *BookPgRepository) List(ctx context.Context, offset, limit int) ([]*doma
 
 	return books, nil
 }
+
+// ListAfter 基于游标的键集分页，按创建时间倒序列出Book
+// cursor 为上一页返回的游标，空字符串表示第一页；返回的 next 为空表示没有更多数据
+func (r *BookPgRepository) ListAfter(ctx context.Context, cursor string, limit int) ([]*domain.Book, string, error) {
+	if limit <= 0 {
+		return nil, "", fmt.Errorf("limit must be positive")
+	}
+
+	ctx, cancel := db.QueryContext(ctx, r.queryTimeout)
+	defer cancel()
+
+	defer metrics.Timer("book_pg_repo.list_after", metrics.WithLog(ctx))()
+
+	query := r.db.WithContext(ctx)
+	if cursor != "" {
+		createdAt, id, err := pagination.DecodeCreatedAtCursor(cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		query = query.Where("(created_at, id) < (?, ?)", createdAt, id)
+	}
+
+	// 多取一条用于判断是否还有下一页
+	var pos []BookPgPO
+	if err := query.Order("created_at DESC, id DESC").Limit(limit + 1).Find(&pos).Error; err != nil {
+		return nil, "", mapBookError(err, "failed to list Books after cursor")
+	}
+
+	var next string
+	if len(pos) > limit {
+		pos = pos[:limit]
+		last := pos[limit-1]
+		token, err := pagination.EncodeCreatedAtCursor(last.CreatedAt, last.ID)
+		if err != nil {
+			return nil, "", err
+		}
+		next = token
+	}
+
+	books := make([]*domain.Book, 0, len(pos))
+	for _, po := range pos {
+		books = append(books, po.ToDomain())
+	}
+
+	return books, next, nil
+}
+
+// booknameUniqueIndex 书名唯一索引，见 migrations/shared-db 中的 books 表定义
+const booknameUniqueIndex = "idx_books_bookname"
+
+// mapBookError 将数据库错误映射为领域错误
+// 记录不存在、唯一约束冲突映射为领域哨兵错误，其他错误按 db.ClassifyError 的分类携带错误码
+func mapBookError(err error, message string) error {
+	switch db.ClassifyError(err) {
+	case db.ErrorClassNotFound:
+		return domain.ErrBookNotFound
+	case db.ErrorClassUniqueViolation:
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.ConstraintName == booknameUniqueIndex {
+			return domain.ErrBooknameTaken
+		}
+		return domain.ErrBookAlreadyExists
+	default:
+		return db.WrapError(err, message)
+	}
+}
@@ -0,0 +1,327 @@
+package psql
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/alfredchaos/demo/internal/book-service/domain"
+	"github.com/alfredchaos/demo/pkg/db"
+	apperrors "github.com/alfredchaos/demo/pkg/errors"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// testBookID 测试用的合法图书 ID
+const testBookID = "7c9e6679-7425-40de-944b-e07fc1f90ae7"
+
+// sqlRecorder 记录 DryRun 模式下生成的 SQL 及执行错误
+type sqlRecorder struct {
+	logger.Interface
+	statements []string
+	errs       []error
+}
+
+func (r *sqlRecorder) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	sql, _ := fc()
+	r.statements = append(r.statements, sql)
+	if err != nil {
+		r.errs = append(r.errs, err)
+	}
+}
+
+// newDryRunDB 创建 DryRun 模式的 GORM 连接
+// 底层使用未设置任何期望的 sqlmock，语句一旦发送到数据库就会报 unexpected call，测试结束时校验没有此类错误
+func newDryRunDB(t *testing.T, recorder *sqlRecorder) *gorm.DB {
+	t.Helper()
+
+	sqlDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() {
+		if len(recorder.errs) > 0 {
+			t.Errorf("dry-run statements reached the database: %v", recorder.errs)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+		sqlDB.Close()
+	})
+
+	gdb, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{
+		DryRun:                 true,
+		SkipDefaultTransaction: true,
+		DisableAutomaticPing:   true,
+		NamingStrategy:         db.NewNamingStrategy(&db.PostgresConfig{}),
+		Logger:                 recorder,
+	})
+	if err != nil {
+		t.Fatalf("failed to open dry-run db: %v", err)
+	}
+	return gdb
+}
+
+// newMockDB 基于 sqlmock 创建 GORM 连接
+func newMockDB(t *testing.T) (*gorm.DB, sqlmock.Sqlmock) {
+	t.Helper()
+
+	sqlDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+
+	gdb, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{
+		NamingStrategy: db.NewNamingStrategy(&db.PostgresConfig{}),
+		Logger:         logger.Discard,
+	})
+	if err != nil {
+		t.Fatalf("failed to open gorm: %v", err)
+	}
+	return gdb, mock
+}
+
+func TestBookPgRepository_CreateAndGetByBooknameUseSameTableAndColumn(t *testing.T) {
+	recorder := &sqlRecorder{Interface: logger.Discard}
+	repo := NewBookPgRepository(newDryRunDB(t, recorder), nil)
+	ctx := context.Background()
+
+	if err := repo.Create(ctx, domain.NewBook("golang", "author@example.com")); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	// DryRun 模式下不会返回记录，只校验生成的 SQL
+	_, _ = repo.GetByBookname(ctx, "golang")
+
+	if len(recorder.statements) != 2 {
+		t.Fatalf("want 2 statements, got %d: %v", len(recorder.statements), recorder.statements)
+	}
+
+	insert, query := recorder.statements[0], recorder.statements[1]
+	if !strings.Contains(insert, `INSERT INTO "books"`) || !strings.Contains(insert, `"bookname"`) {
+		t.Fatalf("unexpected insert sql: %s", insert)
+	}
+	if !strings.Contains(query, `FROM "books"`) || !strings.Contains(query, "bookname = ") {
+		t.Fatalf("unexpected query sql: %s", query)
+	}
+}
+
+func TestBookPgRepository_GetByBooknameReturnsCreatedBook(t *testing.T) {
+	gdb, mock := newMockDB(t)
+	repo := NewBookPgRepository(gdb, nil)
+	ctx := context.Background()
+
+	book := domain.NewBook("golang", "author@example.com")
+	book.ID = testBookID
+	mock.ExpectBegin()
+	mock.ExpectExec(`INSERT INTO "books" \("id","bookname","email","created_at","updated_at"\)`).
+		WithArgs(testBookID, "golang", "author@example.com", sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	if err := repo.Create(ctx, book); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	mock.ExpectQuery(`SELECT \* FROM "books" WHERE bookname = \$1`).
+		WithArgs("golang").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "bookname", "email", "created_at", "updated_at"}).
+			AddRow(testBookID, "golang", "author@example.com", book.CreatedAt, book.UpdatedAt))
+
+	got, err := repo.GetByBookname(ctx, "golang")
+	if err != nil {
+		t.Fatalf("get by bookname: %v", err)
+	}
+	if got.ID != testBookID || got.Bookname != "golang" || got.Email != "author@example.com" {
+		t.Fatalf("unexpected book: %+v", got)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBookPgRepository_UpdateUsesSnakeCaseColumn(t *testing.T) {
+	recorder := &sqlRecorder{Interface: logger.Discard}
+	repo := NewBookPgRepository(newDryRunDB(t, recorder), nil)
+
+	book := domain.NewBook("golang", "author@example.com")
+	book.ID = testBookID
+	// DryRun 模式下 RowsAffected 为 0，只校验生成的 SQL
+	_ = repo.Update(context.Background(), book)
+
+	if len(recorder.statements) != 1 {
+		t.Fatalf("want 1 statement, got %d: %v", len(recorder.statements), recorder.statements)
+	}
+	if update := recorder.statements[0]; !strings.Contains(update, `UPDATE "books" SET "bookname"=`) {
+		t.Fatalf("unexpected update sql: %s", update)
+	}
+}
+
+func TestBookPgRepository_Exists(t *testing.T) {
+	tests := []struct {
+		name string
+		rows *sqlmock.Rows
+		want bool
+	}{
+		{name: "existing", rows: sqlmock.NewRows([]string{"?column?"}).AddRow(1), want: true},
+		{name: "missing", rows: sqlmock.NewRows([]string{"?column?"}), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gdb, mock := newMockDB(t)
+			mock.ExpectQuery(`SELECT 1 FROM "books" WHERE id = \$1 LIMIT 1`).
+				WithArgs(testBookID).
+				WillReturnRows(tt.rows)
+
+			got, err := NewBookPgRepository(gdb, nil).Exists(context.Background(), testBookID)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("want %v, got %v", tt.want, got)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestBookPgRepository_WithTxCountsOnTransaction(t *testing.T) {
+	gdb, mock := newMockDB(t)
+	// 只有一个连接：事务副本若在原连接池上统计会一直等待连接，直到 ctx 超时
+	sqlDB, err := gdb.DB()
+	if err != nil {
+		t.Fatalf("get sql.DB: %v", err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+
+	repo := NewBookPgRepository(gdb, nil)
+	countRows := func(n int64) *sqlmock.Rows { return sqlmock.NewRows([]string{"count"}).AddRow(n) }
+	mock.ExpectQuery(`SELECT count\(\*\) FROM "books"`).WillReturnRows(countRows(5))
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT count\(\*\) FROM "books"`).WillReturnRows(countRows(6))
+	mock.ExpectRollback()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	if n, err := repo.Count(ctx); err != nil || n != 5 {
+		t.Fatalf("want 5 before transaction, got %d, %v", n, err)
+	}
+
+	tx := gdb.Begin()
+	// 事务内的行数包含未提交的写入，不使用原仓库的缓存
+	if n, err := repo.WithTx(tx).Count(ctx); err != nil || n != 6 {
+		t.Fatalf("want 6 inside transaction, got %d, %v", n, err)
+	}
+	if err := tx.Rollback().Error; err != nil {
+		t.Fatalf("rollback: %v", err)
+	}
+
+	// 事务内的结果不写入缓存
+	if n, err := repo.Count(ctx); err != nil || n != 5 {
+		t.Fatalf("want cached 5 after rollback, got %d, %v", n, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBookPgRepository_ValidationErrorsSkipQuery(t *testing.T) {
+	gdb, mock := newMockDB(t)
+	repo := NewBookPgRepository(gdb, nil)
+	ctx := context.Background()
+
+	// 未设置任何期望，访问数据库会导致 sqlmock 报错
+	checks := map[string]error{
+		"Create": repo.Create(ctx, &domain.Book{Email: "author@example.com"}),
+		"Update": repo.Update(ctx, &domain.Book{ID: testBookID, Bookname: "golang"}),
+	}
+	for op, err := range checks {
+		var verr *domain.ValidationError
+		if !errors.As(err, &verr) || !errors.Is(err, domain.ErrInvalidBook) {
+			t.Fatalf("%s: want ValidationError, got %v", op, err)
+		}
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBookPgRepository_MapsDatabaseErrors(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("bookname taken", func(t *testing.T) {
+		gdb, mock := newMockDB(t)
+		mock.ExpectBegin()
+		mock.ExpectExec(`INSERT INTO "books"`).WillReturnError(&pgconn.PgError{Code: "23505", ConstraintName: "idx_books_bookname"})
+		mock.ExpectRollback()
+
+		err := NewBookPgRepository(gdb, nil).Create(ctx, domain.NewBook("golang", "author@example.com"))
+		if !errors.Is(err, domain.ErrBooknameTaken) {
+			t.Fatalf("want ErrBooknameTaken, got %v", err)
+		}
+	})
+
+	t.Run("id conflict", func(t *testing.T) {
+		gdb, mock := newMockDB(t)
+		mock.ExpectBegin()
+		mock.ExpectExec(`INSERT INTO "books"`).WillReturnError(&pgconn.PgError{Code: "23505", ConstraintName: "books_pkey"})
+		mock.ExpectRollback()
+
+		err := NewBookPgRepository(gdb, nil).Create(ctx, domain.NewBook("golang", "author@example.com"))
+		if !errors.Is(err, domain.ErrBookAlreadyExists) {
+			t.Fatalf("want ErrBookAlreadyExists, got %v", err)
+		}
+	})
+
+	t.Run("update bookname taken", func(t *testing.T) {
+		gdb, mock := newMockDB(t)
+		mock.ExpectBegin()
+		mock.ExpectExec(`UPDATE "books"`).WillReturnError(&pgconn.PgError{Code: "23505", ConstraintName: "idx_books_bookname"})
+		mock.ExpectRollback()
+
+		book := domain.NewBook("golang", "author@example.com")
+		book.ID = testBookID
+		if err := NewBookPgRepository(gdb, nil).Update(ctx, book); !errors.Is(err, domain.ErrBooknameTaken) {
+			t.Fatalf("want ErrBooknameTaken, got %v", err)
+		}
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		gdb, mock := newMockDB(t)
+		mock.ExpectQuery(`SELECT \* FROM "books" WHERE bookname = \$1`).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+		if _, err := NewBookPgRepository(gdb, nil).GetByBookname(ctx, "golang"); !errors.Is(err, domain.ErrBookNotFound) {
+			t.Fatalf("want ErrBookNotFound, got %v", err)
+		}
+	})
+
+	t.Run("delete missing", func(t *testing.T) {
+		gdb, mock := newMockDB(t)
+		mock.ExpectBegin()
+		mock.ExpectExec(`DELETE FROM "books"`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectCommit()
+
+		if err := NewBookPgRepository(gdb, nil).Delete(ctx, testBookID); !errors.Is(err, domain.ErrBookNotFound) {
+			t.Fatalf("want ErrBookNotFound, got %v", err)
+		}
+	})
+
+	t.Run("connection error", func(t *testing.T) {
+		gdb, mock := newMockDB(t)
+		mock.ExpectQuery(`SELECT \* FROM "books" WHERE id = \$1`).WillReturnError(&pgconn.PgError{Code: "08006"})
+
+		_, err := NewBookPgRepository(gdb, nil).GetByID(ctx, testBookID)
+		if apperrors.CodeOf(err) != apperrors.ErrServiceUnavailable {
+			t.Fatalf("want service unavailable code, got %v", err)
+		}
+	})
+}
@@ -8,21 +8,29 @@ import (
 
 type BookRepository interface {
 	Create(ctx context.Context, book *domain.Book) error
-	GetByID(ctx context.Context, id string) (*domain.Book, error)
+	GetByID(ctx context.Context, id domain.BookID) (*domain.Book, error)
+	// Exists 判断图书是否存在，不加载完整记录
+	Exists(ctx context.Context, id domain.BookID) (bool, error)
 	GetByBookname(ctx context.Context, bookname string) (*domain.Book, error)
 	Update(ctx context.Context, book *domain.Book) error
-	Delete(ctx context.Context, id string) error
+	Delete(ctx context.Context, id domain.BookID) error
 	List(ctx context.Context, offset, limit int) ([]*domain.Book, error)
+	// ListAfter 基于游标的键集分页，next 为空表示没有更多数据
+	ListAfter(ctx context.Context, cursor string, limit int) (books []*domain.Book, next string, err error)
+	// Count 统计数量，结果可能被短暂缓存或为估算值
+	Count(ctx context.Context) (int64, error)
 }
 
 type BookDocumentRepository interface {
-	SaveDocument(ctx context.Context, bookID string, document map[string]interface{}) error
-	GetDocument(ctx context.Context, bookID string) (map[string]interface{}, error)
-	DeleteDocument(ctx context.Context, bookID string) error
+	SaveDocument(ctx context.Context, bookID domain.BookID, document map[string]interface{}) error
+	GetDocument(ctx context.Context, bookID domain.BookID) (map[string]interface{}, error)
+	// Exists 判断图书文档是否存在，不加载完整文档
+	Exists(ctx context.Context, bookID domain.BookID) (bool, error)
+	DeleteDocument(ctx context.Context, bookID domain.BookID) error
 
 	// filter: MongoDB 查询条件，例如 bson.M{"bookname": "alice"}
 	FindDocuments(ctx context.Context, filter map[string]interface{}, skip, limit int64) ([]map[string]interface{}, error)
 
 	// fields: 要更新的字段，例如 map[string]interface{}{"email": "new@example.com"}
-	UpdateDocumentFields(ctx context.Context, bookID string, fields map[string]interface{}) error
+	UpdateDocumentFields(ctx context.Context, bookID domain.BookID, fields map[string]interface{}) error
 }
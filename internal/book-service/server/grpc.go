@@ -6,28 +6,45 @@ import (
 
 	"github.com/alfredchaos/demo/internal/book-service/conf"
 	"github.com/alfredchaos/demo/pkg/log"
+	"github.com/alfredchaos/demo/pkg/netutil"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
 )
 
 // GRPCServer gRPC 服务器封装
 type GRPCServer struct {
-	server *grpc.Server
-	config *conf.ServerConfig
+	server   *grpc.Server
+	config   *conf.ServerConfig
+	listener net.Listener
 }
 
-// Start 启动 gRPC 服务器
-func (s *GRPCServer) Start() error {
+// Listen 绑定监听端口
+// 与 Start 分开调用时，可以在端口绑定成功之后、开始服务之前标记就绪
+func (s *GRPCServer) Listen() error {
 	addr := fmt.Sprintf("%s:%d", s.config.Host, s.config.Port)
 
 	listener, err := net.Listen("tcp", addr)
 	if err != nil {
 		return fmt.Errorf("failed to listen: %w", err)
 	}
+	if s.config.MaxConnections > 0 {
+		listener = netutil.LimitListener(listener, s.config.MaxConnections)
+	}
+	s.listener = listener
+	return nil
+}
+
+// Start 启动 gRPC 服务器，未调用 Listen 时先绑定监听端口
+func (s *GRPCServer) Start() error {
+	if s.listener == nil {
+		if err := s.Listen(); err != nil {
+			return err
+		}
+	}
 
-	log.Info("gRPC server starting", zap.String("addr", addr))
+	log.Info("gRPC server starting", zap.String("addr", s.listener.Addr().String()))
 
-	if err := s.server.Serve(listener); err != nil {
+	if err := s.server.Serve(s.listener); err != nil {
 		return fmt.Errorf("failed to serve: %w", err)
 	}
 
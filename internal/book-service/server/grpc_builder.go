@@ -16,6 +16,7 @@ type ServiceRegistrar func(*grpc.Server)
 
 type GRPCServerBuilder struct {
 	config     *conf.ServerConfig
+	middleware middleware.Config
 	registrars []ServiceRegistrar
 }
 
@@ -34,21 +35,37 @@ func (b *GRPCServerBuilder) WithBookService(svc *service.BookService) *GRPCServe
 	return b
 }
 
+// WithMiddleware 设置拦截器配置，未调用时启用全部默认拦截器
+func (b *GRPCServerBuilder) WithMiddleware(cfg middleware.Config) *GRPCServerBuilder {
+	b.middleware = cfg
+	return b
+}
+
 // Build 构建 gRPC 服务器
 func (b *GRPCServerBuilder) Build() *GRPCServer {
-	server := grpc.NewServer(
-		// 一元拦截器（按顺序执行）
-		grpc.ChainUnaryInterceptor(
-			middleware.UnaryServerRecovery(), // 1. Panic恢复
-			middleware.UnaryServerTracing(),  // 2. 追踪
-			middleware.UnaryServerLogging(),  // 3. 日志记录
-		),
-		// 流拦截器（按顺序执行）
-		grpc.ChainStreamInterceptor(
-			middleware.StreamServerRecovery(),
-			middleware.StreamServerTracing(),
-			middleware.StreamServerLogging(),
-		),
+	mw := b.middleware
+	// 未单独配置慢请求阈值时沿用 server.slow_threshold
+	if mw.Logging.SlowThreshold == 0 {
+		mw.Logging.SlowThreshold = time.Duration(b.config.SlowThreshold) * time.Millisecond
+	}
+
+	unary, stream := mw.Interceptors()
+	var opts []grpc.ServerOption
+	if n := b.config.MaxInFlight; n > 0 {
+		// 全局并发上限，超过时返回 ResourceExhausted，放在拦截器链最前面
+		limiter := middleware.NewConcurrencyLimiter(n)
+		unary = append([]grpc.UnaryServerInterceptor{middleware.UnaryServerConcurrencyLimit(limiter)}, unary...)
+		stream = append([]grpc.StreamServerInterceptor{middleware.StreamServerConcurrencyLimit(limiter)}, stream...)
+	}
+	if n := b.config.MaxConcurrentStreams; n > 0 {
+		// 单连接的 HTTP/2 流上限，由客户端在连接上排队
+		opts = append(opts, grpc.MaxConcurrentStreams(n))
+	}
+
+	opts = append(opts,
+		// 拦截器链由配置决定，顺序见 middleware.Config
+		grpc.ChainUnaryInterceptor(unary...),
+		grpc.ChainStreamInterceptor(stream...),
 		// KeepAlive 策略：允许客户端发送 ping
 		grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
 			MinTime:             30 * time.Second, // 允许客户端最快30秒发一次ping（小于客户端的60秒）
@@ -63,6 +80,7 @@ func (b *GRPCServerBuilder) Build() *GRPCServer {
 			Timeout:               1 * time.Second,  // ping超时1秒
 		}),
 	)
+	server := grpc.NewServer(opts...)
 
 	// 注册所有服务
 	for _, registrar := range b.registrars {
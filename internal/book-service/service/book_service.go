@@ -5,7 +5,10 @@ import (
 
 	bookv1 "github.com/alfredchaos/demo/api/book/v1"
 	"github.com/alfredchaos/demo/internal/book-service/biz"
+	"github.com/alfredchaos/demo/internal/book-service/domain"
+	"github.com/alfredchaos/demo/pkg/errors"
 	"github.com/alfredchaos/demo/pkg/log"
+	"github.com/alfredchaos/demo/pkg/pagination"
 	"go.uber.org/zap"
 )
 
@@ -30,7 +33,7 @@ func (s *BookService) JustTellMe(ctx context.Context, req *bookv1.TellMeRequest)
 	message, err := s.useCase.JustTellMe(ctx, "")
 	if err != nil {
 		log.WithContext(ctx).Error("failed to say hello", zap.Error(err))
-		return nil, err
+		return nil, errors.ToGRPCError(err)
 	}
 
 	log.WithContext(ctx).Info("SayHello completed", zap.String("message", message))
@@ -40,3 +43,79 @@ func (s *BookService) JustTellMe(ctx context.Context, req *bookv1.TellMeRequest)
 		Message: message,
 	}, nil
 }
+
+// CountBooks 实现BookService.CountBooks方法
+func (s *BookService) CountBooks(ctx context.Context, req *bookv1.CountBooksRequest) (*bookv1.CountBooksResponse, error) {
+	count, err := s.useCase.CountBooks(ctx)
+	if err != nil {
+		log.WithContext(ctx).Error("failed to count books", zap.Error(err))
+		return nil, errors.ToGRPCError(err)
+	}
+
+	return &bookv1.CountBooksResponse{
+		Count: count,
+	}, nil
+}
+
+// CreateBook 实现BookService.CreateBook方法
+func (s *BookService) CreateBook(ctx context.Context, req *bookv1.CreateBookRequest) (*bookv1.CreateBookResponse, error) {
+	book, err := s.useCase.CreateBook(ctx, req.GetBookname(), req.GetEmail())
+	if err != nil {
+		log.WithContext(ctx).Error("failed to create book", zap.Error(err))
+		return nil, errors.ToGRPCError(err)
+	}
+
+	return &bookv1.CreateBookResponse{
+		Book: toBookInfo(book),
+	}, nil
+}
+
+// GetBook 实现BookService.GetBook方法
+func (s *BookService) GetBook(ctx context.Context, req *bookv1.GetBookRequest) (*bookv1.GetBookResponse, error) {
+	id, err := domain.ParseBookID(req.GetId())
+	if err != nil {
+		return nil, errors.ToGRPCError(err)
+	}
+
+	book, err := s.useCase.GetBook(ctx, id)
+	if err != nil {
+		log.WithContext(ctx).Error("failed to get book", zap.String("book_id", req.GetId()), zap.Error(err))
+		return nil, errors.ToGRPCError(err)
+	}
+
+	return &bookv1.GetBookResponse{
+		Book: toBookInfo(book),
+	}, nil
+}
+
+// ListBooks 实现BookService.ListBooks方法
+func (s *BookService) ListBooks(ctx context.Context, req *bookv1.ListBooksRequest) (*bookv1.ListBooksResponse, error) {
+	limit, cursor, err := pagination.NormalizePageRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	books, next, err := s.useCase.ListBooks(ctx, cursor, limit)
+	if err != nil {
+		log.WithContext(ctx).Error("failed to list books", zap.Error(err))
+		return nil, errors.ToGRPCError(err)
+	}
+
+	resp := &bookv1.ListBooksResponse{
+		Books:         make([]*bookv1.BookInfo, 0, len(books)),
+		NextPageToken: next,
+	}
+	for _, book := range books {
+		resp.Books = append(resp.Books, toBookInfo(book))
+	}
+	return resp, nil
+}
+
+// toBookInfo 将领域对象转换为 gRPC 图书信息
+func toBookInfo(book *domain.Book) *bookv1.BookInfo {
+	return &bookv1.BookInfo{
+		Id:       book.ID.String(),
+		Bookname: book.Bookname,
+		Email:    book.Email,
+	}
+}
@@ -0,0 +1,211 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+
+	bookv1 "github.com/alfredchaos/demo/api/book/v1"
+	"github.com/alfredchaos/demo/internal/book-service/biz"
+	"github.com/alfredchaos/demo/internal/book-service/domain"
+	"github.com/alfredchaos/demo/pkg/log"
+	"github.com/alfredchaos/demo/pkg/pagination"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// useNopLogger 测试期间使用空日志，结束后恢复原日志
+func useNopLogger(t *testing.T) {
+	t.Helper()
+	original := log.Logger
+	log.Logger = zap.NewNop()
+	t.Cleanup(func() { log.Logger = original })
+}
+
+// fakeBookRepo 内存图书仓库，按插入顺序倒序列出，游标为已返回的条数
+type fakeBookRepo struct {
+	books   []*domain.Book
+	listErr error
+}
+
+func (r *fakeBookRepo) Create(ctx context.Context, book *domain.Book) error {
+	for _, existing := range r.books {
+		if existing.Bookname == book.Bookname {
+			return domain.ErrBooknameTaken
+		}
+	}
+	if book.ID == "" {
+		book.ID = domain.BookID("b" + strconv.Itoa(len(r.books)+1))
+	}
+	copied := *book
+	r.books = append(r.books, &copied)
+	return nil
+}
+
+func (r *fakeBookRepo) GetByID(ctx context.Context, id domain.BookID) (*domain.Book, error) {
+	for _, book := range r.books {
+		if book.ID == id {
+			copied := *book
+			return &copied, nil
+		}
+	}
+	return nil, domain.ErrBookNotFound
+}
+
+func (r *fakeBookRepo) Exists(ctx context.Context, id domain.BookID) (bool, error) {
+	_, err := r.GetByID(ctx, id)
+	return err == nil, nil
+}
+
+func (r *fakeBookRepo) GetByBookname(ctx context.Context, bookname string) (*domain.Book, error) {
+	return nil, domain.ErrBookNotFound
+}
+
+func (r *fakeBookRepo) Update(ctx context.Context, book *domain.Book) error { return nil }
+
+func (r *fakeBookRepo) Delete(ctx context.Context, id domain.BookID) error { return nil }
+
+func (r *fakeBookRepo) List(ctx context.Context, offset, limit int) ([]*domain.Book, error) {
+	return nil, nil
+}
+
+func (r *fakeBookRepo) ListAfter(ctx context.Context, cursor string, limit int) ([]*domain.Book, string, error) {
+	if r.listErr != nil {
+		return nil, "", r.listErr
+	}
+
+	offset := 0
+	if cursor != "" {
+		fields, err := pagination.DecodeCursor(cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		offset, _ = strconv.Atoi(fields["offset"].(string))
+	}
+
+	sorted := make([]*domain.Book, 0, len(r.books))
+	for i := len(r.books) - 1; i >= 0; i-- {
+		sorted = append(sorted, r.books[i])
+	}
+
+	end := offset + limit
+	var next string
+	if end < len(sorted) {
+		var err error
+		if next, err = pagination.EncodeCursor(map[string]interface{}{"offset": strconv.Itoa(end)}); err != nil {
+			return nil, "", err
+		}
+	} else {
+		end = len(sorted)
+	}
+	return sorted[offset:end], next, nil
+}
+
+func (r *fakeBookRepo) Count(ctx context.Context) (int64, error) {
+	return int64(len(r.books)), nil
+}
+
+func newTestService(repo *fakeBookRepo) *BookService {
+	return NewBookService(biz.NewBookUseCase(repo))
+}
+
+func TestBookService_CreateBook(t *testing.T) {
+	useNopLogger(t)
+	repo := &fakeBookRepo{}
+	svc := newTestService(repo)
+
+	resp, err := svc.CreateBook(context.Background(), &bookv1.CreateBookRequest{Bookname: "go", Email: "go@example.com"})
+	if err != nil {
+		t.Fatalf("CreateBook: %v", err)
+	}
+	if resp.GetBook().GetId() == "" || resp.GetBook().GetBookname() != "go" || resp.GetBook().GetEmail() != "go@example.com" {
+		t.Fatalf("unexpected book: %+v", resp.GetBook())
+	}
+	if len(repo.books) != 1 {
+		t.Fatalf("want book persisted, got %d books", len(repo.books))
+	}
+
+	tests := []struct {
+		name string
+		req  *bookv1.CreateBookRequest
+		want codes.Code
+	}{
+		{"missing bookname", &bookv1.CreateBookRequest{Email: "go@example.com"}, codes.InvalidArgument},
+		{"missing email", &bookv1.CreateBookRequest{Bookname: "rust"}, codes.InvalidArgument},
+		{"duplicate bookname", &bookv1.CreateBookRequest{Bookname: "go", Email: "other@example.com"}, codes.AlreadyExists},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := svc.CreateBook(context.Background(), tt.req); status.Code(err) != tt.want {
+				t.Fatalf("want %s, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestBookService_GetBook(t *testing.T) {
+	useNopLogger(t)
+	id := domain.NewBookID()
+	repo := &fakeBookRepo{books: []*domain.Book{{ID: id, Bookname: "go", Email: "go@example.com"}}}
+	svc := newTestService(repo)
+
+	resp, err := svc.GetBook(context.Background(), &bookv1.GetBookRequest{Id: id.String()})
+	if err != nil {
+		t.Fatalf("GetBook: %v", err)
+	}
+	if resp.GetBook().GetBookname() != "go" {
+		t.Fatalf("unexpected book: %+v", resp.GetBook())
+	}
+
+	if _, err := svc.GetBook(context.Background(), &bookv1.GetBookRequest{Id: domain.NewBookID().String()}); status.Code(err) != codes.NotFound {
+		t.Fatalf("want NotFound for missing book, got %v", err)
+	}
+	for _, raw := range []string{"", "b1"} {
+		if _, err := svc.GetBook(context.Background(), &bookv1.GetBookRequest{Id: raw}); status.Code(err) != codes.InvalidArgument {
+			t.Fatalf("want InvalidArgument for id %q, got %v", raw, err)
+		}
+	}
+}
+
+func TestBookService_ListBooks(t *testing.T) {
+	useNopLogger(t)
+	repo := &fakeBookRepo{}
+	for _, name := range []string{"a", "b", "c"} {
+		repo.books = append(repo.books, &domain.Book{ID: domain.BookID(name), Bookname: name, Email: name + "@example.com"})
+	}
+	svc := newTestService(repo)
+
+	// 按页遍历，最新插入的排在前面
+	var got []string
+	req := &bookv1.ListBooksRequest{PageSize: 2}
+	for {
+		resp, err := svc.ListBooks(context.Background(), req)
+		if err != nil {
+			t.Fatalf("ListBooks: %v", err)
+		}
+		for _, book := range resp.GetBooks() {
+			got = append(got, book.GetId())
+		}
+		if resp.GetNextPageToken() == "" {
+			break
+		}
+		req = &bookv1.ListBooksRequest{PageSize: 2, PageToken: resp.GetNextPageToken()}
+	}
+	if len(got) != 3 || got[0] != "c" || got[1] != "b" || got[2] != "a" {
+		t.Fatalf("want [c b a], got %v", got)
+	}
+
+	if _, err := svc.ListBooks(context.Background(), &bookv1.ListBooksRequest{PageSize: -1}); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("want InvalidArgument for negative page size, got %v", err)
+	}
+	if _, err := svc.ListBooks(context.Background(), &bookv1.ListBooksRequest{PageToken: "not-a-cursor"}); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("want InvalidArgument for malformed page token, got %v", err)
+	}
+
+	repo.listErr = errors.New("connection reset")
+	if _, err := svc.ListBooks(context.Background(), &bookv1.ListBooksRequest{}); status.Code(err) != codes.Internal {
+		t.Fatalf("want Internal for repository failure, got %v", err)
+	}
+}
@@ -0,0 +1,23 @@
+// Package clients 登记本项目各下游服务的 gRPC 客户端工厂
+// pkg/grpcclient 只负责连接管理，不依赖具体服务的 proto 包；各服务启动时把这里的工厂交给 grpcclient.InitGRPCClientManager
+package clients
+
+import (
+	bookv1 "github.com/alfredchaos/demo/api/book/v1"
+	userv1 "github.com/alfredchaos/demo/api/user/v1"
+	"github.com/alfredchaos/demo/pkg/grpcclient"
+	"google.golang.org/grpc"
+)
+
+// Factories 返回内置的客户端工厂，按客户端类型（配置中的 client 字段）索引
+// 每次调用返回新的 map，调用方修改不会影响其他服务；新增下游服务时在此登记
+func Factories() map[string]grpcclient.ClientFactory {
+	return map[string]grpcclient.ClientFactory{
+		"user-service": func(conn *grpc.ClientConn) interface{} {
+			return userv1.NewUserServiceClient(conn)
+		},
+		"book-service": func(conn *grpc.ClientConn) interface{} {
+			return bookv1.NewBookServiceClient(conn)
+		},
+	}
+}
@@ -9,6 +9,7 @@ import (
 
 // TaskMessage 任务消息结构
 type TaskMessage struct {
+	TaskID    string `json:"task_id"` // 任务 ID，处理完成后按此保存执行结果；旧发布者的消息可能为空
 	UserID    string `json:"user_id"`
 	Username  string `json:"username"`
 	TaskType  string `json:"task_type"`
@@ -2,12 +2,17 @@ package conf
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/alfredchaos/demo/pkg/cache"
 	"github.com/alfredchaos/demo/pkg/db"
+	"github.com/alfredchaos/demo/pkg/debugserver"
 	"github.com/alfredchaos/demo/pkg/grpcclient"
+	"github.com/alfredchaos/demo/pkg/health"
 	"github.com/alfredchaos/demo/pkg/log"
+	"github.com/alfredchaos/demo/pkg/middleware"
 	"github.com/alfredchaos/demo/pkg/mq"
+	"github.com/alfredchaos/demo/pkg/startup"
 )
 
 // 配置类型别名
@@ -19,15 +24,22 @@ type (
 
 // Config nice-service 配置结构
 type Config struct {
-	Server      ServerConfig      `yaml:"server" mapstructure:"server"`             // 服务器配置（未来可能需要）
-	Log         log.LogConfig     `yaml:"log" mapstructure:"log"`                   // 日志配置
-	RabbitMQ    MQConfig          `yaml:"rabbitmq" mapstructure:"rabbitmq"`         // 消息队列配置（主要）
-	GRPCClients grpcclient.Config `yaml:"grpc_clients" mapstructure:"grpc_clients"` // gRPC客户端配置（未来可能需要）
+	Server       ServerConfig        `yaml:"server" mapstructure:"server"`               // 服务器配置（未来可能需要）
+	Log          log.LogConfig       `yaml:"log" mapstructure:"log"`                     // 日志配置
+	RabbitMQ     MQConfig            `yaml:"rabbitmq" mapstructure:"rabbitmq"`           // 消息队列配置（主要）
+	GRPCClients  grpcclient.Config   `yaml:"grpc_clients" mapstructure:"grpc_clients"`   // gRPC客户端配置（未来可能需要）
+	Middleware   middleware.Config   `yaml:"middleware" mapstructure:"middleware"`       // gRPC 服务端拦截器开关（未来可能需要）
+	Debug        debugserver.Config  `yaml:"debug" mapstructure:"debug"`                 // 调试服务（pprof）配置，默认关闭
+	StartupRetry startup.RetryPolicy `yaml:"startup_retry" mapstructure:"startup_retry"` // 启动时连接依赖的重试策略
+	Probe        health.ProbeConfig  `yaml:"probe" mapstructure:"probe"`                 // 探针服务配置（/livez、/readyz）
+
+	// 启用 Redis 后，任务处理完成时按消息中的 task_id 保存执行结果（见 pkg/taskresult）
+	Redis         CacheConfig   `yaml:"redis" mapstructure:"redis"`
+	TaskResultTTL time.Duration `yaml:"task_result_ttl" mapstructure:"task_result_ttl"` // 任务结果保留时间，默认 10m
 	
 	// 未来可能需要的配置（暂时注释）
 	// Database    DatabaseConfig    `yaml:"database" mapstructure:"database"`
 	// MongoDB     db.MongoConfig    `yaml:"mongodb" mapstructure:"mongodb"`
-	// Redis       CacheConfig       `yaml:"redis" mapstructure:"redis"`
 }
 
 // ServerConfig 服务器配置
@@ -6,8 +6,12 @@ import (
 	"github.com/alfredchaos/demo/internal/nice-service/messaging"
 	"github.com/alfredchaos/demo/internal/nice-service/messaging/rabbitmq"
 	"github.com/alfredchaos/demo/internal/nice-service/service"
+	"github.com/alfredchaos/demo/pkg/cache"
 	"github.com/alfredchaos/demo/pkg/grpcclient"
+	"github.com/alfredchaos/demo/pkg/health"
 	"github.com/alfredchaos/demo/pkg/log"
+	"github.com/alfredchaos/demo/pkg/startup"
+	"github.com/alfredchaos/demo/pkg/taskresult"
 	"go.uber.org/zap"
 )
 
@@ -17,6 +21,7 @@ type AppContext struct {
 	Consumer      messaging.Consumer     // 消息消费者
 	HandleService *service.HandleService // 消息处理服务（Service层）
 	TaskUseCase   *biz.TaskUseCase       // 任务业务逻辑（Biz层）
+	Redis         *cache.RedisClient     // 保存任务结果的 Redis，未启用时为 nil
 
 	// 未来可能需要的字段（暂时注释）
 	// GRPCClients  map[string]interface{}  // gRPC客户端
@@ -29,18 +34,43 @@ type AppContext struct {
 type Dependencies struct {
 	ClientManager *grpcclient.Manager // gRPC客户端管理器
 	Cfg           *conf.Config        // 配置
+	Readiness     *health.Readiness   // 就绪状态，依赖连接成功后在其中标记，可为 nil
 }
 
 // InjectDependencies 注入依赖并初始化应用上下文
 func InjectDependencies(deps *Dependencies) (*AppContext, error) {
+	// 启用 Redis 时保存任务执行结果，供网关按任务 ID 查询
+	var redisClient *cache.RedisClient
+	if deps.Cfg.Redis.IsEnabled() {
+		deps.Readiness.Require("redis")
+		if err := startup.RetryConnect("redis", func() (err error) {
+			redisClient, err = cache.NewRedisClient(&deps.Cfg.Redis)
+			return err
+		}, deps.Cfg.StartupRetry); err != nil {
+			return nil, err
+		}
+		deps.Readiness.MarkReady("redis", redisClient)
+	}
+
 	// 初始化 RabbitMQ 消息队列（nice-service作为消费者）
-	messageQueue := rabbitmq.MustInitRabbitMQ(&deps.Cfg.RabbitMQ)
+	// RabbitMQ 可能晚于本服务就绪，连接失败时按 startup_retry 重试
+	var messageQueue *rabbitmq.MessageQueue
+	deps.Readiness.Require("rabbitmq")
+	if err := startup.RetryConnect("rabbitmq", func() (err error) {
+		messageQueue, err = rabbitmq.InitRabbitMQ(&deps.Cfg.RabbitMQ)
+		return err
+	}, deps.Cfg.StartupRetry); err != nil {
+		closeRedis(redisClient)
+		return nil, err
+	}
+	deps.Readiness.MarkReady("rabbitmq", messageQueue)
 	log.Info("rabbitmq message queue initialized successfully")
 
 	// 创建消费者
 	consumer, err := messageQueue.NewConsumer()
 	if err != nil {
 		log.Error("failed to create consumer", zap.Error(err))
+		closeRedis(redisClient)
 		return nil, err
 	}
 	log.Info("consumer created successfully")
@@ -55,6 +85,9 @@ func InjectDependencies(deps *Dependencies) (*AppContext, error) {
 
 	// 2. Service层 - 服务层（依赖Biz层）
 	handleService := service.NewHandleService(taskUseCase)
+	if redisClient != nil {
+		handleService.WithResultStore(taskresult.NewTaskResultStore(redisClient, deps.Cfg.TaskResultTTL))
+	}
 	log.Info("handle service created successfully")
 
 	// 未来如果需要 gRPC 客户端调用其他服务
@@ -85,5 +118,16 @@ func InjectDependencies(deps *Dependencies) (*AppContext, error) {
 		Consumer:      consumer,
 		HandleService: handleService,
 		TaskUseCase:   taskUseCase,
+		Redis:         redisClient,
 	}, nil
 }
+
+// closeRedis 依赖初始化失败时关闭已连接的 Redis
+func closeRedis(client *cache.RedisClient) {
+	if client == nil {
+		return
+	}
+	if err := client.Close(); err != nil {
+		log.Error("failed to close redis client", zap.Error(err))
+	}
+}
@@ -1,20 +1,35 @@
 package messaging
 
-import "context"
+import (
+	"context"
+
+	"github.com/alfredchaos/demo/pkg/mq"
+)
 
 // MessageHandler 消息处理函数类型
 type MessageHandler func(ctx context.Context, message []byte) error
 
+// DeliveryHandler 完整投递的处理函数类型（含 RoutingKey 等元数据）
+type DeliveryHandler = mq.DeliveryHandler
+
+// OutgoingMessage 批量发布的单条消息
+type OutgoingMessage = mq.OutgoingMessage
+
 // Publisher 消息发布者接口
 type Publisher interface {
 	Publish(ctx context.Context, message []byte) error
 	PublishWithRouting(ctx context.Context, routingKey string, message []byte) error
+	// PublishWithPriority 按优先级发布，仅对声明了 max_priority 的队列生效
+	PublishWithPriority(ctx context.Context, routingKey string, message []byte, priority uint8) error
+	// PublishMany 批量发布，部分失败时返回 *mq.PublishManyError
+	PublishMany(ctx context.Context, msgs []OutgoingMessage) error
 	Close() error
 }
 
 // Consumer 消息消费者接口
 type Consumer interface {
 	Consume(ctx context.Context, handler MessageHandler) error
+	ConsumeDeliveries(ctx context.Context, handler DeliveryHandler) error
 	Close() error
 }
 
@@ -14,6 +14,7 @@ type consumer struct {
 }
 
 // NewConsumer 创建 RabbitMQ 消费者
+// 处理失败时使用 mq 的默认确认策略：永久错误（见 mq.IsPermanent）直接进入死信，其余错误在达到 delivery_limit 前重新入队
 func NewConsumer(client *mq.RabbitMQClient) messaging.Consumer {
 	return &consumer{
 		mqConsumer: mq.NewRabbitMQConsumer(client),
@@ -32,6 +33,11 @@ func (c *consumer) Consume(ctx context.Context, handler messaging.MessageHandler
 	return c.mqConsumer.Consume(ctx, mqHandler)
 }
 
+// ConsumeDeliveries 开始消费消息，处理函数接收完整的投递
+func (c *consumer) ConsumeDeliveries(ctx context.Context, handler messaging.DeliveryHandler) error {
+	return c.mqConsumer.ConsumeDeliveries(ctx, handler)
+}
+
 // Close 关闭消费者
 func (c *consumer) Close() error {
 	return c.mqConsumer.Close()
@@ -1,6 +1,7 @@
 package rabbitmq
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/alfredchaos/demo/internal/nice-service/messaging"
@@ -73,6 +74,14 @@ func (mq *MessageQueue) IsHealthy() bool {
 	return mq.client.IsConnected()
 }
 
+// PingContext 探测连接状态，实现 health.Pinger 接口
+func (mq *MessageQueue) PingContext(ctx context.Context) error {
+	if mq.client == nil {
+		return fmt.Errorf("rabbitmq client is not initialized")
+	}
+	return mq.client.PingContext(ctx)
+}
+
 // MustInitRabbitMQ 初始化 RabbitMQ，失败则 panic
 func MustInitRabbitMQ(cfg *mq.RabbitMQConfig) *MessageQueue {
 	mq, err := InitRabbitMQ(cfg)
@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"github.com/alfredchaos/demo/pkg/db"
+	apperrors "github.com/alfredchaos/demo/pkg/errors"
 )
 
 // Data 数据访问层容器
@@ -26,21 +27,17 @@ func NewData(
 
 // Close 关闭所有数据连接
 func (d *Data) Close(ctx context.Context) error {
-	var errs []error
+	var errs apperrors.MultiError
 
 	// 关闭 MongoDB
-	if err := d.closeMongo(ctx); err != nil {
-		errs = append(errs, err)
-	}
+	errs.Append(d.closeMongo(ctx))
 
 	// 关闭 PostgreSQL
-	if err := d.closePostgres(); err != nil {
-		errs = append(errs, err)
-	}
+	errs.Append(d.closePostgres())
 
 	// 汇总错误
-	if len(errs) > 0 {
-		return fmt.Errorf("failed to close data layer: %v", errs)
+	if err := errs.ErrorOrNil(); err != nil {
+		return fmt.Errorf("failed to close data layer: %w", err)
 	}
 
 	return nil
@@ -6,6 +6,7 @@ import (
 
 	"github.com/alfredchaos/demo/internal/book-service/conf"
 	"github.com/alfredchaos/demo/pkg/log"
+	"github.com/alfredchaos/demo/pkg/netutil"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
 )
@@ -24,6 +25,9 @@ func (s *GRPCServer) Start() error {
 	if err != nil {
 		return fmt.Errorf("failed to listen: %w", err)
 	}
+	if s.config.MaxConnections > 0 {
+		listener = netutil.LimitListener(listener, s.config.MaxConnections)
+	}
 
 	log.Info("gRPC server starting", zap.String("addr", addr))
 
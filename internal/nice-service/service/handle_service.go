@@ -2,11 +2,14 @@ package service
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/alfredchaos/demo/internal/nice-service/biz"
 	"github.com/alfredchaos/demo/pkg/log"
+	"github.com/alfredchaos/demo/pkg/mq"
+	"github.com/alfredchaos/demo/pkg/taskresult"
+	amqp "github.com/rabbitmq/amqp091-go"
 	"go.uber.org/zap"
 )
 
@@ -14,30 +17,75 @@ import (
 // 负责接收消息、解析消息、路由到具体的业务逻辑处理
 type HandleService struct {
 	taskUseCase *biz.TaskUseCase
+	taskDecoder *mq.VersionedDecoder[biz.TaskMessage]
+	results     resultStore // 任务结果存储，未配置时不保存
+}
+
+// resultStore 任务结果存储
+type resultStore interface {
+	Save(ctx context.Context, result *taskresult.Result) error
 }
 
 // NewHandleService 创建新的消息处理服务
 func NewHandleService(taskUseCase *biz.TaskUseCase) *HandleService {
 	return &HandleService{
 		taskUseCase: taskUseCase,
+		taskDecoder: newTaskDecoder(),
 	}
 }
 
-// HandleMessage 处理接收到的消息
-// 这是消息消费者的入口点
-func (s *HandleService) HandleMessage(ctx context.Context, message []byte) error {
+// WithResultStore 设置任务结果存储，任务处理完成后按消息中的 task_id 保存执行结果
+func (s *HandleService) WithResultStore(store resultStore) *HandleService {
+	s.results = store
+	return s
+}
+
+// Dispatcher 创建按路由键分发的消息处理器
+// 这是消息消费者的入口点，队列绑定的每个路由模式都应在此注册处理函数
+func (s *HandleService) Dispatcher() *mq.Dispatcher {
+	return mq.NewDispatcher().
+		Handle(mq.RoutingKeyTaskPattern, s.handleTaskDelivery).
+		Handle(mq.RoutingKeyNicePattern, s.HandleNiceMessage)
+}
+
+// handleTaskDelivery 处理 task.# 消息
+// 按 schema_version 消息头解码；不支持的版本与无法解码的消息体重试也不会成功，返回永久错误，不重新入队而是进入死信队列，
+// 待消费者升级后可从死信队列重放
+func (s *HandleService) handleTaskDelivery(ctx context.Context, delivery amqp.Delivery) error {
 	log.WithContext(ctx).Info("received message from rabbitmq",
-		zap.ByteString("raw_message", message))
+		zap.String("schema_version", mq.SchemaVersion(delivery.Headers)),
+		zap.ByteString("raw_message", delivery.Body))
 
-	// 解析消息
-	var taskMsg biz.TaskMessage
-	if err := json.Unmarshal(message, &taskMsg); err != nil {
-		log.WithContext(ctx).Error("failed to unmarshal message",
+	taskMsg, err := s.taskDecoder.Decode(delivery)
+	if err != nil {
+		log.WithContext(ctx).Error("failed to decode task message",
 			zap.Error(err),
-			zap.ByteString("message", message))
-		return fmt.Errorf("failed to unmarshal message: %w", err)
+			zap.ByteString("message", delivery.Body))
+		return err
+	}
+
+	return s.HandleTask(ctx, taskMsg)
+}
+
+// HandleNiceMessage 处理 nice.# 消息
+func (s *HandleService) HandleNiceMessage(ctx context.Context, delivery amqp.Delivery) error {
+	log.WithContext(ctx).Info("received nice message from rabbitmq",
+		zap.String("routing_key", delivery.RoutingKey),
+		zap.ByteString("raw_message", delivery.Body))
+
+	switch delivery.RoutingKey {
+	case mq.RoutingKeyNiceProcess:
+		// 暂无业务逻辑，确认消息即可
+		return nil
+	default:
+		log.WithContext(ctx).Warn("unknown nice routing key",
+			zap.String("routing_key", delivery.RoutingKey))
+		return mq.Permanent(fmt.Errorf("unknown nice routing key: %s", delivery.RoutingKey))
 	}
+}
 
+// HandleTask 处理已解码的 task 消息
+func (s *HandleService) HandleTask(ctx context.Context, taskMsg *biz.TaskMessage) error {
 	log.WithContext(ctx).Info("parsed task message",
 		zap.String("user_id", taskMsg.UserID),
 		zap.String("username", taskMsg.Username),
@@ -46,12 +94,42 @@ func (s *HandleService) HandleMessage(ctx context.Context, message []byte) error
 		zap.String("created_at", taskMsg.CreatedAt))
 
 	// 根据任务类型路由到不同的业务逻辑处理器
+	var err error
 	switch taskMsg.TaskType {
 	case "sayhello":
-		return s.taskUseCase.HandleSayHelloTask(ctx, &taskMsg)
+		err = s.taskUseCase.HandleSayHelloTask(ctx, taskMsg)
 	default:
 		log.WithContext(ctx).Warn("unknown task type",
 			zap.String("task_type", taskMsg.TaskType))
-		return fmt.Errorf("unknown task type: %s", taskMsg.TaskType)
+		err = mq.Permanent(fmt.Errorf("unknown task type: %s", taskMsg.TaskType))
+	}
+
+	s.saveResult(ctx, taskMsg, err)
+	return err
+}
+
+// saveResult 保存任务执行结果
+// 可重试的错误会重新投递，此时不写入结果；保存失败只记录日志，不影响消息确认
+func (s *HandleService) saveResult(ctx context.Context, taskMsg *biz.TaskMessage, err error) {
+	if s.results == nil || taskMsg.TaskID == "" {
+		return
+	}
+	if err != nil && !mq.IsPermanent(err) {
+		return
+	}
+
+	result := &taskresult.Result{
+		TaskID:     taskMsg.TaskID,
+		Status:     taskresult.StatusSucceeded,
+		FinishedAt: time.Now().UTC(),
+	}
+	if err != nil {
+		result.Status = taskresult.StatusFailed
+		result.Error = err.Error()
+	}
+	if saveErr := s.results.Save(ctx, result); saveErr != nil {
+		log.WithContext(ctx).Warn("failed to save task result",
+			zap.String("task_id", taskMsg.TaskID),
+			zap.Error(saveErr))
 	}
 }
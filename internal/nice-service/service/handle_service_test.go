@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/alfredchaos/demo/internal/nice-service/biz"
+	"github.com/alfredchaos/demo/pkg/log"
+	"github.com/alfredchaos/demo/pkg/mq"
+	"github.com/alfredchaos/demo/pkg/taskresult"
+	amqp "github.com/rabbitmq/amqp091-go"
+	"go.uber.org/zap"
+)
+
+// TestHandleTaskDelivery_UndecodableMessagesArePermanent 无法解码的消息返回永久错误，消费者不会重新入队
+func TestHandleTaskDelivery_UndecodableMessagesArePermanent(t *testing.T) {
+	prev := log.Logger
+	log.Logger = zap.NewNop()
+	t.Cleanup(func() { log.Logger = prev })
+
+	cases := []struct {
+		name     string
+		delivery amqp.Delivery
+	}{
+		{"invalid json", amqp.Delivery{RoutingKey: "task.sayhello", Body: []byte(`{not json`)}},
+		{"unsupported version", amqp.Delivery{
+			RoutingKey: "task.sayhello",
+			Headers:    amqp.Table{mq.SchemaVersionHeader: "99"},
+			Body:       []byte(`{}`),
+		}},
+		{"unknown task type", amqp.Delivery{RoutingKey: "task.unknown", Body: []byte(`{"task_type":"unknown"}`)}},
+	}
+
+	s := NewHandleService(nil)
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := s.handleTaskDelivery(context.Background(), c.delivery)
+			if err == nil || !mq.IsPermanent(err) {
+				t.Fatalf("want permanent error, got %v", err)
+			}
+		})
+	}
+}
+
+// fakeResultStore 记录保存的任务结果
+type fakeResultStore struct {
+	saved []*taskresult.Result
+	err   error
+}
+
+func (f *fakeResultStore) Save(ctx context.Context, result *taskresult.Result) error {
+	f.saved = append(f.saved, result)
+	return f.err
+}
+
+func TestHandleTask_SavesResult(t *testing.T) {
+	prev := log.Logger
+	log.Logger = zap.NewNop()
+	t.Cleanup(func() { log.Logger = prev })
+
+	cases := []struct {
+		name       string
+		msg        *biz.TaskMessage
+		wantStatus string // 为空表示不保存
+	}{
+		{"succeeded", &biz.TaskMessage{TaskID: "t1", TaskType: "sayhello"}, taskresult.StatusSucceeded},
+		{"permanent failure", &biz.TaskMessage{TaskID: "t2", TaskType: "unknown"}, taskresult.StatusFailed},
+		{"without task id", &biz.TaskMessage{TaskType: "sayhello"}, ""},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			store := &fakeResultStore{}
+			s := NewHandleService(biz.NewTaskUseCase()).WithResultStore(store)
+			s.HandleTask(context.Background(), c.msg)
+
+			if c.wantStatus == "" {
+				if len(store.saved) != 0 {
+					t.Fatalf("want no result saved, got %+v", store.saved)
+				}
+				return
+			}
+			if len(store.saved) != 1 {
+				t.Fatalf("want 1 result saved, got %d", len(store.saved))
+			}
+			got := store.saved[0]
+			if got.TaskID != c.msg.TaskID || got.Status != c.wantStatus || got.FinishedAt.IsZero() {
+				t.Fatalf("unexpected result: %+v", got)
+			}
+			if (c.wantStatus == taskresult.StatusFailed) != (got.Error != "") {
+				t.Fatalf("want error only on failure, got %q", got.Error)
+			}
+		})
+	}
+}
+
+func TestHandleTask_SaveFailureDoesNotFailTask(t *testing.T) {
+	prev := log.Logger
+	log.Logger = zap.NewNop()
+	t.Cleanup(func() { log.Logger = prev })
+
+	store := &fakeResultStore{err: errors.New("redis unavailable")}
+	s := NewHandleService(biz.NewTaskUseCase()).WithResultStore(store)
+	if err := s.HandleTask(context.Background(), &biz.TaskMessage{TaskID: "t1", TaskType: "sayhello"}); err != nil {
+		t.Fatalf("want task handled despite save failure, got %v", err)
+	}
+}
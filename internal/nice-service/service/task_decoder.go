@@ -0,0 +1,60 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/alfredchaos/demo/internal/nice-service/biz"
+	"github.com/alfredchaos/demo/pkg/mq"
+)
+
+// taskMessageV2 v2 格式的任务消息
+// 用户信息收拢到 user 对象中，创建时间改为毫秒时间戳
+type taskMessageV2 struct {
+	TaskID string `json:"task_id"`
+	User   struct {
+		ID       string `json:"id"`
+		Username string `json:"username"`
+	} `json:"user"`
+	TaskType  string `json:"task_type"`
+	Message   string `json:"message"`
+	CreatedAt int64  `json:"created_at_ms"`
+}
+
+// newTaskDecoder 创建任务消息解码器
+// 按 schema_version 消息头选择解码函数，新增格式时在此注册，旧版本保留到所有发布者升级完成
+func newTaskDecoder() *mq.VersionedDecoder[biz.TaskMessage] {
+	return mq.NewVersionedDecoder[biz.TaskMessage]().
+		Register("1", decodeTaskMessageV1).
+		Register("2", decodeTaskMessageV2)
+}
+
+// decodeTaskMessageV1 解码 v1 格式（未携带版本头的历史消息同样按 v1 处理）
+func decodeTaskMessageV1(body []byte) (*biz.TaskMessage, error) {
+	var msg biz.TaskMessage
+	if err := json.Unmarshal(body, &msg); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal message: %w", err)
+	}
+	return &msg, nil
+}
+
+// decodeTaskMessageV2 解码 v2 格式并转换为当前的 TaskMessage
+func decodeTaskMessageV2(body []byte) (*biz.TaskMessage, error) {
+	var v2 taskMessageV2
+	if err := json.Unmarshal(body, &v2); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal message: %w", err)
+	}
+
+	msg := &biz.TaskMessage{
+		TaskID:   v2.TaskID,
+		UserID:   v2.User.ID,
+		Username: v2.User.Username,
+		TaskType: v2.TaskType,
+		Message:  v2.Message,
+	}
+	if v2.CreatedAt > 0 {
+		msg.CreatedAt = time.UnixMilli(v2.CreatedAt).UTC().Format(time.RFC3339)
+	}
+	return msg, nil
+}
@@ -0,0 +1,61 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/alfredchaos/demo/internal/nice-service/biz"
+	"github.com/alfredchaos/demo/pkg/mq"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+func TestTaskDecoder_DecodesSupportedVersions(t *testing.T) {
+	want := biz.TaskMessage{
+		TaskID:    "t1",
+		UserID:    "u1",
+		Username:  "alice",
+		TaskType:  "sayhello",
+		Message:   "hi",
+		CreatedAt: "2024-11-03T11:25:45Z",
+	}
+
+	cases := []struct {
+		name     string
+		delivery amqp.Delivery
+	}{
+		{"v1 without header", amqp.Delivery{
+			Body: []byte(`{"task_id":"t1","user_id":"u1","username":"alice","task_type":"sayhello","message":"hi","created_at":"2024-11-03T11:25:45Z"}`),
+		}},
+		{"v1", amqp.Delivery{
+			Headers: amqp.Table{mq.SchemaVersionHeader: "1"},
+			Body:    []byte(`{"task_id":"t1","user_id":"u1","username":"alice","task_type":"sayhello","message":"hi","created_at":"2024-11-03T11:25:45Z"}`),
+		}},
+		{"v2", amqp.Delivery{
+			Headers: amqp.Table{mq.SchemaVersionHeader: int32(2)},
+			Body:    []byte(`{"task_id":"t1","user":{"id":"u1","username":"alice"},"task_type":"sayhello","message":"hi","created_at_ms":1730633145000}`),
+		}},
+	}
+
+	decoder := newTaskDecoder()
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := decoder.Decode(c.delivery)
+			if err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+			if *got != want {
+				t.Fatalf("Decode() = %+v, want %+v", *got, want)
+			}
+		})
+	}
+}
+
+func TestTaskDecoder_RejectsUnknownVersion(t *testing.T) {
+	_, err := newTaskDecoder().Decode(amqp.Delivery{
+		Headers: amqp.Table{mq.SchemaVersionHeader: "99"},
+		Body:    []byte(`{}`),
+	})
+	if !errors.Is(err, mq.ErrUnsupportedSchemaVersion) {
+		t.Fatalf("want ErrUnsupportedSchemaVersion, got %v", err)
+	}
+}
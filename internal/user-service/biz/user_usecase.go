@@ -3,7 +3,9 @@ package biz
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	bookv1 "github.com/alfredchaos/demo/api/book/v1"
@@ -11,17 +13,48 @@ import (
 	"github.com/alfredchaos/demo/internal/user-service/domain"
 	"github.com/alfredchaos/demo/internal/user-service/messaging"
 	"github.com/alfredchaos/demo/internal/user-service/repository"
+	apperrors "github.com/alfredchaos/demo/pkg/errors"
+	"github.com/alfredchaos/demo/pkg/featureflag"
 	"github.com/alfredchaos/demo/pkg/log"
+	"github.com/alfredchaos/demo/pkg/metrics"
 	"github.com/alfredchaos/demo/pkg/mq"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
+	"golang.org/x/sync/singleflight"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 // UserUseCase 用户业务逻辑用例接口
 type IUserUseCase interface {
 	SayHello(ctx context.Context, name string) (string, error)
+	GetUser(ctx context.Context, id domain.UserID) (*domain.User, error)
+	GetUsers(ctx context.Context, ids []domain.UserID) (map[domain.UserID]*domain.User, error)
+	CountUsers(ctx context.Context) (int64, error)
 }
 
+var _ IUserUseCase = (*UserUseCase)(nil)
+
+const (
+	// userCacheTTL 用户缓存过期时间（秒）
+	userCacheTTL = 60
+
+	// userLockWaitInterval 未获取到重建锁时轮询缓存的间隔
+	userLockWaitInterval = 20 * time.Millisecond
+
+	// userLockWaitRetries 未获取到重建锁时轮询缓存的最大次数，超过后直接查询数据库
+	userLockWaitRetries = 10
+
+	// userRefreshTimeout 提前刷新时后台加载用户的超时时间
+	userRefreshTimeout = 3 * time.Second
+
+	// userCompensateTimeout 回滚部分写入的超时时间，避免数据库卡住时补偿无限阻塞
+	userCompensateTimeout = 3 * time.Second
+)
+
+// ErrEmptyBookMessage book-service 返回了空消息，SayHello 直接失败，即使开启 book_fallback 也不创建用户
+var ErrEmptyBookMessage = apperrors.NewCoded(apperrors.ErrServiceUnavailable, "book-service returned empty message")
+
 // userUseCase 用户业务逻辑用例实现
 type UserUseCase struct {
 	bookClient  bookv1.BookServiceClient
@@ -29,6 +62,12 @@ type UserUseCase struct {
 	userDocRepo repository.UserDocumentRepository
 	userCache   cache.UserCache
 	publisher   messaging.Publisher
+	flags       *featureflag.Flags
+
+	// refreshAhead 提前刷新窗口，0 表示不启用
+	refreshAhead time.Duration
+	// refreshGroup 合并同一用户的后台刷新
+	refreshGroup singleflight.Group
 }
 
 // NewUserUseCase 创建新的用户业务逻辑用例
@@ -38,6 +77,7 @@ func NewUserUseCase(
 	userDocRepo repository.UserDocumentRepository,
 	userCache cache.UserCache,
 	publisher messaging.Publisher,
+	flags *featureflag.Flags,
 ) *UserUseCase {
 	return &UserUseCase{
 		bookClient:  bookClient,
@@ -45,9 +85,18 @@ func NewUserUseCase(
 		userDocRepo: userDocRepo,
 		userCache:   userCache,
 		publisher:   publisher,
+		flags:       flags,
 	}
 }
 
+// WithRefreshAhead 开启提前刷新（refresh-ahead）
+// 缓存命中且剩余过期时间不超过 window 时，返回当前值的同时在后台重新加载并刷新缓存，
+// 使热点用户在过期前保持有效，避免过期瞬间的请求落到数据库
+func (uc *UserUseCase) WithRefreshAhead(window time.Duration) *UserUseCase {
+	uc.refreshAhead = window
+	return uc
+}
+
 func (uc *UserUseCase) SayHello(ctx context.Context, name string) (string, error) {
 	log.WithContext(ctx).Info("processing SayHello request", zap.String("name", name))
 
@@ -59,17 +108,31 @@ func (uc *UserUseCase) SayHello(ctx context.Context, name string) (string, error
 
 	// 2. 同步调用book-service获取消息
 	log.Info("calling book-service via gRPC")
+	stop := metrics.Timer("book_client.just_tell_me", metrics.WithLog(ctx))
 	bookResp, err := uc.bookClient.JustTellMe(ctx, &bookv1.TellMeRequest{})
-	if err != nil {
+	stop()
+	if err == nil && strings.TrimSpace(bookResp.GetMessage()) == "" {
+		// book-service 返回了成功响应却没有内容，说明其自身状态异常，不属于暂时不可用，不降级
+		err = ErrEmptyBookMessage
+	}
+	var bookMessage string
+	degraded := false
+	switch {
+	case err == nil:
+		bookMessage = bookResp.Message
+		log.Info("received message from book-service", zap.String("message", bookMessage))
+	case uc.flags.Enabled(featureflag.BookFallback) && isBookUnavailable(err):
+		// 降级：book-service 暂时不可用时用户创建不受影响，邮箱留空，不写入占位内容
+		degraded = true
+		log.WithContext(ctx).Warn("book-service unavailable, SayHello degraded", zap.Error(err))
+	default:
 		log.Error("failed to call book-service", zap.Error(err))
 		return "", err
 	}
-	bookMessage := bookResp.Message
-	log.Info("received message from book-service", zap.String("message", bookMessage))
 
 	// 3. 组合User结构
 	user := domain.User{
-		ID:       uuid.New().String(),
+		ID:       domain.NewUserID(),
 		Username: userMessage,
 		Email:    bookMessage,
 	}
@@ -86,18 +149,21 @@ func (uc *UserUseCase) SayHello(ctx context.Context, name string) (string, error
 		"email":    user.Email,
 	}); err != nil {
 		log.Error("failed to save user document", zap.Error(err))
+		uc.compensateCreate(ctx, user.ID)
 		return "", err
 	}
 
-	// 7. 缓存用户
-	if err := uc.userCache.SetUser(ctx, &user, 60); err != nil {
-		log.Error("failed to cache user", zap.Error(err))
-		return "", err
+	// 7. 缓存用户（同时覆盖可能存在的墓碑）
+	// Create 已将数据库生成的时间戳回填到 user，缓存与持久化记录保持一致；
+	// 缓存只是数据库的副本，写入失败不回滚已保存的用户，下次读取时由 GetUser 回填
+	if err := uc.userCache.SetUser(ctx, &user, userCacheTTL); err != nil {
+		log.WithContext(ctx).Warn("failed to cache user", zap.Error(err))
 	}
 
 	// 8. 发送异步任务消息（使用 Topic Exchange）
 	// 构建任务消息
 	taskMsg := map[string]interface{}{
+		"task_id":    uuid.NewString(), // nice-service 按此保存执行结果
 		"user_id":    user.ID,
 		"username":   user.Username,
 		"task_type":  "sayhello",
@@ -117,12 +183,242 @@ func (uc *UserUseCase) SayHello(ctx context.Context, name string) (string, error
 		} else {
 			log.Info("task message published successfully",
 				zap.String("routing_key", mq.RoutingKeyTaskSayHelloCreate),
-				zap.String("user_id", user.ID))
+				zap.String("user_id", user.ID.String()))
 		}
 	}
 
 	// 9. 转成字符串
 	userString := fmt.Sprintf("User{ID: %s, Username: %s, Email: %s}", user.ID, user.Username, user.Email)
+	if degraded {
+		userString += " (degraded: book-service unavailable)"
+	}
 
 	return userString, nil
 }
+
+// isBookUnavailable 判断 book-service 调用失败是否为暂时不可用
+// 只有连接失败、熔断（Unavailable）和超时（DeadlineExceeded）可以降级；参数错误、内部错误等直接返回，避免掩盖真正的问题
+func isBookUnavailable(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded:
+		return true
+	}
+	return false
+}
+
+// compensateCreate 尽力回滚 SayHello 中已保存的用户记录，避免文档写入失败后留下孤立的用户
+// 使用脱离取消的上下文，保证请求超时或取消后补偿仍会执行，并单独设置超时；补偿失败只记录日志，需人工处理
+func (uc *UserUseCase) compensateCreate(ctx context.Context, userID domain.UserID) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), userCompensateTimeout)
+	defer cancel()
+	logger := log.WithContext(ctx).With(zap.String("user_id", userID.String()))
+
+	if err := uc.userRepo.Delete(ctx, userID); err != nil {
+		logger.Error("compensation failed: user row left behind", zap.Error(err))
+		return
+	}
+	logger.Warn("compensated partially created user")
+}
+
+// GetUser 按 ID 获取用户（cache-aside）
+// 1. 先查缓存，命中墓碑直接返回 domain.ErrUserNotFound
+// 2. 未命中则在互斥锁保护下查询数据库并回填缓存
+// 3. 数据库中不存在时写入短期墓碑，避免重复穿透
+// 开启提前刷新时，命中即将过期的缓存会触发一次后台重新加载
+func (uc *UserUseCase) GetUser(ctx context.Context, id domain.UserID) (*domain.User, error) {
+	user, ttl, err := uc.getCachedUser(ctx, id)
+	if err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			return nil, err
+		}
+		// 缓存异常不影响主流程，降级为直接查询数据库
+		log.WithContext(ctx).Warn("failed to get user from cache", zap.String("user_id", id.String()), zap.Error(err))
+	}
+	if user != nil {
+		if uc.refreshAhead > 0 && ttl >= 0 && ttl <= uc.refreshAhead {
+			uc.refreshUserAsync(ctx, id)
+		}
+		return user, nil
+	}
+
+	return uc.loadUser(ctx, id)
+}
+
+// getCachedUser 读取用户缓存，开启提前刷新时同时读取剩余过期时间
+// 未开启时 ttl 固定为 -1
+func (uc *UserUseCase) getCachedUser(ctx context.Context, id domain.UserID) (*domain.User, time.Duration, error) {
+	if uc.refreshAhead <= 0 {
+		user, err := uc.userCache.GetUser(ctx, id)
+		return user, -1, err
+	}
+	return uc.userCache.GetUserWithTTL(ctx, id)
+}
+
+// refreshUserAsync 在后台从数据库重新加载用户并刷新缓存
+// 同一用户同时只会有一个刷新在进行；刷新使用独立于请求的 context，请求结束不会中断刷新
+func (uc *UserUseCase) refreshUserAsync(ctx context.Context, id domain.UserID) {
+	uc.refreshGroup.DoChan(id.String(), func() (interface{}, error) {
+		refreshCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), userRefreshTimeout)
+		defer cancel()
+
+		if _, err := uc.loadUserFromDB(refreshCtx, id); err != nil {
+			log.WithContext(refreshCtx).Warn("failed to refresh user cache", zap.String("user_id", id.String()), zap.Error(err))
+		}
+		return nil, nil
+	})
+}
+
+// loadUser 在互斥锁保护下从数据库加载用户，防止热点键过期时的缓存击穿
+// 获取到锁的调用方负责重建缓存，其余调用方短暂等待后读取新缓存
+func (uc *UserUseCase) loadUser(ctx context.Context, id domain.UserID) (*domain.User, error) {
+	unlock, acquired, err := uc.userCache.LockUser(ctx, id)
+	if err != nil {
+		// 锁服务异常时降级为直接查询数据库
+		log.WithContext(ctx).Warn("failed to lock user cache", zap.String("user_id", id.String()), zap.Error(err))
+		return uc.loadUserFromDB(ctx, id)
+	}
+
+	if !acquired {
+		if user, ok, err := uc.waitForUserCache(ctx, id); ok {
+			return user, err
+		}
+		return uc.loadUserFromDB(ctx, id)
+	}
+	defer unlock()
+
+	// 双重检查：获取锁期间缓存可能已被其他调用方重建
+	user, err := uc.userCache.GetUser(ctx, id)
+	if errors.Is(err, domain.ErrUserNotFound) {
+		return nil, err
+	}
+	if err == nil && user != nil {
+		return user, nil
+	}
+
+	return uc.loadUserFromDB(ctx, id)
+}
+
+// waitForUserCache 等待其他调用方重建缓存
+// ok 为 false 表示等待超时，调用方应自行查询数据库
+func (uc *UserUseCase) waitForUserCache(ctx context.Context, id domain.UserID) (*domain.User, bool, error) {
+	ticker := time.NewTicker(userLockWaitInterval)
+	defer ticker.Stop()
+
+	for i := 0; i < userLockWaitRetries; i++ {
+		select {
+		case <-ctx.Done():
+			return nil, true, ctx.Err()
+		case <-ticker.C:
+		}
+
+		user, err := uc.userCache.GetUser(ctx, id)
+		if errors.Is(err, domain.ErrUserNotFound) {
+			return nil, true, err
+		}
+		if err == nil && user != nil {
+			return user, true, nil
+		}
+	}
+
+	return nil, false, nil
+}
+
+// loadUserFromDB 从数据库加载用户并回填缓存
+// 数据库中不存在且开启 negative_caching 时写入短期墓碑，避免重复穿透
+func (uc *UserUseCase) loadUserFromDB(ctx context.Context, id domain.UserID) (*domain.User, error) {
+	user, err := uc.userRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) && uc.flags.Enabled(featureflag.NegativeCaching) {
+			if cacheErr := uc.userCache.SetUserNotFound(ctx, id); cacheErr != nil {
+				log.WithContext(ctx).Warn("failed to cache user tombstone", zap.String("user_id", id.String()), zap.Error(cacheErr))
+			}
+		}
+		return nil, err
+	}
+
+	if err := uc.userCache.SetUser(ctx, user, userCacheTTL); err != nil {
+		log.WithContext(ctx).Warn("failed to cache user", zap.String("user_id", id.String()), zap.Error(err))
+	}
+
+	return user, nil
+}
+
+// GetUsers 按 ID 批量获取用户（cache-aside）
+// 先通过 MGET 查询缓存，未命中的 ID 批量查询数据库并回填缓存
+// 返回以 ID 为键的映射，不存在的用户不会出现在结果中
+func (uc *UserUseCase) GetUsers(ctx context.Context, ids []domain.UserID) (map[domain.UserID]*domain.User, error) {
+	result := make(map[domain.UserID]*domain.User, len(ids))
+	if len(ids) == 0 {
+		return result, nil
+	}
+
+	cached, err := uc.userCache.GetUsers(ctx, ids)
+	if err != nil {
+		// 缓存异常不影响主流程，降级为全部查询数据库
+		log.WithContext(ctx).Warn("failed to get users from cache", zap.Error(err))
+		cached = nil
+	}
+
+	misses := make([]domain.UserID, 0, len(ids))
+	for _, id := range ids {
+		user, hit := cached[id]
+		if !hit {
+			misses = append(misses, id)
+			continue
+		}
+		// 值为 nil 表示命中墓碑
+		if user != nil {
+			result[id] = user
+		}
+	}
+
+	if len(misses) == 0 {
+		return result, nil
+	}
+
+	loaded, err := uc.userRepo.GetByIDs(ctx, misses)
+	if err != nil {
+		return nil, err
+	}
+
+	backfill := make([]*domain.User, 0, len(loaded))
+	for id, user := range loaded {
+		result[id] = user
+		backfill = append(backfill, user)
+	}
+
+	if err := uc.userCache.SetUsers(ctx, backfill, userCacheTTL); err != nil {
+		log.WithContext(ctx).Warn("failed to backfill users cache", zap.Error(err))
+	}
+
+	return result, nil
+}
+
+// ListUsers 基于游标分页列出用户，按创建时间倒序
+// next 为空表示没有更多数据
+func (uc *UserUseCase) ListUsers(ctx context.Context, cursor string, limit int) ([]*domain.User, string, error) {
+	if uc.userRepo == nil {
+		return nil, "", fmt.Errorf("user repository is not configured")
+	}
+
+	users, next, err := uc.userRepo.ListAfter(ctx, cursor, limit)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to list users: %w", err)
+	}
+
+	return users, next, nil
+}
+
+// CountUsers 统计用户总数
+func (uc *UserUseCase) CountUsers(ctx context.Context) (int64, error) {
+	if uc.userRepo == nil {
+		return 0, fmt.Errorf("user repository is not configured")
+	}
+
+	count, err := uc.userRepo.Count(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count users: %w", err)
+	}
+
+	return count, nil
+}
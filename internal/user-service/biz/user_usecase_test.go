@@ -0,0 +1,656 @@
+package biz
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	bookv1 "github.com/alfredchaos/demo/api/book/v1"
+	"github.com/alfredchaos/demo/internal/user-service/domain"
+	"github.com/alfredchaos/demo/internal/user-service/repository"
+	"github.com/alfredchaos/demo/pkg/featureflag"
+	"github.com/alfredchaos/demo/pkg/log"
+	"github.com/alfredchaos/demo/pkg/mq"
+	"github.com/alfredchaos/demo/pkg/mq/mqtest"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// useNopLogger 测试期间使用空日志，结束后恢复原日志
+func useNopLogger(t *testing.T) {
+	t.Helper()
+	original := log.Logger
+	log.Logger = zap.NewNop()
+	t.Cleanup(func() { log.Logger = original })
+}
+
+// fakeUserRepo 内存用户仓库，记录 GetByID 调用次数
+type fakeUserRepo struct {
+	mu            sync.Mutex
+	users         map[domain.UserID]*domain.User
+	getByIDCalls  int
+	getByIDsCalls [][]domain.UserID
+	delay         time.Duration
+	// deleteCtxs 记录 Delete 收到的上下文，用于校验补偿的超时
+	deleteCtxs []context.Context
+}
+
+func newFakeUserRepo() *fakeUserRepo {
+	return &fakeUserRepo{users: make(map[domain.UserID]*domain.User)}
+}
+
+func (r *fakeUserRepo) Create(ctx context.Context, user *domain.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.users[user.ID] = user
+	return nil
+}
+
+func (r *fakeUserRepo) GetByID(ctx context.Context, id domain.UserID) (*domain.User, error) {
+	time.Sleep(r.delay)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.getByIDCalls++
+	if user, ok := r.users[id]; ok {
+		return user, nil
+	}
+	return nil, domain.ErrUserNotFound
+}
+
+func (r *fakeUserRepo) Exists(ctx context.Context, id domain.UserID) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, ok := r.users[id]
+	return ok, nil
+}
+
+func (r *fakeUserRepo) GetByIDs(ctx context.Context, ids []domain.UserID) (map[domain.UserID]*domain.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.getByIDsCalls = append(r.getByIDsCalls, ids)
+	users := make(map[domain.UserID]*domain.User)
+	for _, id := range ids {
+		if user, ok := r.users[id]; ok {
+			users[id] = user
+		}
+	}
+	return users, nil
+}
+
+func (r *fakeUserRepo) GetByUsername(ctx context.Context, username string) (*domain.User, error) {
+	return nil, domain.ErrUserNotFound
+}
+
+func (r *fakeUserRepo) Upsert(ctx context.Context, user *domain.User) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, exists := r.users[user.ID]
+	r.users[user.ID] = user
+	return !exists, nil
+}
+
+func (r *fakeUserRepo) Update(ctx context.Context, user *domain.User) error { return nil }
+
+func (r *fakeUserRepo) Delete(ctx context.Context, id domain.UserID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.deleteCtxs = append(r.deleteCtxs, ctx)
+	delete(r.users, id)
+	return nil
+}
+
+func (r *fakeUserRepo) List(ctx context.Context, offset, limit int) ([]*domain.User, error) {
+	return nil, nil
+}
+
+func (r *fakeUserRepo) ListAfter(ctx context.Context, cursor string, limit int) ([]*domain.User, string, error) {
+	return nil, "", nil
+}
+
+func (r *fakeUserRepo) Count(ctx context.Context) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return int64(len(r.users)), nil
+}
+
+// fakeUserCache 内存用户缓存，支持墓碑
+type fakeUserCache struct {
+	mu         sync.Mutex
+	users      map[domain.UserID]*domain.User
+	ttls       map[domain.UserID]time.Duration
+	tombstones map[domain.UserID]bool
+	locks      map[domain.UserID]bool
+}
+
+func newFakeUserCache() *fakeUserCache {
+	return &fakeUserCache{
+		users:      make(map[domain.UserID]*domain.User),
+		ttls:       make(map[domain.UserID]time.Duration),
+		tombstones: make(map[domain.UserID]bool),
+		locks:      make(map[domain.UserID]bool),
+	}
+}
+
+func (c *fakeUserCache) SetUser(ctx context.Context, user *domain.User, ttl int) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.tombstones, user.ID)
+	c.users[user.ID] = user
+	c.ttls[user.ID] = time.Duration(ttl) * time.Second
+	return nil
+}
+
+func (c *fakeUserCache) GetUserWithTTL(ctx context.Context, userID domain.UserID) (*domain.User, time.Duration, error) {
+	user, err := c.GetUser(ctx, userID)
+	if user == nil {
+		return nil, -1, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return user, c.ttls[userID], nil
+}
+
+// ttl 返回缓存的过期时间
+func (c *fakeUserCache) ttl(userID domain.UserID) time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.ttls[userID]
+}
+
+func (c *fakeUserCache) GetUser(ctx context.Context, userID domain.UserID) (*domain.User, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.tombstones[userID] {
+		return nil, domain.ErrUserNotFound
+	}
+	return c.users[userID], nil
+}
+
+func (c *fakeUserCache) GetUsers(ctx context.Context, userIDs []domain.UserID) (map[domain.UserID]*domain.User, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	users := make(map[domain.UserID]*domain.User)
+	for _, id := range userIDs {
+		if c.tombstones[id] {
+			users[id] = nil
+		} else if user, ok := c.users[id]; ok {
+			users[id] = user
+		}
+	}
+	return users, nil
+}
+
+func (c *fakeUserCache) SetUsers(ctx context.Context, users []*domain.User, ttl int) error {
+	for _, user := range users {
+		_ = c.SetUser(ctx, user, ttl)
+	}
+	return nil
+}
+
+func (c *fakeUserCache) SetUserNotFound(ctx context.Context, userID domain.UserID) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.tombstones[userID] = true
+	return nil
+}
+
+func (c *fakeUserCache) DeleteUser(ctx context.Context, userID domain.UserID) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.users, userID)
+	delete(c.tombstones, userID)
+	return nil
+}
+
+func (c *fakeUserCache) LockUser(ctx context.Context, userID domain.UserID) (func(), bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.locks[userID] {
+		return nil, false, nil
+	}
+	c.locks[userID] = true
+	return func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		delete(c.locks, userID)
+	}, true, nil
+}
+
+// negativeCachingFlags 开启负缓存的功能开关
+func negativeCachingFlags() *featureflag.Flags {
+	return featureflag.New(map[string]bool{featureflag.NegativeCaching: true})
+}
+
+func TestGetUser_NegativeCacheDisabledByFlag(t *testing.T) {
+	useNopLogger(t)
+	repo := newFakeUserRepo()
+	uc := NewUserUseCase(nil, repo, nil, newFakeUserCache(), nil, nil)
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		if _, err := uc.GetUser(ctx, "missing"); !errors.Is(err, domain.ErrUserNotFound) {
+			t.Fatalf("lookup %d: want ErrUserNotFound, got %v", i, err)
+		}
+	}
+
+	if repo.getByIDCalls != 2 {
+		t.Fatalf("want every lookup to hit the db without negative caching, got %d", repo.getByIDCalls)
+	}
+}
+
+func TestGetUser_NegativeCacheSkipsDB(t *testing.T) {
+	useNopLogger(t)
+	repo := newFakeUserRepo()
+	uc := NewUserUseCase(nil, repo, nil, newFakeUserCache(), nil, negativeCachingFlags())
+	ctx := context.Background()
+
+	if _, err := uc.GetUser(ctx, "missing"); !errors.Is(err, domain.ErrUserNotFound) {
+		t.Fatalf("first lookup: want ErrUserNotFound, got %v", err)
+	}
+	if _, err := uc.GetUser(ctx, "missing"); !errors.Is(err, domain.ErrUserNotFound) {
+		t.Fatalf("second lookup: want ErrUserNotFound, got %v", err)
+	}
+
+	if repo.getByIDCalls != 1 {
+		t.Fatalf("want 1 db lookup, got %d", repo.getByIDCalls)
+	}
+}
+
+func TestGetUser_TombstoneInvalidatedOnCreate(t *testing.T) {
+	useNopLogger(t)
+	repo := newFakeUserRepo()
+	userCache := newFakeUserCache()
+	uc := NewUserUseCase(nil, repo, nil, userCache, nil, negativeCachingFlags())
+	ctx := context.Background()
+
+	if _, err := uc.GetUser(ctx, "u1"); !errors.Is(err, domain.ErrUserNotFound) {
+		t.Fatalf("want ErrUserNotFound, got %v", err)
+	}
+
+	user := &domain.User{ID: "u1", Username: "alice", Email: "alice@example.com"}
+	_ = repo.Create(ctx, user)
+	_ = userCache.SetUser(ctx, user, userCacheTTL)
+
+	got, err := uc.GetUser(ctx, "u1")
+	if err != nil {
+		t.Fatalf("want user, got error %v", err)
+	}
+	if got.Username != "alice" {
+		t.Fatalf("want alice, got %s", got.Username)
+	}
+	// 第二次查询由缓存返回，只有第一次未命中查询了数据库
+	if repo.getByIDCalls != 1 {
+		t.Fatalf("want 1 db lookup, got %d", repo.getByIDCalls)
+	}
+}
+
+func TestGetUser_RefreshAheadReloadsOnceNearExpiry(t *testing.T) {
+	useNopLogger(t)
+	repo := newFakeUserRepo()
+	repo.delay = 50 * time.Millisecond
+	userCache := newFakeUserCache()
+	uc := NewUserUseCase(nil, repo, nil, userCache, nil, nil).WithRefreshAhead(5 * time.Second)
+	ctx := context.Background()
+
+	user := &domain.User{ID: "hot", Username: "alice"}
+	_ = repo.Create(ctx, user)
+	// 剩余 1 秒过期，落在 5 秒的提前刷新窗口内
+	_ = userCache.SetUser(ctx, user, 1)
+
+	// 并发读取全部直接返回当前缓存值，只触发一次后台刷新
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if got, err := uc.GetUser(ctx, "hot"); err != nil || got != user {
+				t.Errorf("want cached user, got %+v, %v", got, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for userCache.ttl("hot") != userCacheTTL*time.Second {
+		if time.Now().After(deadline) {
+			t.Fatal("want cache refreshed with full TTL in background")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	// 刷新后剩余时间超出窗口，后续读取不再触发刷新
+	if _, err := uc.GetUser(ctx, "hot"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	time.Sleep(100 * time.Millisecond)
+
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	if repo.getByIDCalls != 1 {
+		t.Fatalf("want exactly 1 background refresh, got %d db lookups", repo.getByIDCalls)
+	}
+}
+
+func TestGetUser_RefreshAheadSkipsFreshEntries(t *testing.T) {
+	useNopLogger(t)
+	repo := newFakeUserRepo()
+	userCache := newFakeUserCache()
+	uc := NewUserUseCase(nil, repo, nil, userCache, nil, nil).WithRefreshAhead(5 * time.Second)
+	ctx := context.Background()
+
+	user := &domain.User{ID: "u1", Username: "alice"}
+	_ = repo.Create(ctx, user)
+	_ = userCache.SetUser(ctx, user, userCacheTTL)
+
+	if _, err := uc.GetUser(ctx, "u1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	time.Sleep(50 * time.Millisecond)
+
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	if repo.getByIDCalls != 0 {
+		t.Fatalf("want no refresh outside the window, got %d db lookups", repo.getByIDCalls)
+	}
+}
+
+func TestGetUsers_PartialCacheHit(t *testing.T) {
+	useNopLogger(t)
+	repo := newFakeUserRepo()
+	userCache := newFakeUserCache()
+	uc := NewUserUseCase(nil, repo, nil, userCache, nil, nil)
+	ctx := context.Background()
+
+	cachedUser := &domain.User{ID: "u1", Username: "alice"}
+	dbUser := &domain.User{ID: "u2", Username: "bob"}
+	_ = userCache.SetUser(ctx, cachedUser, userCacheTTL)
+	_ = repo.Create(ctx, dbUser)
+
+	users, err := uc.GetUsers(ctx, []domain.UserID{"u1", "u2", "u3"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(users) != 2 || users["u1"] != cachedUser || users["u2"] != dbUser {
+		t.Fatalf("unexpected result: %+v", users)
+	}
+	if _, ok := users["u3"]; ok {
+		t.Fatalf("missing user should be omitted")
+	}
+
+	// 只有未命中的 ID 才会查询数据库
+	if len(repo.getByIDsCalls) != 1 || len(repo.getByIDsCalls[0]) != 2 {
+		t.Fatalf("want one db batch for 2 misses, got %v", repo.getByIDsCalls)
+	}
+
+	// 数据库加载的用户应已回填缓存
+	if got, _ := userCache.GetUser(ctx, "u2"); got != dbUser {
+		t.Fatalf("want u2 backfilled into cache")
+	}
+}
+
+func TestGetUser_ConcurrentMissLoadsOnce(t *testing.T) {
+	useNopLogger(t)
+	repo := newFakeUserRepo()
+	repo.delay = 50 * time.Millisecond
+	uc := NewUserUseCase(nil, repo, nil, newFakeUserCache(), nil, nil)
+	ctx := context.Background()
+
+	user := &domain.User{ID: "hot", Username: "alice"}
+	_ = repo.Create(ctx, user)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			got, err := uc.GetUser(ctx, "hot")
+			if err != nil || got.Username != "alice" {
+				t.Errorf("want alice, got %v, %v", got, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if repo.getByIDCalls != 1 {
+		t.Fatalf("want 1 db lookup, got %d", repo.getByIDCalls)
+	}
+}
+
+// failingBookClient 始终返回指定错误码的 book-service 客户端
+type failingBookClient struct {
+	bookv1.BookServiceClient
+	code codes.Code
+}
+
+func (c failingBookClient) JustTellMe(ctx context.Context, in *bookv1.TellMeRequest, opts ...grpc.CallOption) (*bookv1.TellMeResponse, error) {
+	return nil, status.Error(c.code, "book-service failed")
+}
+
+// fakeBookClient 返回固定消息的 book-service 客户端
+type fakeBookClient struct {
+	bookv1.BookServiceClient
+}
+
+func (c fakeBookClient) JustTellMe(ctx context.Context, in *bookv1.TellMeRequest, opts ...grpc.CallOption) (*bookv1.TellMeResponse, error) {
+	return &bookv1.TellMeResponse{Message: "hello from books"}, nil
+}
+
+// emptyBookClient 返回空白消息的 book-service 客户端，模拟降级中的 book-service
+type emptyBookClient struct {
+	bookv1.BookServiceClient
+}
+
+func (c emptyBookClient) JustTellMe(ctx context.Context, in *bookv1.TellMeRequest, opts ...grpc.CallOption) (*bookv1.TellMeResponse, error) {
+	return &bookv1.TellMeResponse{Message: "  "}, nil
+}
+
+// fakeUserDocRepo 内存用户文档仓库，saveErr 非空时写入失败
+type fakeUserDocRepo struct {
+	repository.UserDocumentRepository
+	mu      sync.Mutex
+	docs    map[domain.UserID]map[string]interface{}
+	saveErr error
+}
+
+func newFakeUserDocRepo() *fakeUserDocRepo {
+	return &fakeUserDocRepo{docs: make(map[domain.UserID]map[string]interface{})}
+}
+
+func (r *fakeUserDocRepo) SaveDocument(ctx context.Context, userID domain.UserID, document map[string]interface{}) error {
+	if r.saveErr != nil {
+		return r.saveErr
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.docs[userID] = document
+	return nil
+}
+
+func (r *fakeUserDocRepo) DeleteDocument(ctx context.Context, userID domain.UserID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.docs, userID)
+	return nil
+}
+
+// failingUserCache 写入始终失败的用户缓存
+type failingUserCache struct {
+	*fakeUserCache
+}
+
+func (c failingUserCache) SetUser(ctx context.Context, user *domain.User, ttl int) error {
+	return errors.New("redis down")
+}
+
+func TestSayHello_BookServiceUnavailable(t *testing.T) {
+	useNopLogger(t)
+	fallback := featureflag.New(map[string]bool{featureflag.BookFallback: true})
+	cases := []struct {
+		name        string
+		flags       *featureflag.Flags
+		code        codes.Code
+		wantCreated bool
+	}{
+		{"fallback disabled fails", nil, codes.Unavailable, false},
+		{"fallback enabled degrades when unavailable", fallback, codes.Unavailable, true},
+		{"fallback enabled degrades on timeout", fallback, codes.DeadlineExceeded, true},
+		{"fallback enabled still fails on invalid argument", fallback, codes.InvalidArgument, false},
+		{"fallback enabled still fails on internal error", fallback, codes.Internal, false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			repo := newFakeUserRepo()
+			userCache := newFakeUserCache()
+			uc := NewUserUseCase(failingBookClient{code: c.code}, repo, newFakeUserDocRepo(), userCache, mqtest.NewFakePublisher(), c.flags)
+
+			msg, err := uc.SayHello(context.Background(), "alice")
+			if !c.wantCreated {
+				if err == nil {
+					t.Fatalf("want error without fallback, got %q", msg)
+				}
+				if len(repo.users) != 0 {
+					t.Fatalf("want no user created, got %d", len(repo.users))
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("want degraded success, got %v", err)
+			}
+			if !strings.Contains(msg, "degraded") {
+				t.Fatalf("want degradation surfaced in response, got %q", msg)
+			}
+			if len(repo.users) != 1 {
+				t.Fatalf("want user created in degraded mode, got %d", len(repo.users))
+			}
+			for id, user := range repo.users {
+				if user.Email != "" {
+					t.Fatalf("want empty email in degraded mode, got %q", user.Email)
+				}
+				if cached, _ := userCache.GetUser(context.Background(), id); cached == nil {
+					t.Fatal("want degraded user cached")
+				}
+			}
+		})
+	}
+}
+
+func TestSayHello_EmptyBookMessage(t *testing.T) {
+	useNopLogger(t)
+	t.Run("fallback disabled fails", func(t *testing.T) {
+		repo := newFakeUserRepo()
+		uc := NewUserUseCase(emptyBookClient{}, repo, newFakeUserDocRepo(), newFakeUserCache(), mqtest.NewFakePublisher(), nil)
+
+		_, err := uc.SayHello(context.Background(), "alice")
+		if !errors.Is(err, ErrEmptyBookMessage) {
+			t.Fatalf("want ErrEmptyBookMessage, got %v", err)
+		}
+		if len(repo.users) != 0 {
+			t.Fatalf("want no user created, got %d", len(repo.users))
+		}
+	})
+
+	t.Run("fallback enabled still fails", func(t *testing.T) {
+		repo := newFakeUserRepo()
+		flags := featureflag.New(map[string]bool{featureflag.BookFallback: true})
+		uc := NewUserUseCase(emptyBookClient{}, repo, newFakeUserDocRepo(), newFakeUserCache(), mqtest.NewFakePublisher(), flags)
+
+		_, err := uc.SayHello(context.Background(), "alice")
+		if !errors.Is(err, ErrEmptyBookMessage) {
+			t.Fatalf("want ErrEmptyBookMessage, got %v", err)
+		}
+		if len(repo.users) != 0 {
+			t.Fatalf("want no user created, got %d", len(repo.users))
+		}
+	})
+}
+
+func TestSayHello_CompensatesPartialWrites(t *testing.T) {
+	useNopLogger(t)
+	t.Run("document write fails", func(t *testing.T) {
+		repo := newFakeUserRepo()
+		docRepo := newFakeUserDocRepo()
+		docRepo.saveErr = errors.New("mongo down")
+		uc := NewUserUseCase(fakeBookClient{}, repo, docRepo, newFakeUserCache(), mqtest.NewFakePublisher(), nil)
+
+		if _, err := uc.SayHello(context.Background(), "alice"); err == nil {
+			t.Fatal("want error when the document write fails")
+		}
+		if len(repo.users) != 0 {
+			t.Fatalf("want postgres row compensated, got %d users", len(repo.users))
+		}
+		// 补偿脱离了请求上下文，但必须有自己的超时
+		if len(repo.deleteCtxs) != 1 {
+			t.Fatalf("want 1 compensating delete, got %d", len(repo.deleteCtxs))
+		}
+		if _, ok := repo.deleteCtxs[0].Deadline(); !ok {
+			t.Fatal("want compensating delete bounded by a timeout")
+		}
+	})
+
+	t.Run("cache write fails keeps user", func(t *testing.T) {
+		repo := newFakeUserRepo()
+		docRepo := newFakeUserDocRepo()
+		uc := NewUserUseCase(fakeBookClient{}, repo, docRepo, failingUserCache{newFakeUserCache()}, mqtest.NewFakePublisher(), nil)
+
+		if _, err := uc.SayHello(context.Background(), "alice"); err != nil {
+			t.Fatalf("want cache failure ignored, got %v", err)
+		}
+		if len(repo.users) != 1 || len(docRepo.docs) != 1 {
+			t.Fatalf("want user and document kept, got %d users and %d documents", len(repo.users), len(docRepo.docs))
+		}
+	})
+}
+
+func TestSayHello_PublishesTask(t *testing.T) {
+	useNopLogger(t)
+	t.Run("publishes sayhello task", func(t *testing.T) {
+		publisher := mqtest.NewFakePublisher()
+		repo := newFakeUserRepo()
+		uc := NewUserUseCase(fakeBookClient{}, repo, newFakeUserDocRepo(), newFakeUserCache(), publisher, nil)
+
+		if _, err := uc.SayHello(context.Background(), "alice"); err != nil {
+			t.Fatalf("say hello: %v", err)
+		}
+
+		msgs := publisher.MessagesFor(mq.RoutingKeyTaskSayHelloCreate)
+		if len(msgs) != 1 {
+			t.Fatalf("want 1 task message, got %d", len(publisher.Messages()))
+		}
+		var task map[string]interface{}
+		if err := json.Unmarshal(msgs[0].Body, &task); err != nil {
+			t.Fatalf("unmarshal task: %v", err)
+		}
+		if task["task_type"] != "sayhello" {
+			t.Fatalf("unexpected task message: %v", task)
+		}
+		if _, ok := repo.users[domain.UserID(task["user_id"].(string))]; !ok {
+			t.Fatalf("want task for the created user, got %v", task["user_id"])
+		}
+	})
+
+	t.Run("publish failure does not fail request", func(t *testing.T) {
+		publisher := mqtest.NewFakePublisher()
+		publisher.SetError(errors.New("rabbitmq down"))
+		repo := newFakeUserRepo()
+		uc := NewUserUseCase(fakeBookClient{}, repo, newFakeUserDocRepo(), newFakeUserCache(), publisher, nil)
+
+		if _, err := uc.SayHello(context.Background(), "alice"); err != nil {
+			t.Fatalf("want success despite publish failure, got %v", err)
+		}
+		if len(repo.users) != 1 {
+			t.Fatalf("want user kept, got %d", len(repo.users))
+		}
+		if len(publisher.Messages()) != 0 {
+			t.Fatal("want no message recorded when publish fails")
+		}
+	})
+}
@@ -0,0 +1,49 @@
+package cache
+
+import (
+	"context"
+	"time"
+
+	"github.com/alfredchaos/demo/internal/user-service/domain"
+)
+
+// NoopUserCache 未启用 Redis 时使用的空缓存
+// 读取总是未命中，写入直接丢弃，重建锁总能获取，所有读请求都会落到数据库
+type NoopUserCache struct{}
+
+// NewNoopUserCache 创建空缓存
+func NewNoopUserCache() *NoopUserCache {
+	return &NoopUserCache{}
+}
+
+func (NoopUserCache) SetUser(ctx context.Context, user *domain.User, ttl int) error {
+	return nil
+}
+
+func (NoopUserCache) GetUser(ctx context.Context, userID domain.UserID) (*domain.User, error) {
+	return nil, nil
+}
+
+func (NoopUserCache) GetUserWithTTL(ctx context.Context, userID domain.UserID) (*domain.User, time.Duration, error) {
+	return nil, -1, nil
+}
+
+func (NoopUserCache) SetUserNotFound(ctx context.Context, userID domain.UserID) error {
+	return nil
+}
+
+func (NoopUserCache) GetUsers(ctx context.Context, userIDs []domain.UserID) (map[domain.UserID]*domain.User, error) {
+	return map[domain.UserID]*domain.User{}, nil
+}
+
+func (NoopUserCache) SetUsers(ctx context.Context, users []*domain.User, ttl int) error {
+	return nil
+}
+
+func (NoopUserCache) DeleteUser(ctx context.Context, userID domain.UserID) error {
+	return nil
+}
+
+func (NoopUserCache) LockUser(ctx context.Context, userID domain.UserID) (func(), bool, error) {
+	return func() {}, true, nil
+}
@@ -3,58 +3,154 @@ package cache
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
+	"github.com/alfredchaos/demo/internal/user-service/conf"
 	"github.com/alfredchaos/demo/internal/user-service/domain"
 	"github.com/alfredchaos/demo/pkg/cache"
+	"github.com/alfredchaos/demo/pkg/codec"
 	"github.com/go-redis/redis/v8"
 )
 
 const (
 	// Redis Key 前缀
 	userCacheKeyPrefix = "user:id:"
+
+	// userLockKeyPrefix 用户缓存重建锁前缀
+	userLockKeyPrefix = "user:lock:"
+
+	// userNotFoundMarker 用户不存在的墓碑值（负缓存）
+	// 使用 JSON null，批量读取时可由 cache.MGetJSON 直接反序列化为 nil
+	userNotFoundMarker = "null"
+
+	// userLockTTL 缓存重建锁的过期时间，应大于一次数据库加载的耗时
+	userLockTTL = 3 * time.Second
 )
 
 type UserCache interface {
 	// SetUser 缓存用户信息（按 ID）
-	// ttl: 缓存过期时间（秒），0 表示永不过期
+	// ttl: 缓存过期时间（秒），0 表示使用默认 TTL，超过上限时截断
 	SetUser(ctx context.Context, user *domain.User, ttl int) error
 
 	// GetUser 获取缓存的用户信息（按 ID）
 	// 如果缓存不存在或已过期，返回 nil
-	GetUser(ctx context.Context, userID string) (*domain.User, error)
+	// 如果命中墓碑（负缓存），返回 domain.ErrUserNotFound
+	GetUser(ctx context.Context, userID domain.UserID) (*domain.User, error)
+
+	// GetUserWithTTL 与 GetUser 相同，同时返回缓存的剩余过期时间
+	// 缓存不存在或未设置过期时间时 ttl 小于 0
+	GetUserWithTTL(ctx context.Context, userID domain.UserID) (user *domain.User, ttl time.Duration, err error)
+
+	// SetUserNotFound 为不存在的用户写入短期墓碑，避免重复穿透到数据库
+	// 未启用负缓存时为空操作
+	SetUserNotFound(ctx context.Context, userID domain.UserID) error
+
+	// GetUsers 批量获取缓存的用户信息（按 ID）
+	// 返回结果只包含命中的 ID，值为 nil 表示命中墓碑（用户确认不存在）
+	GetUsers(ctx context.Context, userIDs []domain.UserID) (map[domain.UserID]*domain.User, error)
+
+	// SetUsers 批量缓存用户信息
+	SetUsers(ctx context.Context, users []*domain.User, ttl int) error
 
 	// DeleteUser 删除用户缓存（按 ID）
-	DeleteUser(ctx context.Context, userID string) error
+	DeleteUser(ctx context.Context, userID domain.UserID) error
+
+	// LockUser 获取重建用户缓存的短期互斥锁，防止缓存击穿
+	// acquired 为 false 表示其他调用方正在重建该用户的缓存
+	LockUser(ctx context.Context, userID domain.UserID) (unlock func(), acquired bool, err error)
 }
 
 // userRedisCache Redis 缓存仓库实现
 // 实现 UserCache 接口，提供基于 Redis 的快速缓存
 type UserRedisCache struct {
-	client *cache.RedisClient
+	client      *cache.RedisClient
+	negativeTTL time.Duration
 }
 
 // NewUserRedisCache 创建 Redis 缓存仓库
-func NewUserRedisCache(cfg *cache.RedisConfig) *UserRedisCache {
-	client := cache.MustNewRedisClient(cfg)
+// policy 为 nil 时不启用负缓存
+func NewUserRedisCache(cfg *cache.RedisConfig, policy *conf.UserCacheConfig) *UserRedisCache {
+	return NewUserRedisCacheWithClient(cache.MustNewRedisClient(cfg), policy)
+}
+
+// NewUserRedisCacheWithClient 使用已建立的 Redis 客户端创建缓存仓库
+// policy 为 nil 时不启用负缓存
+func NewUserRedisCacheWithClient(client *cache.RedisClient, policy *conf.UserCacheConfig) *UserRedisCache {
+	var negativeTTL time.Duration
+	if policy != nil && policy.NegativeTTL > 0 {
+		negativeTTL = time.Duration(policy.NegativeTTL) * time.Second
+	}
+
 	return &UserRedisCache{
-		client: client,
+		client:      client,
+		negativeTTL: negativeTTL,
 	}
 }
 
 // buildUserKey 构建用户 ID 缓存键
-func buildUserKey(userID string) string {
-	return userCacheKeyPrefix + userID
+func buildUserKey(userID domain.UserID) string {
+	return userCacheKeyPrefix + string(userID)
+}
+
+// buildUserLockKey 构建用户缓存重建锁的键
+func buildUserLockKey(userID domain.UserID) string {
+	return userLockKeyPrefix + string(userID)
+}
+
+// cachedUser 用户在缓存中的规范表示
+// 字段名与直接序列化 domain.User 时保持一致以兼容已有缓存；
+// 时间戳为零值时省略，避免未持久化的用户在缓存命中时返回 0001-01-01
+type cachedUser struct {
+	ID        domain.UserID
+	Username  string
+	Email     string
+	CreatedAt *time.Time `json:",omitempty"`
+	UpdatedAt *time.Time `json:",omitempty"`
+}
+
+// newCachedUser 将领域对象转换为缓存表示
+func newCachedUser(user *domain.User) *cachedUser {
+	cu := &cachedUser{
+		ID:       user.ID,
+		Username: user.Username,
+		Email:    user.Email,
+	}
+	if !user.CreatedAt.IsZero() {
+		createdAt := user.CreatedAt
+		cu.CreatedAt = &createdAt
+	}
+	if !user.UpdatedAt.IsZero() {
+		updatedAt := user.UpdatedAt
+		cu.UpdatedAt = &updatedAt
+	}
+	return cu
+}
+
+// toDomain 将缓存表示转换为领域对象
+func (cu *cachedUser) toDomain() *domain.User {
+	user := &domain.User{
+		ID:       cu.ID,
+		Username: cu.Username,
+		Email:    cu.Email,
+	}
+	if cu.CreatedAt != nil {
+		user.CreatedAt = *cu.CreatedAt
+	}
+	if cu.UpdatedAt != nil {
+		user.UpdatedAt = *cu.UpdatedAt
+	}
+	return user
 }
 
 // serializeUser 序列化用户对象为 JSON
 func serializeUser(user *domain.User) (string, error) {
-	data, err := json.Marshal(user)
+	data, err := codec.MarshalString(newCachedUser(user))
 	if err != nil {
 		return "", fmt.Errorf("failed to serialize user: %w", err)
 	}
-	return string(data), nil
+	return data, nil
 }
 
 // deserializeUser 反序列化 JSON 为用户对象
@@ -63,11 +159,11 @@ func deserializeUser(data string) (*domain.User, error) {
 		return nil, nil
 	}
 
-	var user domain.User
-	if err := json.Unmarshal([]byte(data), &user); err != nil {
+	var cu cachedUser
+	if err := json.Unmarshal([]byte(data), &cu); err != nil {
 		return nil, fmt.Errorf("failed to deserialize user: %w", err)
 	}
-	return &user, nil
+	return cu.toDomain(), nil
 }
 
 // SetUser 缓存用户信息（按 ID）
@@ -95,26 +191,147 @@ func (r *UserRedisCache) SetUser(ctx context.Context, user *domain.User, ttl int
 }
 
 // GetUser 获取缓存的用户信息（按 ID）
-func (r *UserRedisCache) GetUser(ctx context.Context, userID string) (*domain.User, error) {
+func (r *UserRedisCache) GetUser(ctx context.Context, userID domain.UserID) (*domain.User, error) {
 	if userID == "" {
 		return nil, fmt.Errorf("user ID is empty")
 	}
 
 	key := buildUserKey(userID)
-	data, err := r.client.Get(ctx, key)
+	data, found, err := r.client.GetOrNil(ctx, key)
 	if err != nil {
-		if err == redis.Nil {
-			// 缓存不存在
-			return nil, nil
-		}
 		return nil, fmt.Errorf("failed to get user cache: %w", err)
 	}
+	if !found {
+		// 缓存不存在
+		return nil, nil
+	}
+
+	// 命中墓碑，说明用户确认不存在
+	if data == userNotFoundMarker {
+		return nil, domain.ErrUserNotFound
+	}
 
 	return deserializeUser(data)
 }
 
+// GetUserWithTTL 在同一个 Pipeline 中读取用户缓存及其剩余过期时间
+func (r *UserRedisCache) GetUserWithTTL(ctx context.Context, userID domain.UserID) (*domain.User, time.Duration, error) {
+	if userID == "" {
+		return nil, -1, fmt.Errorf("user ID is empty")
+	}
+
+	key := buildUserKey(userID)
+	pipe := r.client.GetClient().Pipeline()
+	getCmd := pipe.Get(ctx, key)
+	ttlCmd := pipe.PTTL(ctx, key)
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		return nil, -1, fmt.Errorf("failed to get user cache: %w", err)
+	}
+
+	data, err := getCmd.Result()
+	if errors.Is(err, redis.Nil) {
+		return nil, -1, nil
+	}
+	if err != nil {
+		return nil, -1, fmt.Errorf("failed to get user cache: %w", err)
+	}
+
+	ttl := ttlCmd.Val()
+	if data == userNotFoundMarker {
+		return nil, ttl, domain.ErrUserNotFound
+	}
+
+	user, err := deserializeUser(data)
+	if err != nil {
+		return nil, -1, err
+	}
+	return user, ttl, nil
+}
+
+// GetUsers 使用 MGET 批量获取缓存的用户信息
+// 命中墓碑的 ID 对应 nil，未缓存的 ID 不会出现在结果中
+func (r *UserRedisCache) GetUsers(ctx context.Context, userIDs []domain.UserID) (map[domain.UserID]*domain.User, error) {
+	users := make(map[domain.UserID]*domain.User, len(userIDs))
+	if len(userIDs) == 0 {
+		return users, nil
+	}
+
+	keys := make([]string, 0, len(userIDs))
+	for _, id := range userIDs {
+		keys = append(keys, buildUserKey(id))
+	}
+
+	values, err := cache.MGetJSON[cachedUser](ctx, r.client, keys...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get users cache: %w", err)
+	}
+
+	for i, key := range keys {
+		cu, ok := values[key]
+		if !ok {
+			continue
+		}
+		if cu == nil {
+			// 墓碑
+			users[userIDs[i]] = nil
+			continue
+		}
+		users[userIDs[i]] = cu.toDomain()
+	}
+
+	return users, nil
+}
+
+// SetUsers 使用 Pipeline 批量缓存用户信息
+func (r *UserRedisCache) SetUsers(ctx context.Context, users []*domain.User, ttl int) error {
+	if len(users) == 0 {
+		return nil
+	}
+
+	// Pipeline 绕过了 RedisClient.Set，需要手动规范化过期时间
+	expiration := r.client.Expiration(time.Duration(ttl) * time.Second)
+
+	pipe := r.client.GetClient().Pipeline()
+	for _, user := range users {
+		if user == nil || user.ID == "" {
+			continue
+		}
+		data, err := serializeUser(user)
+		if err != nil {
+			return err
+		}
+		pipe.Set(ctx, buildUserKey(user.ID), data, expiration)
+	}
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to set users cache: %w", err)
+	}
+
+	return nil
+}
+
+// SetUserNotFound 写入用户不存在的墓碑
+// 墓碑与正常缓存共用同一个键，因此创建用户后 SetUser 会直接覆盖墓碑
+func (r *UserRedisCache) SetUserNotFound(ctx context.Context, userID domain.UserID) error {
+	if userID == "" {
+		return fmt.Errorf("user ID is empty")
+	}
+
+	// 未启用负缓存
+	if r.negativeTTL <= 0 {
+		return nil
+	}
+
+	key := buildUserKey(userID)
+	if err := r.client.Set(ctx, key, userNotFoundMarker, r.negativeTTL); err != nil {
+		return fmt.Errorf("failed to set user tombstone: %w", err)
+	}
+
+	return nil
+}
+
 // DeleteUser 删除用户缓存（按 ID）
-func (r *UserRedisCache) DeleteUser(ctx context.Context, userID string) error {
+func (r *UserRedisCache) DeleteUser(ctx context.Context, userID domain.UserID) error {
 	if userID == "" {
 		return fmt.Errorf("user ID is empty")
 	}
@@ -126,3 +343,27 @@ func (r *UserRedisCache) DeleteUser(ctx context.Context, userID string) error {
 
 	return nil
 }
+
+// LockUser 获取重建用户缓存的分布式锁
+func (r *UserRedisCache) LockUser(ctx context.Context, userID domain.UserID) (func(), bool, error) {
+	if userID == "" {
+		return nil, false, fmt.Errorf("user ID is empty")
+	}
+
+	lock, err := r.client.TryLock(ctx, buildUserLockKey(userID), userLockTTL)
+	if err != nil {
+		if errors.Is(err, cache.ErrLockNotAcquired) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+
+	unlock := func() {
+		// 使用独立的 context，避免请求取消后锁无法释放（最坏情况由 TTL 兜底）
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		_ = lock.Unlock(ctx)
+	}
+
+	return unlock, true, nil
+}
@@ -0,0 +1,139 @@
+package cache
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alfredchaos/demo/internal/user-service/conf"
+	"github.com/alfredchaos/demo/internal/user-service/domain"
+	"github.com/alfredchaos/demo/pkg/cache"
+	"github.com/alfredchaos/demo/pkg/log"
+	"github.com/alicebob/miniredis/v2"
+	"go.uber.org/zap"
+)
+
+// useNopLogger 测试期间使用空日志，结束后恢复原日志
+func useNopLogger(t *testing.T) {
+	t.Helper()
+	original := log.Logger
+	log.Logger = zap.NewNop()
+	t.Cleanup(func() { log.Logger = original })
+}
+
+func TestUserRedisCache_GetUsersMergesHitsAndTombstones(t *testing.T) {
+	useNopLogger(t)
+	mr := miniredis.RunT(t)
+	userCache := NewUserRedisCache(&cache.RedisConfig{Addr: mr.Addr()}, &conf.UserCacheConfig{NegativeTTL: 30})
+	ctx := context.Background()
+
+	alice := &domain.User{ID: "u1", Username: "alice"}
+	if err := userCache.SetUser(ctx, alice, 60); err != nil {
+		t.Fatalf("set user: %v", err)
+	}
+	if err := userCache.SetUserNotFound(ctx, "u2"); err != nil {
+		t.Fatalf("set tombstone: %v", err)
+	}
+
+	users, err := userCache.GetUsers(ctx, []domain.UserID{"u1", "u2", "u3"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := users["u1"]; got == nil || got.Username != "alice" {
+		t.Fatalf("want u1 hit, got %+v", got)
+	}
+	if got, ok := users["u2"]; !ok || got != nil {
+		t.Fatalf("want u2 tombstone as nil entry, got %+v (present=%v)", got, ok)
+	}
+	if _, ok := users["u3"]; ok {
+		t.Fatalf("uncached u3 should be omitted")
+	}
+
+	// 单条读取同样识别墓碑
+	if _, err := userCache.GetUser(ctx, "u2"); err != domain.ErrUserNotFound {
+		t.Fatalf("want ErrUserNotFound for tombstone, got %v", err)
+	}
+}
+
+func TestUserRedisCache_GetUserWithTTL(t *testing.T) {
+	useNopLogger(t)
+	mr := miniredis.RunT(t)
+	userCache := NewUserRedisCache(&cache.RedisConfig{Addr: mr.Addr()}, &conf.UserCacheConfig{NegativeTTL: 30})
+	ctx := context.Background()
+
+	if user, ttl, err := userCache.GetUserWithTTL(ctx, "u1"); err != nil || user != nil || ttl >= 0 {
+		t.Fatalf("want miss with negative ttl, got %+v, %v, %v", user, ttl, err)
+	}
+
+	if err := userCache.SetUser(ctx, &domain.User{ID: "u1", Username: "alice"}, 60); err != nil {
+		t.Fatalf("set user: %v", err)
+	}
+	mr.FastForward(45 * time.Second)
+
+	user, ttl, err := userCache.GetUserWithTTL(ctx, "u1")
+	if err != nil || user == nil || user.Username != "alice" {
+		t.Fatalf("want alice, got %+v, %v", user, err)
+	}
+	if ttl != 15*time.Second {
+		t.Fatalf("want 15s remaining, got %v", ttl)
+	}
+
+	if err := userCache.SetUserNotFound(ctx, "u2"); err != nil {
+		t.Fatalf("set tombstone: %v", err)
+	}
+	if _, _, err := userCache.GetUserWithTTL(ctx, "u2"); err != domain.ErrUserNotFound {
+		t.Fatalf("want ErrUserNotFound for tombstone, got %v", err)
+	}
+}
+
+func TestUserRedisCache_TimestampsRoundTrip(t *testing.T) {
+	useNopLogger(t)
+	mr := miniredis.RunT(t)
+	userCache := NewUserRedisCache(&cache.RedisConfig{Addr: mr.Addr()}, nil)
+	ctx := context.Background()
+
+	// 持久化后的时间戳（与数据库回填的值一致）应原样从缓存返回
+	createdAt := time.Date(2024, 5, 1, 8, 30, 0, 123456000, time.UTC)
+	updatedAt := createdAt.Add(time.Hour)
+	persisted := &domain.User{ID: "u1", Username: "alice", CreatedAt: createdAt, UpdatedAt: updatedAt}
+	if err := userCache.SetUsers(ctx, []*domain.User{persisted}, 60); err != nil {
+		t.Fatalf("set users: %v", err)
+	}
+
+	got, err := userCache.GetUser(ctx, "u1")
+	if err != nil {
+		t.Fatalf("get user: %v", err)
+	}
+	if !got.CreatedAt.Equal(createdAt) || !got.UpdatedAt.Equal(updatedAt) {
+		t.Fatalf("want timestamps %v/%v, got %v/%v", createdAt, updatedAt, got.CreatedAt, got.UpdatedAt)
+	}
+
+	users, err := userCache.GetUsers(ctx, []domain.UserID{"u1"})
+	if err != nil {
+		t.Fatalf("get users: %v", err)
+	}
+	if u := users["u1"]; u == nil || !u.CreatedAt.Equal(createdAt) || !u.UpdatedAt.Equal(updatedAt) {
+		t.Fatalf("want batch timestamps %v/%v, got %+v", createdAt, updatedAt, u)
+	}
+}
+
+func TestUserRedisCache_OmitsZeroTimestamps(t *testing.T) {
+	useNopLogger(t)
+	mr := miniredis.RunT(t)
+	userCache := NewUserRedisCache(&cache.RedisConfig{Addr: mr.Addr()}, nil)
+	ctx := context.Background()
+
+	if err := userCache.SetUser(ctx, &domain.User{ID: "u1", Username: "alice"}, 60); err != nil {
+		t.Fatalf("set user: %v", err)
+	}
+
+	raw, err := mr.Get(buildUserKey("u1"))
+	if err != nil {
+		t.Fatalf("get raw: %v", err)
+	}
+	if strings.Contains(raw, "0001-01-01") {
+		t.Fatalf("cached entry should not contain zero timestamps: %s", raw)
+	}
+}
@@ -5,9 +5,13 @@ import (
 
 	"github.com/alfredchaos/demo/pkg/cache"
 	"github.com/alfredchaos/demo/pkg/db"
+	"github.com/alfredchaos/demo/pkg/debugserver"
 	"github.com/alfredchaos/demo/pkg/grpcclient"
+	"github.com/alfredchaos/demo/pkg/health"
 	"github.com/alfredchaos/demo/pkg/log"
+	"github.com/alfredchaos/demo/pkg/middleware"
 	"github.com/alfredchaos/demo/pkg/mq"
+	"github.com/alfredchaos/demo/pkg/startup"
 )
 
 // 配置类型别名
@@ -19,13 +23,20 @@ type (
 
 // Config user-service 配置结构
 type Config struct {
-	Server      ServerConfig      `yaml:"server" mapstructure:"server"`             // 服务器配置
-	Log         log.LogConfig     `yaml:"log" mapstructure:"log"`                   // 日志配置
-	Database    DatabaseConfig    `yaml:"database" mapstructure:"database"`         // 数据库配置
-	MongoDB     db.MongoConfig    `yaml:"mongodb" mapstructure:"mongodb"`           // MongoDB配置
-	Redis       CacheConfig       `yaml:"redis" mapstructure:"redis"`               // 缓存配置
-	RabbitMQ    MQConfig          `yaml:"rabbitmq" mapstructure:"rabbitmq"`         // 消息队列配置
-	GRPCClients grpcclient.Config `yaml:"grpc_clients" mapstructure:"grpc_clients"` // gRPC客户端配置
+	Server       ServerConfig        `yaml:"server" mapstructure:"server"`               // 服务器配置
+	Log          log.LogConfig       `yaml:"log" mapstructure:"log"`                     // 日志配置
+	Database     DatabaseConfig      `yaml:"database" mapstructure:"database"`           // 数据库配置
+	MongoDB      db.MongoConfig      `yaml:"mongodb" mapstructure:"mongodb"`             // MongoDB配置
+	Redis        CacheConfig         `yaml:"redis" mapstructure:"redis"`                 // 缓存配置
+	RabbitMQ     MQConfig            `yaml:"rabbitmq" mapstructure:"rabbitmq"`           // 消息队列配置
+	GRPCClients  grpcclient.Config   `yaml:"grpc_clients" mapstructure:"grpc_clients"`   // gRPC客户端配置
+	Middleware   middleware.Config   `yaml:"middleware" mapstructure:"middleware"`       // gRPC 服务端拦截器开关
+	Debug        debugserver.Config  `yaml:"debug" mapstructure:"debug"`                 // 调试服务（pprof）配置，默认关闭
+	Probe        health.ProbeConfig  `yaml:"probe" mapstructure:"probe"`                 // k8s 探针服务（/livez、/readyz）配置
+	StartupRetry startup.RetryPolicy `yaml:"startup_retry" mapstructure:"startup_retry"` // 启动时连接依赖的重试策略
+	UserCache    UserCacheConfig     `yaml:"user_cache" mapstructure:"user_cache"`       // 用户缓存策略配置
+
+	FeatureFlags map[string]bool `yaml:"feature_flags" mapstructure:"feature_flags"` // 功能开关，用于灰度启用新行为
 }
 
 // ServerConfig 服务器配置
@@ -33,6 +44,18 @@ type ServerConfig struct {
 	Name string `yaml:"name" mapstructure:"name"` // 服务名称
 	Host string `yaml:"host" mapstructure:"host"` // 监听地址
 	Port int    `yaml:"port" mapstructure:"port"` // 监听端口
+
+	SlowThreshold int `yaml:"slow_threshold" mapstructure:"slow_threshold"` // 慢请求阈值(毫秒)，超过时以 warn 级别记录，默认1000
+
+	MaxConcurrentStreams uint32 `yaml:"max_concurrent_streams" mapstructure:"max_concurrent_streams"` // 单个连接上的最大并发流数（HTTP/2 SETTINGS），超过时客户端在本连接上排队，0 表示使用 gRPC 默认值
+	MaxInFlight          int    `yaml:"max_in_flight" mapstructure:"max_in_flight"`                   // 全局同时处理的最大请求（流）数，超过时返回 ResourceExhausted，0 表示不限制
+	MaxConnections       int    `yaml:"max_connections" mapstructure:"max_connections"`               // 同时保持的最大连接数，超过时新连接被立即关闭，0 表示不限制
+}
+
+// UserCacheConfig 用户缓存策略配置
+type UserCacheConfig struct {
+	NegativeTTL  int `yaml:"negative_ttl" mapstructure:"negative_ttl"`   // 不存在用户的墓碑缓存时间(秒)，0 表示不启用负缓存
+	RefreshAhead int `yaml:"refresh_ahead" mapstructure:"refresh_ahead"` // 提前刷新窗口(秒)，剩余过期时间小于该值时读取会触发后台重新加载，0 表示不启用
 }
 
 // GetAddr 获取完整的服务地址
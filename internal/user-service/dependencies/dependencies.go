@@ -1,6 +1,8 @@
 package dependencies
 
 import (
+	"time"
+
 	bookv1 "github.com/alfredchaos/demo/api/book/v1"
 	"github.com/alfredchaos/demo/internal/user-service/biz"
 	"github.com/alfredchaos/demo/internal/user-service/cache"
@@ -11,9 +13,11 @@ import (
 	"github.com/alfredchaos/demo/internal/user-service/repository/mongo"
 	"github.com/alfredchaos/demo/internal/user-service/repository/psql"
 	"github.com/alfredchaos/demo/internal/user-service/service"
-	"github.com/alfredchaos/demo/pkg/db"
+	"github.com/alfredchaos/demo/pkg/featureflag"
 	"github.com/alfredchaos/demo/pkg/grpcclient"
+	"github.com/alfredchaos/demo/pkg/health"
 	"github.com/alfredchaos/demo/pkg/log"
+	"github.com/alfredchaos/demo/pkg/startup"
 	"go.uber.org/zap"
 )
 
@@ -28,40 +32,57 @@ type AppContext struct {
 type Dependencies struct {
 	ClientManager *grpcclient.Manager
 	Cfg           *conf.Config
+	Readiness     *health.Readiness // 就绪状态，依赖连接成功后在其中标记，可为 nil
 }
 
 func InjectDependencies(deps *Dependencies) (*AppContext, error) {
-	// 获取 gRPC 客户端（使用 GetClient 自动创建类型化客户端）
-	client, err := deps.ClientManager.GetClient("book-service")
+	// 获取类型化的 gRPC 客户端，客户端注册错误时返回错误而不是 panic
+	bookClient, err := grpcclient.Typed[bookv1.BookServiceClient](deps.ClientManager, "book-service")
+	if err != nil {
+		log.Error("failed to get book service client, check the client configured for book-service", zap.Error(err))
+		return nil, err
+	}
+
+	// 按 enabled 开关连接各存储后端
+	stores, err := connectStores(deps.Cfg, defaultStoreConnectors, deps.Readiness)
 	if err != nil {
-		log.Fatal("failed to get user service client", zap.Error(err))
 		return nil, err
 	}
-	bookClient := client.(bookv1.BookServiceClient)
 
-	var pgClient *db.PostgresClient
 	var userRepo repository.UserRepository
-	if deps.Cfg.Database.Enabled {
-		pgClient = psql.MustInitPostgresClient(&deps.Cfg.Database)
-		userRepo = psql.NewUserPgRepository(pgClient.GetDB())
+	if stores.postgres != nil {
+		userRepo = psql.NewUserPgRepository(stores.postgres.GetDB(), &deps.Cfg.Database)
 	}
 
-	var mongoClient *db.MongoClient
 	var userDocumentRepo repository.UserDocumentRepository
-	if deps.Cfg.MongoDB.URI != "" {
-		mongoCfg := mongo.FromMongoConfig(&deps.Cfg.MongoDB)
-		mongoClient = mongo.MustInitMongoClient(mongoCfg)
-		userDocumentRepo = mongo.NewUserMongoDocumentRepository(mongoClient)
+	if stores.mongo != nil {
+		userDocumentRepo = mongo.NewUserMongoDocumentRepository(stores.mongo)
 	}
 
-	data := repository.NewData(pgClient, mongoClient, userRepo, userDocumentRepo)
-	userCache := cache.NewUserRedisCache(&deps.Cfg.Redis)
+	data := repository.NewData(stores.postgres, stores.mongo, userRepo, userDocumentRepo)
+
+	// 未启用 Redis 时使用空缓存，读请求直接查询数据库
+	var userCache cache.UserCache = cache.NewNoopUserCache()
+	if stores.redis != nil {
+		userCache = cache.NewUserRedisCacheWithClient(stores.redis, &deps.Cfg.UserCache)
+	}
 
 	// 初始化 RabbitMQ，user-service 仅作为消息发布者
-	messageQueue := rabbitmq.MustInitRabbitMQ(&deps.Cfg.RabbitMQ)
+	var messageQueue *rabbitmq.MessageQueue
+	deps.Readiness.Require("rabbitmq")
+	if err := startup.RetryConnect("rabbitmq", func() (err error) {
+		messageQueue, err = rabbitmq.InitRabbitMQ(&deps.Cfg.RabbitMQ)
+		return err
+	}, deps.Cfg.StartupRetry); err != nil {
+		return nil, err
+	}
+	deps.Readiness.MarkReady("rabbitmq", messageQueue)
 	publisher, err := messageQueue.NewPublisher()
 	if err != nil {
-		log.Fatal("failed to create publisher", zap.Error(err))
+		log.Error("failed to create publisher", zap.Error(err))
+		if closeErr := messageQueue.Close(); closeErr != nil {
+			log.Error("failed to close rabbitmq", zap.Error(closeErr))
+		}
 		return nil, err
 	}
 
@@ -71,7 +92,8 @@ func InjectDependencies(deps *Dependencies) (*AppContext, error) {
 		data.UserDocumentRepo,
 		userCache,
 		publisher,
-	)
+		featureflag.New(deps.Cfg.FeatureFlags),
+	).WithRefreshAhead(time.Duration(deps.Cfg.UserCache.RefreshAhead) * time.Second)
 
 	userService := service.NewUserService(userUseCase)
 
@@ -0,0 +1,61 @@
+package dependencies
+
+import (
+	"errors"
+	"testing"
+
+	userv1 "github.com/alfredchaos/demo/api/user/v1"
+	"github.com/alfredchaos/demo/internal/clients"
+	"github.com/alfredchaos/demo/internal/user-service/conf"
+	"github.com/alfredchaos/demo/pkg/grpcclient"
+	"github.com/alfredchaos/demo/pkg/log"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+)
+
+// TestInjectDependencies_MisconfiguredBookClient book-service 注册了错误的客户端时返回错误，不 panic，也不连接存储
+func TestInjectDependencies_MisconfiguredBookClient(t *testing.T) {
+	prevLogger := log.Logger
+	log.Logger = zap.NewNop()
+	t.Cleanup(func() {
+		log.Logger = prevLogger
+		grpcclient.GlobalRegistry.Register("book-service", clients.Factories()["book-service"])
+	})
+
+	cases := []struct {
+		name    string
+		factory grpcclient.ClientFactory
+	}{
+		{"wrong client type", func(conn *grpc.ClientConn) interface{} { return userv1.NewUserServiceClient(conn) }},
+		{"nil client", func(conn *grpc.ClientConn) interface{} { return nil }},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			grpcclient.GlobalRegistry.Register("book-service", c.factory)
+
+			manager := grpcclient.NewManager()
+			defer manager.Close()
+			if err := manager.Register(&grpcclient.ServiceConfig{Name: "book-service", Address: "127.0.0.1:1"}); err != nil {
+				t.Fatalf("register: %v", err)
+			}
+			if err := manager.Connect("book-service"); err != nil {
+				t.Fatalf("connect: %v", err)
+			}
+
+			// 存储均启用，若未在获取客户端时返回会尝试连接数据库
+			cfg := &conf.Config{}
+			cfg.Database.Enabled = true
+			cfg.MongoDB.URI = "mongodb://127.0.0.1:1"
+			cfg.Redis.Addr = "127.0.0.1:1"
+
+			appCtx, err := InjectDependencies(&Dependencies{ClientManager: manager, Cfg: cfg})
+			if !errors.Is(err, grpcclient.ErrClientType) {
+				t.Fatalf("want ErrClientType, got %v", err)
+			}
+			if appCtx != nil {
+				t.Fatalf("want nil app context, got %+v", appCtx)
+			}
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package dependencies
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/alfredchaos/demo/internal/user-service/conf"
+	"github.com/alfredchaos/demo/internal/user-service/repository/mongo"
+	pkgcache "github.com/alfredchaos/demo/pkg/cache"
+	"github.com/alfredchaos/demo/pkg/db"
+	"github.com/alfredchaos/demo/pkg/log"
+	"github.com/alfredchaos/demo/pkg/startup"
+	"go.uber.org/zap"
+)
+
+// fakeConnectors 记录各后端是否被连接
+type fakeConnectors struct {
+	calls map[string]int
+}
+
+func (f *fakeConnectors) connectors() storeConnectors {
+	f.calls = map[string]int{}
+	return storeConnectors{
+		postgres: func(cfg *db.PostgresConfig) (*db.PostgresClient, error) {
+			f.calls["postgres"]++
+			return &db.PostgresClient{}, nil
+		},
+		mongo: func(cfg *mongo.Config) (*db.MongoClient, error) {
+			f.calls["mongodb"]++
+			return &db.MongoClient{}, nil
+		},
+		redis: func(cfg *pkgcache.RedisConfig) (*pkgcache.RedisClient, error) {
+			f.calls["redis"]++
+			return &pkgcache.RedisClient{}, nil
+		},
+	}
+}
+
+func TestConnectStores_EnabledCombinations(t *testing.T) {
+	useNopLogger(t)
+
+	for mask := 0; mask < 8; mask++ {
+		pg, mg, rd := mask&1 != 0, mask&2 != 0, mask&4 != 0
+		t.Run(fmt.Sprintf("postgres=%t,mongodb=%t,redis=%t", pg, mg, rd), func(t *testing.T) {
+			cfg := &conf.Config{}
+			cfg.Database.Enabled = pg
+			cfg.MongoDB.Enabled = &mg
+			cfg.Redis.Enabled = &rd
+
+			fake := &fakeConnectors{}
+			clients, err := connectStores(cfg, fake.connectors(), nil)
+			if err != nil {
+				t.Fatalf("connectStores: %v", err)
+			}
+
+			if (clients.postgres != nil) != pg || fake.calls["postgres"] != boolToCalls(pg) {
+				t.Errorf("postgres: want connected=%t, got client=%v calls=%d", pg, clients.postgres, fake.calls["postgres"])
+			}
+			if (clients.mongo != nil) != mg || fake.calls["mongodb"] != boolToCalls(mg) {
+				t.Errorf("mongodb: want connected=%t, got client=%v calls=%d", mg, clients.mongo, fake.calls["mongodb"])
+			}
+			if (clients.redis != nil) != rd || fake.calls["redis"] != boolToCalls(rd) {
+				t.Errorf("redis: want connected=%t, got client=%v calls=%d", rd, clients.redis, fake.calls["redis"])
+			}
+		})
+	}
+}
+
+// TestConnectStores_EnabledDefaultsFromAddress 未配置 enabled 时按地址是否为空决定，兼容旧配置文件
+func TestConnectStores_EnabledDefaultsFromAddress(t *testing.T) {
+	useNopLogger(t)
+
+	disabled := false
+	cases := []struct {
+		name      string
+		configure func(cfg *conf.Config)
+		wantMongo bool
+		wantRedis bool
+	}{
+		{"no address", func(cfg *conf.Config) {}, false, false},
+		{"address without enabled", func(cfg *conf.Config) {
+			cfg.MongoDB.URI = "mongodb://localhost:27017"
+			cfg.Redis.Addr = "localhost:6379"
+		}, true, true},
+		{"explicitly disabled", func(cfg *conf.Config) {
+			cfg.MongoDB.URI = "mongodb://localhost:27017"
+			cfg.MongoDB.Enabled = &disabled
+			cfg.Redis.Addr = "localhost:6379"
+			cfg.Redis.Enabled = &disabled
+		}, false, false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cfg := &conf.Config{}
+			c.configure(cfg)
+
+			fake := &fakeConnectors{}
+			clients, err := connectStores(cfg, fake.connectors(), nil)
+			if err != nil {
+				t.Fatalf("connectStores: %v", err)
+			}
+			if (clients.mongo != nil) != c.wantMongo || (clients.redis != nil) != c.wantRedis {
+				t.Fatalf("want mongodb=%t redis=%t, got mongodb=%v redis=%v", c.wantMongo, c.wantRedis, clients.mongo, clients.redis)
+			}
+		})
+	}
+}
+
+func TestConnectStores_EnabledBackendFailureIsReturned(t *testing.T) {
+	useNopLogger(t)
+
+	cfg := &conf.Config{StartupRetry: startup.RetryPolicy{MaxAttempts: 1}}
+	cfg.Database.Enabled = true
+	cfg.Redis.Addr = "localhost:6379"
+
+	connErr := errors.New("connection refused")
+	fake := &fakeConnectors{}
+	connectors := fake.connectors()
+	connectors.redis = func(cfg *pkgcache.RedisConfig) (*pkgcache.RedisClient, error) {
+		return nil, connErr
+	}
+
+	if _, err := connectStores(cfg, connectors, nil); !errors.Is(err, connErr) {
+		t.Fatalf("want redis connection error, got %v", err)
+	}
+}
+
+func boolToCalls(enabled bool) int {
+	if enabled {
+		return 1
+	}
+	return 0
+}
+
+// useNopLogger 测试期间使用空日志，结束后恢复原日志
+func useNopLogger(t *testing.T) {
+	t.Helper()
+	original := log.Logger
+	log.Logger = zap.NewNop()
+	t.Cleanup(func() { log.Logger = original })
+}
@@ -1,17 +1,18 @@
 package domain
 
-import "errors"
+import "github.com/alfredchaos/demo/pkg/errors"
 
+// 领域哨兵错误携带错误码，可通过 errors.HTTPStatusOf / errors.GRPCCodeOf 统一映射
 var (
 	// ErrInvalidUsername 无效的用户名
-	ErrInvalidUsername = errors.New("invalid username")
-	
+	ErrInvalidUsername = errors.NewCoded(errors.ErrInvalidParams, "invalid username")
+
 	// ErrInvalidEmail 无效的邮箱
-	ErrInvalidEmail = errors.New("invalid email")
-	
+	ErrInvalidEmail = errors.NewCoded(errors.ErrInvalidParams, "invalid email")
+
 	// ErrUserNotFound 用户不存在
-	ErrUserNotFound = errors.New("user not found")
-	
+	ErrUserNotFound = errors.NewCoded(errors.ErrNotFound, "user not found")
+
 	// ErrUserAlreadyExists 用户已存在
-	ErrUserAlreadyExists = errors.New("user already exists")
+	ErrUserAlreadyExists = errors.NewCoded(errors.ErrConflict, "user already exists")
 )
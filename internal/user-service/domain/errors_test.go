@@ -0,0 +1,47 @@
+package domain
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/alfredchaos/demo/pkg/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestErrUserNotFound_MapsToNotFound(t *testing.T) {
+	// 仓库层通常会包装一层错误
+	err := fmt.Errorf("failed to load user: %w", ErrUserNotFound)
+
+	if got := errors.HTTPStatusOf(err); got != http.StatusNotFound {
+		t.Fatalf("want http 404, got %d", got)
+	}
+	if got := errors.GRPCCodeOf(err); got != codes.NotFound {
+		t.Fatalf("want codes.NotFound, got %s", got)
+	}
+	if got := status.Code(errors.ToGRPCError(err)); got != codes.NotFound {
+		t.Fatalf("want grpc status NotFound, got %s", got)
+	}
+}
+
+func TestValidationErrors_MapToInvalidArgument(t *testing.T) {
+	err := (&User{Email: "a@example.com"}).Validate()
+
+	if got := errors.HTTPStatusOf(err); got != http.StatusBadRequest {
+		t.Fatalf("want http 400, got %d", got)
+	}
+	if got := errors.GRPCCodeOf(err); got != codes.InvalidArgument {
+		t.Fatalf("want codes.InvalidArgument, got %s", got)
+	}
+}
+
+func TestValidate_Email(t *testing.T) {
+	// 邮箱为空表示暂未获取，允许保存
+	if err := (&User{Username: "alice"}).Validate(); err != nil {
+		t.Fatalf("want empty email accepted, got %v", err)
+	}
+	if err := (&User{Username: "alice", Email: "  "}).Validate(); err != ErrInvalidEmail {
+		t.Fatalf("want ErrInvalidEmail for blank email, got %v", err)
+	}
+}
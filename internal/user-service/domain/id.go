@@ -0,0 +1,31 @@
+package domain
+
+import "github.com/alfredchaos/demo/pkg/idgen"
+
+// UserID 用户 ID
+// 与其他实体的 ID 区分类型，避免在编译期无法发现的 ID 混用；JSON / BSON 中仍序列化为普通字符串
+type UserID string
+
+// NewUserID 生成新的用户 ID（UUID）
+func NewUserID() UserID {
+	return UserID(idgen.UUID.New())
+}
+
+// ParseUserID 解析并校验外部传入的用户 ID，格式不合法时返回包装了 idgen.ErrInvalidID 的错误
+func ParseUserID(s string) (UserID, error) {
+	id := UserID(s)
+	if err := id.Validate(); err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+// Validate 校验 ID 是否为标准格式的 UUID
+func (id UserID) Validate() error {
+	return idgen.UUID.Validate(string(id))
+}
+
+// String 实现 fmt.Stringer 接口
+func (id UserID) String() string {
+	return string(id)
+}
@@ -0,0 +1,58 @@
+package domain
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"testing"
+
+	"github.com/alfredchaos/demo/pkg/errors"
+	"github.com/alfredchaos/demo/pkg/idgen"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestParseUserID(t *testing.T) {
+	id := NewUserID()
+	got, err := ParseUserID(id.String())
+	if err != nil || got != id {
+		t.Fatalf("want %q to parse, got %q (err=%v)", id, got, err)
+	}
+
+	for _, raw := range []string{
+		"",
+		"u1",
+		"6ba7b8109dad11d180b400c04fd430c8",
+		"{6ba7b810-9dad-11d1-80b4-00c04fd430c8}",
+		"urn:uuid:6ba7b810-9dad-11d1-80b4-00c04fd430c8",
+	} {
+		got, err := ParseUserID(raw)
+		if !stderrors.Is(err, idgen.ErrInvalidID) || got != "" {
+			t.Errorf("%q: want ErrInvalidID, got %q (err=%v)", raw, got, err)
+		}
+		if errors.CodeOf(err) != errors.ErrInvalidParams {
+			t.Errorf("%q: want ErrInvalidParams code, got %d", raw, errors.CodeOf(err))
+		}
+	}
+}
+
+func TestUserID_MarshalsAsPlainString(t *testing.T) {
+	const raw = "6ba7b810-9dad-41d1-80b4-00c04fd430c8"
+	user := struct {
+		ID UserID `json:"id" bson:"_id"`
+	}{ID: UserID(raw)}
+
+	data, err := json.Marshal(user)
+	if err != nil {
+		t.Fatalf("json marshal: %v", err)
+	}
+	if string(data) != `{"id":"`+raw+`"}` {
+		t.Fatalf("unexpected json: %s", data)
+	}
+
+	doc, err := bson.Marshal(user)
+	if err != nil {
+		t.Fatalf("bson marshal: %v", err)
+	}
+	if got, ok := bson.Raw(doc).Lookup("_id").StringValueOK(); !ok || got != raw {
+		t.Fatalf("want bson string %q, got %v", raw, bson.Raw(doc).Lookup("_id"))
+	}
+}
@@ -1,12 +1,15 @@
 package domain
 
-import "time"
+import (
+	"strings"
+	"time"
+)
 
 // User 用户领域模型
 type User struct {
-	ID        string    // 用户ID
+	ID        UserID    // 用户ID
 	Username  string    // 用户名
-	Email     string    // 邮箱
+	Email     string    // 邮箱，为空表示暂未获取
 	CreatedAt time.Time // 创建时间
 	UpdatedAt time.Time // 更新时间
 }
@@ -23,11 +26,12 @@ func NewUser(username, email string) *User {
 }
 
 // Validate 验证用户数据
+// 邮箱可以为空，表示暂未获取（如 book-service 降级时创建的用户），但不能是空白字符
 func (u *User) Validate() error {
 	if u.Username == "" {
 		return ErrInvalidUsername
 	}
-	if u.Email == "" {
+	if u.Email != "" && strings.TrimSpace(u.Email) == "" {
 		return ErrInvalidEmail
 	}
 	return nil
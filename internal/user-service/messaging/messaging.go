@@ -1,14 +1,25 @@
 package messaging
 
-import "context"
+import (
+	"context"
+
+	"github.com/alfredchaos/demo/pkg/mq"
+)
 
 // MessageHandler 消息处理函数类型
 type MessageHandler func(ctx context.Context, message []byte) error
 
+// OutgoingMessage 批量发布的单条消息
+type OutgoingMessage = mq.OutgoingMessage
+
 // Publisher 消息发布者接口
 type Publisher interface {
 	Publish(ctx context.Context, message []byte) error
 	PublishWithRouting(ctx context.Context, routingKey string, message []byte) error
+	// PublishWithPriority 按优先级发布，仅对声明了 max_priority 的队列生效
+	PublishWithPriority(ctx context.Context, routingKey string, message []byte, priority uint8) error
+	// PublishMany 批量发布，部分失败时返回 *mq.PublishManyError
+	PublishMany(ctx context.Context, msgs []OutgoingMessage) error
 	Close() error
 }
 
@@ -46,6 +46,16 @@ func (p *publisher) PublishWithRouting(ctx context.Context, routingKey string, m
 	)
 }
 
+// PublishWithPriority 以指定优先级发布消息
+func (p *publisher) PublishWithPriority(ctx context.Context, routingKey string, message []byte, priority uint8) error {
+	return p.mqPublisher.PublishWithPriority(ctx, routingKey, message, priority)
+}
+
+// PublishMany 在一个确认批次中发布多条消息
+func (p *publisher) PublishMany(ctx context.Context, msgs []messaging.OutgoingMessage) error {
+	return p.mqPublisher.PublishMany(ctx, msgs)
+}
+
 // Close 关闭发布者
 func (p *publisher) Close() error {
 	return p.mqPublisher.Close()
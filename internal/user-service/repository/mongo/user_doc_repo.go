@@ -31,8 +31,8 @@ func NewUserMongoDocumentRepository(client *db.MongoClient) *UserMongoDocumentRe
 }
 
 // SaveDocument 保存用户文档（JSON 格式）
-func (r *UserMongoDocumentRepository) SaveDocument(ctx context.Context, userID string, document map[string]interface{}) error {
-	document["_id"] = userID
+func (r *UserMongoDocumentRepository) SaveDocument(ctx context.Context, userID domain.UserID, document map[string]interface{}) error {
+	document["_id"] = string(userID)
 
 	// 自动添加/更新时间戳
 	now := time.Now()
@@ -42,7 +42,7 @@ func (r *UserMongoDocumentRepository) SaveDocument(ctx context.Context, userID s
 	document["updated_at"] = now
 
 	// Upsert 操作
-	filter := bson.M{"_id": userID}
+	filter := bson.M{"_id": string(userID)}
 	update := bson.M{"$set": document}
 	opts := options.Update().SetUpsert(true)
 
@@ -54,11 +54,38 @@ func (r *UserMongoDocumentRepository) SaveDocument(ctx context.Context, userID s
 	return nil
 }
 
+// UpsertDocument 创建或更新用户文档
+// created 表示是否插入了新文档；created_at 仅在插入时写入，更新时保留原值
+func (r *UserMongoDocumentRepository) UpsertDocument(ctx context.Context, userID domain.UserID, document map[string]interface{}) (bool, error) {
+	now := time.Now()
+	createdAt, exists := document["created_at"]
+	if !exists {
+		createdAt = now
+	}
+	delete(document, "_id")
+	delete(document, "created_at")
+	document["updated_at"] = now
+
+	filter := bson.M{"_id": string(userID)}
+	update := bson.M{
+		"$set":         document,
+		"$setOnInsert": bson.M{"created_at": createdAt},
+	}
+	opts := options.Update().SetUpsert(true)
+
+	result, err := r.collection.UpdateOne(ctx, filter, update, opts)
+	if err != nil {
+		return false, fmt.Errorf("failed to upsert document: %w", err)
+	}
+
+	return result.UpsertedCount > 0, nil
+}
+
 // GetDocument 根据ID获取用户文档（JSON 格式）
-func (r *UserMongoDocumentRepository) GetDocument(ctx context.Context, userID string) (map[string]interface{}, error) {
+func (r *UserMongoDocumentRepository) GetDocument(ctx context.Context, userID domain.UserID) (map[string]interface{}, error) {
 	var document map[string]interface{}
 
-	err := r.collection.FindOne(ctx, bson.M{"_id": userID}).Decode(&document)
+	err := r.collection.FindOne(ctx, bson.M{"_id": string(userID)}).Decode(&document)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			return nil, domain.ErrUserNotFound
@@ -69,9 +96,55 @@ func (r *UserMongoDocumentRepository) GetDocument(ctx context.Context, userID st
 	return document, nil
 }
 
+// GetDocumentsByIDs 根据ID批量获取用户文档
+func (r *UserMongoDocumentRepository) GetDocumentsByIDs(ctx context.Context, userIDs []domain.UserID) (map[domain.UserID]map[string]interface{}, error) {
+	documents := make(map[domain.UserID]map[string]interface{}, len(userIDs))
+	if len(userIDs) == 0 {
+		return documents, nil
+	}
+
+	rawIDs := make([]string, 0, len(userIDs))
+	for _, id := range userIDs {
+		rawIDs = append(rawIDs, string(id))
+	}
+
+	cursor, err := r.collection.Find(ctx, bson.M{"_id": bson.M{"$in": rawIDs}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find documents by ids: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var results []map[string]interface{}
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, fmt.Errorf("failed to decode documents: %w", err)
+	}
+
+	for _, doc := range results {
+		if id, ok := doc["_id"].(string); ok {
+			documents[domain.UserID(id)] = doc
+		}
+	}
+
+	return documents, nil
+}
+
+// Exists 判断用户文档是否存在
+// 只投影 _id 字段，避免传输整个文档
+func (r *UserMongoDocumentRepository) Exists(ctx context.Context, userID domain.UserID) (bool, error) {
+	opts := options.FindOne().SetProjection(bson.M{"_id": 1})
+	err := r.collection.FindOne(ctx, bson.M{"_id": string(userID)}, opts).Err()
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to check document existence: %w", err)
+	}
+	return true, nil
+}
+
 // DeleteDocument 删除用户文档
-func (r *UserMongoDocumentRepository) DeleteDocument(ctx context.Context, userID string) error {
-	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": userID})
+func (r *UserMongoDocumentRepository) DeleteDocument(ctx context.Context, userID domain.UserID) error {
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": string(userID)})
 	if err != nil {
 		return fmt.Errorf("failed to delete document: %w", err)
 	}
@@ -113,10 +186,10 @@ func (r *UserMongoDocumentRepository) FindDocuments(ctx context.Context, filter
 }
 
 // UpdateDocumentFields 更新文档的部分字段
-func (r *UserMongoDocumentRepository) UpdateDocumentFields(ctx context.Context, userID string, fields map[string]interface{}) error {
+func (r *UserMongoDocumentRepository) UpdateDocumentFields(ctx context.Context, userID domain.UserID, fields map[string]interface{}) error {
 	fields["updated_at"] = time.Now()
 
-	filter := bson.M{"_id": userID}
+	filter := bson.M{"_id": string(userID)}
 	update := bson.M{"$set": fields}
 
 	result, err := r.collection.UpdateOne(ctx, filter, update)
@@ -0,0 +1,76 @@
+package mongo
+
+import (
+	"context"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestUserMongoDocumentRepository_Exists(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("existing", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "test.users", mtest.FirstBatch, bson.D{{Key: "_id", Value: "u1"}}))
+		repo := &UserMongoDocumentRepository{collection: mt.Coll}
+
+		got, err := repo.Exists(context.Background(), "u1")
+		if err != nil {
+			mt.Fatalf("unexpected error: %v", err)
+		}
+		if !got {
+			mt.Fatalf("want existing document")
+		}
+	})
+
+	mt.Run("missing", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "test.users", mtest.FirstBatch))
+		repo := &UserMongoDocumentRepository{collection: mt.Coll}
+
+		got, err := repo.Exists(context.Background(), "u1")
+		if err != nil {
+			mt.Fatalf("unexpected error: %v", err)
+		}
+		if got {
+			mt.Fatalf("want missing document")
+		}
+	})
+}
+
+func TestUserMongoDocumentRepository_UpsertDocument(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("first insert", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateSuccessResponse(
+			bson.E{Key: "n", Value: 1},
+			bson.E{Key: "nModified", Value: 0},
+			bson.E{Key: "upserted", Value: bson.A{bson.D{{Key: "index", Value: 0}, {Key: "_id", Value: "u1"}}}},
+		))
+		repo := &UserMongoDocumentRepository{collection: mt.Coll}
+
+		created, err := repo.UpsertDocument(context.Background(), "u1", map[string]interface{}{"username": "alice"})
+		if err != nil {
+			mt.Fatalf("unexpected error: %v", err)
+		}
+		if !created {
+			mt.Fatalf("want created on first insert")
+		}
+	})
+
+	mt.Run("conflict update", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateSuccessResponse(
+			bson.E{Key: "n", Value: 1},
+			bson.E{Key: "nModified", Value: 1},
+		))
+		repo := &UserMongoDocumentRepository{collection: mt.Coll}
+
+		created, err := repo.UpsertDocument(context.Background(), "u1", map[string]interface{}{"username": "alice"})
+		if err != nil {
+			mt.Fatalf("unexpected error: %v", err)
+		}
+		if created {
+			mt.Fatalf("want update on existing document")
+		}
+	})
+}
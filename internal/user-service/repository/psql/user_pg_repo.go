@@ -6,23 +6,27 @@ import (
 	"time"
 
 	"github.com/alfredchaos/demo/internal/user-service/domain"
-	"github.com/google/uuid"
+	"github.com/alfredchaos/demo/internal/user-service/repository"
+	"github.com/alfredchaos/demo/pkg/db"
+	"github.com/alfredchaos/demo/pkg/idgen"
+	"github.com/alfredchaos/demo/pkg/metrics"
+	"github.com/alfredchaos/demo/pkg/pagination"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // UserPgPO 用户持久化对象（PostgreSQL）
 // 负责与PostgreSQL交互的数据结构
+// 表名由 db.NewNamingStrategy 统一生成（默认 users）
 type UserPgPO struct {
 	ID        string    `gorm:"column:id;primaryKey"`
 	Username  string    `gorm:"column:username;uniqueIndex;not null"`
 	Email     string    `gorm:"column:email;not null"`
 	CreatedAt time.Time `gorm:"column:created_at"`
 	UpdatedAt time.Time `gorm:"column:updated_at"`
-}
 
-// TableName 指定表名
-func (UserPgPO) TableName() string {
-	return "users"
+	// Inserted 仅由 Upsert 的 RETURNING 子句填充，标记本次写入是否为新插入
+	Inserted bool `gorm:"column:inserted;->;-:migration"`
 }
 
 // BeforeCreate GORM 钩子：创建前自动设置时间戳
@@ -46,7 +50,7 @@ func (po *UserPgPO) BeforeUpdate(tx *gorm.DB) error {
 // ToDomain 将持久化对象转换为领域对象
 func (po *UserPgPO) ToDomain() *domain.User {
 	return &domain.User{
-		ID:        po.ID,
+		ID:        domain.UserID(po.ID),
 		Username:  po.Username,
 		Email:     po.Email,
 		CreatedAt: po.CreatedAt,
@@ -57,7 +61,7 @@ func (po *UserPgPO) ToDomain() *domain.User {
 // FromDomainUser 从领域对象创建持久化对象
 func FromDomainUser(user *domain.User) *UserPgPO {
 	return &UserPgPO{
-		ID:        user.ID,
+		ID:        string(user.ID),
 		Username:  user.Username,
 		Email:     user.Email,
 		CreatedAt: user.CreatedAt,
@@ -67,19 +71,47 @@ func FromDomainUser(user *domain.User) *UserPgPO {
 
 // userPgRepository PostgreSQL仓库实现
 type UserPgRepository struct {
-	db *gorm.DB
+	db           *gorm.DB
+	counter      *db.RowCounter
+	ids          idgen.Generator
+	queryTimeout time.Duration // 单次调用的默认超时，ctx 中的 reqctx.WithDBTimeout 优先
 }
 
 // NewUserPgRepository 创建PostgreSQL用户仓库
-func NewUserPgRepository(db *gorm.DB) *UserPgRepository {
-	return &UserPgRepository{db: db}
+// cfg 用于行数统计的缓存和估算策略以及默认查询超时，可为 nil
+func NewUserPgRepository(gormDB *gorm.DB, cfg *db.PostgresConfig) *UserPgRepository {
+	return &UserPgRepository{
+		db:           gormDB,
+		counter:      db.NewRowCounter(gormDB, &UserPgPO{}, cfg),
+		ids:          idgen.UUID,
+		queryTimeout: cfg.GetQueryTimeout(),
+	}
+}
+
+// WithIDGenerator 设置 ID 生成器，ID 格式校验随之改变，默认使用 UUID
+func (r *UserPgRepository) WithIDGenerator(gen idgen.Generator) *UserPgRepository {
+	r.ids = gen
+	return r
+}
+
+// WithTx 返回使用事务连接 tx 的仓库副本
+// 行数统计同样在事务连接上执行（见 db.RowCounter.WithTx），写操作仍使原仓库的行数缓存失效
+func (r *UserPgRepository) WithTx(tx *gorm.DB) repository.UserRepository {
+	return &UserPgRepository{
+		db:           tx,
+		counter:      r.counter.WithTx(tx),
+		ids:          r.ids,
+		queryTimeout: r.queryTimeout,
+	}
 }
 
 // Create 创建用户
 func (r *UserPgRepository) Create(ctx context.Context, user *domain.User) error {
-	// 生成UUID作为ID
+	// 未指定ID时生成新ID，指定时校验格式
 	if user.ID == "" {
-		user.ID = uuid.New().String()
+		user.ID = domain.UserID(r.ids.New())
+	} else if err := r.ids.Validate(string(user.ID)); err != nil {
+		return err
 	}
 
 	// 验证用户数据
@@ -87,12 +119,19 @@ func (r *UserPgRepository) Create(ctx context.Context, user *domain.User) error
 		return fmt.Errorf("invalid user data: %w", err)
 	}
 
+	ctx, cancel := db.QueryContext(ctx, r.queryTimeout)
+	defer cancel()
+
+	defer metrics.Timer("user_pg_repo.create", metrics.WithLog(ctx))()
+
 	po := FromDomainUser(user)
 	// GORM 会自动设置 CreatedAt 和 UpdatedAt
 	if err := r.db.WithContext(ctx).Create(po).Error; err != nil {
-		return fmt.Errorf("failed to create user: %w", err)
+		return mapUserError(err, "failed to create user")
 	}
 
+	r.counter.Invalidate()
+
 	// 将 GORM 自动生成的时间戳同步回领域对象
 	user.CreatedAt = po.CreatedAt
 	user.UpdatedAt = po.UpdatedAt
@@ -101,35 +140,152 @@ func (r *UserPgRepository) Create(ctx context.Context, user *domain.User) error
 }
 
 // GetByID 根据ID获取用户
-func (r *UserPgRepository) GetByID(ctx context.Context, id string) (*domain.User, error) {
+func (r *UserPgRepository) GetByID(ctx context.Context, id domain.UserID) (*domain.User, error) {
+	if err := r.ids.Validate(string(id)); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := db.QueryContext(ctx, r.queryTimeout)
+	defer cancel()
+
+	defer metrics.Timer("user_pg_repo.get_by_id", metrics.WithLog(ctx))()
+
 	var po UserPgPO
-	err := r.db.WithContext(ctx).Where("id = ?", id).First(&po).Error
+	err := r.db.WithContext(ctx).Where("id = ?", string(id)).First(&po).Error
 	if err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return nil, domain.ErrUserNotFound
-		}
-		return nil, fmt.Errorf("failed to get user by id: %w", err)
+		return nil, mapUserError(err, "failed to get user by id")
 	}
 	return po.ToDomain(), nil
 }
 
+// GetByIDs 根据ID批量获取用户
+func (r *UserPgRepository) GetByIDs(ctx context.Context, ids []domain.UserID) (map[domain.UserID]*domain.User, error) {
+	users := make(map[domain.UserID]*domain.User, len(ids))
+	if len(ids) == 0 {
+		return users, nil
+	}
+
+	rawIDs := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if err := r.ids.Validate(string(id)); err != nil {
+			return nil, err
+		}
+		rawIDs = append(rawIDs, string(id))
+	}
+
+	ctx, cancel := db.QueryContext(ctx, r.queryTimeout)
+	defer cancel()
+
+	defer metrics.Timer("user_pg_repo.get_by_ids", metrics.WithLog(ctx))()
+
+	var pos []UserPgPO
+	if err := r.db.WithContext(ctx).Where("id IN ?", rawIDs).Find(&pos).Error; err != nil {
+		return nil, mapUserError(err, "failed to get users by ids")
+	}
+
+	for i := range pos {
+		user := pos[i].ToDomain()
+		users[user.ID] = user
+	}
+
+	return users, nil
+}
+
+// Exists 判断用户是否存在
+// 只查询常量列，避免加载整行数据
+func (r *UserPgRepository) Exists(ctx context.Context, id domain.UserID) (bool, error) {
+	if err := r.ids.Validate(string(id)); err != nil {
+		return false, err
+	}
+
+	ctx, cancel := db.QueryContext(ctx, r.queryTimeout)
+	defer cancel()
+
+	defer metrics.Timer("user_pg_repo.exists", metrics.WithLog(ctx))()
+
+	var found int
+	result := r.db.WithContext(ctx).
+		Model(&UserPgPO{}).
+		Select("1").
+		Where("id = ?", string(id)).
+		Limit(1).
+		Scan(&found)
+	if result.Error != nil {
+		return false, mapUserError(result.Error, "failed to check user existence")
+	}
+	return result.RowsAffected > 0, nil
+}
+
 // GetByUsername 根据用户名获取用户
 func (r *UserPgRepository) GetByUsername(ctx context.Context, username string) (*domain.User, error) {
+	ctx, cancel := db.QueryContext(ctx, r.queryTimeout)
+	defer cancel()
+
+	defer metrics.Timer("user_pg_repo.get_by_username", metrics.WithLog(ctx))()
+
 	var po UserPgPO
 	err := r.db.WithContext(ctx).Where("username = ?", username).First(&po).Error
 	if err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return nil, domain.ErrUserNotFound
-		}
-		return nil, fmt.Errorf("failed to get user by username: %w", err)
+		return nil, mapUserError(err, "failed to get user by username")
 	}
 	return po.ToDomain(), nil
 }
 
+// Upsert 创建或更新用户
+// 基于主键冲突实现原子的 insert-or-update，created 表示是否插入了新记录
+// 冲突时只更新 username、email、updated_at，保留原有的 created_at
+func (r *UserPgRepository) Upsert(ctx context.Context, user *domain.User) (bool, error) {
+	// 未指定ID时生成新ID，指定时校验格式
+	if user.ID == "" {
+		user.ID = domain.UserID(r.ids.New())
+	} else if err := r.ids.Validate(string(user.ID)); err != nil {
+		return false, err
+	}
+
+	// 验证用户数据
+	if err := user.Validate(); err != nil {
+		return false, fmt.Errorf("invalid user data: %w", err)
+	}
+
+	po := FromDomainUser(user)
+
+	ctx, cancel := db.QueryContext(ctx, r.queryTimeout)
+	defer cancel()
+
+	defer metrics.Timer("user_pg_repo.upsert", metrics.WithLog(ctx))()
+
+	err := r.db.WithContext(ctx).
+		Clauses(
+			clause.OnConflict{
+				Columns:   []clause.Column{{Name: "id"}},
+				DoUpdates: clause.AssignmentColumns([]string{"username", "email", "updated_at"}),
+			},
+			// xmax 为 0 说明该行由本次 INSERT 产生，而不是冲突后的 UPDATE
+			clause.Returning{Columns: []clause.Column{
+				{Name: "created_at"},
+				{Name: "(xmax = 0) AS inserted", Raw: true},
+			}},
+		).
+		Create(po).Error
+	if err != nil {
+		return false, mapUserError(err, "failed to upsert user")
+	}
+
+	if po.Inserted {
+		r.counter.Invalidate()
+	}
+
+	// 同步数据库中的时间戳到领域对象
+	user.CreatedAt = po.CreatedAt
+	user.UpdatedAt = po.UpdatedAt
+
+	return po.Inserted, nil
+}
+
 // Update 更新用户
 func (r *UserPgRepository) Update(ctx context.Context, user *domain.User) error {
-	if user.ID == "" {
-		return fmt.Errorf("user id is required for update")
+	if err := r.ids.Validate(string(user.ID)); err != nil {
+		return err
 	}
 
 	// 验证用户数据
@@ -138,14 +294,20 @@ func (r *UserPgRepository) Update(ctx context.Context, user *domain.User) error
 	}
 
 	po := FromDomainUser(user)
+
+	ctx, cancel := db.QueryContext(ctx, r.queryTimeout)
+	defer cancel()
+
+	defer metrics.Timer("user_pg_repo.update", metrics.WithLog(ctx))()
+
 	result := r.db.WithContext(ctx).
 		Model(&UserPgPO{}).
-		Where("id = ?", user.ID).
+		Where("id = ?", string(user.ID)).
 		Select("username", "email", "updated_at").
 		Updates(po)
 
 	if result.Error != nil {
-		return fmt.Errorf("failed to update user: %w", result.Error)
+		return mapUserError(result.Error, "failed to update user")
 	}
 
 	if result.RowsAffected == 0 {
@@ -159,27 +321,54 @@ func (r *UserPgRepository) Update(ctx context.Context, user *domain.User) error
 }
 
 // Delete 删除用户
-func (r *UserPgRepository) Delete(ctx context.Context, id string) error {
-	if id == "" {
-		return fmt.Errorf("user id is required for delete")
+func (r *UserPgRepository) Delete(ctx context.Context, id domain.UserID) error {
+	if err := r.ids.Validate(string(id)); err != nil {
+		return err
 	}
 
-	result := r.db.WithContext(ctx).Where("id = ?", id).Delete(&UserPgPO{})
+	ctx, cancel := db.QueryContext(ctx, r.queryTimeout)
+	defer cancel()
+
+	defer metrics.Timer("user_pg_repo.delete", metrics.WithLog(ctx))()
+
+	result := r.db.WithContext(ctx).Where("id = ?", string(id)).Delete(&UserPgPO{})
 	if result.Error != nil {
-		return fmt.Errorf("failed to delete user: %w", result.Error)
+		return mapUserError(result.Error, "failed to delete user")
 	}
 
 	if result.RowsAffected == 0 {
 		return domain.ErrUserNotFound
 	}
 
+	r.counter.Invalidate()
+
 	return nil
 }
 
+// Count 统计用户数量
+// 结果会被短暂缓存，大表可配置为返回估算值
+func (r *UserPgRepository) Count(ctx context.Context) (int64, error) {
+	ctx, cancel := db.QueryContext(ctx, r.queryTimeout)
+	defer cancel()
+
+	defer metrics.Timer("user_pg_repo.count", metrics.WithLog(ctx))()
+
+	count, err := r.counter.Count(ctx)
+	if err != nil {
+		return 0, mapUserError(err, "failed to count users")
+	}
+	return count, nil
+}
+
 // List 列出用户
 func (r *UserPgRepository) List(ctx context.Context, offset, limit int) ([]*domain.User, error) {
 	var pos []UserPgPO
 
+	ctx, cancel := db.QueryContext(ctx, r.queryTimeout)
+	defer cancel()
+
+	defer metrics.Timer("user_pg_repo.list", metrics.WithLog(ctx))()
+
 	query := r.db.WithContext(ctx)
 
 	// 设置分页参数
@@ -192,7 +381,7 @@ func (r *UserPgRepository) List(ctx context.Context, offset, limit int) ([]*doma
 
 	// 按创建时间倒序排列
 	if err := query.Order("created_at DESC").Find(&pos).Error; err != nil {
-		return nil, fmt.Errorf("failed to list users: %w", err)
+		return nil, mapUserError(err, "failed to list users")
 	}
 
 	// 转换为领域对象
@@ -203,3 +392,62 @@ func (r *UserPgRepository) List(ctx context.Context, offset, limit int) ([]*doma
 
 	return users, nil
 }
+
+// ListAfter 基于游标的键集分页，按创建时间倒序列出用户
+// cursor 为上一页返回的游标，空字符串表示第一页；返回的 next 为空表示没有更多数据
+func (r *UserPgRepository) ListAfter(ctx context.Context, cursor string, limit int) ([]*domain.User, string, error) {
+	if limit <= 0 {
+		return nil, "", fmt.Errorf("limit must be positive")
+	}
+
+	ctx, cancel := db.QueryContext(ctx, r.queryTimeout)
+	defer cancel()
+
+	defer metrics.Timer("user_pg_repo.list_after", metrics.WithLog(ctx))()
+
+	query := r.db.WithContext(ctx)
+	if cursor != "" {
+		createdAt, id, err := pagination.DecodeCreatedAtCursor(cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		query = query.Where("(created_at, id) < (?, ?)", createdAt, id)
+	}
+
+	// 多取一条用于判断是否还有下一页
+	var pos []UserPgPO
+	if err := query.Order("created_at DESC, id DESC").Limit(limit + 1).Find(&pos).Error; err != nil {
+		return nil, "", mapUserError(err, "failed to list users after cursor")
+	}
+
+	var next string
+	if len(pos) > limit {
+		pos = pos[:limit]
+		last := pos[limit-1]
+		token, err := pagination.EncodeCreatedAtCursor(last.CreatedAt, last.ID)
+		if err != nil {
+			return nil, "", err
+		}
+		next = token
+	}
+
+	users := make([]*domain.User, 0, len(pos))
+	for _, po := range pos {
+		users = append(users, po.ToDomain())
+	}
+
+	return users, next, nil
+}
+
+// mapUserError 将数据库错误映射为领域错误
+// 记录不存在、唯一约束冲突映射为领域哨兵错误，其他错误按 db.ClassifyError 的分类携带错误码
+func mapUserError(err error, message string) error {
+	switch db.ClassifyError(err) {
+	case db.ErrorClassNotFound:
+		return domain.ErrUserNotFound
+	case db.ErrorClassUniqueViolation:
+		return domain.ErrUserAlreadyExists
+	default:
+		return db.WrapError(err, message)
+	}
+}
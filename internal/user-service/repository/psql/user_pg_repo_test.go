@@ -0,0 +1,351 @@
+package psql
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/alfredchaos/demo/internal/user-service/domain"
+	"github.com/alfredchaos/demo/pkg/db"
+	apperrors "github.com/alfredchaos/demo/pkg/errors"
+	"github.com/alfredchaos/demo/pkg/idgen"
+	"github.com/alfredchaos/demo/pkg/reqctx"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// testUserID 测试用的合法用户 ID
+const testUserID = "6ba7b810-9dad-41d1-80b4-00c04fd430c8"
+
+// newMockDB 基于 sqlmock 创建 GORM 连接
+func newMockDB(t *testing.T) (*gorm.DB, sqlmock.Sqlmock) {
+	t.Helper()
+
+	sqlDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+
+	gdb, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{
+		NamingStrategy: db.NewNamingStrategy(&db.PostgresConfig{}),
+		Logger:         logger.Discard,
+	})
+	if err != nil {
+		t.Fatalf("failed to open gorm: %v", err)
+	}
+	return gdb, mock
+}
+
+func TestUserPgRepository_Exists(t *testing.T) {
+	tests := []struct {
+		name string
+		rows *sqlmock.Rows
+		want bool
+	}{
+		{name: "existing", rows: sqlmock.NewRows([]string{"?column?"}).AddRow(1), want: true},
+		{name: "missing", rows: sqlmock.NewRows([]string{"?column?"}), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gdb, mock := newMockDB(t)
+			mock.ExpectQuery(`SELECT 1 FROM "users" WHERE id = \$1 LIMIT 1`).
+				WithArgs(testUserID).
+				WillReturnRows(tt.rows)
+
+			got, err := NewUserPgRepository(gdb, nil).Exists(context.Background(), testUserID)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("want %v, got %v", tt.want, got)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestUserPgRepository_Upsert(t *testing.T) {
+	createdAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		inserted    bool
+		wantCreated bool
+	}{
+		{name: "first insert", inserted: true, wantCreated: true},
+		{name: "conflict update", inserted: false, wantCreated: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gdb, mock := newMockDB(t)
+			mock.ExpectBegin()
+			mock.ExpectQuery(`INSERT INTO "users" .* ON CONFLICT \("id"\) DO UPDATE SET "username"="excluded"."username","email"="excluded"."email","updated_at"="excluded"."updated_at" RETURNING "created_at",\(xmax = 0\) AS inserted`).
+				WillReturnRows(sqlmock.NewRows([]string{"created_at", "inserted"}).AddRow(createdAt, tt.inserted))
+			mock.ExpectCommit()
+
+			user := &domain.User{ID: testUserID, Username: "alice", Email: "alice@example.com"}
+			created, err := NewUserPgRepository(gdb, nil).Upsert(context.Background(), user)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if created != tt.wantCreated {
+				t.Fatalf("want created=%v, got %v", tt.wantCreated, created)
+			}
+			if !user.CreatedAt.Equal(createdAt) {
+				t.Fatalf("want created_at synced from db, got %v", user.CreatedAt)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestUserPgRepository_CountReflectsInsertsAndDeletes(t *testing.T) {
+	gdb, mock := newMockDB(t)
+	repo := NewUserPgRepository(gdb, &db.PostgresConfig{CountCacheTTL: 60})
+	ctx := context.Background()
+
+	expectCount := func(n int) {
+		mock.ExpectQuery(`SELECT count\(\*\) FROM "users"`).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(n))
+	}
+	assertCount := func(want int64) {
+		t.Helper()
+		got, err := repo.Count(ctx)
+		if err != nil {
+			t.Fatalf("count: %v", err)
+		}
+		if got != want {
+			t.Fatalf("want count %d, got %d", want, got)
+		}
+	}
+
+	expectCount(2)
+	assertCount(2)
+	// 缓存有效期内不会再次查询数据库
+	assertCount(2)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`INSERT INTO "users"`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	if err := repo.Create(ctx, &domain.User{Username: "alice", Email: "alice@example.com"}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	expectCount(3)
+	assertCount(3)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`DELETE FROM "users"`).WithArgs(testUserID).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	if err := repo.Delete(ctx, testUserID); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	expectCount(2)
+	assertCount(2)
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestUserPgRepository_CountUsesEstimateForLargeTables(t *testing.T) {
+	gdb, mock := newMockDB(t)
+	repo := NewUserPgRepository(gdb, &db.PostgresConfig{ApproximateCountThreshold: 1000000})
+
+	mock.ExpectQuery(`SELECT COALESCE\(MAX\(reltuples\), 0\)::bigint FROM pg_class WHERE oid = to_regclass\(\$1\)`).
+		WithArgs("users").
+		WillReturnRows(sqlmock.NewRows([]string{"coalesce"}).AddRow(5000000))
+
+	got, err := repo.Count(context.Background())
+	if err != nil {
+		t.Fatalf("count: %v", err)
+	}
+	if got != 5000000 {
+		t.Fatalf("want estimated count, got %d", got)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestUserPgRepository_ListAfter(t *testing.T) {
+	gdb, mock := newMockDB(t)
+	repo := NewUserPgRepository(gdb, nil)
+	ctx := context.Background()
+
+	t1 := time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC)
+	t2 := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	t3 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	columns := []string{"id", "username", "email", "created_at", "updated_at"}
+
+	// 第一页：多取一条判断是否还有下一页
+	mock.ExpectQuery(`SELECT \* FROM "users" ORDER BY created_at DESC, id DESC LIMIT 3`).
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow(testUserID, "a", "a@example.com", t1, t1).
+			AddRow("u2", "b", "b@example.com", t2, t2).
+			AddRow("u3", "c", "c@example.com", t3, t3))
+
+	users, next, err := repo.ListAfter(ctx, "", 2)
+	if err != nil {
+		t.Fatalf("first page: %v", err)
+	}
+	if len(users) != 2 || users[1].ID != "u2" || next == "" {
+		t.Fatalf("unexpected first page: %v, next=%q", users, next)
+	}
+
+	// 第二页：游标携带上一页最后一条的排序键
+	mock.ExpectQuery(`SELECT \* FROM "users" WHERE \(created_at, id\) < \(\$1, \$2\) ORDER BY created_at DESC, id DESC LIMIT 3`).
+		WithArgs(t2, "u2").
+		WillReturnRows(sqlmock.NewRows(columns).AddRow("u3", "c", "c@example.com", t3, t3))
+
+	users, next, err = repo.ListAfter(ctx, next, 2)
+	if err != nil {
+		t.Fatalf("second page: %v", err)
+	}
+	if len(users) != 1 || users[0].ID != "u3" || next != "" {
+		t.Fatalf("unexpected second page: %v, next=%q", users, next)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestUserPgRepository_MapsDatabaseErrors(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("unique violation", func(t *testing.T) {
+		gdb, mock := newMockDB(t)
+		mock.ExpectBegin()
+		mock.ExpectExec(`INSERT INTO "users"`).WillReturnError(&pgconn.PgError{Code: "23505", ConstraintName: "idx_users_username"})
+		mock.ExpectRollback()
+
+		err := NewUserPgRepository(gdb, nil).Create(ctx, &domain.User{Username: "alice", Email: "alice@example.com"})
+		if !errors.Is(err, domain.ErrUserAlreadyExists) {
+			t.Fatalf("want ErrUserAlreadyExists, got %v", err)
+		}
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		gdb, mock := newMockDB(t)
+		mock.ExpectQuery(`SELECT \* FROM "users" WHERE id = \$1`).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+		if _, err := NewUserPgRepository(gdb, nil).GetByID(ctx, testUserID); !errors.Is(err, domain.ErrUserNotFound) {
+			t.Fatalf("want ErrUserNotFound, got %v", err)
+		}
+	})
+
+	t.Run("connection error", func(t *testing.T) {
+		gdb, mock := newMockDB(t)
+		mock.ExpectQuery(`SELECT \* FROM "users" WHERE id = \$1`).WillReturnError(&pgconn.PgError{Code: "08006"})
+
+		_, err := NewUserPgRepository(gdb, nil).GetByID(ctx, testUserID)
+		if apperrors.CodeOf(err) != apperrors.ErrServiceUnavailable {
+			t.Fatalf("want service unavailable code, got %v", err)
+		}
+	})
+}
+
+func TestUserPgRepository_RejectsMalformedIDBeforeQuery(t *testing.T) {
+	gdb, mock := newMockDB(t)
+	repo := NewUserPgRepository(gdb, nil)
+	ctx := context.Background()
+
+	// 未设置任何期望，访问数据库会导致 sqlmock 报错
+	checks := map[string]error{}
+	_, checks["GetByID"] = repo.GetByID(ctx, "not-a-uuid")
+	_, checks["GetByIDs"] = repo.GetByIDs(ctx, []domain.UserID{testUserID, "not-a-uuid"})
+	_, checks["Exists"] = repo.Exists(ctx, "not-a-uuid")
+	checks["Update"] = repo.Update(ctx, &domain.User{ID: "not-a-uuid", Username: "alice", Email: "alice@example.com"})
+	checks["Delete"] = repo.Delete(ctx, "not-a-uuid")
+	checks["Create"] = repo.Create(ctx, &domain.User{ID: "not-a-uuid", Username: "alice", Email: "alice@example.com"})
+
+	for op, err := range checks {
+		if !errors.Is(err, idgen.ErrInvalidID) || apperrors.CodeOf(err) != apperrors.ErrInvalidParams {
+			t.Errorf("%s: want ErrInvalidID with ErrInvalidParams code, got %v", op, err)
+		}
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unexpected database access: %v", err)
+	}
+}
+
+// sequentialIDs 按序生成数字 ID 的生成器，只接受纯数字 ID
+type sequentialIDs struct{ next int }
+
+func (g *sequentialIDs) New() string {
+	g.next++
+	return strconv.Itoa(g.next)
+}
+
+func (g *sequentialIDs) Validate(id string) error {
+	if _, err := strconv.Atoi(id); err != nil {
+		return idgen.ErrInvalidID
+	}
+	return nil
+}
+
+func TestUserPgRepository_UsesConfiguredIDGenerator(t *testing.T) {
+	gdb, mock := newMockDB(t)
+	repo := NewUserPgRepository(gdb, nil).WithIDGenerator(&sequentialIDs{})
+
+	mock.ExpectQuery(`SELECT \* FROM "users" WHERE id = \$1`).WithArgs("42").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "username", "email"}).AddRow("42", "alice", "alice@example.com"))
+	if _, err := repo.GetByID(context.Background(), "42"); err != nil {
+		t.Fatalf("want numeric id accepted, got %v", err)
+	}
+	if _, err := repo.GetByID(context.Background(), testUserID); !errors.Is(err, idgen.ErrInvalidID) {
+		t.Fatalf("want uuid rejected by numeric generator, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestUserPgRepository_AppliesPerCallQueryTimeout(t *testing.T) {
+	gdb, mock := newMockDB(t)
+	repo := NewUserPgRepository(gdb, &db.PostgresConfig{QueryTimeout: 5000})
+
+	// 默认超时 5 秒足够等待慢查询，ctx 中的 20ms 超时应覆盖默认值并取消查询
+	mock.ExpectQuery(`SELECT \* FROM "users"`).
+		WillDelayFor(time.Second).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	ctx := reqctx.WithDBTimeout(context.Background(), 20*time.Millisecond)
+	start := time.Now()
+	if _, err := repo.List(ctx, 0, 10); err == nil {
+		t.Fatal("want slow query canceled by per-call timeout")
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Fatalf("want query canceled after about 20ms, took %v", elapsed)
+	}
+}
+
+func TestUserPgRepository_AppliesDefaultQueryTimeout(t *testing.T) {
+	gdb, mock := newMockDB(t)
+	repo := NewUserPgRepository(gdb, &db.PostgresConfig{QueryTimeout: 20})
+
+	mock.ExpectQuery(`SELECT \* FROM "users"`).
+		WillDelayFor(time.Second).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	start := time.Now()
+	if _, err := repo.List(context.Background(), 0, 10); err == nil {
+		t.Fatal("want slow query canceled by default timeout")
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Fatalf("want query canceled after about 20ms, took %v", elapsed)
+	}
+}
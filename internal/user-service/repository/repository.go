@@ -5,6 +5,8 @@ import (
 	"fmt"
 
 	"github.com/alfredchaos/demo/pkg/db"
+	apperrors "github.com/alfredchaos/demo/pkg/errors"
+	"gorm.io/gorm"
 )
 
 // Data 数据访问层容器
@@ -31,23 +33,35 @@ func NewData(
 	}
 }
 
+// txBoundUserRepository 可绑定到事务连接的User仓库
+type txBoundUserRepository interface {
+	WithTx(tx *gorm.DB) UserRepository
+}
+
+// WithTx 返回绑定到事务连接 tx 的数据访问层副本
+// 副本中的 PostgreSQL 仓库通过 tx 执行操作，与 tx 一起提交或回滚；
+// MongoDB 仓库不参与该事务，仍使用原连接
+func (d *Data) WithTx(tx *gorm.DB) *Data {
+	txData := *d
+	if repo, ok := d.UserRepo.(txBoundUserRepository); ok {
+		txData.UserRepo = repo.WithTx(tx)
+	}
+	return &txData
+}
+
 // Close 关闭所有数据连接
 func (d *Data) Close(ctx context.Context) error {
-	var errs []error
+	var errs apperrors.MultiError
 
 	// 关闭 MongoDB
-	if err := d.closeMongo(ctx); err != nil {
-		errs = append(errs, err)
-	}
+	errs.Append(d.closeMongo(ctx))
 
 	// 关闭 PostgreSQL
-	if err := d.closePostgres(); err != nil {
-		errs = append(errs, err)
-	}
+	errs.Append(d.closePostgres())
 
 	// 汇总错误
-	if len(errs) > 0 {
-		return fmt.Errorf("failed to close data layer: %v", errs)
+	if err := errs.ErrorOrNil(); err != nil {
+		return fmt.Errorf("failed to close data layer: %w", err)
 	}
 
 	return nil
@@ -0,0 +1,65 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/alfredchaos/demo/internal/user-service/domain"
+	"github.com/alfredchaos/demo/internal/user-service/repository"
+	"github.com/alfredchaos/demo/internal/user-service/repository/psql"
+	"github.com/alfredchaos/demo/pkg/db"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestData_WithTxRollsBackTogether(t *testing.T) {
+	sqlDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	defer sqlDB.Close()
+
+	gdb, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{
+		NamingStrategy: db.NewNamingStrategy(&db.PostgresConfig{}),
+		Logger:         logger.Discard,
+	})
+	if err != nil {
+		t.Fatalf("failed to open gorm: %v", err)
+	}
+
+	data := repository.NewData(nil, nil, psql.NewUserPgRepository(gdb, nil), nil)
+
+	// 两次写入在同一个事务中执行，第二次失败后整个事务回滚，不会提交
+	mock.ExpectBegin()
+	mock.ExpectExec(`INSERT INTO "users"`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO "users"`).WillReturnError(&pgconn.PgError{Code: "23505"})
+	mock.ExpectRollback()
+
+	ctx := context.Background()
+	err = gdb.Transaction(func(tx *gorm.DB) error {
+		txData := data.WithTx(tx)
+		if txData.UserRepo == data.UserRepo {
+			t.Fatal("want tx-bound repository, got the original one")
+		}
+		if err := txData.UserRepo.Create(ctx, &domain.User{Username: "alice", Email: "alice@example.com"}); err != nil {
+			return err
+		}
+		return txData.UserRepo.Create(ctx, &domain.User{Username: "alice", Email: "alice@example.org"})
+	})
+	if err != domain.ErrUserAlreadyExists {
+		t.Fatalf("want ErrUserAlreadyExists from second insert, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestData_WithTxKeepsNonTransactionalRepositories(t *testing.T) {
+	data := repository.NewData(nil, nil, nil, nil)
+	if txData := data.WithTx(&gorm.DB{}); txData == data || txData.UserRepo != nil {
+		t.Fatalf("want a copy with repositories unchanged, got %+v", txData)
+	}
+}
@@ -8,21 +8,37 @@ import (
 
 type UserRepository interface {
 	Create(ctx context.Context, user *domain.User) error
-	GetByID(ctx context.Context, id string) (*domain.User, error)
+	GetByID(ctx context.Context, id domain.UserID) (*domain.User, error)
+	// Exists 判断用户是否存在，不加载完整记录
+	Exists(ctx context.Context, id domain.UserID) (bool, error)
+	// GetByIDs 批量获取用户，返回以 ID 为键的映射，不存在的 ID 不会出现在结果中
+	GetByIDs(ctx context.Context, ids []domain.UserID) (map[domain.UserID]*domain.User, error)
 	GetByUsername(ctx context.Context, username string) (*domain.User, error)
+	// Upsert 按主键原子地创建或更新用户，created 表示是否插入了新记录
+	Upsert(ctx context.Context, user *domain.User) (created bool, err error)
 	Update(ctx context.Context, user *domain.User) error
-	Delete(ctx context.Context, id string) error
+	Delete(ctx context.Context, id domain.UserID) error
 	List(ctx context.Context, offset, limit int) ([]*domain.User, error)
+	// ListAfter 基于游标的键集分页，next 为空表示没有更多数据
+	ListAfter(ctx context.Context, cursor string, limit int) (users []*domain.User, next string, err error)
+	// Count 统计数量，结果可能被短暂缓存或为估算值
+	Count(ctx context.Context) (int64, error)
 }
 
 type UserDocumentRepository interface {
-	SaveDocument(ctx context.Context, userID string, document map[string]interface{}) error
-	GetDocument(ctx context.Context, userID string) (map[string]interface{}, error)
-	DeleteDocument(ctx context.Context, userID string) error
+	SaveDocument(ctx context.Context, userID domain.UserID, document map[string]interface{}) error
+	// UpsertDocument 创建或更新用户文档，created 表示是否插入了新文档
+	UpsertDocument(ctx context.Context, userID domain.UserID, document map[string]interface{}) (created bool, err error)
+	GetDocument(ctx context.Context, userID domain.UserID) (map[string]interface{}, error)
+	// Exists 判断用户文档是否存在，不加载完整文档
+	Exists(ctx context.Context, userID domain.UserID) (bool, error)
+	// GetDocumentsByIDs 批量获取用户文档，返回以 ID 为键的映射，不存在的 ID 不会出现在结果中
+	GetDocumentsByIDs(ctx context.Context, userIDs []domain.UserID) (map[domain.UserID]map[string]interface{}, error)
+	DeleteDocument(ctx context.Context, userID domain.UserID) error
 
 	// filter: MongoDB 查询条件，例如 bson.M{"username": "alice"}
 	FindDocuments(ctx context.Context, filter map[string]interface{}, skip, limit int64) ([]map[string]interface{}, error)
 
 	// fields: 要更新的字段，例如 map[string]interface{}{"email": "new@example.com"}
-	UpdateDocumentFields(ctx context.Context, userID string, fields map[string]interface{}) error
+	UpdateDocumentFields(ctx context.Context, userID domain.UserID, fields map[string]interface{}) error
 }
@@ -5,7 +5,9 @@ import (
 
 	userv1 "github.com/alfredchaos/demo/api/user/v1"
 	"github.com/alfredchaos/demo/internal/user-service/biz"
+	"github.com/alfredchaos/demo/pkg/errors"
 	"github.com/alfredchaos/demo/pkg/log"
+	"github.com/alfredchaos/demo/pkg/pagination"
 	"go.uber.org/zap"
 )
 
@@ -23,14 +25,15 @@ func NewUserService(useCase *biz.UserUseCase) *UserService {
 }
 
 // SayHello 实现UserService.SayHello方法
+// name 已由校验拦截器（HelloRequest.Validate）检查
 func (s *UserService) SayHello(ctx context.Context, req *userv1.HelloRequest) (*userv1.HelloResponse, error) {
-	log.WithContext(ctx).Info("received SayHello request")
+	log.WithContext(ctx).Info("received SayHello request", zap.String("name", req.GetName()))
 
 	// 调用业务逻辑层
-	message, err := s.useCase.SayHello(ctx, "")
+	message, err := s.useCase.SayHello(ctx, req.GetName())
 	if err != nil {
 		log.WithContext(ctx).Error("failed to say hello", zap.Error(err))
-		return nil, err
+		return nil, errors.ToGRPCError(err)
 	}
 
 	log.WithContext(ctx).Info("SayHello completed", zap.String("message", message))
@@ -40,3 +43,43 @@ func (s *UserService) SayHello(ctx context.Context, req *userv1.HelloRequest) (*
 		Message: message,
 	}, nil
 }
+
+// CountUsers 实现UserService.CountUsers方法
+func (s *UserService) CountUsers(ctx context.Context, req *userv1.CountUsersRequest) (*userv1.CountUsersResponse, error) {
+	count, err := s.useCase.CountUsers(ctx)
+	if err != nil {
+		log.WithContext(ctx).Error("failed to count users", zap.Error(err))
+		return nil, errors.ToGRPCError(err)
+	}
+
+	return &userv1.CountUsersResponse{
+		Count: count,
+	}, nil
+}
+
+// ListUsers 实现UserService.ListUsers方法
+func (s *UserService) ListUsers(ctx context.Context, req *userv1.ListUsersRequest) (*userv1.ListUsersResponse, error) {
+	limit, cursor, err := pagination.NormalizePageRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	users, next, err := s.useCase.ListUsers(ctx, cursor, limit)
+	if err != nil {
+		log.WithContext(ctx).Error("failed to list users", zap.Error(err))
+		return nil, errors.ToGRPCError(err)
+	}
+
+	resp := &userv1.ListUsersResponse{
+		Users:         make([]*userv1.UserInfo, 0, len(users)),
+		NextPageToken: next,
+	}
+	for _, user := range users {
+		resp.Users = append(resp.Users, &userv1.UserInfo{
+			Id:       user.ID.String(),
+			Username: user.Username,
+			Email:    user.Email,
+		})
+	}
+	return resp, nil
+}
@@ -1,118 +1,214 @@
 package migrations
 
 import (
+	"context"
 	"database/sql"
 	"embed"
+	"errors"
 	"fmt"
+	"io/fs"
 
 	"github.com/pressly/goose/v3"
 )
 
+// SharedDB 共享数据库迁移在注册表中的名称
+const SharedDB = "shared-db"
+
 // SharedDBMigrations 共享数据库的迁移文件
 // 所有使用共享数据库的微服务的迁移文件都应放在这里
 //
 //go:embed shared-db/*.sql
 var SharedDBMigrations embed.FS
 
-// MigrateUp 执行数据库迁移（升级到最新版本）
-func MigrateUp(db *sql.DB) error {
-	if err := setupGoose(); err != nil {
-		return err
+func init() {
+	sharedFS, err := fs.Sub(SharedDBMigrations, "shared-db")
+	if err != nil {
+		panic(err)
 	}
+	// 沿用 goose 默认的版本记录表，兼容已执行过迁移的数据库
+	defaultRegistry.MustRegister(SharedDB, Source{FS: sharedFS})
+}
 
-	if err := goose.Up(db, "shared-db"); err != nil {
-		return fmt.Errorf("failed to run migrations: %w", err)
-	}
+// 迁移命令
+const (
+	ActionUp      = "up"      // 升级到最新版本
+	ActionUpTo    = "up-to"   // 升级到指定版本
+	ActionDown    = "down"    // 回滚最后一次迁移
+	ActionDownTo  = "down-to" // 回滚到指定版本
+	ActionStatus  = "status"  // 查看迁移状态
+	ActionVersion = "version" // 查看当前版本
+	ActionReset   = "reset"   // 回滚所有迁移
+)
 
-	return nil
+// Command 迁移命令
+type Command struct {
+	Action  string // 命令，见 Action* 常量
+	Version int64  // 目标版本，仅 up-to / down-to 使用
 }
 
-// MigrateDown 回滚最后一次迁移
-func MigrateDown(db *sql.DB) error {
-	if err := setupGoose(); err != nil {
-		return err
-	}
+// Result 迁移执行结果
+type Result struct {
+	FromVersion int64                    // 执行前的数据库版本
+	Version     int64                    // 执行后的数据库版本
+	Applied     []*goose.MigrationResult // 本次执行（升级或回滚）的迁移，status / version 命令为空；部分失败时最后一项为失败的迁移
+	Status      []*goose.MigrationStatus // 各迁移的状态，仅 status 命令返回
+}
 
-	if err := goose.Down(db, "shared-db"); err != nil {
-		return fmt.Errorf("failed to rollback migration: %w", err)
-	}
+// 迁移执行结果分类，见 Outcome
+const (
+	OutcomeApplied  = "applied"   // 执行（升级或回滚）了迁移
+	OutcomeNoChange = "no_change" // 没有需要执行的迁移，数据库未变更；status 未发现待执行迁移时同样为此
+	OutcomePending  = "pending"   // status 发现待执行的迁移
+	OutcomeFailed   = "failed"    // 执行失败
+)
 
-	return nil
+// Pending 返回待执行的迁移数，仅 status 命令的结果有意义
+func (r *Result) Pending() int {
+	pending := 0
+	for _, status := range r.Status {
+		if status.State == goose.StatePending {
+			pending++
+		}
+	}
+	return pending
 }
 
-// MigrateStatus 查看迁移状态
-func MigrateStatus(db *sql.DB) error {
-	if err := setupGoose(); err != nil {
-		return err
+// Outcome 根据 Migrate 的返回值判断执行结果分类，供 CI 区分是否变更了数据库
+func Outcome(result *Result, err error) string {
+	switch {
+	case err != nil || result == nil:
+		return OutcomeFailed
+	case len(result.Applied) > 0:
+		return OutcomeApplied
+	case result.Pending() > 0:
+		return OutcomePending
+	default:
+		return OutcomeNoChange
 	}
+}
 
-	if err := goose.Status(db, "shared-db"); err != nil {
-		return fmt.Errorf("failed to get migration status: %w", err)
-	}
+// Migrate 使用默认注册表对名为 name 的迁移来源执行命令
+func Migrate(ctx context.Context, db *sql.DB, name string, cmd Command) (*Result, error) {
+	return defaultRegistry.Migrate(ctx, db, name, cmd)
+}
 
-	return nil
+// MigrateUp 执行共享数据库迁移（升级到最新版本）
+func MigrateUp(db *sql.DB) error {
+	_, err := Migrate(context.Background(), db, SharedDB, Command{Action: ActionUp})
+	return err
 }
 
-// MigrateUpTo 迁移到指定版本（up-to）
-func MigrateUpTo(db *sql.DB, version int64) error {
-	if err := setupGoose(); err != nil {
-		return err
-	}
+// MigrateDown 回滚共享数据库最后一次迁移
+func MigrateDown(db *sql.DB) error {
+	_, err := Migrate(context.Background(), db, SharedDB, Command{Action: ActionDown})
+	return err
+}
 
-	if err := goose.UpTo(db, "shared-db", version); err != nil {
-		return fmt.Errorf("failed to migrate up to version %d: %w", version, err)
-	}
+// MigrateStatus 查看共享数据库迁移状态，只返回是否成功；需要各迁移的状态时使用 Migrate
+func MigrateStatus(db *sql.DB) error {
+	_, err := Migrate(context.Background(), db, SharedDB, Command{Action: ActionStatus})
+	return err
+}
 
-	return nil
+// MigrateUpTo 共享数据库迁移到指定版本（up-to）
+func MigrateUpTo(db *sql.DB, version int64) error {
+	_, err := Migrate(context.Background(), db, SharedDB, Command{Action: ActionUpTo, Version: version})
+	return err
 }
 
-// MigrateDownTo 回滚到指定版本（down-to）
+// MigrateDownTo 共享数据库回滚到指定版本（down-to）
 func MigrateDownTo(db *sql.DB, version int64) error {
-	if err := setupGoose(); err != nil {
-		return err
-	}
-
-	if err := goose.DownTo(db, "shared-db", version); err != nil {
-		return fmt.Errorf("failed to migrate down to version %d: %w", version, err)
-	}
-
-	return nil
+	_, err := Migrate(context.Background(), db, SharedDB, Command{Action: ActionDownTo, Version: version})
+	return err
 }
 
-// MigrateReset 重置数据库（回滚所有迁移）
+// MigrateReset 重置共享数据库（回滚所有迁移）
 func MigrateReset(db *sql.DB) error {
-	if err := setupGoose(); err != nil {
-		return err
+	_, err := Migrate(context.Background(), db, SharedDB, Command{Action: ActionReset})
+	return err
+}
+
+// GetCurrentVersion 获取共享数据库当前版本
+func GetCurrentVersion(db *sql.DB) (int64, error) {
+	result, err := Migrate(context.Background(), db, SharedDB, Command{Action: ActionVersion})
+	if err != nil {
+		return 0, err
 	}
+	return result.Version, nil
+}
 
-	if err := goose.Reset(db, "shared-db"); err != nil {
-		return fmt.Errorf("failed to reset migrations: %w", err)
+// Migrate 对名为 name 的迁移来源执行命令
+func (r *Registry) Migrate(ctx context.Context, db *sql.DB, name string, cmd Command) (*Result, error) {
+	switch cmd.Action {
+	case ActionUp, ActionUpTo, ActionDown, ActionDownTo, ActionReset, ActionStatus, ActionVersion:
+	default:
+		return nil, fmt.Errorf("unknown migration command: %s", cmd.Action)
 	}
 
-	return nil
-}
+	provider, err := r.newProvider(db, name)
+	if err != nil {
+		return nil, err
+	}
 
-// GetCurrentVersion 获取当前数据库版本
-func GetCurrentVersion(db *sql.DB) (int64, error) {
-	if err := setupGoose(); err != nil {
-		return 0, err
+	result := &Result{}
+	if result.FromVersion, err = provider.GetDBVersion(ctx); err != nil {
+		return nil, fmt.Errorf("failed to get db version for %s: %w", name, err)
 	}
 
-	version, err := goose.GetDBVersion(db)
+	switch cmd.Action {
+	case ActionUp:
+		result.Applied, err = provider.Up(ctx)
+	case ActionUpTo:
+		result.Applied, err = provider.UpTo(ctx, cmd.Version)
+	case ActionDown:
+		var applied *goose.MigrationResult
+		if applied, err = provider.Down(ctx); applied != nil {
+			result.Applied = []*goose.MigrationResult{applied}
+		}
+	case ActionDownTo:
+		result.Applied, err = provider.DownTo(ctx, cmd.Version)
+	case ActionReset:
+		result.Applied, err = provider.DownTo(ctx, 0)
+	case ActionStatus:
+		result.Status, err = provider.Status(ctx)
+	}
 	if err != nil {
-		return 0, fmt.Errorf("failed to get db version: %w", err)
+		// 部分迁移已执行后失败时，记录已执行的迁移及失败的迁移
+		var partial *goose.PartialError
+		if errors.As(err, &partial) {
+			result.Applied = append(partial.Applied, partial.Failed)
+		}
+		result.Version = result.FromVersion
+		if version, verr := provider.GetDBVersion(ctx); verr == nil {
+			result.Version = version
+		}
+		return result, fmt.Errorf("failed to run %s migrations for %s: %w", cmd.Action, name, err)
 	}
 
-	return version, nil
+	if result.Version, err = provider.GetDBVersion(ctx); err != nil {
+		return result, fmt.Errorf("failed to get db version for %s: %w", name, err)
+	}
+	return result, nil
 }
 
-// setupGoose 配置 goose
-func setupGoose() error {
-	goose.SetBaseFS(SharedDBMigrations)
+// newProvider 为迁移来源创建独立的 goose.Provider
+func (r *Registry) newProvider(db *sql.DB, name string) (*goose.Provider, error) {
+	src, err := r.lookup(name)
+	if err != nil {
+		return nil, err
+	}
 
-	if err := goose.SetDialect("postgres"); err != nil {
-		return fmt.Errorf("failed to set goose dialect: %w", err)
+	opts := []goose.ProviderOption{
+		// 不使用 goose.AddMigration 注册的全局 Go 迁移，各来源只执行自己的 SQL 文件
+		goose.WithDisableGlobalRegistry(true),
+	}
+	if src.TableName != "" {
+		opts = append(opts, goose.WithTableName(src.TableName))
 	}
 
-	return nil
+	provider, err := goose.NewProvider(r.dialect, db, src.FS, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create migration provider for %s: %w", name, err)
+	}
+	return provider, nil
 }
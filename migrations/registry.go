@@ -0,0 +1,100 @@
+package migrations
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"sort"
+	"sync"
+
+	"github.com/pressly/goose/v3"
+)
+
+// ErrNotRegistered 迁移来源未注册
+var ErrNotRegistered = errors.New("migrations not registered")
+
+// Source 单个服务的迁移来源
+type Source struct {
+	FS        fs.FS  // 迁移文件，*.sql 位于根目录；embed.FS 带子目录时先用 fs.Sub 截取
+	TableName string // 版本记录表，默认 goose_db_version；多个服务共用一个数据库时必须各不相同
+}
+
+// Registry 迁移来源注册表
+// 每次执行迁移都会创建独立的 goose.Provider，不修改 goose 的全局状态，
+// 同一进程中可以安全地执行多个服务的迁移
+type Registry struct {
+	mu      sync.RWMutex
+	dialect goose.Dialect
+	sources map[string]Source
+}
+
+// NewRegistry 创建迁移注册表，dialect 为所有来源共用的数据库方言
+func NewRegistry(dialect goose.Dialect) *Registry {
+	return &Registry{
+		dialect: dialect,
+		sources: make(map[string]Source),
+	}
+}
+
+// Register 按名称注册迁移来源，名称重复时返回错误
+func (r *Registry) Register(name string, src Source) error {
+	if name == "" {
+		return fmt.Errorf("migrations name must not be empty")
+	}
+	if src.FS == nil {
+		return fmt.Errorf("migrations %q: fs must not be nil", name)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.sources[name]; exists {
+		return fmt.Errorf("migrations %q already registered", name)
+	}
+	r.sources[name] = src
+	return nil
+}
+
+// MustRegister 注册迁移来源，失败时 panic，用于包初始化
+func (r *Registry) MustRegister(name string, src Source) {
+	if err := r.Register(name, src); err != nil {
+		panic(err)
+	}
+}
+
+// Names 返回已注册的来源名称（按字母排序）
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, 0, len(r.sources))
+	for name := range r.sources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// lookup 查找已注册的迁移来源
+func (r *Registry) lookup(name string) (Source, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	src, ok := r.sources[name]
+	if !ok {
+		return Source{}, fmt.Errorf("%w: %q", ErrNotRegistered, name)
+	}
+	return src, nil
+}
+
+// defaultRegistry 默认注册表（PostgreSQL），各服务的迁移在包初始化时注册到这里
+var defaultRegistry = NewRegistry(goose.DialectPostgres)
+
+// Register 向默认注册表注册迁移来源
+func Register(name string, src Source) error {
+	return defaultRegistry.Register(name, src)
+}
+
+// Names 返回默认注册表中的来源名称
+func Names() []string {
+	return defaultRegistry.Names()
+}
@@ -0,0 +1,52 @@
+-- +goose Up
+-- 兼容旧版本 AutoMigrate 生成的大写表名 "Books"：重命名保留已有数据
+-- +goose StatementBegin
+DO $$
+BEGIN
+    IF to_regclass('public."Books"') IS NOT NULL AND to_regclass('public.books') IS NULL THEN
+        ALTER TABLE "Books" RENAME TO books;
+    END IF;
+END
+$$;
+-- +goose StatementEnd
+
+-- 创建图书表（列名统一使用小写 snake_case，与 BookPgPO 的 gorm 标签保持一致）
+CREATE TABLE IF NOT EXISTS books (
+    id VARCHAR(36) PRIMARY KEY,
+    bookname VARCHAR(255) NOT NULL,
+    email VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- 兼容旧版本 AutoMigrate 生成的大写列名 "Bookname"
+-- +goose StatementBegin
+DO $$
+BEGIN
+    IF EXISTS (
+        SELECT 1 FROM information_schema.columns
+        WHERE table_name = 'books' AND column_name = 'Bookname'
+    ) THEN
+        ALTER TABLE books RENAME COLUMN "Bookname" TO bookname;
+    END IF;
+END
+$$;
+-- +goose StatementEnd
+
+-- 创建唯一索引
+CREATE UNIQUE INDEX IF NOT EXISTS idx_books_bookname ON books(bookname);
+CREATE INDEX IF NOT EXISTS idx_books_created_at ON books(created_at DESC);
+
+-- 添加表和字段注释
+COMMENT ON TABLE books IS '图书表';
+COMMENT ON COLUMN books.id IS '图书ID（UUID）';
+COMMENT ON COLUMN books.bookname IS '书名（唯一）';
+COMMENT ON COLUMN books.email IS '作者邮箱';
+COMMENT ON COLUMN books.created_at IS '创建时间';
+COMMENT ON COLUMN books.updated_at IS '更新时间';
+
+-- +goose Down
+-- 回滚：只删除本迁移新增的索引
+-- 表可能在本迁移之前已由 AutoMigrate 创建并写入数据，不删除表；表名与列名保持小写，与当前代码一致
+DROP INDEX IF EXISTS idx_books_created_at;
+DROP INDEX IF EXISTS idx_books_bookname;
@@ -0,0 +1,114 @@
+package migrations
+
+import (
+	"encoding/json"
+	"io"
+	"path/filepath"
+	"time"
+
+	"github.com/pressly/goose/v3"
+)
+
+// 迁移执行结果
+const (
+	SummaryResultSuccess = "success"
+	SummaryResultFailed  = "failed"
+)
+
+// Summary 迁移执行摘要，migrate 命令 -output json 时输出，便于 CI 解析
+type Summary struct {
+	Command     string             `json:"command"`          // 执行的命令
+	Name        string             `json:"name"`             // 迁移来源名称
+	FromVersion int64              `json:"from_version"`     // 执行前的数据库版本
+	ToVersion   int64              `json:"to_version"`       // 执行后的数据库版本
+	Applied     []AppliedMigration `json:"applied"`          // 本次执行的迁移，没有时为空数组
+	Status      []MigrationState   `json:"status,omitempty"` // 各迁移的状态，仅 status 命令输出
+	Result      string             `json:"result"`           // success / failed
+	Outcome     string             `json:"outcome"`          // applied / no_change / pending / failed，见 Outcome
+	Pending     int                `json:"pending"`          // 待执行的迁移数，仅 status 命令统计
+	Error       string             `json:"error,omitempty"`  // 失败原因
+	DurationMs  int64              `json:"duration_ms"`      // 总耗时(毫秒)
+}
+
+// AppliedMigration 单个迁移的执行结果
+type AppliedMigration struct {
+	Version    int64  `json:"version"`
+	Source     string `json:"source"`          // 迁移文件名
+	Direction  string `json:"direction"`       // up / down
+	DurationMs int64  `json:"duration_ms"`     // 耗时(毫秒)
+	Empty      bool   `json:"empty,omitempty"` // 迁移没有可执行的语句
+	Error      string `json:"error,omitempty"` // 失败原因
+}
+
+// MigrationState 单个迁移的状态
+type MigrationState struct {
+	Version   int64      `json:"version"`
+	Source    string     `json:"source"`               // 迁移文件名
+	State     string     `json:"state"`                // pending / applied
+	AppliedAt *time.Time `json:"applied_at,omitempty"` // 执行时间，未执行时不输出
+}
+
+// NewSummary 根据 Migrate 的返回值生成执行摘要
+// result 可能为 nil（命令未执行到数据库），此时版本均为 0
+func NewSummary(name string, cmd Command, result *Result, err error, elapsed time.Duration) *Summary {
+	summary := &Summary{
+		Command:    cmd.Action,
+		Name:       name,
+		Applied:    []AppliedMigration{},
+		Result:     SummaryResultSuccess,
+		Outcome:    Outcome(result, err),
+		DurationMs: elapsed.Milliseconds(),
+	}
+	if err != nil {
+		summary.Result = SummaryResultFailed
+		summary.Error = err.Error()
+	}
+	if result == nil {
+		return summary
+	}
+
+	summary.FromVersion = result.FromVersion
+	summary.ToVersion = result.Version
+	summary.Pending = result.Pending()
+	for _, applied := range result.Applied {
+		summary.Applied = append(summary.Applied, newAppliedMigration(applied))
+	}
+	for _, status := range result.Status {
+		summary.Status = append(summary.Status, newMigrationState(status))
+	}
+	return summary
+}
+
+// WriteJSON 将摘要以单行 JSON 写入 w
+func (s *Summary) WriteJSON(w io.Writer) error {
+	return json.NewEncoder(w).Encode(s)
+}
+
+func newAppliedMigration(r *goose.MigrationResult) AppliedMigration {
+	applied := AppliedMigration{
+		Direction:  r.Direction,
+		DurationMs: r.Duration.Milliseconds(),
+		Empty:      r.Empty,
+	}
+	if r.Source != nil {
+		applied.Version = r.Source.Version
+		applied.Source = filepath.Base(r.Source.Path)
+	}
+	if r.Error != nil {
+		applied.Error = r.Error.Error()
+	}
+	return applied
+}
+
+func newMigrationState(s *goose.MigrationStatus) MigrationState {
+	state := MigrationState{State: string(s.State)}
+	if s.Source != nil {
+		state.Version = s.Source.Version
+		state.Source = filepath.Base(s.Source.Path)
+	}
+	if !s.AppliedAt.IsZero() {
+		appliedAt := s.AppliedAt
+		state.AppliedAt = &appliedAt
+	}
+	return state
+}
@@ -0,0 +1,113 @@
+package migrations
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/pressly/goose/v3"
+)
+
+func TestSummary_StatusRunJSON(t *testing.T) {
+	sqlDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	defer sqlDB.Close()
+
+	r := NewRegistry(goose.DialectPostgres)
+	r.MustRegister("books", Source{FS: serviceFS(map[string]string{
+		"00001_books.sql":   "books",
+		"00002_authors.sql": "authors",
+	})})
+
+	// 版本 1 已执行，版本 2 待执行
+	appliedAt := time.Date(2025, 11, 2, 5, 54, 12, 0, time.UTC)
+	mock.ExpectQuery(`pg_tables`).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectQuery(`SELECT max\(version_id\)`).WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(1))
+	mock.ExpectQuery(`WHERE version_id=`).WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"tstamp", "is_applied"}).AddRow(appliedAt, true))
+	mock.ExpectQuery(`WHERE version_id=`).WithArgs(2).
+		WillReturnRows(sqlmock.NewRows([]string{"tstamp", "is_applied"}))
+	mock.ExpectQuery(`SELECT max\(version_id\)`).WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(1))
+
+	cmd := Command{Action: ActionStatus}
+	result, err := r.Migrate(context.Background(), sqlDB, "books", cmd)
+	if err != nil {
+		t.Fatalf("status: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := NewSummary("books", cmd, result, nil, 1500*time.Millisecond).WriteJSON(&buf); err != nil {
+		t.Fatalf("write summary: %v", err)
+	}
+
+	want := map[string]interface{}{
+		"command":      "status",
+		"name":         "books",
+		"from_version": float64(1),
+		"to_version":   float64(1),
+		"applied":      []interface{}{},
+		"status": []interface{}{
+			map[string]interface{}{
+				"version":    float64(1),
+				"source":     "00001_books.sql",
+				"state":      "applied",
+				"applied_at": "2025-11-02T05:54:12Z",
+			},
+			map[string]interface{}{
+				"version": float64(2),
+				"source":  "00002_authors.sql",
+				"state":   "pending",
+			},
+		},
+		"result":      "success",
+		"outcome":     "pending",
+		"pending":     float64(1),
+		"duration_ms": float64(1500),
+	}
+	var got map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("invalid json %q: %v", buf.String(), err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected summary:\n got: %s", buf.String())
+	}
+}
+
+func TestNewSummary_Failed(t *testing.T) {
+	result := &Result{
+		FromVersion: 1,
+		Version:     2,
+		Applied: []*goose.MigrationResult{
+			{Source: &goose.Source{Path: "shared-db/00002_orders.sql", Version: 2}, Direction: "up", Duration: 30 * time.Millisecond},
+			{Source: &goose.Source{Path: "shared-db/00003_items.sql", Version: 3}, Direction: "up", Error: errors.New("syntax error")},
+		},
+	}
+
+	summary := NewSummary(SharedDB, Command{Action: ActionUp}, result, errors.New("failed to run up migrations"), time.Second)
+	if summary.Result != SummaryResultFailed || summary.Error != "failed to run up migrations" {
+		t.Fatalf("want failed result with error, got %q / %q", summary.Result, summary.Error)
+	}
+	want := []AppliedMigration{
+		{Version: 2, Source: "00002_orders.sql", Direction: "up", DurationMs: 30},
+		{Version: 3, Source: "00003_items.sql", Direction: "up", Error: "syntax error"},
+	}
+	if !reflect.DeepEqual(summary.Applied, want) {
+		t.Fatalf("unexpected applied migrations: %+v", summary.Applied)
+	}
+
+	// 命令未执行到数据库时只有命令与错误信息
+	summary = NewSummary(SharedDB, Command{Action: "sideways"}, nil, errors.New("unknown migration command"), 0)
+	if summary.Result != SummaryResultFailed || summary.Applied == nil || summary.ToVersion != 0 {
+		t.Fatalf("unexpected summary without result: %+v", summary)
+	}
+}
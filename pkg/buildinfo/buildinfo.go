@@ -0,0 +1,59 @@
+package buildinfo
+
+import (
+	"runtime"
+
+	"github.com/alfredchaos/demo/pkg/log"
+	"go.uber.org/zap"
+)
+
+// 构建信息，编译时通过 ldflags 注入，例如：
+//
+//	go build -ldflags "-X github.com/alfredchaos/demo/pkg/buildinfo.Version=v1.2.0 \
+//	  -X github.com/alfredchaos/demo/pkg/buildinfo.GitCommit=$(git rev-parse --short HEAD) \
+//	  -X github.com/alfredchaos/demo/pkg/buildinfo.BuildTime=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+var (
+	Version   = "dev"     // 版本号
+	GitCommit = "unknown" // Git 提交哈希
+	BuildTime = "unknown" // 构建时间
+)
+
+// Info 构建信息
+type Info struct {
+	Version   string
+	GitCommit string
+	BuildTime string
+	GoVersion string
+}
+
+// Get 获取当前二进制的构建信息
+func Get() Info {
+	return Info{
+		Version:   Version,
+		GitCommit: GitCommit,
+		BuildTime: BuildTime,
+		GoVersion: runtime.Version(),
+	}
+}
+
+// Fields 以日志字段形式返回构建信息
+func (i Info) Fields() []zap.Field {
+	return []zap.Field{
+		zap.String("version", i.Version),
+		zap.String("git_commit", i.GitCommit),
+		zap.String("build_time", i.BuildTime),
+		zap.String("go_version", i.GoVersion),
+	}
+}
+
+// UserAgent 返回标识服务及版本的 User-Agent，格式为 service/version
+func UserAgent(service string) string {
+	return service + "/" + Version
+}
+
+// LogStartup 记录服务启动日志，附带服务名和构建信息，便于在日志中识别部署的版本
+// fields 为额外的启动信息（如监听地址）
+func LogStartup(service string, fields ...zap.Field) {
+	all := append([]zap.Field{zap.String("service", service)}, Get().Fields()...)
+	log.Logger.WithOptions(zap.AddCallerSkip(1)).Info("starting "+service, append(all, fields...)...)
+}
@@ -0,0 +1,45 @@
+package buildinfo
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/alfredchaos/demo/pkg/log"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestLogStartup_LogsBuildInfo(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	prev := log.Logger
+	log.Logger = zap.New(core)
+	t.Cleanup(func() { log.Logger = prev })
+
+	// 模拟 ldflags 注入
+	origVersion, origCommit, origTime := Version, GitCommit, BuildTime
+	Version, GitCommit, BuildTime = "v1.2.3", "abc1234", "2024-05-01T08:30:00Z"
+	defer func() { Version, GitCommit, BuildTime = origVersion, origCommit, origTime }()
+
+	LogStartup("user-service", zap.String("addr", "0.0.0.0:9001"))
+
+	entries := logs.FilterMessage("starting user-service").All()
+	if len(entries) != 1 {
+		t.Fatalf("want 1 startup entry, got %d", len(entries))
+	}
+
+	fields := entries[0].ContextMap()
+	want := map[string]string{
+		"service":    "user-service",
+		"version":    "v1.2.3",
+		"git_commit": "abc1234",
+		"build_time": "2024-05-01T08:30:00Z",
+		"go_version": runtime.Version(),
+		"addr":       "0.0.0.0:9001",
+	}
+	for key, value := range want {
+		if fields[key] != value {
+			t.Errorf("field %s: want %q, got %v", key, value, fields[key])
+		}
+	}
+}
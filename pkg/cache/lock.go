@@ -0,0 +1,59 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+)
+
+// ErrLockNotAcquired 锁已被其他持有者占用
+var ErrLockNotAcquired = errors.New("lock not acquired")
+
+// unlockScript 仅当锁仍由当前持有者持有时才删除，避免误删他人的锁
+var unlockScript = redis.NewScript(`
+if redis.call("get", KEYS[1]) == ARGV[1] then
+	return redis.call("del", KEYS[1])
+end
+return 0
+`)
+
+// Lock 基于 Redis 的分布式互斥锁
+type Lock struct {
+	client *RedisClient
+	key    string
+	token  string
+}
+
+// TryLock 尝试获取分布式锁（SET NX PX）
+// ttl: 锁的自动过期时间，防止持有者异常退出导致死锁
+// 锁被占用时返回 ErrLockNotAcquired
+func (rc *RedisClient) TryLock(ctx context.Context, key string, ttl time.Duration) (*Lock, error) {
+	token := uuid.New().String()
+
+	ok, err := rc.client.SetNX(ctx, key, token, ttl).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire lock: %w", err)
+	}
+	if !ok {
+		return nil, ErrLockNotAcquired
+	}
+
+	return &Lock{
+		client: rc,
+		key:    key,
+		token:  token,
+	}, nil
+}
+
+// Unlock 释放分布式锁
+// 锁已过期或被其他持有者获取时为空操作
+func (l *Lock) Unlock(ctx context.Context) error {
+	if err := unlockScript.Run(ctx, l.client.client, []string{l.key}, l.token).Err(); err != nil {
+		return fmt.Errorf("failed to release lock: %w", err)
+	}
+	return nil
+}
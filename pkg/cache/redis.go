@@ -2,6 +2,8 @@ package cache
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
@@ -12,6 +14,7 @@ import (
 
 // RedisConfig Redis 配置
 type RedisConfig struct {
+	Enabled           *bool  `yaml:"enabled" mapstructure:"enabled"`                         // 是否启用，未配置时按 addr 是否为空决定，见 IsEnabled
 	Addr              string `yaml:"addr" mapstructure:"addr"`                               // Redis 地址
 	Password          string `yaml:"password" mapstructure:"password"`                       // 密码
 	DB                int    `yaml:"db" mapstructure:"db"`                                   // 数据库编号
@@ -23,27 +26,84 @@ type RedisConfig struct {
 	LogLevel          string `yaml:"log_level" mapstructure:"log_level"`                     // 日志级别 (silent, error, warn, info)
 	SlowOpThreshold   int    `yaml:"slow_op_threshold" mapstructure:"slow_op_threshold"`     // 慢操作阈值(毫秒)，默认100ms
 	EnableDetailedLog bool   `yaml:"enable_detailed_log" mapstructure:"enable_detailed_log"` // 是否记录详细命令
+	DefaultTTL        int    `yaml:"default_ttl" mapstructure:"default_ttl"`                 // 未指定过期时间（0）时使用的默认 TTL(秒)，默认 3600
+	MaxTTL            int    `yaml:"max_ttl" mapstructure:"max_ttl"`                         // TTL 上限(秒)，超过时截断，默认 86400
+
+	TLSEnabled         bool   `yaml:"tls_enabled" mapstructure:"tls_enabled"`                   // 是否启用 TLS（托管 Redis 传输加密时需要）
+	CAFile             string `yaml:"ca_file" mapstructure:"ca_file"`                           // CA 证书路径，为空时使用系统根证书
+	CertFile           string `yaml:"cert_file" mapstructure:"cert_file"`                       // 客户端证书路径（双向 TLS，可选）
+	KeyFile            string `yaml:"key_file" mapstructure:"key_file"`                         // 客户端私钥路径（双向 TLS，可选）
+	ServerName         string `yaml:"server_name" mapstructure:"server_name"`                   // 校验证书时使用的主机名，为空时取 addr 中的主机
+	InsecureSkipVerify bool   `yaml:"insecure_skip_verify" mapstructure:"insecure_skip_verify"` // 跳过服务端证书校验（仅用于测试环境）
+
+	SentinelMasterName string   `yaml:"sentinel_master_name" mapstructure:"sentinel_master_name"` // Sentinel 监控的主节点名称，配置后通过 Sentinel 发现主节点并跟随故障转移
+	SentinelAddrs      []string `yaml:"sentinel_addrs" mapstructure:"sentinel_addrs"`             // Sentinel 节点地址列表，此时忽略 addr
+	SentinelPassword   string   `yaml:"sentinel_password" mapstructure:"sentinel_password"`       // Sentinel 节点密码（与 Redis 密码不同时设置）
+
+	LocalCache LocalCacheConfig `yaml:"local_cache" mapstructure:"local_cache"` // 本地（L1）缓存配置，仅对 NewCache 生效
 }
 
+const (
+	// defaultTTL 未配置 default_ttl 时的默认过期时间
+	defaultTTL = time.Hour
+	// defaultMaxTTL 未配置 max_ttl 时的过期时间上限
+	defaultMaxTTL = 24 * time.Hour
+)
+
 // RedisClient Redis 客户端封装
 type RedisClient struct {
 	client *redis.Client
 	config *RedisConfig
 }
 
+// IsEnabled 是否启用 Redis
+// 显式配置 enabled 时以其为准；未配置时配置了 addr 或 sentinel 即视为启用，兼容引入 enabled 之前的配置文件
+func (c *RedisConfig) IsEnabled() bool {
+	if c.Enabled != nil {
+		return *c.Enabled
+	}
+	return c.Addr != "" || c.SentinelMasterName != ""
+}
+
 // NewRedisClient 创建新的 Redis 客户端
 func NewRedisClient(cfg *RedisConfig) (*RedisClient, error) {
-	// 创建 Redis 客户端
-	client := redis.NewClient(&redis.Options{
-		Addr:         cfg.Addr,
-		Password:     cfg.Password,
-		DB:           cfg.DB,
-		PoolSize:     cfg.PoolSize,
-		MinIdleConns: cfg.MinIdleConns,
-		DialTimeout:  time.Duration(cfg.DialTimeout) * time.Second,
-		ReadTimeout:  time.Duration(cfg.ReadTimeout) * time.Second,
-		WriteTimeout: time.Duration(cfg.WriteTimeout) * time.Second,
-	})
+	tlsConfig, err := cfg.buildTLSConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	// 创建 Redis 客户端：配置了 Sentinel 时使用故障转移客户端，否则直连单节点
+	var client *redis.Client
+	if cfg.SentinelMasterName != "" {
+		if len(cfg.SentinelAddrs) == 0 {
+			return nil, fmt.Errorf("redis sentinel master %q configured without sentinel_addrs", cfg.SentinelMasterName)
+		}
+		client = redis.NewFailoverClient(&redis.FailoverOptions{
+			MasterName:       cfg.SentinelMasterName,
+			SentinelAddrs:    cfg.SentinelAddrs,
+			SentinelPassword: cfg.SentinelPassword,
+			Password:         cfg.Password,
+			DB:               cfg.DB,
+			PoolSize:         cfg.PoolSize,
+			MinIdleConns:     cfg.MinIdleConns,
+			DialTimeout:      time.Duration(cfg.DialTimeout) * time.Second,
+			ReadTimeout:      time.Duration(cfg.ReadTimeout) * time.Second,
+			WriteTimeout:     time.Duration(cfg.WriteTimeout) * time.Second,
+			TLSConfig:        tlsConfig,
+		})
+	} else {
+		client = redis.NewClient(&redis.Options{
+			Addr:         cfg.Addr,
+			Password:     cfg.Password,
+			DB:           cfg.DB,
+			PoolSize:     cfg.PoolSize,
+			MinIdleConns: cfg.MinIdleConns,
+			DialTimeout:  time.Duration(cfg.DialTimeout) * time.Second,
+			ReadTimeout:  time.Duration(cfg.ReadTimeout) * time.Second,
+			WriteTimeout: time.Duration(cfg.WriteTimeout) * time.Second,
+			TLSConfig:    tlsConfig,
+		})
+	}
 
 	// 添加日志 Hook
 	if cfg.LogLevel != "" && cfg.LogLevel != "silent" {
@@ -55,6 +115,8 @@ func NewRedisClient(cfg *RedisConfig) (*RedisClient, error) {
 	defer cancel()
 
 	if err := client.Ping(ctx).Err(); err != nil {
+		// 释放已创建的连接池，避免启动重试时泄漏
+		client.Close()
 		return nil, fmt.Errorf("failed to connect to redis: %w", err)
 	}
 
@@ -70,15 +132,92 @@ func (rc *RedisClient) GetClient() *redis.Client {
 }
 
 // Set 设置键值对
+// expiration 为 0 时使用默认 TTL，超过上限时截断，避免缓存永不过期；为 redis.KeepTTL 时保留键原有的过期时间
 func (rc *RedisClient) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
-	return rc.client.Set(ctx, key, value, expiration).Err()
+	return rc.client.Set(ctx, key, value, rc.Expiration(expiration)).Err()
+}
+
+// Expiration 按配置规范化过期时间：0 使用默认 TTL，超过上限时截断
+// redis.KeepTTL 原样返回（只对 SET 有效）；其他负数在 go-redis 中表示永不过期，同样使用默认 TTL
+// 直接使用原始客户端（如 Pipeline）写入时应先调用此方法
+func (rc *RedisClient) Expiration(expiration time.Duration) time.Duration {
+	def, max := defaultTTL, defaultMaxTTL
+	if rc.config != nil {
+		if rc.config.DefaultTTL > 0 {
+			def = time.Duration(rc.config.DefaultTTL) * time.Second
+		}
+		if rc.config.MaxTTL > 0 {
+			max = time.Duration(rc.config.MaxTTL) * time.Second
+		}
+	}
+
+	if expiration == redis.KeepTTL {
+		return expiration
+	}
+	if expiration <= 0 {
+		expiration = def
+	}
+	if expiration > max {
+		expiration = max
+	}
+	return expiration
 }
 
 // Get 获取键对应的值
+// 键不存在时返回 redis.Nil，不希望依赖 redis 包的调用方应使用 GetOrNil
 func (rc *RedisClient) Get(ctx context.Context, key string) (string, error) {
 	return rc.client.Get(ctx, key).Result()
 }
 
+// GetOrNil 获取键对应的值，区分未命中和真正的错误
+// 键不存在时返回 found=false 且 err 为 nil；只有连接、超时等异常才返回 err
+func (rc *RedisClient) GetOrNil(ctx context.Context, key string) (value string, found bool, err error) {
+	value, err = rc.client.Get(ctx, key).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return value, true, nil
+}
+
+// MGet 批量获取键对应的值
+// 返回结果与 keys 一一对应，不存在的键对应 nil
+func (rc *RedisClient) MGet(ctx context.Context, keys ...string) ([]interface{}, error) {
+	return rc.client.MGet(ctx, keys...).Result()
+}
+
+// MGetJSON 使用 MGET 批量获取并反序列化 JSON 值
+// 返回以键为索引的结果：不存在的键不会出现在结果中，值为 JSON null 的键对应 nil
+func MGetJSON[T any](ctx context.Context, rc *RedisClient, keys ...string) (map[string]*T, error) {
+	result := make(map[string]*T, len(keys))
+	if len(keys) == 0 {
+		return result, nil
+	}
+
+	values, err := rc.MGet(ctx, keys...)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, value := range values {
+		data, ok := value.(string)
+		if !ok {
+			// 键不存在
+			continue
+		}
+
+		var v *T
+		if err := json.Unmarshal([]byte(data), &v); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal value of key %s: %w", keys[i], err)
+		}
+		result[keys[i]] = v
+	}
+
+	return result, nil
+}
+
 // Del 删除键
 func (rc *RedisClient) Del(ctx context.Context, keys ...string) error {
 	return rc.client.Del(ctx, keys...).Err()
@@ -109,6 +248,19 @@ func (rc *RedisClient) Decr(ctx context.Context, key string) (int64, error) {
 	return rc.client.Decr(ctx, key).Result()
 }
 
+// Stats 获取连接池统计信息
+func (rc *RedisClient) Stats() (map[string]interface{}, error) {
+	stats := rc.client.PoolStats()
+	return map[string]interface{}{
+		"hits":        stats.Hits,
+		"misses":      stats.Misses,
+		"timeouts":    stats.Timeouts,
+		"total_conns": stats.TotalConns,
+		"idle_conns":  stats.IdleConns,
+		"stale_conns": stats.StaleConns,
+	}, nil
+}
+
 // Close 关闭 Redis 连接
 func (rc *RedisClient) Close() error {
 	if rc.client != nil {
@@ -119,6 +271,11 @@ func (rc *RedisClient) Close() error {
 
 // Ping 检查 Redis 连接是否正常
 func (rc *RedisClient) Ping(ctx context.Context) error {
+	return rc.PingContext(ctx)
+}
+
+// PingContext 检查 Redis 连接是否正常，超时或取消由 ctx 控制
+func (rc *RedisClient) PingContext(ctx context.Context) error {
 	return rc.client.Ping(ctx).Err()
 }
 
@@ -183,7 +340,8 @@ func (h *redisLogHook) AfterProcessPipeline(ctx context.Context, cmds []redis.Cm
 
 // logCommand 记录命令执行日志
 func (h *redisLogHook) logCommand(ctx context.Context, cmd redis.Cmder, duration time.Duration) error {
-	contextLogger := log.WithContext(ctx).WithOptions(zap.AddCallerSkip(2))
+	// 复用请求入口绑定的 logger，不在每条命令上重新提取上下文字段
+	contextLogger := log.FromContext(ctx).WithOptions(zap.AddCallerSkip(2))
 
 	fields := []zap.Field{
 		zap.String("command", cmd.Name()),
@@ -0,0 +1,142 @@
+package cache
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+)
+
+func TestRedisClient_SetEnforcesDefaultAndMaxTTL(t *testing.T) {
+	mr := miniredis.RunT(t)
+	rc, err := NewRedisClient(&RedisConfig{Addr: mr.Addr(), DefaultTTL: 60, MaxTTL: 300})
+	if err != nil {
+		t.Fatalf("new redis client: %v", err)
+	}
+	defer rc.Close()
+	ctx := context.Background()
+
+	cases := []struct {
+		key        string
+		expiration time.Duration
+		want       time.Duration
+	}{
+		{"zero", 0, time.Minute},
+		{"within", 2 * time.Minute, 2 * time.Minute},
+		{"over", time.Hour, 5 * time.Minute},
+	}
+
+	for _, c := range cases {
+		if err := rc.Set(ctx, c.key, "v", c.expiration); err != nil {
+			t.Fatalf("set %s: %v", c.key, err)
+		}
+		if got := mr.TTL(c.key); got != c.want {
+			t.Errorf("%s: want ttl %v, got %v", c.key, c.want, got)
+		}
+	}
+}
+
+func TestRedisClient_SetKeepTTL(t *testing.T) {
+	mr := miniredis.RunT(t)
+	rc, err := NewRedisClient(&RedisConfig{Addr: mr.Addr(), DefaultTTL: 60, MaxTTL: 300})
+	if err != nil {
+		t.Fatalf("new redis client: %v", err)
+	}
+	defer rc.Close()
+	ctx := context.Background()
+
+	if err := rc.Set(ctx, "k", "v1", 2*time.Minute); err != nil {
+		t.Fatalf("set: %v", err)
+	}
+	// KeepTTL 只更新值，保留原有的过期时间，不替换为默认 TTL
+	if err := rc.Set(ctx, "k", "v2", redis.KeepTTL); err != nil {
+		t.Fatalf("set keepttl: %v", err)
+	}
+	if got := mr.TTL("k"); got != 2*time.Minute {
+		t.Fatalf("want ttl kept at 2m, got %v", got)
+	}
+	if got, _ := mr.Get("k"); got != "v2" {
+		t.Fatalf("want value updated, got %q", got)
+	}
+}
+
+func TestRedisClient_ExpirationBuiltinDefaults(t *testing.T) {
+	rc := &RedisClient{config: &RedisConfig{}}
+
+	if got := rc.Expiration(0); got != defaultTTL {
+		t.Fatalf("want default %v, got %v", defaultTTL, got)
+	}
+	if got := rc.Expiration(7 * 24 * time.Hour); got != defaultMaxTTL {
+		t.Fatalf("want cap %v, got %v", defaultMaxTTL, got)
+	}
+}
+
+func TestRedisClient_PingContextRespectsCancellation(t *testing.T) {
+	// 只接受连接、从不响应的服务，ping 只能依靠上下文超时返回
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer ln.Close()
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+
+	rc := &RedisClient{client: redis.NewClient(&redis.Options{Addr: ln.Addr().String(), ReadTimeout: 10 * time.Second})}
+	defer rc.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if err := rc.PingContext(ctx); err == nil {
+		t.Fatal("want error after context deadline")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("want ping to honour context deadline, took %v", elapsed)
+	}
+}
+
+func TestRedisClient_GetOrNilDistinguishesMissFromError(t *testing.T) {
+	mr := miniredis.RunT(t)
+	rc, err := NewRedisClient(&RedisConfig{Addr: mr.Addr()})
+	if err != nil {
+		t.Fatalf("new redis client: %v", err)
+	}
+	defer rc.Close()
+	ctx := context.Background()
+
+	if err := rc.Set(ctx, "present", "v", time.Minute); err != nil {
+		t.Fatalf("set: %v", err)
+	}
+
+	value, found, err := rc.GetOrNil(ctx, "present")
+	if err != nil || !found || value != "v" {
+		t.Fatalf("hit: want (v, true, nil), got (%q, %v, %v)", value, found, err)
+	}
+
+	value, found, err = rc.GetOrNil(ctx, "missing")
+	if err != nil || found || value != "" {
+		t.Fatalf("miss: want (\"\", false, nil), got (%q, %v, %v)", value, found, err)
+	}
+
+	// 服务端错误（类型不匹配）必须作为错误返回，而不是未命中
+	mr.Lpush("list", "x")
+	if _, found, err := rc.GetOrNil(ctx, "list"); err == nil || found {
+		t.Fatalf("wrong type: want error, got found=%v err=%v", found, err)
+	}
+
+	mr.Close()
+	if _, found, err := rc.GetOrNil(ctx, "present"); err == nil || found {
+		t.Fatalf("server down: want error, got found=%v err=%v", found, err)
+	}
+}
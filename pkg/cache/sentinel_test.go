@@ -0,0 +1,82 @@
+package cache
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/alicebob/miniredis/v2/server"
+)
+
+// startFakeSentinel 启动一个只实现客户端所需命令的 Sentinel 节点
+// 期望的拓扑：一个或多个 Sentinel 监控名为 masterName 的主节点，
+// 客户端通过 SENTINEL get-master-addr-by-name 发现主节点地址，并订阅 +switch-master 跟随故障转移
+func startFakeSentinel(t *testing.T, masterName, masterAddr string) *server.Server {
+	t.Helper()
+
+	srv, err := server.NewServer("127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("start fake sentinel: %v", err)
+	}
+	t.Cleanup(srv.Close)
+
+	host, port, _ := net.SplitHostPort(masterAddr)
+	_ = srv.Register("SENTINEL", func(c *server.Peer, cmd string, args []string) {
+		switch {
+		case len(args) == 2 && strings.EqualFold(args[0], "get-master-addr-by-name") && args[1] == masterName:
+			c.WriteStrings([]string{host, port})
+		case len(args) == 2 && strings.EqualFold(args[0], "get-master-addr-by-name"):
+			c.WriteNull()
+		case len(args) == 2 && strings.EqualFold(args[0], "sentinels"):
+			c.WriteLen(0)
+		default:
+			c.WriteError("ERR unsupported sentinel command")
+		}
+	})
+	_ = srv.Register("SUBSCRIBE", func(c *server.Peer, cmd string, args []string) {
+		for i, ch := range args {
+			c.WriteLen(3)
+			c.WriteBulk("subscribe")
+			c.WriteBulk(ch)
+			c.WriteInt(i + 1)
+		}
+	})
+	_ = srv.Register("PING", func(c *server.Peer, cmd string, args []string) {
+		c.WriteInline("PONG")
+	})
+
+	return srv
+}
+
+func TestNewRedisClient_Sentinel(t *testing.T) {
+	master := miniredis.RunT(t)
+	sentinel := startFakeSentinel(t, "mymaster", master.Addr())
+
+	rc, err := NewRedisClient(&RedisConfig{
+		SentinelMasterName: "mymaster",
+		SentinelAddrs:      []string{sentinel.Addr().String()},
+	})
+	if err != nil {
+		t.Fatalf("new redis client: %v", err)
+	}
+	defer rc.Close()
+
+	ctx := context.Background()
+	if err := rc.Set(ctx, "k", "v", time.Minute); err != nil {
+		t.Fatalf("set via sentinel: %v", err)
+	}
+	// 写入应落在 Sentinel 报告的主节点上
+	if got, err := master.Get("k"); err != nil || got != "v" {
+		t.Fatalf("want v on master, got %q (err=%v)", got, err)
+	}
+}
+
+func TestNewRedisClient_SentinelRequiresAddrs(t *testing.T) {
+	_, err := NewRedisClient(&RedisConfig{SentinelMasterName: "mymaster"})
+	if err == nil || !strings.Contains(err.Error(), "sentinel_addrs") {
+		t.Fatalf("want sentinel_addrs error, got %v", err)
+	}
+}
@@ -0,0 +1,309 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alfredchaos/demo/pkg/log"
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	// defaultLocalCacheSize 本地缓存默认容量
+	defaultLocalCacheSize = 1024
+	// defaultLocalCacheTTL 本地缓存默认过期时间
+	defaultLocalCacheTTL = 5 * time.Second
+	// defaultInvalidationChannel 默认的失效通知频道
+	defaultInvalidationChannel = "cache:invalidate"
+	// invalidationSeparator 失效消息中实例 ID 与键的分隔符
+	invalidationSeparator = "|"
+)
+
+// LocalCacheConfig 本地（L1）缓存配置
+//
+// 过期窗口：本实例写入后其他副本在收到失效通知前最多读到 TTL 时长的旧值；
+// 通知丢失（如订阅断线）时同样以 TTL 为上限，因此 TTL 应保持在秒级
+type LocalCacheConfig struct {
+	Enabled             bool   `yaml:"enabled" mapstructure:"enabled"`                           // 是否启用本地缓存
+	Size                int    `yaml:"size" mapstructure:"size"`                                 // 最大条目数，默认 1024
+	TTL                 int    `yaml:"ttl" mapstructure:"ttl"`                                   // 本地条目过期时间(毫秒)，默认 5000
+	InvalidationChannel string `yaml:"invalidation_channel" mapstructure:"invalidation_channel"` // 失效通知的 Pub/Sub 频道，默认 cache:invalidate
+}
+
+// Cache 通用键值缓存接口
+type Cache interface {
+	Get(ctx context.Context, key string) (string, error)
+	Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error
+	Del(ctx context.Context, keys ...string) error
+}
+
+// NewCache 根据配置创建通用缓存
+// 启用 local_cache 时返回带本地 L1 的 TieredCache，否则直接返回 RedisClient
+func NewCache(cfg *RedisConfig) (Cache, error) {
+	rc, err := NewRedisClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if !cfg.LocalCache.Enabled {
+		return rc, nil
+	}
+
+	tiered, err := NewTieredCache(rc, &cfg.LocalCache)
+	if err != nil {
+		_ = rc.Close()
+		return nil, err
+	}
+	return tiered, nil
+}
+
+// TieredCache 两级缓存：进程内 LRU（L1）+ Redis（L2）
+// 写入和删除通过 Redis Pub/Sub 广播失效通知，使其他副本丢弃各自的 L1 条目
+type TieredCache struct {
+	redis      *RedisClient
+	local      *localLRU
+	channel    string
+	instanceID string
+	pubsub     *redis.PubSub
+	done       chan struct{}
+}
+
+// NewTieredCache 创建两级缓存并订阅失效通知频道
+func NewTieredCache(rc *RedisClient, cfg *LocalCacheConfig) (*TieredCache, error) {
+	size := defaultLocalCacheSize
+	ttl := defaultLocalCacheTTL
+	channel := defaultInvalidationChannel
+	if cfg != nil {
+		if cfg.Size > 0 {
+			size = cfg.Size
+		}
+		if cfg.TTL > 0 {
+			ttl = time.Duration(cfg.TTL) * time.Millisecond
+		}
+		if cfg.InvalidationChannel != "" {
+			channel = cfg.InvalidationChannel
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	pubsub := rc.client.Subscribe(ctx, channel)
+	// 等待订阅确认，确保返回后不会错过失效通知
+	if _, err := pubsub.Receive(ctx); err != nil {
+		_ = pubsub.Close()
+		return nil, fmt.Errorf("failed to subscribe invalidation channel: %w", err)
+	}
+
+	tc := &TieredCache{
+		redis:      rc,
+		local:      newLocalLRU(size, ttl),
+		channel:    channel,
+		instanceID: uuid.New().String(),
+		pubsub:     pubsub,
+		done:       make(chan struct{}),
+	}
+	go tc.listen()
+
+	return tc, nil
+}
+
+// Get 获取缓存值，优先读取 L1，未命中时回源 Redis 并写入 L1
+// 键不存在时返回 redis.Nil
+func (tc *TieredCache) Get(ctx context.Context, key string) (string, error) {
+	if value, ok := tc.local.get(key); ok {
+		return value, nil
+	}
+
+	// 回源期间本地的 Set/Del 或失效通知可能已删除该键，此时读到的可能是旧值，不再回填 L1
+	gen := tc.local.generation()
+	value, err := tc.redis.Get(ctx, key)
+	if err != nil {
+		return "", err
+	}
+
+	tc.local.setIfGeneration(key, value, gen)
+	return value, nil
+}
+
+// Set 写入 Redis，并使本地及其他副本的 L1 条目失效
+// 本地不直接回填，下一次 Get 会从 Redis 读取规范化后的值
+func (tc *TieredCache) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
+	if err := tc.redis.Set(ctx, key, value, expiration); err != nil {
+		return err
+	}
+
+	tc.local.delete(key)
+	tc.publishInvalidation(ctx, key)
+	return nil
+}
+
+// Del 删除 Redis 中的键，并使本地及其他副本的 L1 条目失效
+func (tc *TieredCache) Del(ctx context.Context, keys ...string) error {
+	if err := tc.redis.Del(ctx, keys...); err != nil {
+		return err
+	}
+
+	for _, key := range keys {
+		tc.local.delete(key)
+		tc.publishInvalidation(ctx, key)
+	}
+	return nil
+}
+
+// Redis 获取底层的 Redis 客户端（L2）
+func (tc *TieredCache) Redis() *RedisClient {
+	return tc.redis
+}
+
+// Close 停止订阅失效通知并清空 L1
+// 不会关闭底层的 Redis 客户端
+func (tc *TieredCache) Close() error {
+	err := tc.pubsub.Close()
+	<-tc.done
+	tc.local.clear()
+	return err
+}
+
+// publishInvalidation 广播键失效通知
+// 发布失败只记录日志，其他副本的 L1 最迟在 TTL 后过期
+func (tc *TieredCache) publishInvalidation(ctx context.Context, key string) {
+	message := tc.instanceID + invalidationSeparator + key
+	if err := tc.redis.client.Publish(ctx, tc.channel, message).Err(); err != nil {
+		log.WithContext(ctx).Warn("failed to publish cache invalidation",
+			zap.String("key", key),
+			zap.Error(err),
+		)
+	}
+}
+
+// listen 消费失效通知，丢弃其他副本写入的键
+func (tc *TieredCache) listen() {
+	defer close(tc.done)
+
+	for msg := range tc.pubsub.Channel() {
+		instanceID, key, ok := strings.Cut(msg.Payload, invalidationSeparator)
+		if !ok || instanceID == tc.instanceID {
+			continue
+		}
+		tc.local.delete(key)
+	}
+}
+
+// localEntry 本地缓存条目
+type localEntry struct {
+	key      string
+	value    string
+	expireAt time.Time
+}
+
+// localLRU 带过期时间的定长 LRU 缓存
+type localLRU struct {
+	mu       sync.Mutex
+	size     int
+	ttl      time.Duration
+	order    *list.List
+	elements map[string]*list.Element
+	// gen 每次删除或清空时递增，用于丢弃删除前开始的回源结果
+	// 不按键记录，避免为已淘汰的键保留版本；代价是任意键删除都会让进行中的回填跳过一次
+	gen uint64
+}
+
+// newLocalLRU 创建本地 LRU 缓存
+func newLocalLRU(size int, ttl time.Duration) *localLRU {
+	return &localLRU{
+		size:     size,
+		ttl:      ttl,
+		order:    list.New(),
+		elements: make(map[string]*list.Element, size),
+	}
+}
+
+// get 获取未过期的条目，并将其移动到队首
+func (l *localLRU) get(key string) (string, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	elem, ok := l.elements[key]
+	if !ok {
+		return "", false
+	}
+
+	entry := elem.Value.(*localEntry)
+	if time.Now().After(entry.expireAt) {
+		l.removeElement(elem)
+		return "", false
+	}
+
+	l.order.MoveToFront(elem)
+	return entry.value, true
+}
+
+// setLocked 写入条目，超出容量时淘汰最久未使用的条目（调用方需持有锁）
+func (l *localLRU) setLocked(key, value string) {
+	expireAt := time.Now().Add(l.ttl)
+	if elem, ok := l.elements[key]; ok {
+		entry := elem.Value.(*localEntry)
+		entry.value = value
+		entry.expireAt = expireAt
+		l.order.MoveToFront(elem)
+		return
+	}
+
+	l.elements[key] = l.order.PushFront(&localEntry{key: key, value: value, expireAt: expireAt})
+	if l.order.Len() > l.size {
+		l.removeElement(l.order.Back())
+	}
+}
+
+// generation 返回当前的删除代数
+func (l *localLRU) generation() uint64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.gen
+}
+
+// setIfGeneration 删除代数仍为 gen 时写入条目，返回是否写入
+func (l *localLRU) setIfGeneration(key, value string, gen uint64) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.gen != gen {
+		return false
+	}
+	l.setLocked(key, value)
+	return true
+}
+
+// delete 删除条目
+func (l *localLRU) delete(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.gen++
+	if elem, ok := l.elements[key]; ok {
+		l.removeElement(elem)
+	}
+}
+
+// clear 清空所有条目
+func (l *localLRU) clear() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.gen++
+	l.order.Init()
+	l.elements = make(map[string]*list.Element, l.size)
+}
+
+// removeElement 移除链表元素（调用方需持有锁）
+func (l *localLRU) removeElement(elem *list.Element) {
+	l.order.Remove(elem)
+	delete(l.elements, elem.Value.(*localEntry).key)
+}
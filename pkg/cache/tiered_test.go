@@ -0,0 +1,162 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alfredchaos/demo/pkg/log"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"go.uber.org/zap"
+)
+
+// useNopLogger 测试期间使用空日志，结束后恢复原日志
+func useNopLogger(t *testing.T) {
+	t.Helper()
+	original := log.Logger
+	log.Logger = zap.NewNop()
+	t.Cleanup(func() { log.Logger = original })
+}
+
+// newTestTieredCache 基于 miniredis 创建两级缓存实例
+func newTestTieredCache(t *testing.T, mr *miniredis.Miniredis) *TieredCache {
+	t.Helper()
+
+	rc, err := NewRedisClient(&RedisConfig{Addr: mr.Addr()})
+	if err != nil {
+		t.Fatalf("new redis client: %v", err)
+	}
+	tc, err := NewTieredCache(rc, &LocalCacheConfig{TTL: 60000})
+	if err != nil {
+		t.Fatalf("new tiered cache: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = tc.Close()
+		_ = rc.Close()
+	})
+	return tc
+}
+
+func TestTieredCache_L1Hit(t *testing.T) {
+	useNopLogger(t)
+	mr := miniredis.RunT(t)
+	tc := newTestTieredCache(t, mr)
+	ctx := context.Background()
+
+	mr.Set("k", "v1")
+	if got, err := tc.Get(ctx, "k"); err != nil || got != "v1" {
+		t.Fatalf("want v1, got %q, %v", got, err)
+	}
+
+	// 绕过缓存直接修改 Redis，L1 仍返回旧值
+	mr.Set("k", "v2")
+	if got, err := tc.Get(ctx, "k"); err != nil || got != "v1" {
+		t.Fatalf("want L1 hit v1, got %q, %v", got, err)
+	}
+}
+
+func TestTieredCache_L1MissFallsBackToRedis(t *testing.T) {
+	useNopLogger(t)
+	mr := miniredis.RunT(t)
+	tc := newTestTieredCache(t, mr)
+	ctx := context.Background()
+
+	if err := tc.Set(ctx, "k", "v1", time.Minute); err != nil {
+		t.Fatalf("set: %v", err)
+	}
+	if _, ok := tc.local.get("k"); ok {
+		t.Fatalf("set should not populate L1")
+	}
+
+	if got, err := tc.Get(ctx, "k"); err != nil || got != "v1" {
+		t.Fatalf("want v1 from redis, got %q, %v", got, err)
+	}
+	if got, ok := tc.local.get("k"); !ok || got != "v1" {
+		t.Fatalf("want L1 populated after miss, got %q, %v", got, ok)
+	}
+}
+
+func TestTieredCache_CrossInstanceInvalidation(t *testing.T) {
+	useNopLogger(t)
+	mr := miniredis.RunT(t)
+	a := newTestTieredCache(t, mr)
+	b := newTestTieredCache(t, mr)
+	ctx := context.Background()
+
+	mr.Set("k", "v1")
+	if got, _ := b.Get(ctx, "k"); got != "v1" {
+		t.Fatalf("want b to cache v1, got %q", got)
+	}
+
+	if err := a.Set(ctx, "k", "v2", time.Minute); err != nil {
+		t.Fatalf("set: %v", err)
+	}
+
+	// 失效通知异步到达
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if _, ok := b.local.get("k"); !ok {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("b's L1 entry was not invalidated")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	if got, err := b.Get(ctx, "k"); err != nil || got != "v2" {
+		t.Fatalf("want v2 after invalidation, got %q, %v", got, err)
+	}
+}
+
+// afterGetHook 在第一次 GET 返回后、调用方处理结果前执行 fn
+type afterGetHook struct {
+	once sync.Once
+	fn   func()
+}
+
+func (h *afterGetHook) BeforeProcess(ctx context.Context, cmd redis.Cmder) (context.Context, error) {
+	return ctx, nil
+}
+
+func (h *afterGetHook) AfterProcess(ctx context.Context, cmd redis.Cmder) error {
+	if cmd.Name() == "get" {
+		h.once.Do(h.fn)
+	}
+	return nil
+}
+
+func (h *afterGetHook) BeforeProcessPipeline(ctx context.Context, cmds []redis.Cmder) (context.Context, error) {
+	return ctx, nil
+}
+
+func (h *afterGetHook) AfterProcessPipeline(ctx context.Context, cmds []redis.Cmder) error {
+	return nil
+}
+
+func TestTieredCache_GetDoesNotBackfillValueDeletedDuringRead(t *testing.T) {
+	useNopLogger(t)
+	mr := miniredis.RunT(t)
+	tc := newTestTieredCache(t, mr)
+	ctx := context.Background()
+
+	// Get 从 Redis 读到 v1 后、回填 L1 前，本实例写入 v2
+	mr.Set("k", "v1")
+	tc.redis.client.AddHook(&afterGetHook{fn: func() {
+		if err := tc.Set(ctx, "k", "v2", time.Minute); err != nil {
+			t.Errorf("set: %v", err)
+		}
+	}})
+
+	if got, err := tc.Get(ctx, "k"); err != nil || got != "v1" {
+		t.Fatalf("want in-flight read to return v1, got %q, %v", got, err)
+	}
+	if got, ok := tc.local.get("k"); ok {
+		t.Fatalf("want stale value kept out of L1, got %q", got)
+	}
+	if got, err := tc.Get(ctx, "k"); err != nil || got != "v2" {
+		t.Fatalf("want v2 after the write, got %q, %v", got, err)
+	}
+}
@@ -0,0 +1,107 @@
+package cache
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+)
+
+// writeSelfSignedCert 生成 127.0.0.1 的自签名证书，返回服务端证书和 CA 文件路径
+func writeSelfSignedCert(t *testing.T) (tls.Certificate, string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "miniredis"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("create certificate: %v", err)
+	}
+
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(caFile, certPEM, 0o600); err != nil {
+		t.Fatalf("write ca file: %v", err)
+	}
+
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}, caFile
+}
+
+func TestNewRedisClient_TLS(t *testing.T) {
+	cert, caFile := writeSelfSignedCert(t)
+
+	mr := miniredis.NewMiniRedis()
+	if err := mr.StartTLS(&tls.Config{Certificates: []tls.Certificate{cert}}); err != nil {
+		t.Fatalf("start tls miniredis: %v", err)
+	}
+	defer mr.Close()
+
+	rc, err := NewRedisClient(&RedisConfig{Addr: mr.Addr(), TLSEnabled: true, CAFile: caFile})
+	if err != nil {
+		t.Fatalf("new redis client: %v", err)
+	}
+	defer rc.Close()
+
+	ctx := context.Background()
+	if err := rc.Set(ctx, "k", "v", time.Minute); err != nil {
+		t.Fatalf("set over tls: %v", err)
+	}
+	if got, err := rc.Get(ctx, "k"); err != nil || got != "v" {
+		t.Fatalf("want v, got %q (err=%v)", got, err)
+	}
+}
+
+func TestNewRedisClient_TLSFailsOnBadCertificates(t *testing.T) {
+	notPEM := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(notPEM, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+
+	cases := []struct {
+		name string
+		cfg  RedisConfig
+		want string
+	}{
+		{"missing ca file", RedisConfig{CAFile: filepath.Join(t.TempDir(), "missing.pem")}, "failed to read redis tls ca file"},
+		{"invalid ca file", RedisConfig{CAFile: notPEM}, "no valid certificates"},
+		{"cert without key", RedisConfig{CertFile: notPEM}, "requires both cert_file and key_file"},
+		{"invalid key pair", RedisConfig{CertFile: notPEM, KeyFile: notPEM}, "failed to load redis tls client certificate"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			c.cfg.Addr = "127.0.0.1:0"
+			c.cfg.TLSEnabled = true
+			_, err := NewRedisClient(&c.cfg)
+			if err == nil || !strings.Contains(err.Error(), c.want) {
+				t.Fatalf("want error containing %q, got %v", c.want, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,152 @@
+package codec
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// benchPayload 与用户缓存、任务消息规模相近的测试数据
+type benchPayload struct {
+	ID        string
+	Username  string
+	Email     string
+	CreatedAt time.Time
+	Tags      map[string]string
+}
+
+func newBenchPayload(i int) benchPayload {
+	return benchPayload{
+		ID:        fmt.Sprintf("user-%d", i),
+		Username:  "alice",
+		Email:     "alice@example.com",
+		CreatedAt: time.Date(2024, 5, 1, 8, 30, 0, 0, time.UTC),
+		Tags:      map[string]string{"task_type": "sayhello", "html": "<b>&</b>"},
+	}
+}
+
+func TestMarshal_MatchesEncodingJSON(t *testing.T) {
+	values := []interface{}{
+		newBenchPayload(1),
+		map[string]interface{}{"user_id": "u1", "message": "<hello>"},
+		nil,
+		[]int{1, 2, 3},
+	}
+
+	for _, v := range values {
+		want, err := json.Marshal(v)
+		if err != nil {
+			t.Fatalf("json.Marshal: %v", err)
+		}
+		got, err := Marshal(v)
+		if err != nil {
+			t.Fatalf("Marshal: %v", err)
+		}
+		if string(got) != string(want) {
+			t.Fatalf("want %s, got %s", want, got)
+		}
+		s, err := MarshalString(v)
+		if err != nil || s != string(want) {
+			t.Fatalf("MarshalString: want %s, got %s (err=%v)", want, s, err)
+		}
+	}
+}
+
+func TestMarshal_ReturnsError(t *testing.T) {
+	if _, err := Marshal(make(chan int)); err == nil {
+		t.Fatal("want error for unsupported type")
+	}
+	// 出错后缓冲区归还池中不能残留半截输出
+	got, err := Marshal("ok")
+	if err != nil || string(got) != `"ok"` {
+		t.Fatalf("want \"ok\", got %s (err=%v)", got, err)
+	}
+}
+
+func TestMarshal_ConcurrentUse(t *testing.T) {
+	var wg sync.WaitGroup
+	errs := make(chan error, 64)
+	for g := 0; g < 16; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 200; i++ {
+				id := g*1000 + i
+				// 混合大小不同的输出，检验结果互不污染
+				v := newBenchPayload(id)
+				v.Username = strings.Repeat("x", i%50)
+
+				got, err := Marshal(v)
+				if err != nil {
+					errs <- err
+					return
+				}
+				want, _ := json.Marshal(v)
+				if string(got) != string(want) {
+					errs <- fmt.Errorf("payload %d corrupted: %s", id, got)
+					return
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Fatal(err)
+	}
+}
+
+func BenchmarkJSONMarshal(b *testing.B) {
+	v := newBenchPayload(1)
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if _, err := json.Marshal(v); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func BenchmarkMarshal(b *testing.B) {
+	v := newBenchPayload(1)
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if _, err := Marshal(v); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+// BenchmarkJSONMarshalString 对照组：缓存路径原先的 json.Marshal + string 转换
+func BenchmarkJSONMarshalString(b *testing.B) {
+	v := newBenchPayload(1)
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			data, err := json.Marshal(v)
+			if err != nil {
+				b.Fatal(err)
+			}
+			_ = string(data)
+		}
+	})
+}
+
+func BenchmarkMarshalString(b *testing.B) {
+	v := newBenchPayload(1)
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if _, err := MarshalString(v); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
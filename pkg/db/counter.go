@@ -0,0 +1,202 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/alfredchaos/demo/pkg/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.uber.org/zap"
+	"golang.org/x/sync/singleflight"
+	"gorm.io/gorm"
+)
+
+// defaultCountCacheTTL 行数缓存的默认有效期
+const defaultCountCacheTTL = 5 * time.Second
+
+// rowCountGauge 实体行数指标，按表名区分
+var rowCountGauge = promauto.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "db_table_rows",
+	Help: "Number of rows in a table, exact or estimated depending on configuration.",
+}, []string{"table"})
+
+// RowCounter 带短期缓存的表行数统计
+// 1. 缓存有效期内直接返回上次结果，避免在大表上频繁执行 COUNT(*)
+// 2. 缓存过期时并发调用合并为一次查询（singleflight），查询期间不持有锁
+// 3. 配置了近似阈值时，先读取 pg_class 中的估算值，超过阈值则直接使用估算值
+type RowCounter struct {
+	db        *gorm.DB
+	model     interface{}
+	ttl       time.Duration
+	threshold int64
+	cache     *countCache
+	inTx      bool // 绑定到事务连接，不读写缓存
+}
+
+// countCache 行数缓存，事务副本与原统计器共享
+type countCache struct {
+	mu         sync.Mutex
+	count      int64
+	expiresAt  time.Time
+	generation uint64 // 每次 Invalidate 加一，失效前开始的查询结果不再写入缓存
+
+	group singleflight.Group
+}
+
+// NewRowCounter 创建表行数统计器
+// model: 持久化对象，用于解析表名
+func NewRowCounter(db *gorm.DB, model interface{}, cfg *PostgresConfig) *RowCounter {
+	ttl := defaultCountCacheTTL
+	var threshold int64
+	if cfg != nil {
+		if cfg.CountCacheTTL > 0 {
+			ttl = time.Duration(cfg.CountCacheTTL) * time.Second
+		}
+		threshold = cfg.ApproximateCountThreshold
+	}
+
+	return &RowCounter{
+		db:        db,
+		model:     model,
+		ttl:       ttl,
+		threshold: threshold,
+		cache:     &countCache{},
+	}
+}
+
+// WithTx 返回在事务连接 tx 上统计的副本
+// 事务内的行数包含尚未提交的写入，不读取也不写入共享缓存；Invalidate 仍使共享缓存失效
+func (c *RowCounter) WithTx(tx *gorm.DB) *RowCounter {
+	txCounter := *c
+	txCounter.db = tx
+	txCounter.inTx = true
+	return &txCounter
+}
+
+// Count 获取表行数
+// 等待合并查询结果时遵循 ctx 的取消；合并的查询不随发起方取消而中断，但沿用其截止时间
+func (c *RowCounter) Count(ctx context.Context) (int64, error) {
+	if c.inTx {
+		return c.load(ctx)
+	}
+
+	c.cache.mu.Lock()
+	if time.Now().Before(c.cache.expiresAt) {
+		count := c.cache.count
+		c.cache.mu.Unlock()
+		return count, nil
+	}
+	generation := c.cache.generation
+	c.cache.mu.Unlock()
+
+	// 按缓存代数合并：Invalidate 之后的调用不会复用失效前开始的查询
+	result := c.cache.group.DoChan(strconv.FormatUint(generation, 10), func() (interface{}, error) {
+		loadCtx := context.WithoutCancel(ctx)
+		if deadline, ok := ctx.Deadline(); ok {
+			var cancel context.CancelFunc
+			loadCtx, cancel = context.WithDeadline(loadCtx, deadline)
+			defer cancel()
+		}
+
+		count, err := c.load(loadCtx)
+		if err != nil {
+			return int64(0), err
+		}
+
+		c.cache.mu.Lock()
+		if c.cache.generation == generation {
+			c.cache.count = count
+			c.cache.expiresAt = time.Now().Add(c.ttl)
+		}
+		c.cache.mu.Unlock()
+		return count, nil
+	})
+
+	select {
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	case r := <-result:
+		if r.Err != nil {
+			return 0, r.Err
+		}
+		return r.Val.(int64), nil
+	}
+}
+
+// Invalidate 使缓存的行数失效，写操作后调用以保证下次读取到最新值
+func (c *RowCounter) Invalidate() {
+	c.cache.mu.Lock()
+	defer c.cache.mu.Unlock()
+	c.cache.expiresAt = time.Time{}
+	c.cache.generation++
+}
+
+// load 从数据库读取行数
+func (c *RowCounter) load(ctx context.Context) (int64, error) {
+	if c.threshold > 0 {
+		estimate, err := c.estimate(ctx)
+		if err != nil {
+			return 0, err
+		}
+		if estimate >= c.threshold {
+			return estimate, nil
+		}
+	}
+
+	var count int64
+	if err := c.db.WithContext(ctx).Model(c.model).Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("failed to count rows: %w", err)
+	}
+	return count, nil
+}
+
+// estimate 读取 PostgreSQL 统计信息中的估算行数（由 ANALYZE/autovacuum 维护）
+func (c *RowCounter) estimate(ctx context.Context) (int64, error) {
+	table, err := c.tableName()
+	if err != nil {
+		return 0, err
+	}
+
+	var estimate int64
+	err = c.db.WithContext(ctx).
+		Raw("SELECT COALESCE(MAX(reltuples), 0)::bigint FROM pg_class WHERE oid = to_regclass(?)", table).
+		Scan(&estimate).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to estimate rows: %w", err)
+	}
+	return estimate, nil
+}
+
+// tableName 根据命名策略解析模型对应的表名
+func (c *RowCounter) tableName() (string, error) {
+	stmt := &gorm.Statement{DB: c.db}
+	if err := stmt.Parse(c.model); err != nil {
+		return "", fmt.Errorf("failed to parse model: %w", err)
+	}
+	return stmt.Schema.Table, nil
+}
+
+// ReportRowCount 周期性地将行数写入 db_table_rows 指标，直到 ctx 取消
+// 读取失败只记录警告，保留上一次的指标值
+func ReportRowCount(ctx context.Context, table string, interval time.Duration, count func(ctx context.Context) (int64, error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if n, err := count(ctx); err != nil {
+			log.WithContext(ctx).Warn("failed to report row count", zap.String("table", table), zap.Error(err))
+		} else {
+			rowCountGauge.WithLabelValues(table).Set(float64(n))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+// counterTestPO 行数统计测试用的模型
+type counterTestPO struct {
+	ID int
+}
+
+func TestRowCounter_CoalescesConcurrentCounts(t *testing.T) {
+	client, mock := newMockPostgresClient(t)
+	counter := NewRowCounter(client.GetDB(), &counterTestPO{}, nil)
+
+	// 只允许一次 COUNT，并发调用必须合并到同一次查询
+	mock.ExpectQuery(`SELECT count\(\*\) FROM "counter_test_pos"`).
+		WillDelayFor(50 * time.Millisecond).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(7))
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 10)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if n, err := counter.Count(context.Background()); err != nil || n != 7 {
+				errs <- errors.New("unexpected count result")
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	if err := <-errs; err != nil {
+		t.Fatal(err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRowCounter_WaiterHonoursOwnContext(t *testing.T) {
+	client, mock := newMockPostgresClient(t)
+	counter := NewRowCounter(client.GetDB(), &counterTestPO{}, nil)
+
+	mock.ExpectQuery(`SELECT count\(\*\) FROM "counter_test_pos"`).
+		WillDelayFor(500 * time.Millisecond).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(7))
+
+	leader := make(chan int64, 1)
+	go func() {
+		n, _ := counter.Count(context.Background())
+		leader <- n
+	}()
+	time.Sleep(20 * time.Millisecond)
+
+	// 等待中的调用方超时后立即返回，不必等合并的查询结束
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, err := counter.Count(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("want DeadlineExceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 200*time.Millisecond {
+		t.Fatalf("want waiter to return on its own deadline, took %v", elapsed)
+	}
+
+	if n := <-leader; n != 7 {
+		t.Fatalf("want leader count 7, got %d", n)
+	}
+}
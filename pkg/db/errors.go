@@ -0,0 +1,141 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+
+	apperrors "github.com/alfredchaos/demo/pkg/errors"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+// ErrorClass 数据库错误分类
+type ErrorClass int
+
+const (
+	// ErrorClassNone 没有错误
+	ErrorClassNone ErrorClass = iota
+	// ErrorClassUnknown 无法识别的数据库错误
+	ErrorClassUnknown
+	// ErrorClassNotFound 记录不存在
+	ErrorClassNotFound
+	// ErrorClassUniqueViolation 唯一约束冲突（23505）
+	ErrorClassUniqueViolation
+	// ErrorClassForeignKeyViolation 外键约束冲突（23503）
+	ErrorClassForeignKeyViolation
+	// ErrorClassConstraintViolation 其他完整性约束冲突（非空、检查约束等 23 类错误）
+	ErrorClassConstraintViolation
+	// ErrorClassConnection 连接错误（连接失败、连接中断、服务端关闭等）
+	ErrorClassConnection
+)
+
+// PostgreSQL SQLSTATE 错误码
+const (
+	pgUniqueViolation     = "23505"
+	pgForeignKeyViolation = "23503"
+)
+
+// String 返回错误分类名称，用于日志和指标
+func (c ErrorClass) String() string {
+	switch c {
+	case ErrorClassNone:
+		return "none"
+	case ErrorClassNotFound:
+		return "not_found"
+	case ErrorClassUniqueViolation:
+		return "unique_violation"
+	case ErrorClassForeignKeyViolation:
+		return "foreign_key_violation"
+	case ErrorClassConstraintViolation:
+		return "constraint_violation"
+	case ErrorClassConnection:
+		return "connection"
+	default:
+		return "unknown"
+	}
+}
+
+// Code 返回错误分类对应的应用错误码
+func (c ErrorClass) Code() apperrors.ErrorCode {
+	switch c {
+	case ErrorClassNone:
+		return apperrors.Success
+	case ErrorClassNotFound:
+		return apperrors.ErrNotFound
+	case ErrorClassUniqueViolation:
+		return apperrors.ErrConflict
+	case ErrorClassForeignKeyViolation, ErrorClassConstraintViolation:
+		return apperrors.ErrInvalidParams
+	case ErrorClassConnection:
+		return apperrors.ErrServiceUnavailable
+	default:
+		return apperrors.ErrDatabaseError
+	}
+}
+
+// ClassifyError 识别 GORM / PostgreSQL 驱动返回的错误类型
+// 支持被 fmt.Errorf 包装过的错误；开启 TranslateError 后 GORM 转换出的错误同样能识别
+func ClassifyError(err error) ErrorClass {
+	if err == nil {
+		return ErrorClassNone
+	}
+
+	switch {
+	// 上下文超时或取消由调用方处理，不视为连接错误（context.DeadlineExceeded 同样实现了 net.Error）
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, context.Canceled):
+		return ErrorClassUnknown
+	case errors.Is(err, gorm.ErrRecordNotFound), errors.Is(err, sql.ErrNoRows):
+		return ErrorClassNotFound
+	case errors.Is(err, gorm.ErrDuplicatedKey):
+		return ErrorClassUniqueViolation
+	case errors.Is(err, gorm.ErrForeignKeyViolated):
+		return ErrorClassForeignKeyViolation
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch {
+		case pgErr.Code == pgUniqueViolation:
+			return ErrorClassUniqueViolation
+		case pgErr.Code == pgForeignKeyViolation:
+			return ErrorClassForeignKeyViolation
+		case strings.HasPrefix(pgErr.Code, "23"):
+			return ErrorClassConstraintViolation
+		// 08 类为连接异常，57P01~57P03 为服务端关闭或尚未就绪
+		case strings.HasPrefix(pgErr.Code, "08"), strings.HasPrefix(pgErr.Code, "57P0"):
+			return ErrorClassConnection
+		default:
+			return ErrorClassUnknown
+		}
+	}
+
+	var connectErr *pgconn.ConnectError
+	var netErr net.Error
+	switch {
+	case errors.As(err, &connectErr), errors.As(err, &netErr),
+		errors.Is(err, driver.ErrBadConn), errors.Is(err, sql.ErrConnDone),
+		errors.Is(err, io.ErrUnexpectedEOF):
+		return ErrorClassConnection
+	}
+
+	return ErrorClassUnknown
+}
+
+// WrapError 按错误分类包装数据库错误
+// 可识别的错误包装为携带对应错误码的 AppError，无法识别的错误仅附加上下文信息
+func WrapError(err error, message string) error {
+	switch class := ClassifyError(err); class {
+	case ErrorClassNone:
+		return nil
+	case ErrorClassUnknown:
+		return fmt.Errorf("%s: %w", message, err)
+	default:
+		return apperrors.Wrap(class.Code(), message, err)
+	}
+}
@@ -0,0 +1,73 @@
+package db
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+
+	apperrors "github.com/alfredchaos/demo/pkg/errors"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want ErrorClass
+	}{
+		{"nil", nil, ErrorClassNone},
+		{"record not found", gorm.ErrRecordNotFound, ErrorClassNotFound},
+		{"wrapped record not found", fmt.Errorf("failed to get user: %w", gorm.ErrRecordNotFound), ErrorClassNotFound},
+		{"unique violation", &pgconn.PgError{Code: "23505"}, ErrorClassUniqueViolation},
+		{"translated duplicated key", gorm.ErrDuplicatedKey, ErrorClassUniqueViolation},
+		{"foreign key violation", fmt.Errorf("failed to create: %w", &pgconn.PgError{Code: "23503"}), ErrorClassForeignKeyViolation},
+		{"translated foreign key", gorm.ErrForeignKeyViolated, ErrorClassForeignKeyViolation},
+		{"not null violation", &pgconn.PgError{Code: "23502"}, ErrorClassConstraintViolation},
+		{"check violation", &pgconn.PgError{Code: "23514"}, ErrorClassConstraintViolation},
+		{"connection exception", &pgconn.PgError{Code: "08006"}, ErrorClassConnection},
+		{"admin shutdown", &pgconn.PgError{Code: "57P01"}, ErrorClassConnection},
+		{"bad conn", driver.ErrBadConn, ErrorClassConnection},
+		{"dial error", &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}, ErrorClassConnection},
+		{"syntax error", &pgconn.PgError{Code: "42601"}, ErrorClassUnknown},
+		{"deadline exceeded", fmt.Errorf("query: %w", context.DeadlineExceeded), ErrorClassUnknown},
+		{"plain error", errors.New("boom"), ErrorClassUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ClassifyError(tt.err); got != tt.want {
+				t.Fatalf("want %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestWrapError(t *testing.T) {
+	if err := WrapError(nil, "failed to create user"); err != nil {
+		t.Fatalf("want nil for nil error, got %v", err)
+	}
+
+	pgErr := &pgconn.PgError{Code: "23505"}
+	err := WrapError(pgErr, "failed to create user")
+	if apperrors.CodeOf(err) != apperrors.ErrConflict {
+		t.Fatalf("want conflict code, got %d", apperrors.CodeOf(err))
+	}
+	if !errors.Is(err, pgErr) {
+		t.Fatalf("want original error kept in chain, got %v", err)
+	}
+
+	if code := apperrors.CodeOf(WrapError(driver.ErrBadConn, "failed to list users")); code != apperrors.ErrServiceUnavailable {
+		t.Fatalf("want service unavailable for connection error, got %d", code)
+	}
+
+	// 无法识别的错误不携带错误码，按内部错误处理
+	plain := errors.New("boom")
+	err = WrapError(plain, "failed to list users")
+	if !errors.Is(err, plain) || apperrors.CodeOf(err) != apperrors.ErrInternalServer {
+		t.Fatalf("want plain wrapped error, got %v", err)
+	}
+}
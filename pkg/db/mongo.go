@@ -3,6 +3,7 @@ package db
 import (
 	"context"
 	"fmt"
+	"sync/atomic"
 	"time"
 
 	"github.com/alfredchaos/demo/pkg/log"
@@ -15,6 +16,7 @@ import (
 
 // MongoConfig MongoDB 配置
 type MongoConfig struct {
+	Enabled            *bool  `yaml:"enabled" mapstructure:"enabled"`                           // 是否启用，未配置时按 uri 是否为空决定，见 IsEnabled
 	URI                string `yaml:"uri" mapstructure:"uri"`                                   // MongoDB 连接 URI
 	Database           string `yaml:"database" mapstructure:"database"`                         // 数据库名称
 	MaxPoolSize        uint64 `yaml:"max_pool_size" mapstructure:"max_pool_size"`               // 最大连接池大小
@@ -25,11 +27,21 @@ type MongoConfig struct {
 	EnableDetailedLog  bool   `yaml:"enable_detailed_log" mapstructure:"enable_detailed_log"`   // 是否记录详细命令
 }
 
+// IsEnabled 是否启用 MongoDB
+// 显式配置 enabled 时以其为准；未配置时配置了 uri 即视为启用，兼容引入 enabled 之前的配置文件
+func (c *MongoConfig) IsEnabled() bool {
+	if c.Enabled != nil {
+		return *c.Enabled
+	}
+	return c.URI != ""
+}
+
 // MongoClient MongoDB 客户端封装
 type MongoClient struct {
 	client   *mongo.Client
 	database *mongo.Database
 	config   *MongoConfig
+	pool     *mongoPoolStats
 }
 
 // NewMongoClient 创建新的 MongoDB 客户端
@@ -43,6 +55,10 @@ func NewMongoClient(cfg *MongoConfig) (*MongoClient, error) {
 		SetMaxPoolSize(cfg.MaxPoolSize).
 		SetMinPoolSize(cfg.MinPoolSize)
 
+	// 驱动不直接暴露连接池状态，通过连接池事件自行计数
+	pool := &mongoPoolStats{}
+	clientOptions.SetPoolMonitor(pool.monitor())
+
 	// 配置命令监控（集成日志）
 	if cfg.LogLevel != "" && cfg.LogLevel != "silent" {
 		clientOptions.SetMonitor(newMongoCommandMonitor(cfg))
@@ -56,6 +72,7 @@ func NewMongoClient(cfg *MongoConfig) (*MongoClient, error) {
 
 	// 验证连接
 	if err := client.Ping(ctx, readpref.Primary()); err != nil {
+		client.Disconnect(context.Background())
 		return nil, fmt.Errorf("failed to ping mongodb: %w", err)
 	}
 
@@ -63,6 +80,7 @@ func NewMongoClient(cfg *MongoConfig) (*MongoClient, error) {
 		client:   client,
 		database: client.Database(cfg.Database),
 		config:   cfg,
+		pool:     pool,
 	}, nil
 }
 
@@ -91,6 +109,11 @@ func (mc *MongoClient) Close(ctx context.Context) error {
 
 // Ping 检查 MongoDB 连接是否正常
 func (mc *MongoClient) Ping(ctx context.Context) error {
+	return mc.PingContext(ctx)
+}
+
+// PingContext 检查 MongoDB 主节点是否可达，超时或取消由 ctx 控制
+func (mc *MongoClient) PingContext(ctx context.Context) error {
 	return mc.client.Ping(ctx, readpref.Primary())
 }
 
@@ -110,6 +133,18 @@ func (mc *MongoClient) WithTransaction(ctx context.Context, fn func(sessCtx mong
 	return err
 }
 
+// Stats 获取连接池统计信息
+// 各服务器的连接池合并计数
+func (mc *MongoClient) Stats() (map[string]interface{}, error) {
+	return map[string]interface{}{
+		"max_pool_size":     mc.config.MaxPoolSize,
+		"open_connections":  mc.pool.open.Load(),
+		"in_use":            mc.pool.inUse.Load(),
+		"checkout_failures": mc.pool.checkoutFailed.Load(),
+		"pool_cleared":      mc.pool.cleared.Load(),
+	}, nil
+}
+
 // MustNewMongoClient 创建 MongoDB 客户端,失败则 panic
 // 适用于服务启动阶段,数据库连接失败应该直接终止程序
 func MustNewMongoClient(cfg *MongoConfig) *MongoClient {
@@ -120,6 +155,36 @@ func MustNewMongoClient(cfg *MongoConfig) *MongoClient {
 	return client
 }
 
+// mongoPoolStats 由连接池事件维护的连接计数
+type mongoPoolStats struct {
+	open           atomic.Int64
+	inUse          atomic.Int64
+	checkoutFailed atomic.Int64
+	cleared        atomic.Int64
+}
+
+// monitor 创建更新计数的连接池监控器
+func (s *mongoPoolStats) monitor() *event.PoolMonitor {
+	return &event.PoolMonitor{
+		Event: func(evt *event.PoolEvent) {
+			switch evt.Type {
+			case event.ConnectionCreated:
+				s.open.Add(1)
+			case event.ConnectionClosed:
+				s.open.Add(-1)
+			case event.GetSucceeded:
+				s.inUse.Add(1)
+			case event.ConnectionReturned:
+				s.inUse.Add(-1)
+			case event.GetFailed:
+				s.checkoutFailed.Add(1)
+			case event.PoolCleared:
+				s.cleared.Add(1)
+			}
+		},
+	}
+}
+
 // ============================================================
 // MongoDB 命令监控器（集成现有的 log 包）
 // ============================================================
@@ -151,7 +216,7 @@ func newMongoCommandMonitor(cfg *MongoConfig) *event.CommandMonitor {
 			// 基础字段
 			fields := []zap.Field{
 				zap.String("command", evt.CommandName),
-				zap.Float64("duration_ms", float64(elapsed.Nanoseconds())/1e6),
+				log.DurationMs(elapsed.Milliseconds()),
 				zap.Int64("request_id", evt.RequestID),
 			}
 
@@ -181,7 +246,7 @@ func newMongoCommandMonitor(cfg *MongoConfig) *event.CommandMonitor {
 			contextLogger.Error("mongodb command failed",
 				zap.String("command", evt.CommandName),
 				zap.String("failure", evt.Failure),
-				zap.Float64("duration_ms", float64(elapsed.Nanoseconds())/1e6),
+				log.DurationMs(elapsed.Milliseconds()),
 				zap.Int64("request_id", evt.RequestID),
 			)
 		},
@@ -0,0 +1,82 @@
+package db
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// assertReturnsPromptly 断言 ping 在上下文取消后及时返回错误
+func assertReturnsPromptly(t *testing.T, ping func(ctx context.Context) error) {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if err := ping(ctx); err == nil {
+		t.Fatal("want error after context deadline")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("want ping to honour context deadline, took %v", elapsed)
+	}
+}
+
+// blackholeAddr 启动只接受连接、从不响应的 TCP 服务
+func blackholeAddr(t *testing.T) string {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			t.Cleanup(func() { conn.Close() })
+		}
+	}()
+	return ln.Addr().String()
+}
+
+func TestPostgresClient_PingContextRespectsCancellation(t *testing.T) {
+	sqlDB, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	defer sqlDB.Close()
+	mock.ExpectPing().WillDelayFor(5 * time.Second)
+
+	gdb, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{DisableAutomaticPing: true})
+	if err != nil {
+		t.Fatalf("failed to open gorm: %v", err)
+	}
+
+	pc := &PostgresClient{db: gdb}
+	assertReturnsPromptly(t, pc.PingContext)
+}
+
+func TestMongoClient_PingContextRespectsCancellation(t *testing.T) {
+	client, err := mongo.Connect(context.Background(), options.Client().
+		ApplyURI("mongodb://"+blackholeAddr(t)).
+		SetServerSelectionTimeout(10*time.Second))
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	defer client.Disconnect(context.Background())
+
+	mc := &MongoClient{client: client}
+	assertReturnsPromptly(t, mc.PingContext)
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"regexp"
+	"strings"
 	"time"
 
 	"github.com/alfredchaos/demo/pkg/log"
@@ -11,8 +13,12 @@ import (
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
+	"gorm.io/gorm/schema"
 )
 
+// persistentObjectSuffix 持久化对象的类型名后缀，生成表名时会被去掉（UserPgPO -> users）
+const persistentObjectSuffix = "PgPO"
+
 // PostgresConfig PostgreSQL 配置
 type PostgresConfig struct {
 	Enabled            bool   `yaml:"enabled" mapstructure:"enabled"`
@@ -30,6 +36,14 @@ type PostgresConfig struct {
 	LogLevel           string `yaml:"log_level" mapstructure:"log_level"`                       // 日志级别 (silent, error, warn, info)
 	SlowQueryThreshold int    `yaml:"slow_query_threshold" mapstructure:"slow_query_threshold"` // 慢查询阈值(毫秒)，默认200ms
 	EnableDetailedLog  bool   `yaml:"enable_detailed_log" mapstructure:"enable_detailed_log"`   // 是否启用详细日志（记录SQL和参数）
+	QueryIDComment     bool   `yaml:"query_id_comment" mapstructure:"query_id_comment"`         // 是否以 /* qid:xxx */ 注释将查询 ID 写入 SQL（可在 pg_stat_activity 中看到），开启后 pgx 语句缓存失效，仅在排查时开启
+	QueryTimeout       int    `yaml:"query_timeout" mapstructure:"query_timeout"`               // 仓库单次调用的默认超时(毫秒)，0 表示不限制，可通过 reqctx.WithDBTimeout 按调用覆盖
+	TablePrefix        string `yaml:"table_prefix" mapstructure:"table_prefix"`                 // 表名前缀（需与迁移文件中的表名保持一致）
+	SingularTable      bool   `yaml:"singular_table" mapstructure:"singular_table"`             // 是否使用单数表名
+
+	CountCacheTTL             int   `yaml:"count_cache_ttl" mapstructure:"count_cache_ttl"`                         // 行数统计缓存时间(秒)，默认5秒
+	ApproximateCountThreshold int64 `yaml:"approximate_count_threshold" mapstructure:"approximate_count_threshold"` // 估算行数超过此值时直接返回估算值，0 表示始终精确统计
+	CountReportInterval       int   `yaml:"count_report_interval" mapstructure:"count_report_interval"`             // 行数指标上报间隔(秒)，0 表示不上报
 }
 
 // PostgresClient PostgreSQL 客户端封装
@@ -54,7 +68,8 @@ func NewPostgresClient(cfg *PostgresConfig) (*PostgresClient, error) {
 
 	// 配置 GORM 自定义 Logger（集成现有的 log 包）
 	gormConfig := &gorm.Config{
-		Logger: NewGormLogger(cfg),
+		Logger:         NewGormLogger(cfg),
+		NamingStrategy: NewNamingStrategy(cfg),
 		// 禁用外键约束检查 (可根据需求调整)
 		DisableForeignKeyConstraintWhenMigrating: true,
 	}
@@ -65,6 +80,11 @@ func NewPostgresClient(cfg *PostgresConfig) (*PostgresClient, error) {
 		return nil, fmt.Errorf("failed to connect to postgresql: %w", err)
 	}
 
+	// 为每条语句生成查询 ID，用于关联日志与数据库侧的语句
+	if err := db.Use(&QueryIDPlugin{Comment: cfg.QueryIDComment}); err != nil {
+		return nil, fmt.Errorf("failed to register query id plugin: %w", err)
+	}
+
 	// 获取底层的 *sql.DB 用于配置连接池
 	sqlDB, err := db.DB()
 	if err != nil {
@@ -87,6 +107,7 @@ func NewPostgresClient(cfg *PostgresConfig) (*PostgresClient, error) {
 
 	// 验证连接
 	if err := sqlDB.Ping(); err != nil {
+		sqlDB.Close()
 		return nil, fmt.Errorf("failed to ping postgresql: %w", err)
 	}
 
@@ -96,6 +117,17 @@ func NewPostgresClient(cfg *PostgresConfig) (*PostgresClient, error) {
 	}, nil
 }
 
+// NewNamingStrategy 创建统一的 GORM 命名策略
+// 表名由持久化对象类型名推导：去掉 PgPO 后缀、转为 snake_case、复数化并加上前缀
+// 例如 UserPgPO -> users，BookPgPO -> books
+func NewNamingStrategy(cfg *PostgresConfig) schema.Namer {
+	return schema.NamingStrategy{
+		TablePrefix:   cfg.TablePrefix,
+		SingularTable: cfg.SingularTable,
+		NameReplacer:  strings.NewReplacer(persistentObjectSuffix, ""),
+	}
+}
+
 // GetDB 获取 GORM DB 实例
 func (pc *PostgresClient) GetDB() *gorm.DB {
 	return pc.db
@@ -114,12 +146,18 @@ func (pc *PostgresClient) Close() error {
 }
 
 // Ping 检查 PostgreSQL 连接是否正常
+// Deprecated: 使用 PingContext，以便调用方控制超时
 func (pc *PostgresClient) Ping() error {
+	return pc.PingContext(context.Background())
+}
+
+// PingContext 检查 PostgreSQL 连接是否正常，超时或取消由 ctx 控制
+func (pc *PostgresClient) PingContext(ctx context.Context) error {
 	sqlDB, err := pc.db.DB()
 	if err != nil {
 		return fmt.Errorf("failed to get sql.DB: %w", err)
 	}
-	return sqlDB.Ping()
+	return sqlDB.PingContext(ctx)
 }
 
 // Transaction 在事务中执行操作
@@ -235,21 +273,51 @@ func (l *GormLogger) Error(ctx context.Context, msg string, data ...interface{})
 }
 
 // Trace 记录 SQL 执行详情
+// 设置了 QueryTracer（见 SetQueryTracer）时同时上报查询 span，不受日志级别影响
 func (l *GormLogger) Trace(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
-	if l.logLevel <= logger.Silent {
+	tracer := currentQueryTracer()
+	if l.logLevel <= logger.Silent && tracer == nil {
 		return
 	}
 
 	elapsed := time.Since(begin)
 	sql, rows := fc()
 
-	// 使用 log.WithContext 自动提取上下文信息（trace_id、request_id、user_id 等）
-	contextLogger := log.WithContext(ctx).WithOptions(zap.AddCallerSkip(3))
+	// 查询 ID 由 QueryIDPlugin 在执行前生成（与 SQL 注释一致），未注册插件时在此生成
+	queryID, ok := QueryIDFromContext(ctx)
+	if !ok {
+		queryID = newQueryID()
+	}
+	operation, table := parseSQLOperation(sql)
+
+	if tracer != nil {
+		tracer.TraceQuery(ctx, TracedQuery{
+			Operation:    operation,
+			Table:        table,
+			Statement:    redactSQL(sql),
+			QueryID:      queryID,
+			Start:        begin,
+			Duration:     elapsed,
+			RowsAffected: rows,
+			Err:          err,
+		})
+	}
+	if l.logLevel <= logger.Silent {
+		return
+	}
+
+	// 复用请求入口绑定的 logger（已带 trace_id、request_id 等），不在每条 SQL 上重新提取字段
+	contextLogger := log.FromContext(ctx).WithOptions(zap.AddCallerSkip(3))
 
 	// 基础字段
 	fields := []zap.Field{
-		zap.Float64("duration_ms", float64(elapsed.Nanoseconds())/1e6),
+		zap.String(QueryIDField, queryID),
+		log.DurationMs(elapsed.Milliseconds()),
 		zap.Int64("rows_affected", rows),
+		zap.String("operation", operation),
+	}
+	if table != "" {
+		fields = append(fields, zap.String("table", table))
 	}
 
 	// 根据配置决定是否记录 SQL
@@ -276,3 +344,44 @@ func (l *GormLogger) Trace(ctx context.Context, begin time.Time, fc func() (sql
 		contextLogger.Info("postgres query", fields...)
 	}
 }
+
+// sqlIdentifier 匹配可选 schema 前缀、可带双引号的表名
+const sqlIdentifier = `((?:"[^"]+"|\w+)(?:\.(?:"[^"]+"|\w+))?)`
+
+// SQL 语句中目标表的匹配规则
+var (
+	insertTableRe = regexp.MustCompile(`(?i)^INSERT\s+INTO\s+` + sqlIdentifier)
+	updateTableRe = regexp.MustCompile(`(?i)^UPDATE\s+(?:ONLY\s+)?` + sqlIdentifier)
+	deleteTableRe = regexp.MustCompile(`(?i)^DELETE\s+FROM\s+(?:ONLY\s+)?` + sqlIdentifier)
+	selectTableRe = regexp.MustCompile(`(?i)\bFROM\s+` + sqlIdentifier)
+)
+
+// parseSQLOperation 解析 SQL 的操作类型（SELECT/INSERT/UPDATE/DELETE 等）和目标表
+// 无法确定表名时（如 SELECT 1、事务语句）table 为空
+func parseSQLOperation(sql string) (operation, table string) {
+	sql = strings.TrimSpace(sql)
+	if sql == "" {
+		return "UNKNOWN", ""
+	}
+
+	operation = strings.ToUpper(strings.Fields(sql)[0])
+
+	var re *regexp.Regexp
+	switch operation {
+	case "INSERT":
+		re = insertTableRe
+	case "UPDATE":
+		re = updateTableRe
+	case "DELETE":
+		re = deleteTableRe
+	case "SELECT":
+		re = selectTableRe
+	default:
+		return operation, ""
+	}
+
+	if m := re.FindStringSubmatch(sql); m != nil {
+		table = strings.ReplaceAll(m[1], `"`, "")
+	}
+	return operation, table
+}
@@ -0,0 +1,56 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alfredchaos/demo/pkg/log"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestGormLogger_TraceLogsOperationAndTable(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	prev := log.Logger
+	log.Logger = zap.New(core)
+	t.Cleanup(func() { log.Logger = prev })
+	gormLogger := NewGormLogger(&PostgresConfig{LogLevel: "info"})
+
+	cases := []struct {
+		sql       string
+		operation string
+		table     string
+	}{
+		{`SELECT * FROM "users" WHERE id = 'u1' LIMIT 1`, "SELECT", "users"},
+		{`INSERT INTO "users" ("id","username") VALUES ('u1','alice')`, "INSERT", "users"},
+		{`UPDATE "public"."books" SET "bookname"='Go' WHERE id = 'b1'`, "UPDATE", "public.books"},
+		{`DELETE FROM users WHERE id = 'u1'`, "DELETE", "users"},
+		{`select count(*) from (SELECT id FROM users) t`, "SELECT", "users"},
+		{`SELECT 1`, "SELECT", ""},
+	}
+
+	for _, c := range cases {
+		sql := c.sql
+		gormLogger.Trace(context.Background(), time.Now(), func() (string, int64) { return sql, 1 }, nil)
+	}
+
+	entries := logs.All()
+	if len(entries) != len(cases) {
+		t.Fatalf("want %d log entries, got %d", len(cases), len(entries))
+	}
+	for i, c := range cases {
+		fields := entries[i].ContextMap()
+		if fields["operation"] != c.operation {
+			t.Errorf("%q: want operation %s, got %v", c.sql, c.operation, fields["operation"])
+		}
+		table, ok := fields["table"]
+		if c.table == "" && ok {
+			t.Errorf("%q: want no table field, got %v", c.sql, table)
+		}
+		if c.table != "" && table != c.table {
+			t.Errorf("%q: want table %s, got %v", c.sql, c.table, table)
+		}
+	}
+}
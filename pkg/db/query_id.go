@@ -0,0 +1,111 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math/rand/v2"
+
+	"gorm.io/gorm"
+)
+
+// QueryIDField 日志中记录查询 ID 的字段名
+const QueryIDField = "db.query_id"
+
+// queryIDKey 查询 ID 在 Statement.Context 中的键
+type queryIDKey struct{}
+
+// QueryIDFromContext 获取当前语句的查询 ID
+func QueryIDFromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(queryIDKey{}).(string)
+	return id, ok && id != ""
+}
+
+// newQueryID 生成 8 位十六进制的短查询 ID，仅用于关联日志与数据库侧的语句，不要求全局唯一
+func newQueryID() string {
+	return fmt.Sprintf("%08x", rand.Uint32())
+}
+
+// QueryIDPlugin 为每条语句生成查询 ID 的 GORM 插件
+// 查询 ID 写入 Statement.Context，GormLogger.Trace 记录到 db.query_id 字段；
+// Comment 为 true 时同时以 /* qid:xxx */ 注释的形式加到 SQL 前面，便于在 pg_stat_activity 中定位慢查询
+//
+// 注意：开启注释后每条语句的 SQL 文本都不相同，pgx 按 SQL 文本缓存的预处理语句永远无法命中，
+// 每次查询都会多一次 Parse/Describe 往返，并不断挤出缓存中的其他语句。因此 Comment 默认关闭，
+// 只在排查慢查询时临时开启；日志中的 db.query_id 不依赖注释
+type QueryIDPlugin struct {
+	Comment bool
+}
+
+// Name 插件名称
+func (p *QueryIDPlugin) Name() string {
+	return "query_id"
+}
+
+// Initialize 在各主回调前后注册生成查询 ID 及还原连接池的回调
+// 写操作的回调限定在事务开启之后、提交之前，确保事务操作使用未包装的连接池
+func (p *QueryIDPlugin) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+	for _, err := range []error{
+		cb.Create().After("gorm:begin_transaction").Before("gorm:create").Register("query_id:before_create", p.before),
+		cb.Create().After("gorm:create").Before("gorm:commit_or_rollback_transaction").Register("query_id:after_create", p.after),
+		cb.Query().Before("gorm:query").Register("query_id:before_query", p.before),
+		cb.Query().After("gorm:query").Register("query_id:after_query", p.after),
+		cb.Update().After("gorm:begin_transaction").Before("gorm:update").Register("query_id:before_update", p.before),
+		cb.Update().After("gorm:update").Before("gorm:commit_or_rollback_transaction").Register("query_id:after_update", p.after),
+		cb.Delete().After("gorm:begin_transaction").Before("gorm:delete").Register("query_id:before_delete", p.before),
+		cb.Delete().After("gorm:delete").Before("gorm:commit_or_rollback_transaction").Register("query_id:after_delete", p.after),
+		cb.Row().Before("gorm:row").Register("query_id:before_row", p.before),
+		cb.Row().After("gorm:row").Register("query_id:after_row", p.after),
+		cb.Raw().Before("gorm:raw").Register("query_id:before_raw", p.before),
+		cb.Raw().After("gorm:raw").Register("query_id:after_raw", p.after),
+	} {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// before 生成查询 ID；开启注释时包装连接池，在执行时为 SQL 加上注释
+func (p *QueryIDPlugin) before(db *gorm.DB) {
+	ctx := db.Statement.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	id := newQueryID()
+	db.Statement.Context = context.WithValue(ctx, queryIDKey{}, id)
+
+	if p.Comment && db.Statement.ConnPool != nil {
+		db.Statement.ConnPool = &commentConnPool{ConnPool: db.Statement.ConnPool, comment: "/* qid:" + id + " */ "}
+	}
+}
+
+// after 还原被包装的连接池
+func (p *QueryIDPlugin) after(db *gorm.DB) {
+	if pool, ok := db.Statement.ConnPool.(*commentConnPool); ok {
+		db.Statement.ConnPool = pool.ConnPool
+	}
+}
+
+// commentConnPool 为执行的 SQL 加上注释的连接池
+type commentConnPool struct {
+	gorm.ConnPool
+	comment string
+}
+
+func (p *commentConnPool) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	return p.ConnPool.PrepareContext(ctx, p.comment+query)
+}
+
+func (p *commentConnPool) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return p.ConnPool.ExecContext(ctx, p.comment+query, args...)
+}
+
+func (p *commentConnPool) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return p.ConnPool.QueryContext(ctx, p.comment+query, args...)
+}
+
+func (p *commentConnPool) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return p.ConnPool.QueryRowContext(ctx, p.comment+query, args...)
+}
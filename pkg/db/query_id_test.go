@@ -0,0 +1,143 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/alfredchaos/demo/pkg/log"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// widgetPgPO 测试用持久化对象
+type widgetPgPO struct {
+	ID   string
+	Name string
+}
+
+func (widgetPgPO) TableName() string { return "widgets" }
+
+// queryIDTestDB 注册了 QueryIDPlugin 的 sqlmock 连接，记录实际执行的 SQL 和日志
+type queryIDTestDB struct {
+	gdb      *gorm.DB
+	mock     sqlmock.Sqlmock
+	logs     *observer.ObservedLogs
+	executed []string
+}
+
+func newQueryIDTestDB(t *testing.T, comment bool) *queryIDTestDB {
+	t.Helper()
+
+	core, logs := observer.New(zapcore.InfoLevel)
+	prev := log.Logger
+	log.Logger = zap.New(core)
+	t.Cleanup(func() { log.Logger = prev })
+
+	tdb := &queryIDTestDB{logs: logs}
+	// 按正则匹配预期 SQL，同时记录实际执行的 SQL
+	matcher := sqlmock.QueryMatcherFunc(func(expected, actual string) error {
+		tdb.executed = append(tdb.executed, actual)
+		if !regexp.MustCompile(expected).MatchString(actual) {
+			return fmt.Errorf("sql %q does not match %q", actual, expected)
+		}
+		return nil
+	})
+
+	sqlDB, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(matcher))
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { _ = sqlDB.Close() })
+
+	gdb, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{
+		Logger: NewGormLogger(&PostgresConfig{LogLevel: "info"}),
+	})
+	if err != nil {
+		t.Fatalf("failed to open gorm: %v", err)
+	}
+	if err := gdb.Use(&QueryIDPlugin{Comment: comment}); err != nil {
+		t.Fatalf("failed to register query id plugin: %v", err)
+	}
+
+	tdb.gdb, tdb.mock = gdb, mock
+	return tdb
+}
+
+// loggedQueryIDs 按顺序返回查询日志中的查询 ID
+func (tdb *queryIDTestDB) loggedQueryIDs(t *testing.T) []string {
+	t.Helper()
+	var ids []string
+	for _, entry := range tdb.logs.FilterMessage("postgres query").All() {
+		id, _ := entry.ContextMap()[QueryIDField].(string)
+		if len(id) != 8 {
+			t.Fatalf("want 8-char query id logged, got %q", id)
+		}
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+func TestQueryIDPlugin_CommentMatchesLoggedID(t *testing.T) {
+	tdb := newQueryIDTestDB(t, true)
+	ctx := context.Background()
+
+	tdb.mock.ExpectBegin()
+	tdb.mock.ExpectExec(`^/\* qid:[0-9a-f]{8} \*/ INSERT INTO "widgets"`).
+		WithArgs("w1", "gear").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	tdb.mock.ExpectCommit()
+	tdb.mock.ExpectQuery(`^/\* qid:[0-9a-f]{8} \*/ SELECT \* FROM "widgets" WHERE id = \$1`).
+		WithArgs("w1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow("w1", "gear"))
+
+	// 写操作在事务中执行，验证包装连接池不影响提交
+	if err := tdb.gdb.WithContext(ctx).Create(&widgetPgPO{ID: "w1", Name: "gear"}).Error; err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	var row widgetPgPO
+	if err := tdb.gdb.WithContext(ctx).Where("id = ?", "w1").Find(&row).Error; err != nil {
+		t.Fatalf("query: %v", err)
+	}
+	if err := tdb.mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+
+	ids := tdb.loggedQueryIDs(t)
+	if len(ids) != 2 || len(tdb.executed) != 2 {
+		t.Fatalf("want 2 logged and executed statements, got %v and %v", ids, tdb.executed)
+	}
+	if ids[0] == ids[1] {
+		t.Fatalf("want distinct query ids per statement, got %v", ids)
+	}
+	for i, id := range ids {
+		if !strings.HasPrefix(tdb.executed[i], "/* qid:"+id+" */ ") {
+			t.Fatalf("want sql %q to carry logged query id %s", tdb.executed[i], id)
+		}
+	}
+}
+
+func TestQueryIDPlugin_WithoutComment(t *testing.T) {
+	tdb := newQueryIDTestDB(t, false)
+
+	tdb.mock.ExpectQuery(`^SELECT \* FROM "widgets"`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}))
+
+	var rows []widgetPgPO
+	if err := tdb.gdb.Find(&rows).Error; err != nil {
+		t.Fatalf("query: %v", err)
+	}
+	if err := tdb.mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+
+	if ids := tdb.loggedQueryIDs(t); len(ids) != 1 {
+		t.Fatalf("want query id logged without sql comment, got %v", ids)
+	}
+}
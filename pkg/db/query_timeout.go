@@ -0,0 +1,30 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/alfredchaos/demo/pkg/reqctx"
+)
+
+// GetQueryTimeout 单条查询的默认超时，cfg 为 nil 或未配置时返回 0（不限制）
+func (c *PostgresConfig) GetQueryTimeout() time.Duration {
+	if c == nil || c.QueryTimeout <= 0 {
+		return 0
+	}
+	return time.Duration(c.QueryTimeout) * time.Millisecond
+}
+
+// QueryContext 为单次仓库调用设置查询超时
+// ctx 中通过 reqctx.WithDBTimeout 设置的超时优先于 defaultTimeout，最终超时 <= 0 时不设置超时
+// 超时只会缩短 ctx 的截止时间，不会延长上游已有的截止时间
+func QueryContext(ctx context.Context, defaultTimeout time.Duration) (context.Context, context.CancelFunc) {
+	timeout := defaultTimeout
+	if override, ok := reqctx.GetDBTimeout(ctx); ok {
+		timeout = override
+	}
+	if timeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, timeout)
+}
@@ -0,0 +1,57 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alfredchaos/demo/pkg/reqctx"
+)
+
+func TestQueryContext(t *testing.T) {
+	tests := []struct {
+		name           string
+		ctx            context.Context
+		defaultTimeout time.Duration
+		want           time.Duration // 0 表示不应设置截止时间
+	}{
+		{name: "no timeout", ctx: context.Background()},
+		{name: "client default", ctx: context.Background(), defaultTimeout: time.Second, want: time.Second},
+		{name: "context override", ctx: reqctx.WithDBTimeout(context.Background(), time.Minute), defaultTimeout: time.Second, want: time.Minute},
+		{name: "override disables default", ctx: reqctx.WithDBTimeout(context.Background(), 0), defaultTimeout: time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := QueryContext(tt.ctx, tt.defaultTimeout)
+			defer cancel()
+
+			deadline, ok := ctx.Deadline()
+			if tt.want == 0 {
+				if ok {
+					t.Fatalf("want no deadline, got %v", time.Until(deadline))
+				}
+				return
+			}
+			if !ok {
+				t.Fatal("want deadline to be set")
+			}
+			if remaining := time.Until(deadline); remaining > tt.want || remaining < tt.want-time.Second {
+				t.Fatalf("want deadline in about %v, got %v", tt.want, remaining)
+			}
+		})
+	}
+}
+
+func TestQueryContext_DoesNotExtendParentDeadline(t *testing.T) {
+	parent, cancelParent := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancelParent()
+
+	ctx, cancel := QueryContext(reqctx.WithDBTimeout(parent, time.Minute), 0)
+	defer cancel()
+
+	deadline, _ := ctx.Deadline()
+	if remaining := time.Until(deadline); remaining > 50*time.Millisecond {
+		t.Fatalf("want parent deadline kept, got %v", remaining)
+	}
+}
@@ -0,0 +1,70 @@
+package db
+
+import (
+	"context"
+	"regexp"
+	"sync/atomic"
+	"time"
+)
+
+// TracedQuery 一次已完成的 SQL 查询，由 GormLogger.Trace 交给 QueryTracer
+type TracedQuery struct {
+	Operation    string        // 操作类型，如 SELECT、INSERT
+	Table        string        // 目标表，无法确定时为空
+	Statement    string        // 脱敏后的 SQL，字面量替换为 ?
+	QueryID      string        // 查询 ID，与日志中的 db.query_id 一致
+	Start        time.Time     // 开始时间
+	Duration     time.Duration // 耗时
+	RowsAffected int64         // 影响行数
+	Err          error         // 查询错误
+}
+
+// SpanName 返回 span 名称：{operation} {table}，无法确定表名时只有操作类型
+func (q TracedQuery) SpanName() string {
+	if q.Table == "" {
+		return q.Operation
+	}
+	return q.Operation + " " + q.Table
+}
+
+// QueryTracer 数据库查询的追踪后端
+// 在 ctx 的活动 span 下为查询创建子 span。接入 OpenTelemetry 时以
+// tracer.Start(ctx, q.SpanName(), trace.WithTimestamp(q.Start)) 创建 span，
+// 设置 db.system、db.statement 等属性后以 span.End(trace.WithTimestamp(q.Start.Add(q.Duration))) 结束
+type QueryTracer interface {
+	TraceQuery(ctx context.Context, q TracedQuery)
+}
+
+// queryTracer 全局查询追踪后端，未设置时不追踪
+var queryTracer atomic.Pointer[QueryTracer]
+
+// SetQueryTracer 设置全局查询追踪后端，所有 GormLogger 共享；传入 nil 关闭追踪
+// 这是接入链路追踪的扩展点：项目尚未引入 OpenTelemetry，各服务启动时不会调用，默认不上报查询 span
+func SetQueryTracer(t QueryTracer) {
+	if t == nil {
+		queryTracer.Store(nil)
+		return
+	}
+	queryTracer.Store(&t)
+}
+
+// currentQueryTracer 返回当前的查询追踪后端，未设置时返回 nil
+func currentQueryTracer() QueryTracer {
+	if t := queryTracer.Load(); t != nil {
+		return *t
+	}
+	return nil
+}
+
+// SQL 字面量的匹配规则
+var (
+	sqlStringLiteralRe  = regexp.MustCompile(`'(?:[^']|'')*'`)
+	sqlNumericLiteralRe = regexp.MustCompile(`\b\d+(?:\.\d+)?\b`)
+)
+
+// redactSQL 将 SQL 中的字符串和数字字面量替换为 ?
+// GORM 交给 Logger 的 SQL 已内联参数值，写入 span 前需去掉其中可能包含的用户数据
+func redactSQL(sql string) string {
+	sql = sqlStringLiteralRe.ReplaceAllString(sql, "?")
+	return sqlNumericLiteralRe.ReplaceAllString(sql, "?")
+}
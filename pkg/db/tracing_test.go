@@ -0,0 +1,104 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordedSpan 内存记录的 span
+type recordedSpan struct {
+	name   string
+	parent string
+	query  TracedQuery
+}
+
+// parentSpanKey 活动 span 在 context 中的键
+type parentSpanKey struct{}
+
+// spanRecorder 内存 span 记录器，以 context 中的活动 span 作为父 span
+type spanRecorder struct {
+	mu    sync.Mutex
+	spans []recordedSpan
+}
+
+func (r *spanRecorder) TraceQuery(ctx context.Context, q TracedQuery) {
+	parent, _ := ctx.Value(parentSpanKey{}).(string)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.spans = append(r.spans, recordedSpan{name: q.SpanName(), parent: parent, query: q})
+}
+
+// useQueryTracer 在测试期间设置全局查询追踪后端
+func useQueryTracer(t *testing.T, tracer QueryTracer) {
+	t.Helper()
+	SetQueryTracer(tracer)
+	t.Cleanup(func() { SetQueryTracer(nil) })
+}
+
+func TestGormLogger_TraceCreatesChildSpan(t *testing.T) {
+	recorder := &spanRecorder{}
+	useQueryTracer(t, recorder)
+
+	// 日志静默时仍上报 span
+	gormLogger := NewGormLogger(&PostgresConfig{LogLevel: "silent"})
+	ctx := context.WithValue(context.Background(), parentSpanKey{}, "request-span")
+	begin := time.Now().Add(-15 * time.Millisecond)
+	queryErr := errors.New("boom")
+
+	gormLogger.Trace(ctx, begin, func() (string, int64) {
+		return `SELECT * FROM "users" WHERE email = 'alice@example.com' AND age > 42 LIMIT 1`, 1
+	}, queryErr)
+
+	if len(recorder.spans) != 1 {
+		t.Fatalf("want 1 span, got %d", len(recorder.spans))
+	}
+	span := recorder.spans[0]
+	if span.parent != "request-span" {
+		t.Fatalf("want span under the active parent, got parent %q", span.parent)
+	}
+	if span.name != "SELECT users" {
+		t.Fatalf("want span name %q, got %q", "SELECT users", span.name)
+	}
+	if want := `SELECT * FROM "users" WHERE email = ? AND age > ? LIMIT ?`; span.query.Statement != want {
+		t.Fatalf("want redacted statement %q, got %q", want, span.query.Statement)
+	}
+	if span.query.Duration < 15*time.Millisecond || span.query.RowsAffected != 1 || span.query.QueryID == "" {
+		t.Fatalf("unexpected traced query: %+v", span.query)
+	}
+	if !errors.Is(span.query.Err, queryErr) {
+		t.Fatalf("want query error recorded, got %v", span.query.Err)
+	}
+}
+
+func TestGormLogger_TraceNoopWithoutTracer(t *testing.T) {
+	gormLogger := NewGormLogger(&PostgresConfig{LogLevel: "silent"})
+
+	called := false
+	gormLogger.Trace(context.Background(), time.Now(), func() (string, int64) {
+		called = true
+		return "SELECT 1", 0
+	}, nil)
+
+	if called {
+		t.Fatal("want SQL not built when logging is silent and no tracer is configured")
+	}
+}
+
+func TestRedactSQL(t *testing.T) {
+	cases := []struct {
+		sql  string
+		want string
+	}{
+		{`INSERT INTO "users" ("id","username") VALUES ('u1','o''brien')`, `INSERT INTO "users" ("id","username") VALUES (?,?)`},
+		{`UPDATE books SET price=9.99 WHERE id = 'b1'`, `UPDATE books SET price=? WHERE id = ?`},
+		{`SELECT * FROM t1 LIMIT 10`, `SELECT * FROM t1 LIMIT ?`},
+	}
+	for _, c := range cases {
+		if got := redactSQL(c.sql); got != c.want {
+			t.Errorf("redactSQL(%q) = %q, want %q", c.sql, got, c.want)
+		}
+	}
+}
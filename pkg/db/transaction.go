@@ -0,0 +1,93 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/alfredchaos/demo/pkg/log"
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// PostgreSQL 事务冲突的 SQLSTATE 错误码，出现时整个事务应当重试
+const (
+	pgSerializationFailure = "40001"
+	pgDeadlockDetected     = "40P01"
+)
+
+// 事务重试退避参数，每次重试等待时间翻倍并加入随机抖动，避免冲突的事务同时重试再次冲突
+var (
+	txRetryInitialBackoff = 20 * time.Millisecond
+	txRetryMaxBackoff     = 1 * time.Second
+)
+
+// TxOptions 事务选项
+//
+// PostgreSQL 支持的隔离级别：
+//   - sql.LevelReadCommitted（默认）：每条语句看到语句开始时已提交的数据，不会因并发冲突中止
+//   - sql.LevelRepeatableRead：整个事务使用同一快照，并发更新同一行时以 40001 中止
+//   - sql.LevelSerializable：保证与串行执行等价，存在读写依赖冲突时以 40001 中止
+//
+// 后两种级别必须配合 TransactionWithRetry 使用，由调用方重试被中止的事务
+type TxOptions struct {
+	Isolation sql.IsolationLevel // 隔离级别，零值为数据库默认级别（READ COMMITTED）
+	ReadOnly  bool               // 是否为只读事务
+}
+
+// sqlTxOptions 转换为 database/sql 的事务选项，nil 时不传入选项以使用默认值
+func (o *TxOptions) sqlTxOptions() []*sql.TxOptions {
+	if o == nil {
+		return nil
+	}
+	return []*sql.TxOptions{{Isolation: o.Isolation, ReadOnly: o.ReadOnly}}
+}
+
+// IsRetryableTxError 判断错误是否为可通过重试整个事务解决的冲突（序列化失败或死锁）
+func IsRetryableTxError(err error) bool {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return false
+	}
+	return pgErr.Code == pgSerializationFailure || pgErr.Code == pgDeadlockDetected
+}
+
+// TransactionWithRetry 在事务中执行操作，遇到序列化失败（40001）或死锁（40P01）时回滚并重试
+// maxRetries 为首次执行之外的最大重试次数；opts 为 nil 时使用默认隔离级别
+// fn 可能被执行多次，不能包含事务外的副作用（如发送消息），应在事务提交后再执行
+func (pc *PostgresClient) TransactionWithRetry(ctx context.Context, fn func(tx *gorm.DB) error, maxRetries int, opts *TxOptions) error {
+	backoff := txRetryInitialBackoff
+	for attempt := 0; ; attempt++ {
+		err := pc.db.WithContext(ctx).Transaction(fn, opts.sqlTxOptions()...)
+		if err == nil || !IsRetryableTxError(err) {
+			return err
+		}
+		if attempt >= maxRetries {
+			return fmt.Errorf("transaction failed after %d retries: %w", maxRetries, err)
+		}
+
+		wait := backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1))
+		log.WithContext(ctx).Warn("retrying transaction after conflict",
+			zap.Int("attempt", attempt+1),
+			zap.Int("max_retries", maxRetries),
+			zap.Duration("backoff", wait),
+			zap.Error(err))
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("transaction retry canceled: %w", err)
+		case <-timer.C:
+		}
+
+		backoff *= 2
+		if backoff > txRetryMaxBackoff {
+			backoff = txRetryMaxBackoff
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/alfredchaos/demo/pkg/log"
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.uber.org/zap"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// newMockPostgresClient 基于 sqlmock 创建 PostgreSQL 客户端，并缩短事务重试退避
+func newMockPostgresClient(t *testing.T) (*PostgresClient, sqlmock.Sqlmock) {
+	t.Helper()
+	prev := log.Logger
+	log.Logger = zap.NewNop()
+	t.Cleanup(func() { log.Logger = prev })
+
+	sqlDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+
+	gdb, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("failed to open gorm: %v", err)
+	}
+
+	initial, max := txRetryInitialBackoff, txRetryMaxBackoff
+	txRetryInitialBackoff, txRetryMaxBackoff = time.Millisecond, 2*time.Millisecond
+	t.Cleanup(func() { txRetryInitialBackoff, txRetryMaxBackoff = initial, max })
+
+	return &PostgresClient{db: gdb}, mock
+}
+
+// transfer 在事务中执行一条更新语句
+func transfer(tx *gorm.DB) error {
+	return tx.Exec(`UPDATE accounts SET balance = balance - 1 WHERE id = 1`).Error
+}
+
+func TestTransactionWithRetry_RetriesSerializationFailure(t *testing.T) {
+	client, mock := newMockPostgresClient(t)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE accounts`).WillReturnError(&pgconn.PgError{Code: "40001"})
+	mock.ExpectRollback()
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE accounts`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	attempts := 0
+	err := client.TransactionWithRetry(context.Background(), func(tx *gorm.DB) error {
+		attempts++
+		return transfer(tx)
+	}, 3, &TxOptions{Isolation: sql.LevelSerializable})
+	if err != nil {
+		t.Fatalf("want success on retry, got %v", err)
+	}
+	if attempts != 2 {
+		t.Fatalf("want 2 attempts, got %d", attempts)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestTransactionWithRetry_GivesUpAfterMaxRetries(t *testing.T) {
+	client, mock := newMockPostgresClient(t)
+
+	for i := 0; i < 2; i++ {
+		mock.ExpectBegin()
+		mock.ExpectExec(`UPDATE accounts`).WillReturnError(&pgconn.PgError{Code: "40P01"})
+		mock.ExpectRollback()
+	}
+
+	err := client.TransactionWithRetry(context.Background(), transfer, 1, nil)
+	if !IsRetryableTxError(err) {
+		t.Fatalf("want deadlock error after retries exhausted, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestTransactionWithRetry_DoesNotRetryOtherErrors(t *testing.T) {
+	client, mock := newMockPostgresClient(t)
+
+	uniqueErr := &pgconn.PgError{Code: "23505"}
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE accounts`).WillReturnError(uniqueErr)
+	mock.ExpectRollback()
+
+	err := client.TransactionWithRetry(context.Background(), transfer, 3, nil)
+	if !errors.Is(err, uniqueErr) {
+		t.Fatalf("want unique violation returned without retry, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
@@ -0,0 +1,109 @@
+// Package debugserver 提供独立端口上的调试 HTTP 服务（pprof）
+// 供只暴露 gRPC 端口的服务在排查时采集 profile，默认关闭
+package debugserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/pprof"
+
+	"github.com/alfredchaos/demo/pkg/log"
+	"github.com/alfredchaos/demo/pkg/netutil"
+	"go.uber.org/zap"
+)
+
+// DefaultAddr 默认监听地址，只监听本机回环
+const DefaultAddr = "127.0.0.1:6060"
+
+// Config 调试服务配置
+type Config struct {
+	Enabled    bool     `yaml:"enabled" mapstructure:"enabled"`         // 是否启用，默认关闭
+	Addr       string   `yaml:"addr" mapstructure:"addr"`               // 监听地址，默认 127.0.0.1:6060
+	AllowedIPs []string `yaml:"allowed_ips" mapstructure:"allowed_ips"` // 允许访问的来源 IP 或 CIDR，为空时不限制
+}
+
+// PprofHandler 返回 /debug/pprof/ 下的 pprof 处理器
+// 与 net/http/pprof 注册到 DefaultServeMux 的路由相同，但不污染全局 mux
+func PprofHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	return mux
+}
+
+// Server 调试 HTTP 服务
+type Server struct {
+	srv      *http.Server
+	listener net.Listener
+}
+
+// Start 按配置启动调试服务，未启用时返回 nil
+func Start(cfg *Config) (*Server, error) {
+	if cfg == nil || !cfg.Enabled {
+		return nil, nil
+	}
+
+	allowList, err := netutil.ParseIPAllowList(cfg.AllowedIPs)
+	if err != nil {
+		return nil, err
+	}
+
+	addr := cfg.Addr
+	if addr == "" {
+		addr = DefaultAddr
+	}
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen debug server: %w", err)
+	}
+
+	s := &Server{
+		srv:      &http.Server{Handler: filterIP(allowList, PprofHandler())},
+		listener: listener,
+	}
+	go func() {
+		if err := s.srv.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Error("debug server stopped with error", zap.Error(err))
+		}
+	}()
+
+	log.Info("debug server started", zap.String("addr", listener.Addr().String()))
+	return s, nil
+}
+
+// Addr 实际监听地址
+func (s *Server) Addr() net.Addr {
+	return s.listener.Addr()
+}
+
+// Stop 关闭调试服务，s 为 nil（未启用）时为空操作
+func (s *Server) Stop(ctx context.Context) error {
+	if s == nil {
+		return nil
+	}
+	return s.srv.Shutdown(ctx)
+}
+
+// filterIP 拒绝白名单之外的来源
+func filterIP(allowList *netutil.IPAllowList, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			host = r.RemoteAddr
+		}
+		if !allowList.Allows(host) {
+			log.Warn("debug request rejected by ip filter",
+				zap.String("client_ip", host),
+				zap.String("path", r.URL.Path))
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
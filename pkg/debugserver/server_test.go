@@ -0,0 +1,62 @@
+package debugserver
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/alfredchaos/demo/pkg/log"
+	"go.uber.org/zap"
+)
+
+// useNopLogger 测试期间使用空日志，结束后恢复原日志
+func useNopLogger(t *testing.T) {
+	t.Helper()
+	original := log.Logger
+	log.Logger = zap.NewNop()
+	t.Cleanup(func() { log.Logger = original })
+}
+
+func TestStart_DisabledByDefault(t *testing.T) {
+	useNopLogger(t)
+	s, err := Start(&Config{})
+	if err != nil || s != nil {
+		t.Fatalf("want no server when disabled, got %v, %v", s, err)
+	}
+	// 未启用时 Stop 为空操作
+	if err := s.Stop(context.Background()); err != nil {
+		t.Fatalf("stop nil server: %v", err)
+	}
+}
+
+func TestStart_ServesPprofToAllowedSources(t *testing.T) {
+	useNopLogger(t)
+	cases := []struct {
+		name       string
+		allowedIPs []string
+		want       int
+	}{
+		{"no allow list", nil, http.StatusOK},
+		{"loopback allowed", []string{"127.0.0.0/8"}, http.StatusOK},
+		{"loopback not allowed", []string{"10.0.0.0/8"}, http.StatusForbidden},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			s, err := Start(&Config{Enabled: true, Addr: "127.0.0.1:0", AllowedIPs: c.allowedIPs})
+			if err != nil {
+				t.Fatalf("start: %v", err)
+			}
+			defer s.Stop(context.Background())
+
+			resp, err := http.Get("http://" + s.Addr().String() + "/debug/pprof/cmdline")
+			if err != nil {
+				t.Fatalf("get: %v", err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != c.want {
+				t.Fatalf("want %d, got %d", c.want, resp.StatusCode)
+			}
+		})
+	}
+}
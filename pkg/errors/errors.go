@@ -1,7 +1,12 @@
 package errors
 
 import (
+	stderrors "errors"
 	"fmt"
+	"net/http"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 // ErrorCode 错误码类型
@@ -32,6 +37,12 @@ const (
 	// ErrTimeout 请求超时
 	ErrTimeout ErrorCode = 10007
 	
+	// ErrConflict 资源已存在或冲突
+	ErrConflict ErrorCode = 10008
+
+	// ErrTooManyRequests 请求过于频繁或资源耗尽
+	ErrTooManyRequests ErrorCode = 10009
+	
 	// ErrDatabaseError 数据库错误
 	ErrDatabaseError ErrorCode = 20001
 	
@@ -93,6 +104,8 @@ func GetErrorMessage(code ErrorCode) string {
 		ErrForbidden:          "forbidden",
 		ErrServiceUnavailable: "service unavailable",
 		ErrTimeout:            "request timeout",
+		ErrConflict:           "resource conflict",
+		ErrTooManyRequests:    "too many requests",
 		ErrDatabaseError:      "database error",
 		ErrCacheError:         "cache error",
 		ErrMessageQueueError:  "message queue error",
@@ -118,3 +131,146 @@ func GetAppError(err error) *AppError {
 	}
 	return nil
 }
+
+// CodedError 携带错误码的哨兵错误
+// 用于领域层定义哨兵错误，使其可以被统一映射为 HTTP/gRPC 状态码
+// 同一个实例可直接使用 errors.Is 比较
+type CodedError struct {
+	Code    ErrorCode // 错误码
+	Message string    // 错误消息
+}
+
+// Error 实现 error 接口
+func (e *CodedError) Error() string {
+	return e.Message
+}
+
+// NewCoded 创建携带错误码的哨兵错误
+func NewCoded(code ErrorCode, message string) *CodedError {
+	return &CodedError{
+		Code:    code,
+		Message: message,
+	}
+}
+
+// CodeOf 提取错误链中的错误码
+// nil 返回 Success，未携带错误码的错误返回 ErrInternalServer
+func CodeOf(err error) ErrorCode {
+	if err == nil {
+		return Success
+	}
+
+	var appErr *AppError
+	if stderrors.As(err, &appErr) {
+		return appErr.Code
+	}
+
+	var codedErr *CodedError
+	if stderrors.As(err, &codedErr) {
+		return codedErr.Code
+	}
+
+	return ErrInternalServer
+}
+
+// HTTPStatus 将错误码映射为 HTTP 状态码
+func HTTPStatus(code ErrorCode) int {
+	switch code {
+	case Success:
+		return http.StatusOK
+	case ErrInvalidParams:
+		return http.StatusBadRequest
+	case ErrNotFound:
+		return http.StatusNotFound
+	case ErrUnauthorized:
+		return http.StatusUnauthorized
+	case ErrForbidden:
+		return http.StatusForbidden
+	case ErrConflict:
+		return http.StatusConflict
+	case ErrTooManyRequests:
+		return http.StatusTooManyRequests
+	case ErrServiceUnavailable:
+		return http.StatusServiceUnavailable
+	case ErrTimeout:
+		return http.StatusGatewayTimeout
+	case ErrRPCError:
+		return http.StatusBadGateway
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// GRPCCode 将错误码映射为 gRPC 状态码
+func GRPCCode(code ErrorCode) codes.Code {
+	switch code {
+	case Success:
+		return codes.OK
+	case ErrInvalidParams:
+		return codes.InvalidArgument
+	case ErrNotFound:
+		return codes.NotFound
+	case ErrUnauthorized:
+		return codes.Unauthenticated
+	case ErrForbidden:
+		return codes.PermissionDenied
+	case ErrConflict:
+		return codes.AlreadyExists
+	case ErrTooManyRequests:
+		return codes.ResourceExhausted
+	case ErrServiceUnavailable:
+		return codes.Unavailable
+	case ErrTimeout:
+		return codes.DeadlineExceeded
+	default:
+		return codes.Internal
+	}
+}
+
+// FromGRPCCode 将 gRPC 状态码映射为错误码
+func FromGRPCCode(code codes.Code) ErrorCode {
+	switch code {
+	case codes.OK:
+		return Success
+	case codes.InvalidArgument, codes.OutOfRange, codes.FailedPrecondition:
+		return ErrInvalidParams
+	case codes.NotFound:
+		return ErrNotFound
+	case codes.Unauthenticated:
+		return ErrUnauthorized
+	case codes.PermissionDenied:
+		return ErrForbidden
+	case codes.AlreadyExists, codes.Aborted:
+		return ErrConflict
+	case codes.ResourceExhausted:
+		return ErrTooManyRequests
+	case codes.Unavailable:
+		return ErrServiceUnavailable
+	case codes.DeadlineExceeded:
+		return ErrTimeout
+	default:
+		return ErrInternalServer
+	}
+}
+
+// HTTPStatusOf 获取错误对应的 HTTP 状态码
+func HTTPStatusOf(err error) int {
+	return HTTPStatus(CodeOf(err))
+}
+
+// GRPCCodeOf 获取错误对应的 gRPC 状态码
+func GRPCCodeOf(err error) codes.Code {
+	return GRPCCode(CodeOf(err))
+}
+
+// ToGRPCError 将错误转换为 gRPC status 错误
+// 已经是 gRPC status 的错误原样返回
+func ToGRPCError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	return status.Error(GRPCCodeOf(err), err.Error())
+}
@@ -0,0 +1,67 @@
+package errors
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MultiError 多个错误的聚合
+// 用于关闭多个资源等需要继续执行并汇总所有失败的场景；
+// 实现 Unwrap() []error，errors.Is / errors.As 会逐个匹配其中的错误
+type MultiError struct {
+	errs []error
+}
+
+// Append 追加错误，nil 会被忽略
+func (m *MultiError) Append(errs ...error) {
+	for _, err := range errs {
+		if err != nil {
+			m.errs = append(m.errs, err)
+		}
+	}
+}
+
+// Len 已聚合的错误数
+func (m *MultiError) Len() int {
+	return len(m.errs)
+}
+
+// Errors 返回已聚合的错误
+func (m *MultiError) Errors() []error {
+	return m.errs
+}
+
+// ErrorOrNil 没有错误时返回 nil，否则返回自身
+// 函数返回值应使用它，避免返回非 nil 的空 MultiError
+func (m *MultiError) ErrorOrNil() error {
+	if m == nil || len(m.errs) == 0 {
+		return nil
+	}
+	return m
+}
+
+// Error 实现 error 接口
+// 单个错误时直接返回其消息，多个错误时逐条列出
+func (m *MultiError) Error() string {
+	switch len(m.errs) {
+	case 0:
+		return "no errors"
+	case 1:
+		return m.errs[0].Error()
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d errors occurred:", len(m.errs))
+	for i, err := range m.errs {
+		fmt.Fprintf(&b, " [%d] %v", i+1, err)
+		if i < len(m.errs)-1 {
+			b.WriteByte(';')
+		}
+	}
+	return b.String()
+}
+
+// Unwrap 返回所有聚合的错误，支持 errors.Is / errors.As
+func (m *MultiError) Unwrap() []error {
+	return m.errs
+}
@@ -0,0 +1,57 @@
+package errors
+
+import (
+	stderrors "errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestMultiError_PreservesAndMatchesAllErrors(t *testing.T) {
+	var merr MultiError
+	merr.Append(nil, io.ErrUnexpectedEOF)
+	merr.Append(fmt.Errorf("failed to close postgres: %w", os.ErrClosed))
+	merr.Append(&AppError{Code: ErrDatabaseError, Message: "close failed"})
+
+	if merr.Len() != 3 {
+		t.Fatalf("want 3 errors (nil ignored), got %d", merr.Len())
+	}
+
+	// 包装后仍能匹配每个成员
+	err := fmt.Errorf("failed to close data layer: %w", merr.ErrorOrNil())
+	for _, target := range []error{io.ErrUnexpectedEOF, os.ErrClosed} {
+		if !stderrors.Is(err, target) {
+			t.Fatalf("want errors.Is to match %v in %v", target, err)
+		}
+	}
+	var appErr *AppError
+	if !stderrors.As(err, &appErr) || appErr.Code != ErrDatabaseError {
+		t.Fatalf("want errors.As to find AppError, got %v", appErr)
+	}
+	if CodeOf(err) != ErrDatabaseError {
+		t.Fatalf("want CodeOf to see member code, got %d", CodeOf(err))
+	}
+
+	// 消息包含每个错误
+	msg := err.Error()
+	for _, part := range []string{"3 errors occurred", "unexpected EOF", "failed to close postgres", "close failed"} {
+		if !strings.Contains(msg, part) {
+			t.Fatalf("want %q in message, got %q", part, msg)
+		}
+	}
+}
+
+func TestMultiError_ErrorOrNil(t *testing.T) {
+	var merr MultiError
+	merr.Append(nil)
+	if err := merr.ErrorOrNil(); err != nil {
+		t.Fatalf("want nil without errors, got %v", err)
+	}
+
+	merr.Append(io.EOF)
+	if err := merr.ErrorOrNil(); err == nil || err.Error() != io.EOF.Error() {
+		t.Fatalf("want single error message unchanged, got %v", err)
+	}
+}
@@ -0,0 +1,97 @@
+package featureflag
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// 已知的功能开关名称
+// 配置经 viper 加载后键名为小写，名称统一使用小写下划线形式
+const (
+	// NegativeCaching 为不存在的记录写入短期墓碑缓存
+	NegativeCaching = "negative_caching"
+	// CacheFailOpen 缓存不可用时降级为直接查询数据库
+	CacheFailOpen = "cache_fail_open"
+	// OutboxDispatch 通过 outbox 表异步投递消息
+	OutboxDispatch = "outbox_dispatch"
+	// BookFallback book-service 不可用时 SayHello 使用默认消息继续创建用户
+	BookFallback = "book_fallback"
+)
+
+// ErrUnknownFlag 开关名称不在已知列表中
+var ErrUnknownFlag = errors.New("unknown feature flag")
+
+// known 已知的开关名称，新增开关时同步登记
+var known = map[string]bool{
+	NegativeCaching: true,
+	CacheFailOpen:   true,
+	OutboxDispatch:  true,
+	BookFallback:    true,
+}
+
+// Known 判断是否为已知的开关名称
+func Known(name string) bool {
+	return known[name]
+}
+
+// Flags 布尔功能开关集合
+// 从配置加载初始值，运行时可通过 Set 修改，并发安全；未配置的开关视为关闭
+type Flags struct {
+	mu    sync.RWMutex
+	flags map[string]bool
+}
+
+// New 创建功能开关集合
+// initial 会被复制，之后对其修改不影响 Flags
+func New(initial map[string]bool) *Flags {
+	flags := make(map[string]bool, len(initial))
+	for name, enabled := range initial {
+		flags[name] = enabled
+	}
+	return &Flags{flags: flags}
+}
+
+// Enabled 判断开关是否开启
+// nil Flags 的所有开关均视为关闭
+func (f *Flags) Enabled(name string) bool {
+	if f == nil {
+		return false
+	}
+
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.flags[name]
+}
+
+// Set 运行时修改开关
+// 名称未知时返回 ErrUnknownFlag，避免拼写错误的开关被静默接受；nil Flags 返回错误而不是 panic
+func (f *Flags) Set(name string, enabled bool) error {
+	if !Known(name) {
+		return fmt.Errorf("%w: %s", ErrUnknownFlag, name)
+	}
+	if f == nil {
+		return errors.New("feature flags not initialized")
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.flags[name] = enabled
+	return nil
+}
+
+// All 获取所有开关的快照
+func (f *Flags) All() map[string]bool {
+	if f == nil {
+		return map[string]bool{}
+	}
+
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	snapshot := make(map[string]bool, len(f.flags))
+	for name, enabled := range f.flags {
+		snapshot[name] = enabled
+	}
+	return snapshot
+}
@@ -0,0 +1,76 @@
+package featureflag
+
+import (
+	"errors"
+	"sync"
+	"testing"
+)
+
+func TestFlags_RuntimeToggle(t *testing.T) {
+	initial := map[string]bool{NegativeCaching: true}
+	flags := New(initial)
+
+	if !flags.Enabled(NegativeCaching) {
+		t.Fatal("want negative_caching enabled from config")
+	}
+	if flags.Enabled(OutboxDispatch) {
+		t.Fatal("want unconfigured flag disabled")
+	}
+
+	if err := flags.Set(NegativeCaching, false); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if err := flags.Set(OutboxDispatch, true); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+
+	if flags.Enabled(NegativeCaching) || !flags.Enabled(OutboxDispatch) {
+		t.Fatalf("runtime toggle not applied: %v", flags.All())
+	}
+	if !initial[NegativeCaching] {
+		t.Fatal("toggling must not modify the initial config map")
+	}
+}
+
+func TestFlags_ConcurrentAccess(t *testing.T) {
+	flags := New(nil)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				flags.Set(CacheFailOpen, (i+j)%2 == 0)
+			}
+		}(i)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				_ = flags.Enabled(CacheFailOpen)
+				_ = flags.All()
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestFlags_NilIsDisabled(t *testing.T) {
+	var flags *Flags
+	if flags.Enabled(NegativeCaching) {
+		t.Fatal("want nil flags disabled")
+	}
+	if err := flags.Set(NegativeCaching, true); err == nil {
+		t.Fatal("want error setting nil flags")
+	}
+}
+
+func TestFlags_SetRejectsUnknownFlag(t *testing.T) {
+	flags := New(nil)
+	if err := flags.Set("negative_cachng", true); !errors.Is(err, ErrUnknownFlag) {
+		t.Fatalf("want ErrUnknownFlag, got %v", err)
+	}
+	if got := flags.All(); len(got) != 0 {
+		t.Fatalf("unknown flag must not be stored, got %v", got)
+	}
+}
@@ -0,0 +1,186 @@
+package grpcclient
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/alfredchaos/demo/pkg/log"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// defaultBreakerFailureThreshold 默认的连续失败熔断阈值
+	defaultBreakerFailureThreshold = 5
+	// defaultBreakerOpenTimeout 默认的熔断持续时间
+	defaultBreakerOpenTimeout = 30 * time.Second
+)
+
+// BreakerConfig 熔断器配置
+type BreakerConfig struct {
+	FailureThreshold int           `yaml:"failure_threshold" mapstructure:"failure_threshold"` // 连续失败多少次后熔断，默认5
+	OpenTimeout      time.Duration `yaml:"open_timeout" mapstructure:"open_timeout"`           // 熔断持续时间，到期后放行一次探测请求，默认30s
+}
+
+// BreakerState 熔断器状态
+type BreakerState int
+
+const (
+	// BreakerClosed 正常放行
+	BreakerClosed BreakerState = iota
+	// BreakerOpen 熔断中，直接拒绝调用
+	BreakerOpen
+	// BreakerHalfOpen 熔断到期，仅放行一次探测调用
+	BreakerHalfOpen
+)
+
+// String 返回状态名称
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half_open"
+	default:
+		return "unknown"
+	}
+}
+
+// Breaker 基于连续失败次数的熔断器
+// 仅 UNAVAILABLE / DEADLINE_EXCEEDED 视为后端故障，业务错误（如 NOT_FOUND）不计入失败
+type Breaker struct {
+	name             string
+	failureThreshold int
+	openTimeout      time.Duration
+
+	mu       sync.Mutex
+	state    BreakerState
+	failures int
+	openedAt time.Time
+	probing  bool // 半开状态下是否已有探测调用在进行
+	now      func() time.Time
+}
+
+// NewBreaker 创建熔断器
+func NewBreaker(name string, cfg *BreakerConfig) *Breaker {
+	b := &Breaker{
+		name:             name,
+		failureThreshold: defaultBreakerFailureThreshold,
+		openTimeout:      defaultBreakerOpenTimeout,
+		now:              time.Now,
+	}
+	if cfg != nil {
+		if cfg.FailureThreshold > 0 {
+			b.failureThreshold = cfg.FailureThreshold
+		}
+		if cfg.OpenTimeout > 0 {
+			b.openTimeout = cfg.OpenTimeout
+		}
+	}
+	return b
+}
+
+// State 获取当前状态
+// 熔断到期但尚未有调用时返回 HalfOpen
+func (b *Breaker) State() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == BreakerOpen && b.now().Sub(b.openedAt) >= b.openTimeout {
+		return BreakerHalfOpen
+	}
+	return b.state
+}
+
+// Allow 判断是否放行本次调用
+func (b *Breaker) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case BreakerOpen:
+		if b.now().Sub(b.openedAt) < b.openTimeout {
+			return false
+		}
+		b.state = BreakerHalfOpen
+		b.probing = true
+		return true
+	case BreakerHalfOpen:
+		if b.probing {
+			return false
+		}
+		b.probing = true
+		return true
+	default:
+		return true
+	}
+}
+
+// Record 记录调用结果并更新状态
+func (b *Breaker) Record(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !isBreakerFailure(err) {
+		if b.state != BreakerClosed {
+			log.Info("circuit breaker closed", zap.String("remote_service", b.name))
+		}
+		b.state = BreakerClosed
+		b.failures = 0
+		b.probing = false
+		return
+	}
+
+	b.failures++
+	if b.state == BreakerHalfOpen || b.failures >= b.failureThreshold {
+		if b.state != BreakerOpen {
+			log.Warn("circuit breaker opened",
+				zap.String("remote_service", b.name),
+				zap.Int("failures", b.failures))
+		}
+		b.state = BreakerOpen
+		b.openedAt = b.now()
+		b.probing = false
+	}
+}
+
+// isBreakerFailure 判断错误是否表示后端不可用
+func isBreakerFailure(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded:
+		return true
+	default:
+		return false
+	}
+}
+
+// CircuitBreakerInterceptor 熔断拦截器
+// 熔断期间直接返回 UNAVAILABLE，避免持续请求已故障的后端
+func CircuitBreakerInterceptor(b *Breaker) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if !b.Allow() {
+			return status.Errorf(codes.Unavailable, "circuit breaker open for %s", b.name)
+		}
+
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		b.Record(err)
+		return err
+	}
+}
+
+// BreakerStates 获取所有已启用熔断的服务的当前状态
+func (m *Manager) BreakerStates() map[string]BreakerState {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	states := make(map[string]BreakerState, len(m.breakers))
+	for serviceName, b := range m.breakers {
+		states[serviceName] = b.State()
+	}
+	return states
+}
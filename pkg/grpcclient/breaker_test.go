@@ -0,0 +1,82 @@
+package grpcclient
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestBreaker_OpensAfterConsecutiveFailuresAndRecovers(t *testing.T) {
+	useNopLogger(t)
+	now := time.Now()
+	b := NewBreaker("user-service", &BreakerConfig{FailureThreshold: 2, OpenTimeout: time.Second})
+	b.now = func() time.Time { return now }
+
+	var calls int
+	result := status.Error(codes.Unavailable, "connection refused")
+	invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		calls++
+		return result
+	}
+	call := func() error {
+		return CircuitBreakerInterceptor(b)(context.Background(), "/svc/Method", nil, nil, nil, invoker)
+	}
+
+	// 业务错误不计入失败
+	result = status.Error(codes.NotFound, "user not found")
+	call()
+	result = status.Error(codes.Unavailable, "connection refused")
+	call()
+	if got := b.State(); got != BreakerClosed {
+		t.Fatalf("want closed after 1 failure, got %s", got)
+	}
+
+	call()
+	if got := b.State(); got != BreakerOpen {
+		t.Fatalf("want open after 2 failures, got %s", got)
+	}
+
+	// 熔断期间不调用后端
+	if err := call(); status.Code(err) != codes.Unavailable || calls != 3 {
+		t.Fatalf("want rejected without invoking, got err=%v calls=%d", err, calls)
+	}
+
+	// 到期后进入半开，探测成功后恢复
+	now = now.Add(time.Second)
+	if got := b.State(); got != BreakerHalfOpen {
+		t.Fatalf("want half_open after timeout, got %s", got)
+	}
+	result = nil
+	if err := call(); err != nil || calls != 4 {
+		t.Fatalf("want probe to pass through, got err=%v calls=%d", err, calls)
+	}
+	if got := b.State(); got != BreakerClosed {
+		t.Fatalf("want closed after successful probe, got %s", got)
+	}
+}
+
+func TestBreaker_FailedProbeReopens(t *testing.T) {
+	useNopLogger(t)
+	now := time.Now()
+	b := NewBreaker("book-service", &BreakerConfig{FailureThreshold: 1, OpenTimeout: time.Second})
+	b.now = func() time.Time { return now }
+
+	b.Record(status.Error(codes.DeadlineExceeded, "timeout"))
+	now = now.Add(time.Second)
+
+	if !b.Allow() {
+		t.Fatal("want probe allowed after timeout")
+	}
+	if b.Allow() {
+		t.Fatal("want only one probe in half_open")
+	}
+
+	b.Record(status.Error(codes.Unavailable, "still down"))
+	if got := b.State(); got != BreakerOpen {
+		t.Fatalf("want open after failed probe, got %s", got)
+	}
+}
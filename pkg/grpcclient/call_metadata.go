@@ -0,0 +1,46 @@
+package grpcclient
+
+import (
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// CallMetadata 一次调用返回的响应头和 trailer
+// 通过 WithCallMetadata 获取的调用选项填充，调用返回后读取
+type CallMetadata struct {
+	Header  metadata.MD
+	Trailer metadata.MD
+}
+
+// WithCallMetadata 创建捕获响应头和 trailer 的调用选项
+// 用法：
+//
+//	md, opts := grpcclient.WithCallMetadata()
+//	resp, err := client.SayHello(ctx, req, opts...)
+//	hint := md.Get("x-ratelimit-remaining")
+func WithCallMetadata() (*CallMetadata, []grpc.CallOption) {
+	md := &CallMetadata{}
+	return md, []grpc.CallOption{grpc.Header(&md.Header), grpc.Trailer(&md.Trailer)}
+}
+
+// Get 获取指定键的第一个值，trailer 优先于响应头；不存在时返回空字符串
+func (m *CallMetadata) Get(key string) string {
+	if values := m.Trailer.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	if values := m.Header.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// Select 按键挑选元数据返回给调用方，不存在的键不会出现在结果中
+func (m *CallMetadata) Select(keys ...string) map[string]string {
+	selected := make(map[string]string, len(keys))
+	for _, key := range keys {
+		if value := m.Get(key); value != "" {
+			selected[key] = value
+		}
+	}
+	return selected
+}
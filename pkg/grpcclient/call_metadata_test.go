@@ -0,0 +1,58 @@
+package grpcclient
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// metadataHealthServer 设置响应头和 trailer 的健康检查服务
+type metadataHealthServer struct {
+	healthpb.UnimplementedHealthServer
+}
+
+func (metadataHealthServer) Check(ctx context.Context, req *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	_ = grpc.SetHeader(ctx, metadata.Pairs("x-served-by", "user-service-1"))
+	_ = grpc.SetTrailer(ctx, metadata.Pairs("x-ratelimit-remaining", "42"))
+	return &healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_SERVING}, nil
+}
+
+func TestWithCallMetadata_CapturesHeaderAndTrailer(t *testing.T) {
+	lis := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+	healthpb.RegisterHealthServer(server, metadataHealthServer{})
+	go func() { _ = server.Serve(lis) }()
+	defer server.Stop()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.Close()
+
+	md, opts := WithCallMetadata()
+	if _, err := healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{}, opts...); err != nil {
+		t.Fatalf("check: %v", err)
+	}
+
+	if got := md.Get("x-ratelimit-remaining"); got != "42" {
+		t.Fatalf("want trailer 42, got %q", got)
+	}
+	if got := md.Get("x-served-by"); got != "user-service-1" {
+		t.Fatalf("want header user-service-1, got %q", got)
+	}
+
+	selected := md.Select("x-ratelimit-remaining", "x-missing")
+	if len(selected) != 1 || selected["x-ratelimit-remaining"] != "42" {
+		t.Fatalf("want only the present key selected, got %v", selected)
+	}
+}
@@ -5,19 +5,33 @@ import "time"
 // Config gRPC客户端配置
 type Config struct {
 	Services []ServiceConfig `yaml:"services" mapstructure:"services"`
+	Warmer   WarmerConfig    `yaml:"warmer" mapstructure:"warmer"` // 后台连接预热
 }
 
 // ServiceConfig 单个服务配置
 type ServiceConfig struct {
 	Name    string        `yaml:"name" mapstructure:"name"`       // 服务名称
 	Address string        `yaml:"address" mapstructure:"address"` // 服务地址
+	Client  string        `yaml:"client" mapstructure:"client"`   // 客户端类型，对应 InitGRPCClientManager 传入的工厂的键，为空时与服务名称相同
 	Timeout time.Duration `yaml:"timeout" mapstructure:"timeout"` // 连接超时
 	
+	DeadlineHeadroom time.Duration `yaml:"deadline_headroom" mapstructure:"deadline_headroom"` // 向下游传递时间预算时预留的余量，默认50ms
+	SlowThreshold    time.Duration `yaml:"slow_threshold" mapstructure:"slow_threshold"`       // 慢调用阈值，超过时以 warn 级别记录，默认1s
+	
 	// 可选配置
 	Retry   *RetryConfig  `yaml:"retry" mapstructure:"retry"`     // 重试配置
+	CircuitBreaker *BreakerConfig `yaml:"circuit_breaker" mapstructure:"circuit_breaker"` // 熔断配置，未配置时不启用
 	TLS     *TLSConfig    `yaml:"tls" mapstructure:"tls"`         // TLS配置
 }
 
+// ClientKind 返回服务使用的客户端类型
+func (c *ServiceConfig) ClientKind() string {
+	if c.Client != "" {
+		return c.Client
+	}
+	return c.Name
+}
+
 // RetryConfig 重试配置
 type RetryConfig struct {
 	Max         int           `yaml:"max" mapstructure:"max"`                   // 最大重试次数
@@ -26,8 +40,11 @@ type RetryConfig struct {
 }
 
 // TLSConfig TLS配置
+// 未配置或未启用时使用明文连接；启用后证书文件缺失或无法解析时 Connect 返回错误，不会回退到明文
 type TLSConfig struct {
-	Enabled  bool   `yaml:"enabled" mapstructure:"enabled"`   // 是否启用TLS
-	CertFile string `yaml:"cert_file" mapstructure:"cert_file"` // 证书文件
-	KeyFile  string `yaml:"key_file" mapstructure:"key_file"`   // 密钥文件
+	Enabled            bool   `yaml:"enabled" mapstructure:"enabled"`                           // 是否启用TLS
+	CAFile             string `yaml:"ca_file" mapstructure:"ca_file"`                           // 校验服务端证书的 CA 文件，为空时使用系统根证书
+	CertFile           string `yaml:"cert_file" mapstructure:"cert_file"`                       // 客户端证书（双向 TLS，需同时配置 key_file）；未配置 key_file 和 ca_file 时作为服务端 CA 证书
+	KeyFile            string `yaml:"key_file" mapstructure:"key_file"`                         // 客户端私钥（双向 TLS）
+	ServerNameOverride string `yaml:"server_name_override" mapstructure:"server_name_override"` // 校验证书时使用的主机名，经负载均衡连接且证书与地址不一致时配置
 }
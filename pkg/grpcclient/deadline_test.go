@@ -0,0 +1,146 @@
+package grpcclient
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/alfredchaos/demo/pkg/middleware"
+	"github.com/alfredchaos/demo/pkg/reqctx"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// deadlineHealthServer 记录收到请求时的截止时间，可选地继续调用下游
+type deadlineHealthServer struct {
+	healthpb.UnimplementedHealthServer
+	deadlines  chan time.Time
+	downstream healthpb.HealthClient
+	detach     bool // 调用下游前脱离请求上下文（只保留上下文中的值）
+}
+
+func (s *deadlineHealthServer) Check(ctx context.Context, req *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	deadline, _ := ctx.Deadline()
+	s.deadlines <- deadline
+
+	if s.downstream != nil {
+		callCtx := ctx
+		if s.detach {
+			callCtx = context.WithoutCancel(ctx)
+		}
+		if _, err := s.downstream.Check(callCtx, req); err != nil {
+			return nil, err
+		}
+	}
+	return &healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_SERVING}, nil
+}
+
+// startDeadlineServer 启动 bufconn 服务，返回连接到它的客户端
+// budget 为 true 时安装服务端时间预算拦截器，否则处理函数的上下文只有 gRPC 自身传递的截止时间
+func startDeadlineServer(t *testing.T, srv *deadlineHealthServer, headroom time.Duration, budget bool) healthpb.HealthClient {
+	t.Helper()
+
+	interceptors := []grpc.UnaryServerInterceptor{middleware.UnaryServerStartTime()}
+	if budget {
+		interceptors = append(interceptors, middleware.UnaryServerDeadlineBudget())
+	}
+
+	lis := bufconn.Listen(1 << 20)
+	server := grpc.NewServer(grpc.ChainUnaryInterceptor(interceptors...))
+	healthpb.RegisterHealthServer(server, srv)
+	go func() { _ = server.Serve(lis) }()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithChainUnaryInterceptor(IncomingDeadlineInterceptor(), DeadlineBudgetInterceptor(headroom)),
+	)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return healthpb.NewHealthClient(conn)
+}
+
+func TestIncomingDeadline_BookCallDerivedFromUserCall(t *testing.T) {
+	const headroom = 50 * time.Millisecond
+
+	book := &deadlineHealthServer{deadlines: make(chan time.Time, 1)}
+	bookClient := startDeadlineServer(t, book, headroom, true)
+
+	user := &deadlineHealthServer{deadlines: make(chan time.Time, 1), downstream: bookClient}
+	userClient := startDeadlineServer(t, user, headroom, true)
+
+	// 网关调用 user-service，总截止时间 1s
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if _, err := userClient.Check(ctx, &healthpb.HealthCheckRequest{}); err != nil {
+		t.Fatalf("check: %v", err)
+	}
+
+	userDeadline := <-user.deadlines
+	bookDeadline := <-book.deadlines
+	if userDeadline.IsZero() || bookDeadline.IsZero() {
+		t.Fatalf("want deadlines on both hops, got user=%v book=%v", userDeadline, bookDeadline)
+	}
+	// book-service 的截止时间由 user-service 的截止时间扣除余量得到
+	if gap := userDeadline.Sub(bookDeadline); gap < headroom/2 || gap > time.Second/2 {
+		t.Fatalf("want book deadline about %v before user deadline, got gap %v", headroom, gap)
+	}
+}
+
+// TestIncomingDeadline_RestoresBudgetOnRequestContext 请求上下文没有截止时间、上游只传递了预算时，由拦截器恢复截止时间
+func TestIncomingDeadline_RestoresBudgetOnRequestContext(t *testing.T) {
+	const headroom = 50 * time.Millisecond
+
+	book := &deadlineHealthServer{deadlines: make(chan time.Time, 1)}
+	bookClient := startDeadlineServer(t, book, headroom, true)
+
+	// user-service 未安装服务端预算拦截器，处理函数的上下文没有截止时间
+	user := &deadlineHealthServer{deadlines: make(chan time.Time, 1), downstream: bookClient}
+	userClient := startDeadlineServer(t, user, headroom, false)
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), reqctx.DeadlineBudgetKey, "1000")
+	start := time.Now()
+	if _, err := userClient.Check(ctx, &healthpb.HealthCheckRequest{}); err != nil {
+		t.Fatalf("check: %v", err)
+	}
+
+	if userDeadline := <-user.deadlines; !userDeadline.IsZero() {
+		t.Fatalf("want user handler without deadline, got %v", userDeadline)
+	}
+	bookDeadline := <-book.deadlines
+	if bookDeadline.IsZero() {
+		t.Fatal("want book deadline restored from incoming budget")
+	}
+	if remaining := bookDeadline.Sub(start); remaining <= 0 || remaining > time.Second {
+		t.Fatalf("want book deadline within the 1s budget, got %v", remaining)
+	}
+}
+
+// TestIncomingDeadline_SkipsDetachedContext 脱离请求的上下文不受请求预算约束
+func TestIncomingDeadline_SkipsDetachedContext(t *testing.T) {
+	const headroom = 50 * time.Millisecond
+
+	book := &deadlineHealthServer{deadlines: make(chan time.Time, 1)}
+	bookClient := startDeadlineServer(t, book, headroom, true)
+
+	user := &deadlineHealthServer{deadlines: make(chan time.Time, 1), downstream: bookClient, detach: true}
+	userClient := startDeadlineServer(t, user, headroom, false)
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), reqctx.DeadlineBudgetKey, "1000")
+	if _, err := userClient.Check(ctx, &healthpb.HealthCheckRequest{}); err != nil {
+		t.Fatalf("check: %v", err)
+	}
+
+	<-user.deadlines
+	if bookDeadline := <-book.deadlines; !bookDeadline.IsZero() {
+		t.Fatalf("want detached call without request deadline, got %v", bookDeadline)
+	}
+}
@@ -5,13 +5,23 @@ import (
 	"time"
 	
 	"github.com/alfredchaos/demo/pkg/log"
+	"github.com/alfredchaos/demo/pkg/reqctx"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 )
 
+// defaultDeadlineHeadroom 向下游传递时间预算时预留的余量，用于上游处理响应
+const defaultDeadlineHeadroom = 50 * time.Millisecond
+
+// defaultSlowThreshold 默认的慢调用阈值
+const defaultSlowThreshold = time.Second
+
 // LoggingInterceptor 日志拦截器
-func LoggingInterceptor() grpc.UnaryClientInterceptor {
+// slowThreshold: 慢调用阈值，耗时超过阈值的成功调用以 warn 级别记录并带上 is_slow 字段
+func LoggingInterceptor(slowThreshold time.Duration) grpc.UnaryClientInterceptor {
 	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
 		start := time.Now()
 		
@@ -22,12 +32,20 @@ func LoggingInterceptor() grpc.UnaryClientInterceptor {
 		err := invoker(ctx, method, req, reply, cc, opts...)
 		
 		duration := time.Since(start)
-		if err != nil {
+		switch {
+		case err != nil:
 			log.WithContext(ctx).Error("grpc client call failed",
 				zap.String("method", method),
 				zap.Duration("duration", duration),
 				zap.Error(err))
-		} else {
+		case slowThreshold > 0 && duration > slowThreshold:
+			log.WithContext(ctx).Warn("grpc client slow call",
+				zap.String("method", method),
+				zap.String("target", cc.Target()),
+				zap.Duration("duration", duration),
+				zap.Bool("is_slow", true),
+				zap.Duration("slow_threshold", slowThreshold))
+		default:
 			log.WithContext(ctx).Info("grpc client call completed",
 				zap.String("method", method),
 				zap.Duration("duration", duration))
@@ -39,26 +57,87 @@ func LoggingInterceptor() grpc.UnaryClientInterceptor {
 
 // TracingInterceptor 追踪拦截器
 // 将trace ID从context传递到gRPC metadata
+// 优先使用 reqctx 中的 trace_id，没有时退回到 request_id（网关入口）
 func TracingInterceptor() grpc.UnaryClientInterceptor {
 	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
 		// 从context中提取trace ID
-		traceID := ""
-		if val := ctx.Value("X-Request-ID"); val != nil {
-			if id, ok := val.(string); ok {
-				traceID = id
-			}
+		traceID := reqctx.GetTraceID(ctx)
+		if traceID == "" {
+			traceID = reqctx.GetRequestID(ctx)
 		}
-		
-		// 添加到metadata
+
+		// 追加到metadata，保留调用方已设置的其他metadata
 		if traceID != "" {
-			md := metadata.Pairs("X-Trace-ID", traceID)
-			ctx = metadata.NewOutgoingContext(ctx, md)
+			ctx = metadata.AppendToOutgoingContext(ctx, reqctx.TraceIDMetadataKey, traceID)
+		}
+
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+// DeadlineBudgetInterceptor 时间预算拦截器
+// 读取当前上下文的截止时间，扣除 headroom 后作为下游的截止时间，
+// 并通过 metadata 传递剩余预算，使每一跳的预算逐级缩小
+func DeadlineBudgetInterceptor(headroom time.Duration) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		budget, ok := reqctx.RemainingBudget(ctx, headroom)
+		if !ok {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+		
+		// 预算已耗尽，不再发起注定超时的调用
+		if budget <= 0 {
+			return status.Errorf(codes.DeadlineExceeded, "deadline budget exhausted before calling %s", method)
 		}
 		
+		ctx, cancel := reqctx.WithDownstreamDeadline(ctx, headroom)
+		defer cancel()
+		ctx = metadata.AppendToOutgoingContext(ctx, reqctx.DeadlineBudgetKey, reqctx.FormatBudget(budget))
+		
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+// IncomingDeadlineInterceptor 入站截止时间拦截器
+// 出站调用的请求上下文没有截止时间时（如服务端未安装 UnaryServerDeadlineBudget，上游只通过 metadata 传递预算），
+// 从入站 metadata 中的时间预算恢复截止时间：以请求开始时间（reqctx.WithStartTime）为起点，未记录时以当前时间为起点。
+// 使用 context.WithoutCancel 脱离请求的上下文（Done 为 nil）是有意不受请求预算约束的后台工作，不恢复截止时间，需由调用方自行设置超时。
+// 应位于 DeadlineBudgetInterceptor 之前，由后者扣除余量并继续向下游传递预算
+func IncomingDeadlineInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if _, ok := ctx.Deadline(); ok || ctx.Done() == nil {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+
+		budget, ok := incomingBudget(ctx)
+		if !ok {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+
+		start, ok := reqctx.GetStartTime(ctx)
+		if !ok {
+			start = time.Now()
+		}
+		ctx, cancel := context.WithDeadline(ctx, start.Add(budget))
+		defer cancel()
+
 		return invoker(ctx, method, req, reply, cc, opts...)
 	}
 }
 
+// incomingBudget 读取入站 metadata 中上游传递的时间预算
+func incomingBudget(ctx context.Context) (time.Duration, bool) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return 0, false
+	}
+	values := md.Get(reqctx.DeadlineBudgetKey)
+	if len(values) == 0 {
+		return 0, false
+	}
+	return reqctx.ParseBudget(values[0])
+}
+
 // RetryInterceptor 重试拦截器
 func RetryInterceptor(cfg *RetryConfig) grpc.UnaryClientInterceptor {
 	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
@@ -0,0 +1,113 @@
+package grpcclient
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alfredchaos/demo/pkg/log"
+	"github.com/alfredchaos/demo/pkg/reqctx"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestDeadlineBudgetInterceptor_ShrinksDownstreamDeadline(t *testing.T) {
+	upstream, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	upstreamDeadline, _ := upstream.Deadline()
+
+	var downstreamDeadline time.Time
+	var budget []string
+	invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		downstreamDeadline, _ = ctx.Deadline()
+		md, _ := metadata.FromOutgoingContext(ctx)
+		budget = md.Get(reqctx.DeadlineBudgetKey)
+		return nil
+	}
+
+	headroom := 100 * time.Millisecond
+	if err := DeadlineBudgetInterceptor(headroom)(upstream, "/svc/Method", nil, nil, nil, invoker); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !downstreamDeadline.Before(upstreamDeadline) {
+		t.Fatalf("want downstream deadline %v before upstream %v", downstreamDeadline, upstreamDeadline)
+	}
+	if gap := upstreamDeadline.Sub(downstreamDeadline); gap < headroom {
+		t.Fatalf("want at least %v headroom, got %v", headroom, gap)
+	}
+	if len(budget) != 1 {
+		t.Fatalf("want budget metadata, got %v", budget)
+	}
+	if ms, ok := reqctx.ParseBudget(budget[0]); !ok || ms > time.Second-headroom {
+		t.Fatalf("want budget below %v, got %s", time.Second-headroom, budget[0])
+	}
+}
+
+func TestDeadlineBudgetInterceptor_RejectsExhaustedBudget(t *testing.T) {
+	upstream, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	called := false
+	invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		called = true
+		return nil
+	}
+
+	err := DeadlineBudgetInterceptor(50*time.Millisecond)(upstream, "/svc/Method", nil, nil, nil, invoker)
+	if status.Code(err) != codes.DeadlineExceeded {
+		t.Fatalf("want DeadlineExceeded, got %v", err)
+	}
+	if called {
+		t.Fatalf("downstream should not be called once the budget is exhausted")
+	}
+}
+
+func TestDeadlineBudgetInterceptor_NoDeadlinePassesThrough(t *testing.T) {
+	invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		if _, ok := ctx.Deadline(); ok {
+			t.Fatalf("no deadline should be added")
+		}
+		return nil
+	}
+
+	if err := DeadlineBudgetInterceptor(defaultDeadlineHeadroom)(context.Background(), "/svc/Method", nil, nil, nil, invoker); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestLoggingInterceptor_WarnsOnSlowCall(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	prev := log.Logger
+	log.Logger = zap.New(core)
+	t.Cleanup(func() { log.Logger = prev })
+
+	cc, err := grpc.NewClient("passthrough:///slow-service", grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+	defer cc.Close()
+
+	invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		time.Sleep(20 * time.Millisecond)
+		return nil
+	}
+
+	if err := LoggingInterceptor(10*time.Millisecond)(context.Background(), "/svc/Slow", nil, nil, cc, invoker); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	slow := logs.FilterLevelExact(zapcore.WarnLevel).All()
+	if len(slow) != 1 {
+		t.Fatalf("want 1 slow warning, got %d", len(slow))
+	}
+	if slow[0].ContextMap()["is_slow"] != true {
+		t.Fatalf("want is_slow=true, got %v", slow[0].ContextMap())
+	}
+}
@@ -2,15 +2,19 @@ package grpcclient
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"reflect"
 	"sync"
 	"time"
 
+	apperrors "github.com/alfredchaos/demo/pkg/errors"
 	"github.com/alfredchaos/demo/pkg/log"
+	"github.com/prometheus/client_golang/prometheus"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/backoff"
-	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/connectivity"
 	"google.golang.org/grpc/keepalive"
 )
 
@@ -19,13 +23,26 @@ type Manager struct {
 	connections map[string]*grpc.ClientConn
 	clients     map[string]interface{} // 缓存客户端实例
 	configs     map[string]*ServiceConfig
+	breakers    map[string]*Breaker // 已启用熔断的服务
 	mu          sync.RWMutex
+
+	// 后台连接预热器，未启动时为 nil；warmerClosed 在 Close 后为 true，不再启动预热器
+	warmerMu     sync.Mutex
+	stopWarmer   context.CancelFunc
+	warmerDone   chan struct{}
+	warmerClosed bool
 }
 
 // 初始化gRPC客户端管理器
-func InitGRPCClientManager(cfg *Config) *Manager {
+// factories 为可用的客户端工厂（按客户端类型索引），按配置为每个服务注册到全局注册表
+func InitGRPCClientManager(cfg *Config, factories map[string]ClientFactory) *Manager {
 	clientManager := NewManager()
 
+	// 按配置注册客户端工厂
+	if err := GlobalRegistry.RegisterFromConfig(cfg, factories); err != nil {
+		log.Fatal("failed to register grpc client factories", zap.Error(err))
+	}
+
 	// 注册服务配置
 	for _, svc := range cfg.Services {
 		log.Info("registering service", zap.String("remote_service", svc.Name))
@@ -41,6 +58,16 @@ func InitGRPCClientManager(cfg *Config) *Manager {
 		log.Fatal("failed to connect remote_services", zap.Error(err))
 	}
 
+	// 注册连接状态指标（重复注册不影响主流程）
+	if err := clientManager.RegisterMetrics(prometheus.DefaultRegisterer); err != nil {
+		log.Warn("failed to register grpc client metrics", zap.Error(err))
+	}
+
+	// 启动后台连接预热
+	if cfg.Warmer.Enabled {
+		clientManager.StartWarmer(cfg.Warmer.Interval)
+	}
+
 	log.Info("grpc client manager initialized")
 	return clientManager
 }
@@ -51,6 +78,7 @@ func NewManager() *Manager {
 		connections: make(map[string]*grpc.ClientConn),
 		clients:     make(map[string]interface{}),
 		configs:     make(map[string]*ServiceConfig),
+		breakers:    make(map[string]*Breaker),
 	}
 }
 
@@ -88,7 +116,10 @@ func (m *Manager) Connect(serviceName string) error {
 	}
 
 	// 构建连接选项
-	opts := m.buildDialOptions(cfg)
+	opts, err := m.buildDialOptions(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to build dial options for %s: %w", serviceName, err)
+	}
 
 	// 设置超时
 	timeout := cfg.Timeout
@@ -144,6 +175,20 @@ func (m *Manager) GetConnection(serviceName string) (*grpc.ClientConn, error) {
 	return conn, nil
 }
 
+// ConnectionStates 获取所有连接的当前状态
+// 返回以服务名为键的快照，用于监控连接数量和后端抖动
+func (m *Manager) ConnectionStates() map[string]connectivity.State {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	states := make(map[string]connectivity.State, len(m.connections))
+	for serviceName, conn := range m.connections {
+		states[serviceName] = conn.GetState()
+	}
+
+	return states
+}
+
 // GetClient 获取指定服务的客户端实例
 // 如果客户端已创建则返回缓存，否则使用注册表创建新客户端
 func (m *Manager) GetClient(serviceName string) (interface{}, error) {
@@ -175,18 +220,43 @@ func (m *Manager) GetClient(serviceName string) (interface{}, error) {
 	return client, nil
 }
 
-// Close 关闭所有连接
+// ErrClientType 客户端实例不是期望的类型，通常是服务配置的 client 与调用方使用的客户端不一致
+var ErrClientType = errors.New("unexpected grpc client type")
+
+// Typed 获取指定服务的类型化客户端，如 Typed[bookv1.BookServiceClient](m, "book-service")
+// 客户端不存在、为 nil 或类型不匹配时返回错误，避免依赖注入时类型断言 panic
+func Typed[T any](m *Manager, serviceName string) (T, error) {
+	var zero T
+	client, err := m.GetClient(serviceName)
+	if err != nil {
+		return zero, err
+	}
+	typed, ok := client.(T)
+	if !ok {
+		return zero, fmt.Errorf("%w: service %s has client %T, want %s",
+			ErrClientType, serviceName, client, reflect.TypeOf((*T)(nil)).Elem())
+	}
+	return typed, nil
+}
+
+// Close 停止连接预热并关闭所有连接
+// 单个连接关闭失败不影响其他连接，所有失败汇总为 MultiError 返回
 func (m *Manager) Close() error {
+	m.warmerMu.Lock()
+	m.warmerClosed = true
+	m.stopWarmerLocked()
+	m.warmerMu.Unlock()
+
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	var errs []error
+	var errs apperrors.MultiError
 	for serviceName, conn := range m.connections {
 		if err := conn.Close(); err != nil {
 			log.Error("failed to close grpc connection",
 				zap.String("service", serviceName),
 				zap.Error(err))
-			errs = append(errs, err)
+			errs.Append(fmt.Errorf("failed to close %s connection: %w", serviceName, err))
 		} else {
 			log.Info("grpc connection closed", zap.String("service", serviceName))
 		}
@@ -195,16 +265,19 @@ func (m *Manager) Close() error {
 	// 清空连接map
 	m.connections = make(map[string]*grpc.ClientConn)
 
-	if len(errs) > 0 {
-		return fmt.Errorf("failed to close %d connection(s)", len(errs))
-	}
-
-	return nil
+	return errs.ErrorOrNil()
 }
 
-// buildDialOptions 构建连接选项
-func (m *Manager) buildDialOptions(cfg *ServiceConfig) []grpc.DialOption {
+// buildDialOptions 构建连接选项，TLS 证书无法加载时返回错误
+func (m *Manager) buildDialOptions(cfg *ServiceConfig) ([]grpc.DialOption, error) {
+	// TLS配置
+	creds, err := buildTransportCredentials(cfg.TLS)
+	if err != nil {
+		return nil, err
+	}
+
 	opts := []grpc.DialOption{
+		grpc.WithTransportCredentials(creds),
 		// 保持连接活跃
 		grpc.WithKeepaliveParams(keepalive.ClientParameters{
 			Time:                60 * time.Second,
@@ -231,19 +304,31 @@ func (m *Manager) buildDialOptions(cfg *ServiceConfig) []grpc.DialOption {
 		}`),
 	}
 
-	// TLS配置
-	if cfg.TLS != nil && cfg.TLS.Enabled {
-		// TODO: 添加TLS凭证
-		// creds, _ := credentials.NewClientTLSFromFile(cfg.TLS.CertFile, "")
-		// opts = append(opts, grpc.WithTransportCredentials(creds))
-	} else {
-		opts = append(opts, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	// 时间预算余量
+	headroom := cfg.DeadlineHeadroom
+	if headroom == 0 {
+		headroom = defaultDeadlineHeadroom
 	}
 
-	// 添加拦截器
+	// 慢调用阈值
+	slowThreshold := cfg.SlowThreshold
+	if slowThreshold == 0 {
+		slowThreshold = defaultSlowThreshold
+	}
+
+	// 添加拦截器（时间预算需在追踪之后，避免其 metadata 被覆盖；入站截止时间需在时间预算之前）
 	unaryInterceptors := []grpc.UnaryClientInterceptor{
-		LoggingInterceptor(),
+		LoggingInterceptor(slowThreshold),
 		TracingInterceptor(),
+		IncomingDeadlineInterceptor(),
+		DeadlineBudgetInterceptor(headroom),
+	}
+
+	// 熔断配置（位于重试之前，一次调用的所有重试只计为一次结果）
+	if cfg.CircuitBreaker != nil {
+		breaker := NewBreaker(cfg.Name, cfg.CircuitBreaker)
+		m.breakers[cfg.Name] = breaker
+		unaryInterceptors = append(unaryInterceptors, CircuitBreakerInterceptor(breaker))
 	}
 
 	// 重试配置
@@ -253,5 +338,5 @@ func (m *Manager) buildDialOptions(cfg *ServiceConfig) []grpc.DialOption {
 
 	opts = append(opts, grpc.WithChainUnaryInterceptor(unaryInterceptors...))
 
-	return opts
+	return opts, nil
 }
@@ -0,0 +1,200 @@
+package grpcclient
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	apperrors "github.com/alfredchaos/demo/pkg/errors"
+	"github.com/alfredchaos/demo/pkg/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+)
+
+// useNopLogger 测试期间使用空日志，结束后恢复原日志
+func useNopLogger(t *testing.T) {
+	t.Helper()
+	original := log.Logger
+	log.Logger = zap.NewNop()
+	t.Cleanup(func() { log.Logger = original })
+}
+
+// startTestServer 启动本地 gRPC 服务并返回监听地址
+func startTestServer(t *testing.T) string {
+	t.Helper()
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+
+	srv := grpc.NewServer()
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	return lis.Addr().String()
+}
+
+// connectAndWaitReady 建立连接并等待其进入 READY 状态
+func connectAndWaitReady(t *testing.T, m *Manager, serviceName string) {
+	t.Helper()
+
+	if err := m.Connect(serviceName); err != nil {
+		t.Fatalf("connect %s: %v", serviceName, err)
+	}
+	conn, err := m.GetConnection(serviceName)
+	if err != nil {
+		t.Fatalf("get connection: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	conn.Connect()
+	for state := conn.GetState(); state != connectivity.Ready; state = conn.GetState() {
+		if !conn.WaitForStateChange(ctx, state) {
+			t.Fatalf("connection %s not ready: %s", serviceName, state)
+		}
+	}
+}
+
+func TestManager_ConnectionStates(t *testing.T) {
+	useNopLogger(t)
+	m := NewManager()
+	defer m.Close()
+
+	if states := m.ConnectionStates(); len(states) != 0 {
+		t.Fatalf("want no connections, got %v", states)
+	}
+
+	for _, name := range []string{"user-service", "book-service"} {
+		if err := m.Register(&ServiceConfig{Name: name, Address: startTestServer(t)}); err != nil {
+			t.Fatalf("register %s: %v", name, err)
+		}
+		connectAndWaitReady(t, m, name)
+	}
+
+	states := m.ConnectionStates()
+	if len(states) != 2 {
+		t.Fatalf("want 2 connections, got %v", states)
+	}
+	for name, state := range states {
+		if state != connectivity.Ready {
+			t.Fatalf("want %s READY, got %s", name, state)
+		}
+	}
+}
+
+func TestManager_RegisterMetrics(t *testing.T) {
+	useNopLogger(t)
+	m := NewManager()
+	defer m.Close()
+
+	if err := m.Register(&ServiceConfig{Name: "user-service", Address: startTestServer(t)}); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	connectAndWaitReady(t, m, "user-service")
+
+	reg := prometheus.NewRegistry()
+	if err := m.RegisterMetrics(reg); err != nil {
+		t.Fatalf("register metrics: %v", err)
+	}
+
+	expected := `
+# HELP grpc_client_connections Number of gRPC client connections held by the manager.
+# TYPE grpc_client_connections gauge
+grpc_client_connections 1
+# HELP grpc_client_connection_state Current connectivity state of each gRPC client connection (1 for the current state).
+# TYPE grpc_client_connection_state gauge
+grpc_client_connection_state{service="user-service",state="CONNECTING"} 0
+grpc_client_connection_state{service="user-service",state="IDLE"} 0
+grpc_client_connection_state{service="user-service",state="READY"} 1
+grpc_client_connection_state{service="user-service",state="SHUTDOWN"} 0
+grpc_client_connection_state{service="user-service",state="TRANSIENT_FAILURE"} 0
+`
+	if err := testutil.GatherAndCompare(reg, strings.NewReader(expected)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestManager_CloseReportsEveryFailedConnection(t *testing.T) {
+	useNopLogger(t)
+	m := NewManager()
+
+	names := []string{"user-service", "book-service"}
+	for _, name := range names {
+		if err := m.Register(&ServiceConfig{Name: name, Address: startTestServer(t)}); err != nil {
+			t.Fatalf("register %s: %v", name, err)
+		}
+		if err := m.Connect(name); err != nil {
+			t.Fatalf("connect %s: %v", name, err)
+		}
+		// 提前关闭，使 Manager.Close 再次关闭时失败
+		conn, _ := m.GetConnection(name)
+		_ = conn.Close()
+	}
+
+	err := m.Close()
+	var merr *apperrors.MultiError
+	if !errors.As(err, &merr) || merr.Len() != len(names) {
+		t.Fatalf("want MultiError with %d errors, got %v", len(names), err)
+	}
+	if !errors.Is(err, grpc.ErrClientConnClosing) {
+		t.Fatalf("want underlying close error matchable, got %v", err)
+	}
+	for _, name := range names {
+		if !strings.Contains(err.Error(), name) {
+			t.Fatalf("want %s in error message, got %q", name, err)
+		}
+	}
+}
+
+// stubClient 测试用的客户端类型
+type stubClient interface{ Name() string }
+
+type namedStub string
+
+func (s namedStub) Name() string { return string(s) }
+
+func TestTyped(t *testing.T) {
+	useNopLogger(t)
+	GlobalRegistry.Register("typed-ok", func(conn *grpc.ClientConn) interface{} { return namedStub("typed-ok") })
+	GlobalRegistry.Register("typed-wrong", func(conn *grpc.ClientConn) interface{} { return conn })
+	GlobalRegistry.Register("typed-nil", func(conn *grpc.ClientConn) interface{} { return nil })
+
+	m := NewManager()
+	defer m.Close()
+	addr := startTestServer(t)
+	for _, name := range []string{"typed-ok", "typed-wrong", "typed-nil", "typed-unregistered"} {
+		if err := m.Register(&ServiceConfig{Name: name, Address: addr}); err != nil {
+			t.Fatalf("register %s: %v", name, err)
+		}
+		if err := m.Connect(name); err != nil {
+			t.Fatalf("connect %s: %v", name, err)
+		}
+	}
+
+	client, err := Typed[stubClient](m, "typed-ok")
+	if err != nil || client.Name() != "typed-ok" {
+		t.Fatalf("want typed client, got %v / %v", client, err)
+	}
+
+	// 类型不匹配或工厂返回 nil 时返回 ErrClientType 而不是 panic
+	for _, name := range []string{"typed-wrong", "typed-nil"} {
+		if _, err := Typed[stubClient](m, name); !errors.Is(err, ErrClientType) {
+			t.Fatalf("%s: want ErrClientType, got %v", name, err)
+		}
+	}
+	if _, err := Typed[stubClient](m, "typed-unregistered"); err == nil || errors.Is(err, ErrClientType) {
+		t.Fatalf("want factory not found error, got %v", err)
+	}
+	if _, err := Typed[stubClient](m, "not-connected"); err == nil {
+		t.Fatal("want error for service without connection")
+	}
+}
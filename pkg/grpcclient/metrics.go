@@ -0,0 +1,65 @@
+package grpcclient
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc/connectivity"
+)
+
+// connectivityStates 所有连接状态，用于为每个服务输出完整的状态序列
+var connectivityStates = []connectivity.State{
+	connectivity.Idle,
+	connectivity.Connecting,
+	connectivity.Ready,
+	connectivity.TransientFailure,
+	connectivity.Shutdown,
+}
+
+var (
+	// connectionsDesc 管理器持有的连接总数
+	connectionsDesc = prometheus.NewDesc(
+		"grpc_client_connections",
+		"Number of gRPC client connections held by the manager.",
+		nil, nil,
+	)
+
+	// connectionStateDesc 每个服务连接的当前状态，当前状态为 1，其余为 0
+	connectionStateDesc = prometheus.NewDesc(
+		"grpc_client_connection_state",
+		"Current connectivity state of each gRPC client connection (1 for the current state).",
+		[]string{"service", "state"}, nil,
+	)
+)
+
+// connectionCollector 连接状态采集器
+// 在每次抓取时读取管理器的实时状态，无需后台刷新
+type connectionCollector struct {
+	manager *Manager
+}
+
+// Describe 实现 prometheus.Collector
+func (c *connectionCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- connectionsDesc
+	ch <- connectionStateDesc
+}
+
+// Collect 实现 prometheus.Collector
+func (c *connectionCollector) Collect(ch chan<- prometheus.Metric) {
+	states := c.manager.ConnectionStates()
+
+	ch <- prometheus.MustNewConstMetric(connectionsDesc, prometheus.GaugeValue, float64(len(states)))
+
+	for serviceName, current := range states {
+		for _, state := range connectivityStates {
+			value := 0.0
+			if state == current {
+				value = 1
+			}
+			ch <- prometheus.MustNewConstMetric(connectionStateDesc, prometheus.GaugeValue, value, serviceName, state.String())
+		}
+	}
+}
+
+// RegisterMetrics 将连接数量和连接状态指标注册到指定的注册器
+func (m *Manager) RegisterMetrics(reg prometheus.Registerer) error {
+	return reg.Register(&connectionCollector{manager: m})
+}
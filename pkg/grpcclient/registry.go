@@ -1,12 +1,17 @@
 package grpcclient
 
 import (
+	"errors"
 	"fmt"
 	"sync"
-	
+
+	apperrors "github.com/alfredchaos/demo/pkg/errors"
 	"google.golang.org/grpc"
 )
 
+// ErrUnknownClient 配置中声明的客户端没有对应的工厂
+var ErrUnknownClient = errors.New("unknown grpc client")
+
 // ClientFactory 客户端创建函数
 type ClientFactory func(conn *grpc.ClientConn) interface{}
 
@@ -47,15 +52,19 @@ func (r *Registry) CreateClient(serviceName string, conn *grpc.ClientConn) (inte
 	return factory(conn), nil
 }
 
-// 使用示例：注册客户端工厂
-// func init() {
-//     // 注册user-service客户端
-//     grpcclient.GlobalRegistry.Register("user-service", func(conn *grpc.ClientConn) interface{} {
-//         return userv1.NewUserServiceClient(conn)
-//     })
-//     
-//     // 注册book-service客户端
-//     grpcclient.GlobalRegistry.Register("book-service", func(conn *grpc.ClientConn) interface{} {
-//         return bookv1.NewBookServiceClient(conn)
-//     })
-// }
+// RegisterFromConfig 按配置为每个服务注册客户端工厂
+// 服务的 client 字段（为空时使用服务名称）指定从 factories 中选用的工厂；
+// 找不到工厂的服务汇总为错误返回，避免启动后 GetClient 才失败
+func (r *Registry) RegisterFromConfig(cfg *Config, factories map[string]ClientFactory) error {
+	var errs apperrors.MultiError
+	for _, svc := range cfg.Services {
+		kind := svc.ClientKind()
+		factory, exists := factories[kind]
+		if !exists {
+			errs.Append(fmt.Errorf("%w: client %q required by service %s", ErrUnknownClient, kind, svc.Name))
+			continue
+		}
+		r.Register(svc.Name, factory)
+	}
+	return errs.ErrorOrNil()
+}
@@ -0,0 +1,58 @@
+package grpcclient
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc"
+)
+
+func TestRegistry_RegisterFromConfig(t *testing.T) {
+	factories := map[string]ClientFactory{
+		"user-service": func(conn *grpc.ClientConn) interface{} { return "user-client" },
+	}
+	cfg := &Config{Services: []ServiceConfig{
+		{Name: "user-service", Address: "localhost:9001"},
+		{Name: "user-service-replica", Address: "localhost:9011", Client: "user-service"},
+	}}
+
+	r := NewRegistry()
+	if err := r.RegisterFromConfig(cfg, factories); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, name := range []string{"user-service", "user-service-replica"} {
+		client, err := r.CreateClient(name, nil)
+		if err != nil {
+			t.Fatalf("create client for %s: %v", name, err)
+		}
+		if client != "user-client" {
+			t.Fatalf("want user-client for %s, got %v", name, client)
+		}
+	}
+}
+
+func TestRegistry_RegisterFromConfig_UnknownClient(t *testing.T) {
+	cfg := &Config{Services: []ServiceConfig{
+		{Name: "user-service", Address: "localhost:9001"},
+		{Name: "order-service", Address: "localhost:9003"},
+	}}
+
+	factories := map[string]ClientFactory{
+		"user-service": func(conn *grpc.ClientConn) interface{} { return "user-client" },
+	}
+
+	r := NewRegistry()
+	err := r.RegisterFromConfig(cfg, factories)
+	if !errors.Is(err, ErrUnknownClient) {
+		t.Fatalf("want ErrUnknownClient, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "order-service") {
+		t.Fatalf("want error to name the service, got %v", err)
+	}
+
+	// 已知的客户端仍然注册成功
+	if _, err := r.CreateClient("user-service", nil); err != nil {
+		t.Fatalf("known client should be registered: %v", err)
+	}
+}
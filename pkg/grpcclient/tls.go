@@ -0,0 +1,56 @@
+package grpcclient
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// buildTransportCredentials 根据 TLS 配置构建传输凭证，未配置或未启用 TLS 时使用明文连接
+// 启用 TLS 但证书无法加载时直接返回错误，避免静默回退到明文连接
+func buildTransportCredentials(cfg *TLSConfig) (credentials.TransportCredentials, error) {
+	if cfg == nil || !cfg.Enabled {
+		return insecure.NewCredentials(), nil
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: cfg.ServerNameOverride, // 为空时 gRPC 使用连接地址中的主机名
+	}
+
+	caFile := cfg.CAFile
+	clientCert := cfg.KeyFile != ""
+	if caFile == "" && !clientCert {
+		// 仅配置 cert_file 时与 credentials.NewClientTLSFromFile 一致，作为服务端证书校验
+		caFile = cfg.CertFile
+	}
+
+	if caFile != "" {
+		pem, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read grpc tls ca file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("failed to parse grpc tls ca file %s: no valid certificates", caFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if clientCert {
+		if cfg.CertFile == "" {
+			return nil, fmt.Errorf("grpc tls requires both cert_file and key_file for client certificate")
+		}
+		cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load grpc tls client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return credentials.NewTLS(tlsConfig), nil
+}
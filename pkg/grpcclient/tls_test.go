@@ -0,0 +1,200 @@
+package grpcclient
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+// testCA 测试用的自签名 CA
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	file string // CA 证书 PEM 文件路径
+}
+
+// newTestCA 生成自签名 CA 并写入临时目录
+func newTestCA(t *testing.T) *testCA {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("create ca certificate: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("parse ca certificate: %v", err)
+	}
+
+	file := filepath.Join(t.TempDir(), "ca.pem")
+	writePEM(t, file, "CERTIFICATE", der)
+	return &testCA{cert: cert, key: key, file: file}
+}
+
+// issue 签发证书，dnsName 为证书中的主机名
+// 返回的证书和私钥同时写入临时目录，供客户端按文件加载
+func (ca *testCA) issue(t *testing.T, dnsName string, usage x509.ExtKeyUsage) (tls.Certificate, string, string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: dnsName},
+		DNSNames:     []string{dnsName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatalf("create certificate: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("marshal key: %v", err)
+	}
+
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	writePEM(t, certFile, "CERTIFICATE", der)
+	writePEM(t, keyFile, "EC PRIVATE KEY", keyDER)
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}, certFile, keyFile
+}
+
+func writePEM(t *testing.T, file, blockType string, der []byte) {
+	t.Helper()
+	if err := os.WriteFile(file, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600); err != nil {
+		t.Fatalf("write %s: %v", file, err)
+	}
+}
+
+// startMTLSServer 启动要求客户端证书的 gRPC 服务，服务端证书只包含 serverName
+func startMTLSServer(t *testing.T, ca *testCA, serverName string) string {
+	t.Helper()
+
+	serverCert, _, _ := ca.issue(t, serverName, x509.ExtKeyUsageServerAuth)
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.cert)
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	srv := grpc.NewServer(grpc.Creds(credentials.NewTLS(&tls.Config{
+		Certificates: []tls.Certificate{serverCert},
+		ClientCAs:    pool,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+	})))
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	return lis.Addr().String()
+}
+
+func TestManager_ConnectWithMutualTLS(t *testing.T) {
+	useNopLogger(t)
+	ca := newTestCA(t)
+	// 服务端证书的主机名与连接地址不一致，需要 ServerNameOverride
+	addr := startMTLSServer(t, ca, "backend.internal")
+	_, certFile, keyFile := ca.issue(t, "client", x509.ExtKeyUsageClientAuth)
+
+	m := NewManager()
+	if err := m.Register(&ServiceConfig{
+		Name:    "secure",
+		Address: addr,
+		TLS: &TLSConfig{
+			Enabled:            true,
+			CAFile:             ca.file,
+			CertFile:           certFile,
+			KeyFile:            keyFile,
+			ServerNameOverride: "backend.internal",
+		},
+	}); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	defer m.Close()
+
+	connectAndWaitReady(t, m, "secure")
+}
+
+func TestManager_ConnectFailsOnBadTLSFiles(t *testing.T) {
+	useNopLogger(t)
+	ca := newTestCA(t)
+	_, certFile, keyFile := ca.issue(t, "client", x509.ExtKeyUsageClientAuth)
+	notPEM := filepath.Join(t.TempDir(), "bad.pem")
+	if err := os.WriteFile(notPEM, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	missing := filepath.Join(t.TempDir(), "missing.pem")
+
+	cases := []struct {
+		name string
+		tls  TLSConfig
+		want string
+	}{
+		{"missing ca file", TLSConfig{CAFile: missing}, "failed to read grpc tls ca file"},
+		{"invalid ca file", TLSConfig{CAFile: notPEM}, "no valid certificates"},
+		{"missing cert file", TLSConfig{CertFile: missing}, "failed to read grpc tls ca file"},
+		{"key without cert", TLSConfig{CAFile: ca.file, KeyFile: keyFile}, "requires both cert_file and key_file"},
+		{"invalid key pair", TLSConfig{CAFile: ca.file, CertFile: certFile, KeyFile: notPEM}, "failed to load grpc tls client certificate"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			c.tls.Enabled = true
+			m := NewManager()
+			if err := m.Register(&ServiceConfig{Name: "secure", Address: "127.0.0.1:0", TLS: &c.tls}); err != nil {
+				t.Fatalf("register: %v", err)
+			}
+			defer m.Close()
+
+			err := m.Connect("secure")
+			if err == nil || !strings.Contains(err.Error(), c.want) {
+				t.Fatalf("want error containing %q, got %v", c.want, err)
+			}
+		})
+	}
+}
+
+func TestBuildTransportCredentials_InsecureByDefault(t *testing.T) {
+	for _, cfg := range []*TLSConfig{nil, {Enabled: false, CAFile: "ignored.pem"}} {
+		creds, err := buildTransportCredentials(cfg)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got := creds.Info().SecurityProtocol; got != "insecure" {
+			t.Fatalf("want insecure credentials, got %s", got)
+		}
+	}
+}
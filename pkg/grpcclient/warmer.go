@@ -0,0 +1,98 @@
+package grpcclient
+
+import (
+	"context"
+	"time"
+
+	"github.com/alfredchaos/demo/pkg/log"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/connectivity"
+)
+
+// defaultWarmInterval 连接预热的默认检查间隔
+const defaultWarmInterval = 10 * time.Second
+
+// WarmerConfig 连接预热配置
+// 后端断开重连后，连接会停留在 IDLE 状态直到下一次调用，导致首个请求额外承担建连延迟；
+// 预热器定期检查连接状态并主动触发重连，使连接保持 READY
+type WarmerConfig struct {
+	Enabled  bool          `yaml:"enabled" mapstructure:"enabled"`   // 是否启用后台预热
+	Interval time.Duration `yaml:"interval" mapstructure:"interval"` // 检查间隔，默认10s
+}
+
+// StartWarmer 启动后台连接预热
+// 每隔 interval 检查一次所有连接，非 READY/CONNECTING 的连接主动发起重连；重复调用会先停止已有的预热器，
+// Manager 关闭后调用不再启动
+func (m *Manager) StartWarmer(interval time.Duration) {
+	if interval <= 0 {
+		interval = defaultWarmInterval
+	}
+
+	// 启动与停止都在 warmerMu 内完成，避免与并发的 StartWarmer / Close 交错后遗留预热器
+	m.warmerMu.Lock()
+	defer m.warmerMu.Unlock()
+
+	if m.warmerClosed {
+		return
+	}
+	m.stopWarmerLocked()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	m.stopWarmer = cancel
+	m.warmerDone = done
+
+	go func() {
+		defer close(done)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		m.warm()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				m.warm()
+			}
+		}
+	}()
+
+	log.Info("grpc connection warmer started", zap.Duration("interval", interval))
+}
+
+// StopWarmer 停止后台连接预热并等待其退出
+func (m *Manager) StopWarmer() {
+	m.warmerMu.Lock()
+	defer m.warmerMu.Unlock()
+	m.stopWarmerLocked()
+}
+
+// stopWarmerLocked 停止预热器并等待其退出，调用方需持有 warmerMu
+// 预热器只获取 m.mu 读锁，持有 warmerMu 等待不会死锁
+func (m *Manager) stopWarmerLocked() {
+	if m.stopWarmer == nil {
+		return
+	}
+	m.stopWarmer()
+	<-m.warmerDone
+	m.stopWarmer, m.warmerDone = nil, nil
+}
+
+// warm 检查所有连接，对冷连接发起重连
+func (m *Manager) warm() {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for serviceName, conn := range m.connections {
+		switch state := conn.GetState(); state {
+		case connectivity.Ready, connectivity.Connecting, connectivity.Shutdown:
+		default:
+			log.Debug("warming grpc connection",
+				zap.String("remote_service", serviceName),
+				zap.String("state", state.String()))
+			conn.Connect()
+		}
+	}
+}
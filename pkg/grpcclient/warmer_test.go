@@ -0,0 +1,127 @@
+package grpcclient
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+)
+
+// serveOn 在指定地址启动 gRPC 服务，返回停止函数
+func serveOn(t *testing.T, addr string) func() {
+	t.Helper()
+
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		t.Fatalf("failed to listen on %s: %v", addr, err)
+	}
+
+	srv := grpc.NewServer()
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+	return srv.Stop
+}
+
+// waitForState 等待连接进入指定状态
+func waitForState(t *testing.T, conn *grpc.ClientConn, want connectivity.State, timeout time.Duration) {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	for state := conn.GetState(); state != want; state = conn.GetState() {
+		if !conn.WaitForStateChange(ctx, state) {
+			t.Fatalf("connection not %s, stuck in %s", want, state)
+		}
+	}
+}
+
+func TestWarmer_KeepsConnectionReady(t *testing.T) {
+	useNopLogger(t)
+	addr := startTestServer(t)
+	m := NewManager()
+	defer m.Close()
+
+	if err := m.Register(&ServiceConfig{Name: "user-service", Address: addr}); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	if err := m.Connect("user-service"); err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	conn, _ := m.GetConnection("user-service")
+
+	m.StartWarmer(20 * time.Millisecond)
+
+	waitForState(t, conn, connectivity.Ready, 5*time.Second)
+}
+
+func TestWarmer_RewarmsAfterDrop(t *testing.T) {
+	useNopLogger(t)
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	addr := lis.Addr().String()
+	lis.Close()
+
+	stop := serveOn(t, addr)
+
+	m := NewManager()
+	defer m.Close()
+	if err := m.Register(&ServiceConfig{Name: "book-service", Address: addr}); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	connectAndWaitReady(t, m, "book-service")
+	conn, _ := m.GetConnection("book-service")
+
+	// 模拟后端断开：连接离开 READY 后会停留在 IDLE，直到有人触发重连
+	stop()
+	waitForState(t, conn, connectivity.Idle, 5*time.Second)
+
+	serveOn(t, addr)
+	m.StartWarmer(20 * time.Millisecond)
+
+	waitForState(t, conn, connectivity.Ready, 5*time.Second)
+}
+
+func TestWarmer_StopIsIdempotent(t *testing.T) {
+	useNopLogger(t)
+	m := NewManager()
+	m.StartWarmer(time.Millisecond)
+	m.StopWarmer()
+	m.StopWarmer()
+	if err := m.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+}
+
+func TestWarmer_ConcurrentStartAndClose(t *testing.T) {
+	useNopLogger(t)
+	for i := 0; i < 50; i++ {
+		m := NewManager()
+
+		var wg sync.WaitGroup
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			m.StartWarmer(time.Millisecond)
+		}()
+		go func() {
+			defer wg.Done()
+			_ = m.Close()
+		}()
+		wg.Wait()
+
+		// 无论先后顺序，Close 返回后都不应留下运行中的预热器
+		m.warmerMu.Lock()
+		running := m.stopWarmer != nil
+		m.warmerMu.Unlock()
+		if running {
+			t.Fatal("want no warmer running after Close")
+		}
+	}
+}
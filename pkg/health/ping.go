@@ -0,0 +1,61 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DefaultPingTimeout 单个客户端探测的默认超时
+const DefaultPingTimeout = 2 * time.Second
+
+// Pinger 可探测连接状态的客户端
+// db.PostgresClient、db.MongoClient、cache.RedisClient、mq.RabbitMQClient 均实现该接口
+type Pinger interface {
+	PingContext(ctx context.Context) error
+}
+
+// Target 带名称的探测目标，名称用于区分结果
+type Target struct {
+	Name   string
+	Pinger Pinger
+}
+
+// Named 创建探测目标
+func Named(name string, pinger Pinger) Target {
+	return Target{Name: name, Pinger: pinger}
+}
+
+// PingAll 并发探测所有目标，每个目标单独受 timeout 约束，慢的依赖不会拖慢其他探测
+// 返回以名称为键的结果，探测成功的值为 nil；timeout <= 0 时使用 DefaultPingTimeout
+func PingAll(ctx context.Context, timeout time.Duration, targets ...Target) map[string]error {
+	if timeout <= 0 {
+		timeout = DefaultPingTimeout
+	}
+
+	results := make(map[string]error, len(targets))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, target := range targets {
+		wg.Add(1)
+		go func(target Target) {
+			defer wg.Done()
+
+			pingCtx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+
+			err := target.Pinger.PingContext(pingCtx)
+			if err != nil {
+				err = fmt.Errorf("failed to ping %s: %w", target.Name, err)
+			}
+
+			mu.Lock()
+			results[target.Name] = err
+			mu.Unlock()
+		}(target)
+	}
+	wg.Wait()
+
+	return results
+}
@@ -0,0 +1,45 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// pingFunc 函数形式的 Pinger
+type pingFunc func(ctx context.Context) error
+
+func (f pingFunc) PingContext(ctx context.Context) error { return f(ctx) }
+
+func TestPingAll_BoundsEachTargetByTimeout(t *testing.T) {
+	healthy := pingFunc(func(ctx context.Context) error { return nil })
+	hanging := pingFunc(func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	broken := pingFunc(func(ctx context.Context) error { return errors.New("connection refused") })
+
+	start := time.Now()
+	results := PingAll(context.Background(), 50*time.Millisecond,
+		Named("redis", healthy),
+		Named("postgres", hanging),
+		Named("mongo", broken),
+	)
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("want PingAll bounded by per-target timeout, took %v", elapsed)
+	}
+
+	if len(results) != 3 {
+		t.Fatalf("want 3 results, got %v", results)
+	}
+	if results["redis"] != nil {
+		t.Errorf("want redis healthy, got %v", results["redis"])
+	}
+	if !errors.Is(results["postgres"], context.DeadlineExceeded) {
+		t.Errorf("want postgres deadline exceeded, got %v", results["postgres"])
+	}
+	if results["mongo"] == nil {
+		t.Error("want mongo error")
+	}
+}
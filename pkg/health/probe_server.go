@@ -0,0 +1,97 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/alfredchaos/demo/pkg/log"
+	"go.uber.org/zap"
+)
+
+// DefaultProbeAddr 探针服务默认监听地址
+const DefaultProbeAddr = ":8086"
+
+// DefaultCheckCacheTTL 依赖探测结果默认缓存时长
+const DefaultCheckCacheTTL = 5 * time.Second
+
+// ProbeConfig 探针服务配置
+type ProbeConfig struct {
+	Enabled       bool          `yaml:"enabled" mapstructure:"enabled"`                 // 是否启用
+	Addr          string        `yaml:"addr" mapstructure:"addr"`                       // 监听地址，默认 :8086，需要能被 k8s 探针访问
+	CheckCacheTTL time.Duration `yaml:"check_cache_ttl" mapstructure:"check_cache_ttl"` // 依赖探测结果缓存时长，默认 5s，负数表示每次都探测
+	DrainDelay    time.Duration `yaml:"drain_delay" mapstructure:"drain_delay"`         // 收到退出信号后 /readyz 先返回 503 并等待的时长，让负载均衡摘除实例，默认 0
+}
+
+// NewReadiness 按配置创建就绪状态
+func (c *ProbeConfig) NewReadiness() *Readiness {
+	ttl := c.CheckCacheTTL
+	if ttl == 0 {
+		ttl = DefaultCheckCacheTTL
+	}
+	return NewReadiness(0).CacheChecks(ttl)
+}
+
+// ProbeServer 提供 /livez 与 /readyz 的 HTTP 服务，供只暴露 gRPC 端口的服务配置 k8s 探针
+type ProbeServer struct {
+	srv      *http.Server
+	listener net.Listener
+}
+
+// ProbeHandler 返回探针路由
+// /livez 只要进程能响应即返回 200；/readyz 由 readiness 决定
+func ProbeHandler(readiness *Readiness) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/livez", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("ok"))
+	})
+	mux.Handle("/readyz", readiness)
+	return mux
+}
+
+// StartProbeServer 按配置启动探针服务，未启用时返回 nil
+// 应在连接依赖之前启动，依赖连接期间 /readyz 返回 503 而不是连接被拒绝
+func StartProbeServer(cfg *ProbeConfig, readiness *Readiness) (*ProbeServer, error) {
+	if cfg == nil || !cfg.Enabled {
+		return nil, nil
+	}
+
+	addr := cfg.Addr
+	if addr == "" {
+		addr = DefaultProbeAddr
+	}
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen probe server: %w", err)
+	}
+
+	s := &ProbeServer{
+		srv:      &http.Server{Handler: ProbeHandler(readiness)},
+		listener: listener,
+	}
+	go func() {
+		if err := s.srv.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Error("probe server stopped with error", zap.Error(err))
+		}
+	}()
+
+	log.Info("probe server started", zap.String("addr", listener.Addr().String()))
+	return s, nil
+}
+
+// Addr 实际监听地址
+func (s *ProbeServer) Addr() net.Addr {
+	return s.listener.Addr()
+}
+
+// Stop 关闭探针服务，s 为 nil（未启用）时为空操作
+func (s *ProbeServer) Stop(ctx context.Context) error {
+	if s == nil {
+		return nil
+	}
+	return s.srv.Shutdown(ctx)
+}
@@ -0,0 +1,211 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/alfredchaos/demo/pkg/log"
+	"go.uber.org/zap"
+)
+
+// startupPending 启动流程未完成时在 pending 中显示的名称
+const startupPending = "startup"
+
+// shutdownPending 服务开始关闭后在 pending 中显示的名称
+const shutdownPending = "shutdown"
+
+// Readiness 服务就绪状态
+// 启动阶段登记必需的依赖，依赖连接成功后标记就绪；启动流程完成且所有依赖就绪前 /readyz 返回 503，
+// 之后的检查会探测已连接的依赖（结果可按 CacheChecks 缓存），任一失败同样返回 503；收到退出信号后调用 Drain 重新返回 503
+// 所有方法对 nil 接收者安全，未启用就绪检查时可直接传 nil
+type Readiness struct {
+	mu       sync.RWMutex
+	started  time.Time
+	pending  map[string]struct{}
+	targets  []Target
+	complete bool
+	draining bool
+	timeout  time.Duration
+	cacheTTL time.Duration
+
+	// checkMu 串行化依赖探测，并发的探针请求共享同一次探测结果
+	checkMu   sync.Mutex
+	cached    ReadinessReport
+	checkedAt time.Time
+}
+
+// NewReadiness 创建就绪状态，pingTimeout 为单个依赖探测的超时，<= 0 时使用 DefaultPingTimeout
+func NewReadiness(pingTimeout time.Duration) *Readiness {
+	return &Readiness{
+		started: time.Now(),
+		pending: make(map[string]struct{}),
+		timeout: pingTimeout,
+	}
+}
+
+// CacheChecks 设置依赖探测结果的缓存时长
+// ttl 内的就绪检查直接返回上次探测结果，避免探针频繁请求时每次都探测所有依赖；<= 0 时不缓存
+func (r *Readiness) CacheChecks(ttl time.Duration) *Readiness {
+	if r == nil {
+		return nil
+	}
+	r.checkMu.Lock()
+	defer r.checkMu.Unlock()
+	r.cacheTTL = ttl
+	return r
+}
+
+// Require 登记必需的依赖，在 MarkReady 之前服务不会就绪
+func (r *Readiness) Require(name string) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.pending[name] = struct{}{}
+}
+
+// MarkReady 标记依赖已连接，pinger 不为 nil 时后续就绪检查会探测该依赖
+func (r *Readiness) MarkReady(name string, pinger Pinger) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.pending, name)
+	if pinger != nil {
+		r.targets = append(r.targets, Named(name, pinger))
+	}
+	log.Info("dependency ready",
+		zap.String("dependency", name),
+		zap.Duration("elapsed", time.Since(r.started)))
+}
+
+// Complete 标记启动流程完成（服务已开始接收请求）
+func (r *Readiness) Complete() {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.complete = true
+}
+
+// Drain 标记服务开始关闭，之后 /readyz 返回 503
+// 并等待 delay，让负载均衡在停止接收请求之前摘除本实例；应在收到退出信号后、关闭服务之前调用
+func (r *Readiness) Drain(delay time.Duration) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	r.draining = true
+	r.mu.Unlock()
+
+	if delay > 0 {
+		log.Info("readiness set to not ready, draining before shutdown", zap.Duration("delay", delay))
+		time.Sleep(delay)
+	}
+}
+
+// Pending 返回尚未就绪的依赖，启动流程未完成时包含 "startup"，开始关闭后包含 "shutdown"
+func (r *Readiness) Pending() []string {
+	if r == nil {
+		return nil
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	pending := make([]string, 0, len(r.pending)+1)
+	for name := range r.pending {
+		pending = append(pending, name)
+	}
+	sort.Strings(pending)
+	if !r.complete {
+		pending = append(pending, startupPending)
+	}
+	if r.draining {
+		pending = append(pending, shutdownPending)
+	}
+	return pending
+}
+
+// ReadinessReport 就绪检查结果
+type ReadinessReport struct {
+	Ready   bool              `json:"ready"`
+	Pending []string          `json:"pending,omitempty"` // 尚未就绪的依赖
+	Checks  map[string]string `json:"checks,omitempty"`  // 已连接依赖的探测结果，成功为 "ok"
+}
+
+// Check 检查服务是否就绪
+// 仍有未就绪的依赖时不做探测，直接返回未就绪
+func (r *Readiness) Check(ctx context.Context) ReadinessReport {
+	if r == nil {
+		return ReadinessReport{Ready: true}
+	}
+	if pending := r.Pending(); len(pending) > 0 {
+		return ReadinessReport{Pending: pending}
+	}
+
+	r.checkMu.Lock()
+	defer r.checkMu.Unlock()
+	if r.cacheTTL > 0 && !r.checkedAt.IsZero() && time.Since(r.checkedAt) < r.cacheTTL {
+		return r.cached
+	}
+
+	r.mu.RLock()
+	targets := append([]Target(nil), r.targets...)
+	r.mu.RUnlock()
+
+	report := ReadinessReport{Ready: true, Checks: make(map[string]string, len(targets))}
+	for name, err := range PingAll(ctx, r.timeout, targets...) {
+		if err != nil {
+			report.Ready = false
+			report.Checks[name] = err.Error()
+			continue
+		}
+		report.Checks[name] = "ok"
+	}
+	r.cached, r.checkedAt = report, time.Now()
+	return report
+}
+
+// ServeHTTP 处理 /readyz，就绪时返回 200，否则返回 503
+func (r *Readiness) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	report := r.Check(req.Context())
+
+	status := http.StatusOK
+	if !report.Ready {
+		status = http.StatusServiceUnavailable
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(report)
+}
+
+// WatchStartup 启动期间每隔 interval 记录一次仍在等待的依赖，全部就绪或 ctx 取消后返回
+// 依赖连接重试较久时，可从日志中看到服务卡在哪个依赖上
+func (r *Readiness) WatchStartup(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		pending := r.Pending()
+		if len(pending) == 0 {
+			log.Info("startup completed", zap.Duration("elapsed", time.Since(r.started)))
+			return
+		}
+		log.Warn("startup in progress, waiting for dependencies",
+			zap.Strings("pending", pending),
+			zap.Duration("elapsed", time.Since(r.started)))
+	}
+}
@@ -0,0 +1,144 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alfredchaos/demo/pkg/log"
+	"go.uber.org/zap"
+)
+
+// useNopLogger 测试期间使用空日志，结束后恢复原日志
+func useNopLogger(t *testing.T) {
+	t.Helper()
+	original := log.Logger
+	log.Logger = zap.NewNop()
+	t.Cleanup(func() { log.Logger = original })
+}
+
+// readyz 请求 /readyz 并返回状态码和结果
+func readyz(t *testing.T, handler http.Handler) (int, ReadinessReport) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	var report ReadinessReport
+	if err := json.Unmarshal(rec.Body.Bytes(), &report); err != nil {
+		t.Fatalf("failed to decode readiness report: %v", err)
+	}
+	return rec.Code, report
+}
+
+func TestReadiness_FlipsToReadyOnlyAfterAllProbesPass(t *testing.T) {
+	useNopLogger(t)
+	readiness := NewReadiness(0)
+	handler := ProbeHandler(readiness)
+
+	var redisDown atomic.Bool
+	redisDown.Store(true)
+	postgres := pingFunc(func(ctx context.Context) error { return nil })
+	redis := pingFunc(func(ctx context.Context) error {
+		if redisDown.Load() {
+			return errors.New("connection refused")
+		}
+		return nil
+	})
+
+	readiness.Require("postgres")
+	readiness.Require("redis")
+
+	// 依赖连接中
+	if code, report := readyz(t, handler); code != http.StatusServiceUnavailable || len(report.Pending) != 3 {
+		t.Fatalf("want 503 with postgres, redis and startup pending, got %d %+v", code, report)
+	}
+
+	// 部分依赖已连接
+	readiness.MarkReady("postgres", postgres)
+	if code, report := readyz(t, handler); code != http.StatusServiceUnavailable ||
+		len(report.Pending) != 2 || report.Pending[0] != "redis" {
+		t.Fatalf("want 503 with redis and startup pending, got %d %+v", code, report)
+	}
+
+	// 依赖全部连接但启动流程未完成
+	readiness.MarkReady("redis", redis)
+	if code, report := readyz(t, handler); code != http.StatusServiceUnavailable ||
+		len(report.Pending) != 1 || report.Pending[0] != "startup" {
+		t.Fatalf("want 503 with startup pending, got %d %+v", code, report)
+	}
+
+	// 启动完成，但探测失败
+	readiness.Complete()
+	if code, report := readyz(t, handler); code != http.StatusServiceUnavailable ||
+		report.Checks["postgres"] != "ok" || report.Checks["redis"] == "ok" {
+		t.Fatalf("want 503 while redis probe fails, got %d %+v", code, report)
+	}
+
+	// 所有探测通过
+	redisDown.Store(false)
+	if code, report := readyz(t, handler); code != http.StatusOK || !report.Ready {
+		t.Fatalf("want 200 once all probes pass, got %d %+v", code, report)
+	}
+}
+
+func TestProbeHandler_LivezIgnoresReadiness(t *testing.T) {
+	readiness := NewReadiness(0)
+	readiness.Require("postgres")
+
+	rec := httptest.NewRecorder()
+	ProbeHandler(readiness).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/livez", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("want /livez 200 while not ready, got %d", rec.Code)
+	}
+}
+
+func TestReadiness_DrainFlipsToNotReady(t *testing.T) {
+	useNopLogger(t)
+	readiness := NewReadiness(0)
+	readiness.Complete()
+	handler := ProbeHandler(readiness)
+
+	if code, _ := readyz(t, handler); code != http.StatusOK {
+		t.Fatalf("want 200 before drain, got %d", code)
+	}
+
+	readiness.Drain(0)
+	if code, report := readyz(t, handler); code != http.StatusServiceUnavailable ||
+		len(report.Pending) != 1 || report.Pending[0] != "shutdown" {
+		t.Fatalf("want 503 with shutdown pending after drain, got %d %+v", code, report)
+	}
+}
+
+func TestReadiness_CachesChecksWithinTTL(t *testing.T) {
+	useNopLogger(t)
+	readiness := NewReadiness(0).CacheChecks(time.Hour)
+	var pings atomic.Int32
+	readiness.Require("postgres")
+	readiness.MarkReady("postgres", pingFunc(func(ctx context.Context) error {
+		pings.Add(1)
+		return nil
+	}))
+	readiness.Complete()
+	handler := ProbeHandler(readiness)
+
+	for i := 0; i < 3; i++ {
+		if code, _ := readyz(t, handler); code != http.StatusOK {
+			t.Fatalf("want 200, got %d", code)
+		}
+	}
+	if got := pings.Load(); got != 1 {
+		t.Fatalf("want dependencies pinged once within ttl, got %d", got)
+	}
+
+	// 不缓存时每次都探测
+	readiness.CacheChecks(0)
+	readyz(t, handler)
+	if got := pings.Load(); got != 2 {
+		t.Fatalf("want dependencies pinged again without cache, got %d", got)
+	}
+}
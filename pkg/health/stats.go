@@ -0,0 +1,82 @@
+package health
+
+import (
+	"runtime"
+	"time"
+)
+
+// StatsProvider 可提供连接池统计的客户端
+// db.PostgresClient、db.MongoClient、cache.RedisClient 均实现该接口
+type StatsProvider interface {
+	Stats() (map[string]interface{}, error)
+}
+
+// StatsSource 带名称的统计来源，名称用于区分结果
+type StatsSource struct {
+	Name     string
+	Provider StatsProvider
+}
+
+// NamedStats 创建统计来源
+func NamedStats(name string, provider StatsProvider) StatsSource {
+	return StatsSource{Name: name, Provider: provider}
+}
+
+// RuntimeStats Go 运行时统计
+type RuntimeStats struct {
+	Goroutines   int    `json:"goroutines"`     // 当前 goroutine 数
+	HeapAlloc    uint64 `json:"heap_alloc"`     // 已分配且未释放的堆内存(字节)
+	HeapInuse    uint64 `json:"heap_inuse"`     // 使用中的堆内存 span(字节)
+	HeapObjects  uint64 `json:"heap_objects"`   // 堆上存活的对象数
+	Sys          uint64 `json:"sys"`            // 从操作系统获取的内存总量(字节)
+	TotalAlloc   uint64 `json:"total_alloc"`    // 累计分配的堆内存(字节)
+	NumGC        uint32 `json:"num_gc"`         // 已完成的 GC 次数
+	PauseTotalNs uint64 `json:"pause_total_ns"` // GC 累计暂停时间(纳秒)
+	LastGC       string `json:"last_gc"`        // 最近一次 GC 的时间，未发生过 GC 时为空
+}
+
+// StatsSnapshot 连接池与运行时统计快照
+// 单个连接池统计失败时，其结果为 {"error": "..."}，不影响其他来源
+type StatsSnapshot struct {
+	Pools   map[string]map[string]interface{} `json:"pools"`
+	Runtime RuntimeStats                      `json:"runtime"`
+}
+
+// CollectStats 采集所有来源的连接池统计及当前运行时统计
+// runtime.ReadMemStats 会短暂 stop-the-world，只应在按需排查时调用，不要放在高频路径上
+func CollectStats(sources ...StatsSource) *StatsSnapshot {
+	pools := make(map[string]map[string]interface{}, len(sources))
+	for _, source := range sources {
+		stats, err := source.Provider.Stats()
+		if err != nil {
+			stats = map[string]interface{}{"error": err.Error()}
+		}
+		pools[source.Name] = stats
+	}
+
+	return &StatsSnapshot{
+		Pools:   pools,
+		Runtime: ReadRuntimeStats(),
+	}
+}
+
+// ReadRuntimeStats 读取当前运行时统计
+func ReadRuntimeStats() RuntimeStats {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	stats := RuntimeStats{
+		Goroutines:   runtime.NumGoroutine(),
+		HeapAlloc:    mem.HeapAlloc,
+		HeapInuse:    mem.HeapInuse,
+		HeapObjects:  mem.HeapObjects,
+		Sys:          mem.Sys,
+		TotalAlloc:   mem.TotalAlloc,
+		NumGC:        mem.NumGC,
+		PauseTotalNs: mem.PauseTotalNs,
+	}
+	if mem.LastGC > 0 {
+		stats.LastGC = time.Unix(0, int64(mem.LastGC)).Format(time.RFC3339Nano)
+	}
+	return stats
+}
@@ -0,0 +1,33 @@
+package health
+
+import (
+	"errors"
+	"testing"
+)
+
+// statsFunc 函数形式的 StatsProvider
+type statsFunc func() (map[string]interface{}, error)
+
+func (f statsFunc) Stats() (map[string]interface{}, error) { return f() }
+
+func TestCollectStats_AggregatesPoolsAndRuntime(t *testing.T) {
+	postgres := statsFunc(func() (map[string]interface{}, error) {
+		return map[string]interface{}{"open_connections": 3, "in_use": 1}, nil
+	})
+	broken := statsFunc(func() (map[string]interface{}, error) {
+		return nil, errors.New("pool closed")
+	})
+
+	snapshot := CollectStats(NamedStats("postgres", postgres), NamedStats("redis", broken))
+
+	if got := snapshot.Pools["postgres"]["open_connections"]; got != 3 {
+		t.Fatalf("want postgres open_connections=3, got %v", got)
+	}
+	// 单个来源失败只影响自身的结果
+	if got := snapshot.Pools["redis"]["error"]; got != "pool closed" {
+		t.Fatalf("want redis error recorded, got %v", snapshot.Pools["redis"])
+	}
+	if snapshot.Runtime.Goroutines <= 0 || snapshot.Runtime.Sys == 0 {
+		t.Fatalf("want runtime stats populated, got %+v", snapshot.Runtime)
+	}
+}
@@ -0,0 +1,175 @@
+package httpclient
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+	"resty.dev/v3"
+)
+
+// 客户端级别的认证方式，见 Config.AuthType
+const (
+	AuthTypeBearer = "bearer" // Authorization: Bearer <AuthToken>
+	AuthTypeBasic  = "basic"  // Authorization: Basic base64(BasicUser:BasicPass)
+)
+
+// defaultTokenRefreshSkew 令牌到期前提前刷新的时间，避免请求途中令牌过期
+const defaultTokenRefreshSkew = 30 * time.Second
+
+// AuthProvider 客户端级别的认证提供者，每次请求发送前调用
+// 实现应直接设置请求头（如 Authorization），请求级别的 WithAuthToken / WithBearerToken / WithBasicAuth 会覆盖它
+// ctx 为本次请求的上下文，获取或刷新凭证时应遵循其取消和超时
+type AuthProvider interface {
+	Apply(ctx context.Context, req *resty.Request) error
+}
+
+// AuthProviderFunc 函数形式的 AuthProvider
+type AuthProviderFunc func(ctx context.Context, req *resty.Request) error
+
+// Apply 实现 AuthProvider 接口
+func (f AuthProviderFunc) Apply(ctx context.Context, req *resty.Request) error {
+	return f(ctx, req)
+}
+
+// StaticTokenProvider 使用固定 Bearer Token 的认证提供者
+type StaticTokenProvider struct {
+	token string
+}
+
+// NewStaticTokenProvider 创建固定 Bearer Token 的认证提供者
+func NewStaticTokenProvider(token string) *StaticTokenProvider {
+	return &StaticTokenProvider{token: token}
+}
+
+// Apply 设置 Authorization: Bearer <token>
+func (p *StaticTokenProvider) Apply(ctx context.Context, req *resty.Request) error {
+	req.SetHeader("Authorization", "Bearer "+p.token)
+	return nil
+}
+
+// BasicAuthProvider 使用固定用户名密码的 Basic 认证提供者
+type BasicAuthProvider struct {
+	header string
+}
+
+// NewBasicAuthProvider 创建 Basic 认证提供者
+func NewBasicAuthProvider(username, password string) *BasicAuthProvider {
+	credential := base64.StdEncoding.EncodeToString([]byte(username + ":" + password))
+	return &BasicAuthProvider{header: "Basic " + credential}
+}
+
+// Apply 设置 Authorization: Basic <credential>
+func (p *BasicAuthProvider) Apply(ctx context.Context, req *resty.Request) error {
+	req.SetHeader("Authorization", p.header)
+	return nil
+}
+
+// configAuthProvider 根据 AuthType 等配置创建认证提供者，未配置认证方式时返回 nil
+func configAuthProvider(cfg *Config) (AuthProvider, error) {
+	switch cfg.AuthType {
+	case "":
+		return nil, nil
+	case AuthTypeBearer:
+		return NewStaticTokenProvider(cfg.AuthToken), nil
+	case AuthTypeBasic:
+		return NewBasicAuthProvider(cfg.BasicUser, cfg.BasicPass), nil
+	default:
+		return nil, fmt.Errorf("unknown auth type: %s", cfg.AuthType)
+	}
+}
+
+// TokenFetcher 获取新令牌的回调，返回令牌及其过期时间，过期时间为零值表示永不过期
+type TokenFetcher func(ctx context.Context) (token string, expiresAt time.Time, err error)
+
+// RefreshingTokenProvider 通过回调获取并缓存 Bearer Token 的认证提供者，令牌即将过期时自动刷新
+// 适用于 OAuth client credentials 等需要定期换取令牌的场景；并发请求只会触发一次刷新
+type RefreshingTokenProvider struct {
+	fetch TokenFetcher
+	skew  time.Duration
+
+	mu         sync.Mutex
+	token      string
+	expiresAt  time.Time
+	generation uint64 // 每次 Invalidate 加一，失效前开始的刷新结果不再缓存
+
+	group singleflight.Group
+}
+
+// NewRefreshingTokenProvider 创建自动刷新令牌的认证提供者
+// skew 为到期前提前刷新的时间，<= 0 时使用 30 秒
+func NewRefreshingTokenProvider(fetch TokenFetcher, skew time.Duration) *RefreshingTokenProvider {
+	if skew <= 0 {
+		skew = defaultTokenRefreshSkew
+	}
+	return &RefreshingTokenProvider{fetch: fetch, skew: skew}
+}
+
+// Apply 设置 Authorization: Bearer <token>，令牌不存在或即将过期时先刷新
+func (p *RefreshingTokenProvider) Apply(ctx context.Context, req *resty.Request) error {
+	token, err := p.Token(ctx)
+	if err != nil {
+		return err
+	}
+	req.SetHeader("Authorization", "Bearer "+token)
+	return nil
+}
+
+// Token 返回当前有效的令牌，必要时调用 fetch 刷新
+// 并发的刷新合并为一次 fetch，刷新期间不持有锁；每个调用方等待时遵循自己 ctx 的取消，
+// 合并的 fetch 不随发起方取消而中断，但沿用其截止时间
+func (p *RefreshingTokenProvider) Token(ctx context.Context) (string, error) {
+	p.mu.Lock()
+	if p.token != "" && (p.expiresAt.IsZero() || time.Until(p.expiresAt) > p.skew) {
+		token := p.token
+		p.mu.Unlock()
+		return token, nil
+	}
+	generation := p.generation
+	p.mu.Unlock()
+
+	// 按失效代数合并：Invalidate 之后的调用不会复用失效前开始的刷新
+	result := p.group.DoChan(strconv.FormatUint(generation, 10), func() (interface{}, error) {
+		fetchCtx := context.WithoutCancel(ctx)
+		if deadline, ok := ctx.Deadline(); ok {
+			var cancel context.CancelFunc
+			fetchCtx, cancel = context.WithDeadline(fetchCtx, deadline)
+			defer cancel()
+		}
+
+		token, expiresAt, err := p.fetch(fetchCtx)
+		if err != nil {
+			return "", fmt.Errorf("failed to refresh auth token: %w", err)
+		}
+
+		p.mu.Lock()
+		if p.generation == generation {
+			p.token, p.expiresAt = token, expiresAt
+		}
+		p.mu.Unlock()
+		return token, nil
+	})
+
+	select {
+	case <-ctx.Done():
+		return "", ctx.Err()
+	case r := <-result:
+		if r.Err != nil {
+			return "", r.Err
+		}
+		return r.Val.(string), nil
+	}
+}
+
+// Invalidate 丢弃缓存的令牌，下次请求时重新获取（如收到 401 后）
+func (p *RefreshingTokenProvider) Invalidate() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.token = ""
+	p.expiresAt = time.Time{}
+	p.generation++
+}
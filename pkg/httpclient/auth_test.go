@@ -0,0 +1,215 @@
+package httpclient_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alfredchaos/demo/pkg/httpclient"
+)
+
+func TestAuthProvider_PerRequestOptionsOverride(t *testing.T) {
+	srv, lastHeader := newHeaderServer(t)
+	client, err := httpclient.New(
+		httpclient.WithBaseURL(srv.URL),
+		httpclient.WithRetryCount(0),
+		httpclient.WithAuthProvider(httpclient.NewStaticTokenProvider("client-token")),
+	)
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+	defer client.Close()
+
+	cases := []struct {
+		name    string
+		options []httpclient.RequestOption
+		want    string
+	}{
+		{"client provider", nil, "Bearer client-token"},
+		{"auth token", []httpclient.RequestOption{httpclient.WithAuthToken("request-token")}, "Bearer request-token"},
+		{"bearer token", []httpclient.RequestOption{httpclient.WithBearerToken("request-token")}, "Bearer request-token"},
+		{"basic auth", []httpclient.RequestOption{httpclient.WithBasicAuth("alice", "secret")}, "Basic YWxpY2U6c2VjcmV0"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if _, err := client.Get(context.Background(), "/", nil, c.options...); err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			if got := lastHeader().Get("Authorization"); got != c.want {
+				t.Fatalf("want Authorization %q, got %q", c.want, got)
+			}
+		})
+	}
+}
+
+func TestRefreshingTokenProvider_RefreshesExpiredToken(t *testing.T) {
+	srv, lastHeader := newHeaderServer(t)
+
+	var fetches int
+	expiresIn := time.Hour
+	provider := httpclient.NewRefreshingTokenProvider(func(ctx context.Context) (string, time.Time, error) {
+		fetches++
+		return "token-" + strconv.Itoa(fetches), time.Now().Add(expiresIn), nil
+	}, time.Minute)
+
+	client, err := httpclient.New(
+		httpclient.WithBaseURL(srv.URL),
+		httpclient.WithRetryCount(0),
+		httpclient.WithAuthProvider(provider),
+	)
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+	defer client.Close()
+
+	get := func() string {
+		t.Helper()
+		if _, err := client.Get(context.Background(), "/", nil); err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		return lastHeader().Get("Authorization")
+	}
+
+	// 令牌有效期内复用缓存
+	if got := get(); got != "Bearer token-1" {
+		t.Fatalf("want first token, got %q", got)
+	}
+	if got := get(); got != "Bearer token-1" || fetches != 1 {
+		t.Fatalf("want cached token, got %q after %d fetches", got, fetches)
+	}
+
+	// 剩余有效期小于提前刷新时间时重新获取
+	expiresIn = 30 * time.Second
+	provider.Invalidate()
+	if got := get(); got != "Bearer token-2" {
+		t.Fatalf("want refreshed token, got %q", got)
+	}
+	if got := get(); got != "Bearer token-3" || fetches != 3 {
+		t.Fatalf("want token about to expire refreshed, got %q after %d fetches", got, fetches)
+	}
+}
+
+func TestRefreshingTokenProvider_HonorsRequestContext(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { requests.Add(1) }))
+	defer srv.Close()
+
+	provider := httpclient.NewRefreshingTokenProvider(func(ctx context.Context) (string, time.Time, error) {
+		<-ctx.Done()
+		return "", time.Time{}, ctx.Err()
+	}, 0)
+	client, err := httpclient.New(
+		httpclient.WithBaseURL(srv.URL),
+		httpclient.WithRetryCount(0),
+		httpclient.WithAuthProvider(provider),
+	)
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, err = client.Get(ctx, "/", nil)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("want token refresh canceled by request context, got %v", err)
+	}
+	if got := requests.Load(); got != 0 {
+		t.Fatalf("want request not sent without credentials, got %d requests", got)
+	}
+}
+
+func TestRefreshingTokenProvider_SharesRefreshAndHonorsEachCaller(t *testing.T) {
+	var fetches atomic.Int32
+	release := make(chan struct{})
+	provider := httpclient.NewRefreshingTokenProvider(func(ctx context.Context) (string, time.Time, error) {
+		fetches.Add(1)
+		<-release
+		return "shared-token", time.Time{}, nil
+	}, 0)
+
+	// 两个调用方等待同一次刷新
+	results := make(chan string, 2)
+	for i := 0; i < 2; i++ {
+		go func() {
+			token, _ := provider.Token(context.Background())
+			results <- token
+		}()
+	}
+	time.Sleep(20 * time.Millisecond)
+
+	// 刷新进行中时，其他调用方在自己的 ctx 超时后立即返回
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, err := provider.Token(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("want DeadlineExceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 200*time.Millisecond {
+		t.Fatalf("want waiter to return on its own deadline, took %v", elapsed)
+	}
+
+	close(release)
+	for i := 0; i < 2; i++ {
+		if got := <-results; got != "shared-token" {
+			t.Fatalf("want shared token, got %q", got)
+		}
+	}
+	if got := fetches.Load(); got != 1 {
+		t.Fatalf("want a single fetch, got %d", got)
+	}
+}
+
+func TestClientAuth_ConfiguredCredentials(t *testing.T) {
+	srv, lastHeader := newHeaderServer(t)
+
+	cases := []struct {
+		name    string
+		auth    httpclient.Option
+		options []httpclient.RequestOption
+		want    string
+	}{
+		{"client token", httpclient.WithClientAuthToken("client-token"), nil, "Bearer client-token"},
+		{"client basic auth", httpclient.WithClientBasicAuth("alice", "secret"), nil, "Basic YWxpY2U6c2VjcmV0"},
+		{"request token overrides client basic auth", httpclient.WithClientBasicAuth("alice", "secret"),
+			[]httpclient.RequestOption{httpclient.WithAuthToken("request-token")}, "Bearer request-token"},
+		{"request basic auth overrides client token", httpclient.WithClientAuthToken("client-token"),
+			[]httpclient.RequestOption{httpclient.WithBasicAuth("bob", "secret")}, "Basic Ym9iOnNlY3JldA=="},
+		{"provider takes precedence", func(c *httpclient.Config) {
+			httpclient.WithClientAuthToken("client-token")(c)
+			httpclient.WithAuthProvider(httpclient.NewStaticTokenProvider("provider-token"))(c)
+		}, nil, "Bearer provider-token"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			client, err := httpclient.New(httpclient.WithBaseURL(srv.URL), httpclient.WithRetryCount(0), c.auth)
+			if err != nil {
+				t.Fatalf("new client: %v", err)
+			}
+			defer client.Close()
+
+			if _, err := client.Get(context.Background(), "/", nil, c.options...); err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			if got := lastHeader().Get("Authorization"); got != c.want {
+				t.Fatalf("want Authorization %q, got %q", c.want, got)
+			}
+		})
+	}
+}
+
+func TestClientAuth_UnknownAuthType(t *testing.T) {
+	// 认证配置错误在创建客户端时返回，而不是等到发送请求
+	client, err := httpclient.New(func(c *httpclient.Config) { c.AuthType = "digest" })
+	if err == nil || client != nil {
+		t.Fatalf("want error on unknown auth type, got client %v, err %v", client, err)
+	}
+}
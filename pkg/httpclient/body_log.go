@@ -0,0 +1,194 @@
+package httpclient
+
+import (
+	"encoding/json"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/alfredchaos/demo/pkg/log"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"resty.dev/v3"
+)
+
+// defaultBodyLogMaxBytes 请求/响应体日志的默认截断长度
+const defaultBodyLogMaxBytes = 4096
+
+// redactedValue 脱敏后的占位值
+const redactedValue = "[REDACTED]"
+
+// defaultRedactHeaders 始终脱敏的请求头/响应头
+var defaultRedactHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie"}
+
+// BodyLogConfig 请求/响应体日志配置，用于排查集成问题，不要在生产环境长期开启
+type BodyLogConfig struct {
+	Enabled          bool     `yaml:"enabled" mapstructure:"enabled"`                       // 是否记录请求/响应体（debug 级别）
+	MaxBytes         int      `yaml:"max_bytes" mapstructure:"max_bytes"`                   // 单个请求体/响应体记录的最大字节数，默认 4096
+	RedactHeaders    []string `yaml:"redact_headers" mapstructure:"redact_headers"`         // 额外需要脱敏的请求头，Authorization、Cookie 等始终脱敏
+	RedactJSONFields []string `yaml:"redact_json_fields" mapstructure:"redact_json_fields"` // 需要脱敏的 JSON 字段名（不区分大小写，任意层级），同样作用于表单参数
+}
+
+// bodyLogger 记录脱敏并截断后的请求/响应体
+type bodyLogger struct {
+	maxBytes     int
+	redactHeader map[string]struct{}
+	redactField  map[string]struct{}
+}
+
+// newBodyLogger 根据配置创建 bodyLogger，未启用时返回 nil
+// 请求/响应体按 debug 级别记录，全局日志未开启 debug 级别时同样返回 nil，避免无用地缓存响应体
+func newBodyLogger(cfg BodyLogConfig) *bodyLogger {
+	if !cfg.Enabled {
+		return nil
+	}
+	if log.Logger != nil && !log.Logger.Core().Enabled(zapcore.DebugLevel) {
+		return nil
+	}
+
+	l := &bodyLogger{
+		maxBytes:     cfg.MaxBytes,
+		redactHeader: make(map[string]struct{}),
+		redactField:  make(map[string]struct{}),
+	}
+	if l.maxBytes <= 0 {
+		l.maxBytes = defaultBodyLogMaxBytes
+	}
+	for _, name := range append(append([]string(nil), defaultRedactHeaders...), cfg.RedactHeaders...) {
+		l.redactHeader[http.CanonicalHeaderKey(name)] = struct{}{}
+	}
+	for _, field := range cfg.RedactJSONFields {
+		l.redactField[strings.ToLower(field)] = struct{}{}
+	}
+	return l
+}
+
+// log 记录一次请求的请求头、请求体和响应体
+func (l *bodyLogger) log(resp *resty.Response) {
+	req := resp.Request
+	header := req.Header
+	if req.RawRequest != nil {
+		// 认证信息等由 resty 在发送前写入 RawRequest
+		header = req.RawRequest.Header
+	}
+
+	reqBody := requestBodyBytes(req.Body)
+	reqContentType := header.Get("Content-Type")
+	if reqBody == nil && len(req.FormData) > 0 {
+		// SetFormData 设置的表单参数不在 Body 中
+		reqBody = []byte(req.FormData.Encode())
+		reqContentType = formContentType
+	}
+
+	log.FromContext(req.Context()).Debug("HTTP请求内容",
+		zap.String("method", req.Method),
+		zap.String("url", req.URL),
+		zap.Int("status_code", resp.StatusCode()),
+		zap.Any("request_headers", l.headers(header)),
+		zap.String("request_body", l.body(reqBody, reqContentType)),
+		zap.Any("response_headers", l.headers(resp.Header())),
+		zap.String("response_body", l.body(resp.Bytes(), resp.Header().Get("Content-Type"))),
+	)
+}
+
+// headers 返回脱敏后的请求头
+func (l *bodyLogger) headers(header http.Header) map[string]string {
+	out := make(map[string]string, len(header))
+	for name, values := range header {
+		if _, ok := l.redactHeader[http.CanonicalHeaderKey(name)]; ok {
+			out[name] = redactedValue
+			continue
+		}
+		out[name] = strings.Join(values, ", ")
+	}
+	return out
+}
+
+// formContentType 表单请求体的 Content-Type
+const formContentType = "application/x-www-form-urlencoded"
+
+// body 脱敏后按 maxBytes 截断，截断位置不会拆开多字节字符
+func (l *bodyLogger) body(data []byte, contentType string) string {
+	if len(data) == 0 {
+		return ""
+	}
+
+	if len(l.redactField) > 0 {
+		data = l.redactBody(data, contentType)
+	}
+
+	if len(data) > l.maxBytes {
+		cut := l.maxBytes
+		for cut > 0 && !utf8.RuneStart(data[cut]) {
+			cut--
+		}
+		return string(data[:cut]) + "...(truncated)"
+	}
+	return string(data)
+}
+
+// redactBody 脱敏表单参数或 JSON 字段
+// 无法解析的内容无从判断是否包含敏感字段，整体替换为占位值
+func (l *bodyLogger) redactBody(data []byte, contentType string) []byte {
+	if mediaType, _, _ := mime.ParseMediaType(contentType); mediaType == formContentType {
+		values, err := url.ParseQuery(string(data))
+		if err != nil {
+			return []byte(redactedValue)
+		}
+		for key := range values {
+			if _, ok := l.redactField[strings.ToLower(key)]; ok {
+				values[key] = []string{redactedValue}
+			}
+		}
+		return []byte(values.Encode())
+	}
+
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return []byte(redactedValue)
+	}
+	redacted, err := json.Marshal(l.redact(v))
+	if err != nil {
+		return []byte(redactedValue)
+	}
+	return redacted
+}
+
+// redact 递归替换需要脱敏的字段值
+func (l *bodyLogger) redact(v interface{}) interface{} {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		for key, field := range value {
+			if _, ok := l.redactField[strings.ToLower(key)]; ok {
+				value[key] = redactedValue
+				continue
+			}
+			value[key] = l.redact(field)
+		}
+	case []interface{}:
+		for i, item := range value {
+			value[i] = l.redact(item)
+		}
+	}
+	return v
+}
+
+// requestBodyBytes 将 resty 请求体转换为字节，结构体等按 JSON 序列化
+func requestBodyBytes(body interface{}) []byte {
+	switch b := body.(type) {
+	case nil:
+		return nil
+	case []byte:
+		return b
+	case string:
+		return []byte(b)
+	default:
+		data, err := json.Marshal(b)
+		if err != nil {
+			return nil
+		}
+		return data
+	}
+}
@@ -0,0 +1,188 @@
+package httpclient_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/alfredchaos/demo/pkg/httpclient"
+	"github.com/alfredchaos/demo/pkg/log"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestBodyLogging_RedactsSecrets(t *testing.T) {
+	logs := observeLogs(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"user":{"name":"alice","password":"hunter2"},"token":"abc"}`))
+	}))
+	defer srv.Close()
+
+	client, err := httpclient.New(
+		httpclient.WithBaseURL(srv.URL),
+		httpclient.WithRetryCount(0),
+		httpclient.WithBodyLogging(1024, []string{"X-Api-Key"}, []string{"password", "token"}),
+	)
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+	defer client.Close()
+
+	body := map[string]string{"username": "alice", "password": "s3cret"}
+	var result map[string]interface{}
+	_, err = client.Post(context.Background(), "/login", body, &result,
+		httpclient.WithBearerToken("secret-token"),
+		httpclient.WithHeader("X-Api-Key", "key-123"))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	if result["token"] != "abc" {
+		t.Fatalf("want result still decoded, got %v", result)
+	}
+
+	entries := logs.FilterMessage("HTTP请求内容").All()
+	if len(entries) != 1 {
+		t.Fatalf("want one body log, got %d", len(entries))
+	}
+	fields := entries[0].ContextMap()
+
+	headers, _ := fields["request_headers"].(map[string]string)
+	if headers["Authorization"] != "[REDACTED]" || headers["X-Api-Key"] != "[REDACTED]" {
+		t.Fatalf("want Authorization and X-Api-Key redacted, got %v", headers)
+	}
+
+	for _, key := range []string{"request_body", "response_body"} {
+		logged, _ := fields[key].(string)
+		if strings.Contains(logged, "s3cret") || strings.Contains(logged, "hunter2") || strings.Contains(logged, `"abc"`) {
+			t.Fatalf("want secrets redacted in %s, got %s", key, logged)
+		}
+		if !strings.Contains(logged, `"password":"[REDACTED]"`) {
+			t.Fatalf("want password field redacted in %s, got %s", key, logged)
+		}
+	}
+}
+
+func TestBodyLogging_TruncatesLargeBodies(t *testing.T) {
+	logs := observeLogs(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(strings.Repeat("x", 100)))
+	}))
+	defer srv.Close()
+
+	client, err := httpclient.New(
+		httpclient.WithBaseURL(srv.URL),
+		httpclient.WithRetryCount(0),
+		httpclient.WithBodyLogging(10, nil, nil),
+	)
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+	defer client.Close()
+
+	if _, err := client.Get(context.Background(), "/", nil); err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+
+	logged, _ := logs.FilterMessage("HTTP请求内容").All()[0].ContextMap()["response_body"].(string)
+	if logged != strings.Repeat("x", 10)+"...(truncated)" {
+		t.Fatalf("want body truncated to 10 bytes, got %q", logged)
+	}
+}
+
+func TestBodyLogging_RedactsFormAndUnparsableBodies(t *testing.T) {
+	logs := observeLogs(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = w.Write([]byte("password: hunter2"))
+	}))
+	defer srv.Close()
+
+	client, err := httpclient.New(
+		httpclient.WithBaseURL(srv.URL),
+		httpclient.WithRetryCount(0),
+		httpclient.WithBodyLogging(1024, nil, []string{"password"}),
+	)
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+	defer client.Close()
+
+	_, err = client.Post(context.Background(), "/login", nil, nil,
+		httpclient.WithFormData(map[string]string{"username": "alice", "Password": "s3cret"}))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+
+	fields := logs.FilterMessage("HTTP请求内容").All()[0].ContextMap()
+	if logged := fields["request_body"].(string); strings.Contains(logged, "s3cret") || !strings.Contains(logged, "username=alice") {
+		t.Fatalf("want form password redacted and other params kept, got %q", logged)
+	}
+	// 非 JSON、非表单的内容无法逐字段脱敏，整体替换
+	if logged := fields["response_body"].(string); logged != "[REDACTED]" {
+		t.Fatalf("want unparsable body redacted, got %q", logged)
+	}
+}
+
+func TestBodyLogging_TruncatesOnRuneBoundary(t *testing.T) {
+	logs := observeLogs(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("你好世界"))
+	}))
+	defer srv.Close()
+
+	client, err := httpclient.New(
+		httpclient.WithBaseURL(srv.URL),
+		httpclient.WithRetryCount(0),
+		httpclient.WithBodyLogging(4, nil, nil),
+	)
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+	defer client.Close()
+
+	if _, err := client.Get(context.Background(), "/", nil); err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+
+	logged, _ := logs.FilterMessage("HTTP请求内容").All()[0].ContextMap()["response_body"].(string)
+	if logged != "你...(truncated)" {
+		t.Fatalf("want truncation before the split rune, got %q", logged)
+	}
+}
+
+func TestBodyLogging_OffWithoutDebugLevel(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	prev := log.Logger
+	log.Logger = zap.New(core)
+	t.Cleanup(func() { log.Logger = prev })
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	client, err := httpclient.New(
+		httpclient.WithBaseURL(srv.URL),
+		httpclient.WithRetryCount(0),
+		httpclient.WithBodyLogging(0, nil, nil),
+	)
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+	defer client.Close()
+
+	// debug 级别未开启时不记录请求/响应体，也不缓存响应体
+	if client.GetRestyClient().ResponseBodyUnlimitedReads() {
+		t.Fatal("want unlimited response reads off without debug logging")
+	}
+	if _, err := client.Get(context.Background(), "/", nil); err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	if n := logs.FilterMessage("HTTP请求内容").Len(); n != 0 {
+		t.Fatalf("want no body logs, got %d", n)
+	}
+}
@@ -3,8 +3,10 @@ package httpclient
 import (
 	"context"
 	"fmt"
+	"math/rand"
 	"time"
 
+	"github.com/alfredchaos/demo/pkg/buildinfo"
 	"github.com/alfredchaos/demo/pkg/log"
 	"go.uber.org/zap"
 	"resty.dev/v3"
@@ -12,12 +14,15 @@ import (
 
 // Client HTTP客户端封装
 type Client struct {
-	client *resty.Client
-	config *Config
+	client     *resty.Client
+	config     *Config
+	bodyLogger *bodyLogger   // 未启用请求/响应体日志时为 nil
+	auth       AuthProvider // 客户端级别的认证提供者，未配置认证时为 nil
 }
 
 // New 创建HTTP客户端
-func New(options ...Option) *Client {
+// 配置不合法（如未知的 AuthType、采样率超出 [0, 1]）时返回错误
+func New(options ...Option) (*Client, error) {
 	// 创建默认配置
 	cfg := DefaultConfig()
 	
@@ -26,6 +31,19 @@ func New(options ...Option) *Client {
 		opt(cfg)
 	}
 	
+	if cfg.LogSampleRate < 0 || cfg.LogSampleRate > 1 {
+		return nil, fmt.Errorf("log sample rate must be within [0, 1], got %v", cfg.LogSampleRate)
+	}
+	
+	// 客户端级别的认证提供者只创建一次，WithAuthProvider 设置的优先于 AuthType 配置
+	auth := cfg.AuthProvider
+	if auth == nil {
+		var err error
+		if auth, err = configAuthProvider(cfg); err != nil {
+			return nil, err
+		}
+	}
+	
 	// 创建 resty 客户端
 	restyClient := resty.New()
 	
@@ -38,11 +56,23 @@ func New(options ...Option) *Client {
 	restyClient.SetTimeout(cfg.Timeout)
 	
 	// 设置重试
+	// 关闭 resty 内置的重试条件，只按配置的状态码重试
 	if cfg.RetryCount > 0 {
 		restyClient.
 			SetRetryCount(cfg.RetryCount).
 			SetRetryWaitTime(cfg.RetryWaitTime).
-			SetRetryMaxWaitTime(cfg.RetryMaxWaitTime)
+			SetRetryMaxWaitTime(cfg.RetryMaxWaitTime).
+			SetRetryDefaultConditions(false).
+			AddRetryConditions(retryOnStatus(cfg.RetryStatusCodes))
+	}
+	
+	// 设置 User-Agent，未配置时使用 服务名/版本号；默认请求头中显式配置的 User-Agent 优先
+	userAgent := cfg.UserAgent
+	if userAgent == "" && cfg.ServiceName != "" {
+		userAgent = buildinfo.UserAgent(cfg.ServiceName)
+	}
+	if userAgent != "" {
+		restyClient.SetHeader("User-Agent", userAgent)
 	}
 	
 	// 设置默认请求头
@@ -55,27 +85,40 @@ func New(options ...Option) *Client {
 		restyClient.SetDebug(true)
 	}
 	
+	// 记录请求/响应体时保留响应体，自动解析到 result 后仍可读取
+	bodyLogger := newBodyLogger(cfg.BodyLog)
+	if bodyLogger != nil {
+		restyClient.SetResponseBodyUnlimitedReads(true)
+	}
+	
 	c := &Client{
-		client: restyClient,
-		config: cfg,
+		client:     restyClient,
+		config:     cfg,
+		bodyLogger: bodyLogger,
+		auth:       auth,
 	}
 	
 	// 添加请求中间件
 	c.setupMiddlewares()
 	
-	return c
+	return c, nil
 }
 
 // setupMiddlewares 设置中间件
 func (c *Client) setupMiddlewares() {
 	// 请求前的日志和延迟记录中间件
 	c.client.AddRequestMiddleware(func(client *resty.Client, req *resty.Request) error {
-		// 记录请求开始时间
-		req.SetContext(context.WithValue(req.Context(), requestStartTimeKey, time.Now()))
+		// 记录请求开始时间和采样结果
+		sampled := c.shouldSample()
+		ctx := context.WithValue(req.Context(), requestStartTimeKey, time.Now())
+		req.SetContext(context.WithValue(ctx, requestSampledKey, sampled))
 		
-		// 记录请求日志
-		if log.Logger != nil {
-			log.Info("HTTP请求开始",
+		// 转发入站请求的 request_id，开启 PropagateTraceHeaders 时同时转发 trace_id、user_id；调用方已设置时不覆盖
+		c.contextHeaders().apply(ctx, req)
+		
+		// 记录请求日志（仅采样命中的请求），携带入站请求的 trace_id / request_id
+		if log.Logger != nil && sampled {
+			log.WithContext(ctx).Info("HTTP请求开始",
 				zap.String("method", req.Method),
 				zap.String("url", req.URL),
 			)
@@ -93,25 +136,37 @@ func (c *Client) setupMiddlewares() {
 		}
 		duration := time.Since(startTime)
 		
+		sampled, ok := resp.Request.Context().Value(requestSampledKey).(bool)
+		if !ok {
+			sampled = true
+		}
+		failed := resp.Err != nil || !IsSuccessStatus(resp.StatusCode())
+		
 		// 记录响应日志
 		if log.Logger != nil {
+			logger := log.WithContext(resp.Request.Context())
 			fields := []zap.Field{
 				zap.String("method", resp.Request.Method),
 				zap.String("url", resp.Request.URL),
 				zap.Int("status_code", resp.StatusCode()),
-				zap.Int64("duration_ms", duration.Milliseconds()),
+				log.DurationMs(duration.Milliseconds()),
 			}
 			
-			// 如果请求时间超过阈值，记录警告
+			// 如果请求时间超过阈值，记录警告；失败请求始终记录，成功请求按采样率记录
 			if duration > c.config.LogSlowThreshold {
-				log.Warn("HTTP慢请求", fields...)
-			} else {
-				log.Info("HTTP请求完成", fields...)
+				logger.Warn("HTTP慢请求", fields...)
+			} else if failed || sampled {
+				logger.Info("HTTP请求完成", fields...)
+			}
+			
+			// 记录脱敏后的请求/响应体
+			if c.bodyLogger != nil {
+				c.bodyLogger.log(resp)
 			}
 			
 			// 错误处理
 			if resp.Err != nil {
-				log.Error("HTTP请求失败",
+				logger.Error("HTTP请求失败",
 					zap.String("method", resp.Request.Method),
 					zap.String("url", resp.Request.URL),
 					zap.Error(resp.Err),
@@ -168,16 +223,16 @@ func (c *Client) doRequest(ctx context.Context, method, url string, body, result
 		req.SetResult(result)
 	}
 	
+	// 执行客户端级别的认证，在请求选项之前执行，请求级别的认证选项可覆盖
+	if err := c.applyAuth(req); err != nil {
+		return nil, err
+	}
+	
 	// 应用请求选项
 	for _, opt := range options {
 		opt(req)
 	}
 	
-	// 执行认证（预留接口，暂不实现）
-	if err := c.applyAuth(req); err != nil {
-		return nil, err
-	}
-	
 	// 执行请求
 	var resp *resty.Response
 	var err error
@@ -215,13 +270,14 @@ func (c *Client) doRequest(ctx context.Context, method, url string, body, result
 	return resp, nil
 }
 
-// applyAuth 应用认证（预留接口，暂不实现）
+// applyAuth 使用客户端级别的认证提供者为请求设置认证信息，未配置认证时不做处理
 func (c *Client) applyAuth(req *resty.Request) error {
-	// TODO: 实现认证逻辑
-	// 可以从配置中读取认证信息，或者通过其他方式获取token
-	// 示例：
-	// req.SetAuthToken("your-token")
-	// req.SetBasicAuth("username", "password")
+	if c.auth == nil {
+		return nil
+	}
+	if err := c.auth.Apply(req.Context(), req); err != nil {
+		return fmt.Errorf("failed to apply auth: %w", err)
+	}
 	return nil
 }
 
@@ -233,6 +289,29 @@ func (c *Client) Close() error {
 	return nil
 }
 
+// contextHeaders 按配置返回需要转发的上下文请求头
+func (c *Client) contextHeaders() contextHeaders {
+	headers := contextHeaders{requestID: c.config.RequestIDHeader}
+	if c.config.PropagateTraceHeaders {
+		headers.traceID = c.config.TraceIDHeader
+		headers.userID = c.config.UserIDHeader
+	}
+	return headers
+}
+
+// shouldSample 判断本次请求的成功日志是否记录
+func (c *Client) shouldSample() bool {
+	rate := c.config.LogSampleRate
+	switch {
+	case rate >= 1:
+		return true
+	case rate <= 0:
+		return false
+	default:
+		return rand.Float64() < rate
+	}
+}
+
 // GetRestyClient 获取底层resty客户端（用于高级用法）
 func (c *Client) GetRestyClient() *resty.Client {
 	return c.client
@@ -241,4 +320,7 @@ func (c *Client) GetRestyClient() *resty.Client {
 // requestStartTimeKey 请求开始时间的context key
 type contextKey string
 
-const requestStartTimeKey contextKey = "request_start_time"
+const (
+	requestStartTimeKey contextKey = "request_start_time"
+	requestSampledKey   contextKey = "request_sampled"
+)
@@ -0,0 +1,437 @@
+package httpclient_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alfredchaos/demo/pkg/buildinfo"
+	"github.com/alfredchaos/demo/pkg/httpclient"
+	"github.com/alfredchaos/demo/pkg/log"
+	"github.com/alfredchaos/demo/pkg/reqctx"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// observeLogs 替换全局 logger 并返回日志观察器
+func observeLogs(t *testing.T) *observer.ObservedLogs {
+	t.Helper()
+
+	core, logs := observer.New(zapcore.DebugLevel)
+	prev := log.Logger
+	log.Logger = zap.New(core)
+	t.Cleanup(func() { log.Logger = prev })
+	return logs
+}
+
+func newTestServer(t *testing.T, status int) *httptest.Server {
+	t.Helper()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestLogSampleRate_SuppressesMostSuccessLogs(t *testing.T) {
+	logs := observeLogs(t)
+	srv := newTestServer(t, http.StatusOK)
+	client, err := httpclient.New(
+		httpclient.WithBaseURL(srv.URL),
+		httpclient.WithRetryCount(0),
+		httpclient.WithLogSampleRate(0.01),
+	)
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+	defer client.Close()
+
+	const total = 200
+	for i := 0; i < total; i++ {
+		if _, err := client.Get(context.Background(), "/", nil); err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+	}
+
+	completed := logs.FilterMessage("HTTP请求完成").Len()
+	if completed > total/10 {
+		t.Fatalf("want most success logs suppressed, got %d of %d", completed, total)
+	}
+}
+
+func TestLogSampleRate_ZeroDisablesSuccessLogs(t *testing.T) {
+	logs := observeLogs(t)
+	srv := newTestServer(t, http.StatusOK)
+	client, err := httpclient.New(
+		httpclient.WithBaseURL(srv.URL),
+		httpclient.WithRetryCount(0),
+		httpclient.WithLogSampleRate(0),
+	)
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+	defer client.Close()
+
+	for i := 0; i < 20; i++ {
+		if _, err := client.Get(context.Background(), "/", nil); err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+	}
+	if completed := logs.FilterMessage("HTTP请求完成").Len(); completed != 0 {
+		t.Fatalf("want no success logs with rate 0, got %d", completed)
+	}
+}
+
+func TestLogSampleRate_RejectsOutOfRange(t *testing.T) {
+	for _, rate := range []float64{-0.5, 1.5} {
+		if _, err := httpclient.New(httpclient.WithLogSampleRate(rate)); err == nil {
+			t.Errorf("rate %v: want error", rate)
+		}
+	}
+}
+
+func TestLogSampleRate_AlwaysLogsErrors(t *testing.T) {
+	logs := observeLogs(t)
+	srv := newTestServer(t, http.StatusInternalServerError)
+	client, err := httpclient.New(
+		httpclient.WithBaseURL(srv.URL),
+		httpclient.WithRetryCount(0),
+		httpclient.WithLogSampleRate(0.0001),
+	)
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+	defer client.Close()
+
+	const total = 20
+	for i := 0; i < total; i++ {
+		_, _ = client.Get(context.Background(), "/", nil)
+	}
+
+	if got := logs.FilterMessage("HTTP请求完成").Len(); got != total {
+		t.Fatalf("want all %d failed requests logged, got %d", total, got)
+	}
+}
+
+func TestLogSampleRate_AlwaysLogsSlowRequests(t *testing.T) {
+	logs := observeLogs(t)
+	srv := newTestServer(t, http.StatusOK)
+	client, err := httpclient.New(
+		httpclient.WithBaseURL(srv.URL),
+		httpclient.WithRetryCount(0),
+		httpclient.WithLogSampleRate(0.0001),
+		httpclient.WithLogSlowThreshold(time.Nanosecond),
+	)
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+	defer client.Close()
+
+	const total = 20
+	for i := 0; i < total; i++ {
+		if _, err := client.Get(context.Background(), "/", nil); err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+	}
+
+	if got := logs.FilterMessage("HTTP慢请求").Len(); got != total {
+		t.Fatalf("want all %d slow requests logged, got %d", total, got)
+	}
+}
+
+func TestLogs_CarryTraceIDFromRequestContext(t *testing.T) {
+	logs := observeLogs(t)
+	srv := newTestServer(t, http.StatusOK)
+	client, err := httpclient.New(
+		httpclient.WithBaseURL(srv.URL),
+		httpclient.WithRetryCount(0),
+	)
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+	defer client.Close()
+
+	ctx := reqctx.WithRequestID(reqctx.WithTraceID(context.Background(), "trace-123"), "req-456")
+	if _, err := client.Get(ctx, "/", nil); err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+
+	for _, msg := range []string{"HTTP请求开始", "HTTP请求完成"} {
+		entries := logs.FilterMessage(msg).All()
+		if len(entries) != 1 {
+			t.Fatalf("want one %q log, got %d", msg, len(entries))
+		}
+		fields := entries[0].ContextMap()
+		if fields["trace_id"] != "trace-123" || fields["request_id"] != "req-456" {
+			t.Fatalf("want %q log to carry trace_id and request_id, got %v", msg, fields)
+		}
+	}
+}
+
+// newHeaderServer 返回记录最近一次请求头的测试服务器
+func newHeaderServer(t *testing.T) (*httptest.Server, func() http.Header) {
+	t.Helper()
+
+	var mu sync.Mutex
+	var last http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		last = r.Header.Clone()
+		mu.Unlock()
+	}))
+	t.Cleanup(srv.Close)
+	return srv, func() http.Header {
+		mu.Lock()
+		defer mu.Unlock()
+		return last
+	}
+}
+
+func TestHeaders_UserAgentAndRequestID(t *testing.T) {
+	srv, lastHeader := newHeaderServer(t)
+	client, err := httpclient.New(
+		httpclient.WithBaseURL(srv.URL),
+		httpclient.WithRetryCount(0),
+		httpclient.WithUserAgent("user-service/v1.2.0"),
+		httpclient.WithRequestIDHeader("X-Correlation-ID"),
+	)
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+	defer client.Close()
+
+	ctx := reqctx.WithRequestID(context.Background(), "req-789")
+	if _, err := client.Get(ctx, "/", nil); err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+
+	header := lastHeader()
+	if got := header.Get("User-Agent"); got != "user-service/v1.2.0" {
+		t.Fatalf("want configured User-Agent, got %q", got)
+	}
+	if got := header.Get("X-Correlation-ID"); got != "req-789" {
+		t.Fatalf("want request_id forwarded in X-Correlation-ID, got %q", got)
+	}
+}
+
+func TestHeaders_DefaultUserAgentAndRequestIDHeader(t *testing.T) {
+	srv, lastHeader := newHeaderServer(t)
+	client, err := httpclient.New(
+		httpclient.WithBaseURL(srv.URL),
+		httpclient.WithRetryCount(0),
+		httpclient.WithServiceName("user-service"),
+	)
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+	defer client.Close()
+
+	ctx := reqctx.WithRequestID(context.Background(), "req-789")
+	if _, err := client.Get(ctx, "/", nil); err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+
+	header := lastHeader()
+	if got := header.Get("User-Agent"); got != "user-service/"+buildinfo.Version {
+		t.Fatalf("want default User-Agent from service name and build version, got %q", got)
+	}
+	if got := header.Get(httpclient.DefaultRequestIDHeader); got != "req-789" {
+		t.Fatalf("want request_id forwarded in %s, got %q", httpclient.DefaultRequestIDHeader, got)
+	}
+}
+
+func TestTraceHeaders(t *testing.T) {
+	ctx := reqctx.WithTraceID(context.Background(), "trace-1")
+	ctx = reqctx.WithRequestID(ctx, "req-1")
+	ctx = reqctx.WithUserID(ctx, "user-1")
+
+	cases := []struct {
+		name    string
+		ctx     context.Context
+		options []httpclient.Option
+		reqOpts func(ctx context.Context) []httpclient.RequestOption
+		want    map[string]string // 值为空表示不应设置该请求头
+	}{
+		{
+			name: "request id only by default",
+			ctx:  ctx,
+			want: map[string]string{"X-Trace-ID": "", "X-Request-ID": "req-1", "X-User-ID": ""},
+		},
+		{
+			name:    "client propagation",
+			ctx:     ctx,
+			options: []httpclient.Option{httpclient.WithTracePropagation()},
+			want:    map[string]string{"X-Trace-ID": "trace-1", "X-Request-ID": "req-1", "X-User-ID": "user-1"},
+		},
+		{
+			name: "custom header names",
+			ctx:  ctx,
+			options: []httpclient.Option{
+				httpclient.WithTracePropagation(),
+				httpclient.WithTraceHeaderNames("Traceparent-ID", "X-Correlation-ID", ""),
+			},
+			want: map[string]string{"Traceparent-ID": "trace-1", "X-Correlation-ID": "req-1", "X-User-ID": ""},
+		},
+		{
+			name: "request option",
+			ctx:  ctx,
+			reqOpts: func(ctx context.Context) []httpclient.RequestOption {
+				return []httpclient.RequestOption{httpclient.WithContextHeaders(ctx)}
+			},
+			want: map[string]string{"X-Trace-ID": "trace-1", "X-Request-ID": "req-1", "X-User-ID": "user-1"},
+		},
+		{
+			name:    "missing fields omitted",
+			ctx:     reqctx.WithRequestID(context.Background(), "req-2"),
+			options: []httpclient.Option{httpclient.WithTracePropagation()},
+			want:    map[string]string{"X-Trace-ID": "", "X-Request-ID": "req-2", "X-User-ID": ""},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			srv, lastHeader := newHeaderServer(t)
+			options := append([]httpclient.Option{httpclient.WithBaseURL(srv.URL), httpclient.WithRetryCount(0)}, c.options...)
+			client, err := httpclient.New(options...)
+			if err != nil {
+				t.Fatalf("new client: %v", err)
+			}
+			defer client.Close()
+
+			var reqOpts []httpclient.RequestOption
+			if c.reqOpts != nil {
+				reqOpts = c.reqOpts(c.ctx)
+			}
+			if _, err := client.Get(c.ctx, "/", nil, reqOpts...); err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+
+			header := lastHeader()
+			for name, want := range c.want {
+				if want == "" {
+					if _, ok := header[http.CanonicalHeaderKey(name)]; ok {
+						t.Fatalf("want %s omitted, got %q", name, header.Get(name))
+					}
+					continue
+				}
+				if got := header.Get(name); got != want {
+					t.Fatalf("want %s=%q, got %q", name, want, got)
+				}
+			}
+		})
+	}
+}
+
+// newCountingServer 返回按顺序响应状态码的测试服务器，并记录每次请求的时间
+func newCountingServer(t *testing.T, header http.Header, statuses ...int) (*httptest.Server, *[]time.Time) {
+	t.Helper()
+
+	var mu sync.Mutex
+	var attempts []time.Time
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		n := len(attempts)
+		attempts = append(attempts, time.Now())
+		mu.Unlock()
+
+		status := http.StatusOK
+		if n < len(statuses) {
+			status = statuses[n]
+		}
+		for k, v := range header {
+			w.Header()[k] = v
+		}
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &attempts
+}
+
+func TestRetryConditions(t *testing.T) {
+	observeLogs(t)
+
+	cases := []struct {
+		name         string
+		status       int
+		wantAttempts int
+	}{
+		{"503 retries", http.StatusServiceUnavailable, 2},
+		{"429 retries", http.StatusTooManyRequests, 2},
+		{"400 does not retry", http.StatusBadRequest, 1},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			srv, attempts := newCountingServer(t, nil, c.status)
+			client, err := httpclient.New(
+				httpclient.WithBaseURL(srv.URL),
+				httpclient.WithRetryCount(2),
+				httpclient.WithRetryWaitTime(time.Millisecond),
+				httpclient.WithRetryMaxWaitTime(10*time.Millisecond),
+			)
+			if err != nil {
+				t.Fatalf("new client: %v", err)
+			}
+			defer client.Close()
+
+			_, _ = client.Get(context.Background(), "/", nil)
+
+			if got := len(*attempts); got != c.wantAttempts {
+				t.Fatalf("want %d attempts, got %d", c.wantAttempts, got)
+			}
+		})
+	}
+}
+
+func TestRetryConditions_Custom(t *testing.T) {
+	observeLogs(t)
+	srv, attempts := newCountingServer(t, nil, http.StatusServiceUnavailable)
+	client, err := httpclient.New(
+		httpclient.WithBaseURL(srv.URL),
+		httpclient.WithRetryCount(2),
+		httpclient.WithRetryWaitTime(time.Millisecond),
+		httpclient.WithRetryConditions(http.StatusTooManyRequests),
+	)
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+	defer client.Close()
+
+	_, _ = client.Get(context.Background(), "/", nil)
+
+	if got := len(*attempts); got != 1 {
+		t.Fatalf("want 503 not retried when excluded, got %d attempts", got)
+	}
+}
+
+func TestRetryAfter_DelaysNextAttempt(t *testing.T) {
+	observeLogs(t)
+	header := http.Header{"Retry-After": []string{"1"}}
+	srv, attempts := newCountingServer(t, header, http.StatusTooManyRequests)
+	client, err := httpclient.New(
+		httpclient.WithBaseURL(srv.URL),
+		httpclient.WithRetryCount(1),
+		httpclient.WithRetryWaitTime(time.Millisecond),
+		httpclient.WithRetryMaxWaitTime(10*time.Millisecond),
+	)
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+	defer client.Close()
+
+	if _, err := client.Get(context.Background(), "/", nil); err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+
+	if len(*attempts) != 2 {
+		t.Fatalf("want 2 attempts, got %d", len(*attempts))
+	}
+	if gap := (*attempts)[1].Sub((*attempts)[0]); gap < 900*time.Millisecond {
+		t.Fatalf("want next attempt delayed by Retry-After, got %v", gap)
+	}
+}
@@ -2,6 +2,9 @@ package httpclient
 
 import "time"
 
+// DefaultRequestIDHeader 转发 request_id 使用的默认请求头
+const DefaultRequestIDHeader = "X-Request-ID"
+
 // Config HTTP客户端配置
 type Config struct {
 	BaseURL          string            `yaml:"base_url" mapstructure:"base_url"`
@@ -9,9 +12,28 @@ type Config struct {
 	RetryCount       int               `yaml:"retry_count" mapstructure:"retry_count"`
 	RetryWaitTime    time.Duration     `yaml:"retry_wait_time" mapstructure:"retry_wait_time"`
 	RetryMaxWaitTime time.Duration     `yaml:"retry_max_wait_time" mapstructure:"retry_max_wait_time"`
+	RetryStatusCodes []int             `yaml:"retry_status_codes" mapstructure:"retry_status_codes"` // 触发重试的状态码，默认 DefaultRetryStatusCodes()
 	Headers          map[string]string `yaml:"headers" mapstructure:"headers"`
 	Debug            bool              `yaml:"debug" mapstructure:"debug"`
 	LogSlowThreshold time.Duration     `yaml:"log_slow_threshold" mapstructure:"log_slow_threshold"`
+	LogSampleRate    float64           `yaml:"log_sample_rate" mapstructure:"log_sample_rate"`     // 成功请求日志采样率 [0,1]，1 全部记录，0 不记录；错误和慢请求始终记录
+	ServiceName      string            `yaml:"service_name" mapstructure:"service_name"`           // 调用方服务名（取自服务配置的 server.name），用于生成默认 User-Agent
+	UserAgent        string            `yaml:"user_agent" mapstructure:"user_agent"`               // User-Agent 请求头，为空时使用 服务名/版本号，服务名也为空时使用 resty 默认值
+	RequestIDHeader  string            `yaml:"request_id_header" mapstructure:"request_id_header"` // 转发 reqctx 中 request_id 的请求头，默认 X-Request-ID，为空时不转发
+
+	PropagateTraceHeaders bool   `yaml:"propagate_trace_headers" mapstructure:"propagate_trace_headers"` // 是否为所有请求转发 trace_id、user_id，调用外部第三方服务时不建议开启
+	TraceIDHeader         string `yaml:"trace_id_header" mapstructure:"trace_id_header"`                 // 转发 trace_id 的请求头，默认 X-Trace-ID，为空时不转发
+	UserIDHeader          string `yaml:"user_id_header" mapstructure:"user_id_header"`                   // 转发 user_id 的请求头，默认 X-User-ID，为空时不转发
+
+	BodyLog BodyLogConfig `yaml:"body_log" mapstructure:"body_log"` // 请求/响应体日志（脱敏），替代会输出敏感信息的 Debug 模式
+
+	// 客户端级别的固定凭证，所有请求默认携带；设置了 AuthProvider 时忽略
+	AuthType  string `yaml:"auth_type" mapstructure:"auth_type"`   // 认证方式: bearer、basic，为空时不认证
+	AuthToken string `yaml:"auth_token" mapstructure:"auth_token"` // bearer 认证的令牌
+	BasicUser string `yaml:"basic_user" mapstructure:"basic_user"` // basic 认证的用户名
+	BasicPass string `yaml:"basic_pass" mapstructure:"basic_pass"` // basic 认证的密码
+
+	AuthProvider AuthProvider `yaml:"-" mapstructure:"-"` // 客户端级别的认证提供者，只能通过 WithAuthProvider 设置
 }
 
 // DefaultConfig 返回默认配置
@@ -21,9 +43,14 @@ func DefaultConfig() *Config {
 		RetryCount:       3,
 		RetryWaitTime:    1 * time.Second,
 		RetryMaxWaitTime: 5 * time.Second,
+		RetryStatusCodes: DefaultRetryStatusCodes(),
 		Headers:          make(map[string]string),
 		Debug:            false,
 		LogSlowThreshold: 3000 * time.Millisecond, // 3秒
+		LogSampleRate:    1,                       // 默认记录全部成功请求
+		RequestIDHeader:  DefaultRequestIDHeader,
+		TraceIDHeader:    DefaultTraceIDHeader,
+		UserIDHeader:     DefaultUserIDHeader,
 	}
 }
 
@@ -65,6 +92,14 @@ func WithRetryMaxWaitTime(maxWait time.Duration) Option {
 	}
 }
 
+// WithRetryConditions 设置触发重试的状态码，替换默认列表
+// 429 和 503 响应携带 Retry-After 时按其指定的时间等待后重试
+func WithRetryConditions(statuses ...int) Option {
+	return func(c *Config) {
+		c.RetryStatusCodes = statuses
+	}
+}
+
 // WithDefaultHeaders 设置客户端默认请求头
 func WithDefaultHeaders(headers map[string]string) Option {
 	return func(c *Config) {
@@ -90,3 +125,94 @@ func WithLogSlowThreshold(threshold time.Duration) Option {
 		c.LogSlowThreshold = threshold
 	}
 }
+
+// WithLogSampleRate 设置成功请求日志的采样率
+// 高频调用方可以降低采样率以减少日志量，0 表示不记录成功请求；错误和慢请求不受采样影响
+// 取值必须在 [0, 1] 内，否则 New 返回错误
+func WithLogSampleRate(rate float64) Option {
+	return func(c *Config) {
+		c.LogSampleRate = rate
+	}
+}
+
+// WithServiceName 设置调用方服务名，未设置 User-Agent 时以 服务名/版本号（如 user-service/v1.2.0）作为 User-Agent
+// 应传入服务配置中的服务名（server.name），而不是进程名
+func WithServiceName(name string) Option {
+	return func(c *Config) {
+		c.ServiceName = name
+	}
+}
+
+// WithUserAgent 设置 User-Agent 请求头，便于上游识别调用方服务，优先于 WithServiceName 生成的默认值
+func WithUserAgent(userAgent string) Option {
+	return func(c *Config) {
+		c.UserAgent = userAgent
+	}
+}
+
+// WithRequestIDHeader 设置转发 request_id 使用的请求头，为空时不转发
+func WithRequestIDHeader(header string) Option {
+	return func(c *Config) {
+		c.RequestIDHeader = header
+	}
+}
+
+// WithAuthProvider 设置客户端级别的认证提供者，所有请求发送前调用
+// 请求级别的 WithAuthToken / WithBearerToken / WithBasicAuth 优先于该提供者
+func WithAuthProvider(provider AuthProvider) Option {
+	return func(c *Config) {
+		c.AuthProvider = provider
+	}
+}
+
+// WithClientAuthToken 为所有请求设置 Authorization: Bearer <token>
+// 请求级别的 WithAuthToken / WithBearerToken / WithBasicAuth 优先于该设置
+func WithClientAuthToken(token string) Option {
+	return func(c *Config) {
+		c.AuthType = AuthTypeBearer
+		c.AuthToken = token
+	}
+}
+
+// WithClientBasicAuth 为所有请求设置 Basic 认证
+// 请求级别的 WithAuthToken / WithBearerToken / WithBasicAuth 优先于该设置
+func WithClientBasicAuth(username, password string) Option {
+	return func(c *Config) {
+		c.AuthType = AuthTypeBasic
+		c.BasicUser = username
+		c.BasicPass = password
+	}
+}
+
+// WithTracePropagation 为所有请求转发上下文中的 trace_id、request_id、user_id
+// 适用于调用内部服务，请求头名称可通过 WithTraceHeaderNames 修改
+func WithTracePropagation() Option {
+	return func(c *Config) {
+		c.PropagateTraceHeaders = true
+	}
+}
+
+// WithTraceHeaderNames 设置转发 trace_id、request_id、user_id 使用的请求头，为空的字段不转发
+func WithTraceHeaderNames(traceID, requestID, userID string) Option {
+	return func(c *Config) {
+		c.TraceIDHeader = traceID
+		c.RequestIDHeader = requestID
+		c.UserIDHeader = userID
+	}
+}
+
+// WithBodyLogging 以 debug 级别记录脱敏并截断后的请求/响应体
+// maxBytes 为单个请求体/响应体记录的最大字节数（<= 0 时为 4096）；
+// redactHeaders 为额外需要脱敏的请求头（Authorization、Cookie 等始终脱敏）；
+// redactJSONFields 为需要脱敏的 JSON 字段名，不区分大小写，匹配任意层级，同样作用于表单参数；
+// 配置了脱敏字段时，既不是 JSON 也不是表单的内容整体替换为 [REDACTED]
+func WithBodyLogging(maxBytes int, redactHeaders, redactJSONFields []string) Option {
+	return func(c *Config) {
+		c.BodyLog = BodyLogConfig{
+			Enabled:          true,
+			MaxBytes:         maxBytes,
+			RedactHeaders:    redactHeaders,
+			RedactJSONFields: redactJSONFields,
+		}
+	}
+}
@@ -24,10 +24,14 @@ type CreateUserRequest struct {
 // Example_basicGet 基本GET请求示例
 func Example_basicGet() {
 	// 创建客户端
-	client := httpclient.New(
+	client, err := httpclient.New(
 		httpclient.WithBaseURL("https://jsonplaceholder.typicode.com"),
 		httpclient.WithTimeout(10*time.Second),
 	)
+	if err != nil {
+		fmt.Printf("创建客户端失败: %v\n", err)
+		return
+	}
 	defer client.Close()
 
 	// 发送GET请求
@@ -44,13 +48,17 @@ func Example_basicGet() {
 
 // Example_getWithQueryParams GET请求带查询参数
 func Example_getWithQueryParams() {
-	client := httpclient.New(
+	client, err := httpclient.New(
 		httpclient.WithBaseURL("https://jsonplaceholder.typicode.com"),
 	)
+	if err != nil {
+		fmt.Printf("创建客户端失败: %v\n", err)
+		return
+	}
 	defer client.Close()
 
 	var posts []map[string]interface{}
-	_, err := client.Get(
+	_, err = client.Get(
 		context.Background(),
 		"/posts",
 		&posts,
@@ -69,9 +77,13 @@ func Example_getWithQueryParams() {
 
 // Example_postRequest POST请求示例
 func Example_postRequest() {
-	client := httpclient.New(
+	client, err := httpclient.New(
 		httpclient.WithBaseURL("https://jsonplaceholder.typicode.com"),
 	)
+	if err != nil {
+		fmt.Printf("创建客户端失败: %v\n", err)
+		return
+	}
 	defer client.Close()
 
 	newUser := CreateUserRequest{
@@ -98,9 +110,13 @@ func Example_postRequest() {
 
 // Example_putRequest PUT请求示例
 func Example_putRequest() {
-	client := httpclient.New(
+	client, err := httpclient.New(
 		httpclient.WithBaseURL("https://jsonplaceholder.typicode.com"),
 	)
+	if err != nil {
+		fmt.Printf("创建客户端失败: %v\n", err)
+		return
+	}
 	defer client.Close()
 
 	updates := map[string]interface{}{
@@ -109,7 +125,7 @@ func Example_putRequest() {
 	}
 
 	var result User
-	_, err := client.Put(
+	_, err = client.Put(
 		context.Background(),
 		"/users/1",
 		updates,
@@ -126,9 +142,13 @@ func Example_putRequest() {
 
 // Example_deleteRequest DELETE请求示例
 func Example_deleteRequest() {
-	client := httpclient.New(
+	client, err := httpclient.New(
 		httpclient.WithBaseURL("https://jsonplaceholder.typicode.com"),
 	)
+	if err != nil {
+		fmt.Printf("创建客户端失败: %v\n", err)
+		return
+	}
 	defer client.Close()
 
 	resp, err := client.Delete(
@@ -147,16 +167,20 @@ func Example_deleteRequest() {
 
 // Example_withAuth 带认证的请求
 func Example_withAuth() {
-	client := httpclient.New(
+	client, err := httpclient.New(
 		httpclient.WithBaseURL("https://api.example.com"),
 		httpclient.WithDefaultHeaders(map[string]string{
 			"User-Agent": "MyApp/1.0",
 		}),
 	)
+	if err != nil {
+		fmt.Printf("创建客户端失败: %v\n", err)
+		return
+	}
 	defer client.Close()
 
 	var result map[string]interface{}
-	_, err := client.Get(
+	_, err = client.Get(
 		context.Background(),
 		"/api/protected/resource",
 		&result,
@@ -178,16 +202,20 @@ func Example_withAuth() {
 
 // Example_withRetry 带重试的请求
 func Example_withRetry() {
-	client := httpclient.New(
+	client, err := httpclient.New(
 		httpclient.WithBaseURL("https://api.example.com"),
 		httpclient.WithRetryCount(3),
 		httpclient.WithRetryWaitTime(1*time.Second),
 		httpclient.WithRetryMaxWaitTime(5*time.Second),
 	)
+	if err != nil {
+		fmt.Printf("创建客户端失败: %v\n", err)
+		return
+	}
 	defer client.Close()
 
 	var result map[string]interface{}
-	_, err := client.Get(
+	_, err = client.Get(
 		context.Background(),
 		"/api/unstable/endpoint",
 		&result,
@@ -203,13 +231,17 @@ func Example_withRetry() {
 
 // Example_pathParams 路径参数示例
 func Example_pathParams() {
-	client := httpclient.New(
+	client, err := httpclient.New(
 		httpclient.WithBaseURL("https://jsonplaceholder.typicode.com"),
 	)
+	if err != nil {
+		fmt.Printf("创建客户端失败: %v\n", err)
+		return
+	}
 	defer client.Close()
 
 	var post map[string]interface{}
-	_, err := client.Get(
+	_, err = client.Get(
 		context.Background(),
 		"/users/{userId}/posts/{postId}",
 		&post,
@@ -229,13 +261,17 @@ func Example_pathParams() {
 
 // Example_formData 表单数据示例
 func Example_formData() {
-	client := httpclient.New(
+	client, err := httpclient.New(
 		httpclient.WithBaseURL("https://httpbin.org"),
 	)
+	if err != nil {
+		fmt.Printf("创建客户端失败: %v\n", err)
+		return
+	}
 	defer client.Close()
 
 	var result map[string]interface{}
-	_, err := client.Post(
+	_, err = client.Post(
 		context.Background(),
 		"/post",
 		nil,
@@ -256,9 +292,13 @@ func Example_formData() {
 
 // Example_errorHandling 错误处理示例
 func Example_errorHandling() {
-	client := httpclient.New(
+	client, err := httpclient.New(
 		httpclient.WithBaseURL("https://jsonplaceholder.typicode.com"),
 	)
+	if err != nil {
+		fmt.Printf("创建客户端失败: %v\n", err)
+		return
+	}
 	defer client.Close()
 
 	var result User
@@ -292,9 +332,13 @@ func Example_errorHandling() {
 
 // Example_context 使用Context示例
 func Example_context() {
-	client := httpclient.New(
+	client, err := httpclient.New(
 		httpclient.WithBaseURL("https://jsonplaceholder.typicode.com"),
 	)
+	if err != nil {
+		fmt.Printf("创建客户端失败: %v\n", err)
+		return
+	}
 	defer client.Close()
 
 	// 创建带超时的context
@@ -302,7 +346,7 @@ func Example_context() {
 	defer cancel()
 
 	var users []User
-	_, err := client.Get(ctx, "/users", &users)
+	_, err = client.Get(ctx, "/users", &users)
 
 	if err != nil {
 		if ctx.Err() == context.DeadlineExceeded {
@@ -1,6 +1,7 @@
 package httpclient
 
 import (
+	"context"
 	"net/http"
 
 	"resty.dev/v3"
@@ -105,13 +106,10 @@ func WithRetry(count int) RequestOption {
 	}
 }
 
-// WithContext 从context中自动提取trace_id等信息并添加到请求头
-// func WithContextHeaders(ctx context.Context) RequestOption {
-// 	return func(req *resty.Request) {
-// 		// 可以从context中提取trace_id、request_id等信息
-// 		// 示例：
-// 		// if traceID := reqctx.GetTraceID(ctx); traceID != "" {
-// 		// 	req.SetHeader("X-Trace-ID", traceID)
-// 		// }
-// 	}
-// }
+// WithContextHeaders 将 ctx 中的 trace_id、request_id、user_id 写入 X-Trace-ID、X-Request-ID、X-User-ID 请求头
+// ctx 中不存在的字段不设置；需要所有请求自动携带或自定义请求头名称时，使用客户端选项 WithTracePropagation
+func WithContextHeaders(ctx context.Context) RequestOption {
+	return func(req *resty.Request) {
+		defaultContextHeaders.apply(ctx, req)
+	}
+}
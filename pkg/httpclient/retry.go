@@ -0,0 +1,35 @@
+package httpclient
+
+import (
+	"net/http"
+
+	"resty.dev/v3"
+)
+
+// DefaultRetryStatusCodes 返回默认触发重试的状态码：限流和临时性的服务端错误
+// 4xx（429 除外）属于调用方错误，重试不会成功，因此不在默认列表中；每次调用返回新的切片，修改不影响其他客户端
+func DefaultRetryStatusCodes() []int {
+	return []int{
+		http.StatusTooManyRequests,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout,
+	}
+}
+
+// retryOnStatus 创建按状态码判断是否重试的条件
+// 未收到响应（状态码为 0）的网络错误同样重试；429/503 的 Retry-After 由 resty 的退避策略处理
+func retryOnStatus(statuses []int) resty.RetryConditionFunc {
+	retryable := make(map[int]bool, len(statuses))
+	for _, status := range statuses {
+		retryable[status] = true
+	}
+
+	return func(resp *resty.Response, err error) bool {
+		if resp == nil || resp.StatusCode() == 0 {
+			return err != nil
+		}
+		return retryable[resp.StatusCode()]
+	}
+}
@@ -0,0 +1,44 @@
+package httpclient
+
+import (
+	"context"
+
+	"github.com/alfredchaos/demo/pkg/reqctx"
+	"resty.dev/v3"
+)
+
+// 传递上下文信息使用的默认请求头
+const (
+	DefaultTraceIDHeader = "X-Trace-ID"
+	DefaultUserIDHeader  = "X-User-ID"
+)
+
+// contextHeaders 上下文字段对应的请求头名称，名称为空的字段不传递
+type contextHeaders struct {
+	traceID   string
+	requestID string
+	userID    string
+}
+
+// defaultContextHeaders 默认请求头名称
+var defaultContextHeaders = contextHeaders{
+	traceID:   DefaultTraceIDHeader,
+	requestID: DefaultRequestIDHeader,
+	userID:    DefaultUserIDHeader,
+}
+
+// apply 将 ctx 中的 trace_id、request_id、user_id 写入请求头
+// ctx 中不存在的字段不设置，请求中已设置的请求头不覆盖
+func (h contextHeaders) apply(ctx context.Context, req *resty.Request) {
+	setHeaderIfAbsent(req, h.traceID, reqctx.GetTraceID(ctx))
+	setHeaderIfAbsent(req, h.requestID, reqctx.GetRequestID(ctx))
+	setHeaderIfAbsent(req, h.userID, reqctx.GetUserID(ctx))
+}
+
+// setHeaderIfAbsent 名称和值都不为空且请求中未设置时设置请求头
+func setHeaderIfAbsent(req *resty.Request, name, value string) {
+	if name == "" || value == "" || req.Header.Get(name) != "" {
+		return
+	}
+	req.SetHeader(name, value)
+}
@@ -0,0 +1,43 @@
+// Package idgen 提供实体 ID 的生成与格式校验
+package idgen
+
+import (
+	"fmt"
+
+	apperrors "github.com/alfredchaos/demo/pkg/errors"
+	"github.com/google/uuid"
+)
+
+// ErrInvalidID ID 格式不合法，映射为参数错误
+var ErrInvalidID = apperrors.NewCoded(apperrors.ErrInvalidParams, "invalid id")
+
+// Generator ID 生成器
+// 生成与校验成对出现，仓库据此在访问数据库前拒绝格式不合法的 ID
+type Generator interface {
+	// New 生成新的 ID
+	New() string
+	// Validate 校验 ID 格式，不合法时返回包装了 ErrInvalidID 的错误
+	Validate(id string) error
+}
+
+// UUID 生成和校验标准格式（8-4-4-4-12）的 UUID
+var UUID Generator = uuidGenerator{}
+
+// uuidGenerator 基于 google/uuid 的 UUID 生成器
+type uuidGenerator struct{}
+
+// New 生成随机（v4）UUID
+func (uuidGenerator) New() string {
+	return uuid.New().String()
+}
+
+// Validate 只接受 36 个字符的标准格式，拒绝 uuid.Parse 兼容的 urn:uuid:、花括号等其他写法
+func (uuidGenerator) Validate(id string) error {
+	if len(id) != 36 {
+		return fmt.Errorf("%w: %q is not a uuid", ErrInvalidID, id)
+	}
+	if _, err := uuid.Parse(id); err != nil {
+		return fmt.Errorf("%w: %q is not a uuid", ErrInvalidID, id)
+	}
+	return nil
+}
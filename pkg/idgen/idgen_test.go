@@ -0,0 +1,35 @@
+package idgen
+
+import (
+	"errors"
+	"testing"
+
+	apperrors "github.com/alfredchaos/demo/pkg/errors"
+)
+
+func TestUUID_ValidateAcceptsGeneratedIDs(t *testing.T) {
+	for i := 0; i < 10; i++ {
+		if id := UUID.New(); UUID.Validate(id) != nil {
+			t.Fatalf("want generated id %q to be valid", id)
+		}
+	}
+}
+
+func TestUUID_ValidateRejectsMalformedIDs(t *testing.T) {
+	for _, id := range []string{
+		"",
+		"u1",
+		"not-a-uuid-not-a-uuid-not-a-uuid-xx",
+		"urn:uuid:6ba7b810-9dad-11d1-80b4-00c04fd430c8",
+		"{6ba7b810-9dad-11d1-80b4-00c04fd430c8}",
+		"6ba7b8109dad11d180b400c04fd430c8",
+	} {
+		err := UUID.Validate(id)
+		if !errors.Is(err, ErrInvalidID) {
+			t.Errorf("%q: want ErrInvalidID, got %v", id, err)
+		}
+		if apperrors.CodeOf(err) != apperrors.ErrInvalidParams {
+			t.Errorf("%q: want ErrInvalidParams code, got %d", id, apperrors.CodeOf(err))
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package log
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+)
+
+// loggerKey 请求级 logger 在 context 中的键
+type loggerKey struct{}
+
+// IntoContext 将 logger 存入 context
+// 中间件在请求入口绑定一次 trace_id、request_id 等字段后存入，下游通过 FromContext 直接复用，
+// 避免每次记录日志都重新提取字段并调用 With 分配新的 logger
+func IntoContext(ctx context.Context, logger *zap.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+// FromContext 获取 IntoContext 存入的 logger，未存入时返回全局 Logger
+// 与 WithContext 不同，不会从 context 中重新提取字段；存入之后再写入 reqctx 的字段不会出现在日志中
+func FromContext(ctx context.Context) *zap.Logger {
+	if ctx != nil {
+		if logger, ok := ctx.Value(loggerKey{}).(*zap.Logger); ok && logger != nil {
+			return logger
+		}
+	}
+	return Logger
+}
@@ -0,0 +1,46 @@
+package log
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alfredchaos/demo/pkg/reqctx"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestFromContext_FallsBackToGlobalLogger(t *testing.T) {
+	prev := Logger
+	t.Cleanup(func() { Logger = prev })
+	Logger = zap.NewNop()
+
+	if got := FromContext(context.Background()); got != Logger {
+		t.Fatal("want global logger when nothing is stored")
+	}
+	var nilCtx context.Context
+	if got := FromContext(nilCtx); got != Logger {
+		t.Fatal("want global logger for nil context")
+	}
+}
+
+func TestFromContext_ReusesBoundLogger(t *testing.T) {
+	prev := Logger
+	t.Cleanup(func() { Logger = prev })
+	core, logs := observer.New(zapcore.DebugLevel)
+	Logger = zap.New(core)
+
+	ctx := reqctx.WithRequestID(reqctx.WithTraceID(context.Background(), "trace-1"), "req-1")
+	ctx = IntoContext(ctx, WithContext(ctx))
+
+	bound := FromContext(ctx)
+	if FromContext(ctx) != bound {
+		t.Fatal("want the same logger instance on every call")
+	}
+
+	bound.Info("handled")
+	fields := logs.All()[0].ContextMap()
+	if fields["trace_id"] != "trace-1" || fields["request_id"] != "req-1" {
+		t.Fatalf("want bound fields on log entry, got %v", fields)
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"sort"
+	"sync"
 	"time"
 
 	"github.com/alfredchaos/demo/pkg/reqctx"
@@ -15,6 +17,9 @@ import (
 var (
 	// Logger 全局日志实例
 	Logger *zap.Logger
+
+	// level 全局日志级别，InitLogger 创建的所有 Core 共享，通过 SetLevel 在运行时调整
+	level = zap.NewAtomicLevel()
 )
 
 // customTimeEncoder 自定义时间编码器
@@ -27,9 +32,42 @@ func customTimeEncoder(t time.Time, enc zapcore.PrimitiveArrayEncoder) {
 type LogConfig struct {
 	Level               string       `yaml:"level" mapstructure:"level"`                                 // 日志级别: debug, info, warn, error
 	Format              string       `yaml:"format" mapstructure:"format"`                               // 日志格式: json, console
-	OutputPaths         []string     `yaml:"output_paths" mapstructure:"output_paths"`                   // 输出路径列表，支持 stdout 或文件路径
-	EnableConsoleWriter bool         `yaml:"enable_console_writer" mapstructure:"enable_console_writer"` // 是否启用 ConsoleWriter（仅对stdout生效）
+	OutputPaths         []string     `yaml:"output_paths" mapstructure:"output_paths"`                   // 输出路径列表，支持 stdout、stderr 或文件路径
+	EnableConsoleWriter bool         `yaml:"enable_console_writer" mapstructure:"enable_console_writer"` // 是否启用 ConsoleWriter（仅对stdout/stderr生效）
 	Rotation            *RotationConfig `yaml:"rotation" mapstructure:"rotation"`                         // 日志切割配置（可选）
+	// 附加到每条日志的静态字段，如 env、region、version；与保留字段（service、timestamp、level 等）同名的键会被忽略
+	Fields map[string]string `yaml:"fields" mapstructure:"fields"`
+	// 需要脱敏的字段名（忽略大小写），如 password、token；匹配的顶层字段值输出为 "***"
+	RedactKeys []string `yaml:"redact_keys" mapstructure:"redact_keys"`
+}
+
+// reservedFieldKeys 编码器和服务名称使用的字段，LogConfig.Fields 中的同名键会被忽略
+var reservedFieldKeys = map[string]struct{}{
+	"service":    {},
+	"timestamp":  {},
+	"level":      {},
+	"logger":     {},
+	"caller":     {},
+	"message":    {},
+	"stacktrace": {},
+}
+
+// staticFields 将 LogConfig.Fields 转换为 zap 字段，按键排序并跳过保留字段
+func staticFields(fields map[string]string) []zap.Field {
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		if _, reserved := reservedFieldKeys[key]; reserved {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	result := make([]zap.Field, 0, len(keys))
+	for _, key := range keys {
+		result = append(result, zap.String(key, fields[key]))
+	}
+	return result
 }
 
 // RotationConfig 日志切割配置
@@ -39,20 +77,70 @@ type RotationConfig struct {
 	MaxBackups int  `yaml:"max_backups" mapstructure:"max_backups"` // 保留的旧日志文件的最大数量，默认10个
 	Compress   bool `yaml:"compress" mapstructure:"compress"`       // 是否压缩旧日志文件，默认false
 	LocalTime  bool `yaml:"local_time" mapstructure:"local_time"`   // 是否使用本地时间，默认使用UTC时间
+	// 按时间切割的粒度: day（默认）、hour、none；none 时文件名不带时间后缀，仅按 MaxSize 切割
+	Granularity string `yaml:"granularity" mapstructure:"granularity"`
+}
+
+// 日志切割粒度
+const (
+	GranularityDay  = "day"  // 按天切割，文件名后缀为 20060102
+	GranularityHour = "hour" // 按小时切割，文件名后缀为 2006010215
+	GranularityNone = "none" // 不按时间切割，仅按大小切割
+)
+
+// granularityLayout 返回切割粒度对应的时间格式，none 返回空字符串，空值按 day 处理
+func granularityLayout(granularity string) (string, error) {
+	switch granularity {
+	case "", GranularityDay:
+		return "20060102", nil
+	case GranularityHour:
+		return "2006010215", nil
+	case GranularityNone:
+		return "", nil
+	default:
+		return "", fmt.Errorf("invalid log rotation granularity %q", granularity)
+	}
 }
 
 // WrapWriterLogs 日志切割写入器
+// zap 会在多个 goroutine 中并发调用 Write，按时间切换文件由 mu 保护；
+// 切换时会替换 Logger，应通过 WrapWriterLogs 的方法访问，不要直接持有内嵌的 Logger
 type WrapWriterLogs struct {
 	*lumberjack.Logger
-	currentDay string
+	mu            sync.Mutex
+	baseFilename  string           // 不含时间后缀的文件名
+	layout        string           // 时间后缀格式，为空时不按时间切割
+	currentPeriod string           // 当前文件对应的时间段
+	now           func() time.Time // 时间来源，测试中用于模拟时间变化
 }
 
-// NewWrapWriterLogs 创建一个支持按天切割日志文件的 WrapWriterLogs 实例。
+// NewWrapWriterLogs 创建一个支持按天切割日志文件的 WrapWriterLogs 实例，按小时或仅按大小切割见 NewWrapWriterLogsFromConfig
 // filename: 日志文件名（包含路径，后面会拼上 _{day}.log）
 // maxSize: 每个日志文件的最大尺寸（以MB为单位）
 // maxAge: 日志文件的最大保存天数
 // maxBackups: 保留的旧日志文件的最大数量
 func NewWrapWriterLogs(filename string, maxSize, maxAge, maxBackups int, compress, localTime bool) *WrapWriterLogs {
+	w, _ := NewWrapWriterLogsFromConfig(filename, &RotationConfig{
+		MaxSize:    maxSize,
+		MaxAge:     maxAge,
+		MaxBackups: maxBackups,
+		Compress:   compress,
+		LocalTime:  localTime,
+	})
+	return w
+}
+
+// NewWrapWriterLogsFromConfig 按切割配置创建 WrapWriterLogs 实例
+// filename: 日志文件名（包含路径），按天或按小时切割时拼上 _{时间}.log，granularity 为 none 时拼上 .log
+// granularity 非法时返回错误
+func NewWrapWriterLogsFromConfig(filename string, cfg *RotationConfig) (*WrapWriterLogs, error) {
+	layout, err := granularityLayout(cfg.Granularity)
+	if err != nil {
+		return nil, err
+	}
+
+	maxSize, maxAge, maxBackups := cfg.MaxSize, cfg.MaxAge, cfg.MaxBackups
+
 	// 设置默认值
 	if maxSize <= 0 {
 		maxSize = 100
@@ -64,47 +152,86 @@ func NewWrapWriterLogs(filename string, maxSize, maxAge, maxBackups int, compres
 		maxBackups = 10
 	}
 
-	// 生成带日期的文件名
-	currentDay := getCurrentDay(localTime)
-	filenameWithDay := fmt.Sprintf("%s_%s.log", filename, currentDay)
+	// 生成带时间后缀的文件名
+	currentPeriod := formatPeriod(time.Now(), layout, cfg.LocalTime)
 
 	return &WrapWriterLogs{
 		Logger: &lumberjack.Logger{
-			Filename:   filenameWithDay,
+			Filename:   periodFilename(filename, currentPeriod),
 			MaxSize:    maxSize,
 			MaxAge:     maxAge,
 			MaxBackups: maxBackups,
-			LocalTime:  localTime,
-			Compress:   compress,
+			LocalTime:  cfg.LocalTime,
+			Compress:   cfg.Compress,
 		},
-		currentDay: currentDay,
-	}
+		baseFilename:  filename,
+		layout:        layout,
+		currentPeriod: currentPeriod,
+		now:           time.Now,
+	}, nil
 }
 
-// Write 实现 io.Writer 接口，支持按天自动切割
+// Write 实现 io.Writer 接口，支持按天或按小时自动切割
+// 时间检查、文件切换与写入在同一把锁内完成，时间段变化时只会切换一次文件
 func (w *WrapWriterLogs) Write(p []byte) (n int, err error) {
-	// 检查是否需要按天切割
-	newDay := getCurrentDay(w.Logger.LocalTime)
-	if newDay != w.currentDay {
-		// 日期变化，关闭旧文件，创建新文件
-		_ = w.Logger.Close()
-		
-		// 更新文件名
-		baseFilename := w.Logger.Filename[:len(w.Logger.Filename)-len(w.currentDay)-5] // 去掉 _{day}.log
-		w.Logger.Filename = fmt.Sprintf("%s_%s.log", baseFilename, newDay)
-		w.currentDay = newDay
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	// 检查是否需要按时间切割，granularity 为 none 时只由 lumberjack 按大小切割
+	if w.layout == "" {
+		return w.Logger.Write(p)
+	}
+	newPeriod := formatPeriod(w.now(), w.layout, w.Logger.LocalTime)
+	if newPeriod != w.currentPeriod {
+		// 时间段变化，关闭旧文件并换用新的 lumberjack.Logger
+		// lumberjack 的后台清理 goroutine 会无锁读取 Filename，不能原地修改
+		prev := w.Logger
+		_ = prev.Close()
+		w.Logger = &lumberjack.Logger{
+			Filename:   periodFilename(w.baseFilename, newPeriod),
+			MaxSize:    prev.MaxSize,
+			MaxAge:     prev.MaxAge,
+			MaxBackups: prev.MaxBackups,
+			LocalTime:  prev.LocalTime,
+			Compress:   prev.Compress,
+		}
+		w.currentPeriod = newPeriod
 	}
 
 	return w.Logger.Write(p)
 }
 
-// getCurrentDay 获取当前日期字符串（格式：20060102）
-func getCurrentDay(localTime bool) string {
-	now := time.Now()
+// Close 关闭当前日志文件
+func (w *WrapWriterLogs) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.Logger.Close()
+}
+
+// Rotate 立即切割当前日志文件
+func (w *WrapWriterLogs) Rotate() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.Logger.Rotate()
+}
+
+// formatPeriod 按 layout 返回时间段字符串（如 20060102、2006010215），layout 为空时返回空字符串
+func formatPeriod(t time.Time, layout string, localTime bool) string {
+	if layout == "" {
+		return ""
+	}
 	if !localTime {
-		now = now.UTC()
+		t = t.UTC()
 	}
-	return now.Format("20060102")
+	return t.Format(layout)
+}
+
+// periodFilename 生成带时间后缀的文件名：{filename}_{period}.log，period 为空时为 {filename}.log
+func periodFilename(filename, period string) string {
+	if period == "" {
+		return filename + ".log"
+	}
+	return fmt.Sprintf("%s_%s.log", filename, period)
 }
 
 // InitLogger 初始化日志系统
@@ -120,8 +247,7 @@ func InitLogger(cfg *LogConfig, serviceName string) error {
 	}
 
 	// 解析日志级别
-	level := zapcore.InfoLevel
-	if err := level.UnmarshalText([]byte(cfg.Level)); err != nil {
+	if err := SetLevel(cfg.Level); err != nil {
 		return err
 	}
 
@@ -148,8 +274,8 @@ func InitLogger(cfg *LogConfig, serviceName string) error {
 		var writeSyncer zapcore.WriteSyncer
 		var encoder zapcore.Encoder
 
-		if path == "stdout" || path == "" {
-			// 输出到标准输出
+		if path == "stdout" || path == "stderr" || path == "" {
+			// 输出到标准输出（或标准错误）
 			if cfg.EnableConsoleWriter {
 				// 使用 ConsoleEncoder 格式化输出（彩色、人眼友好）
 				consoleEncoderConfig := encoderConfig
@@ -161,6 +287,9 @@ func InitLogger(cfg *LogConfig, serviceName string) error {
 				encoder = zapcore.NewJSONEncoder(encoderConfig)
 			}
 			writeSyncer = zapcore.AddSync(os.Stdout)
+			if path == "stderr" {
+				writeSyncer = zapcore.AddSync(os.Stderr)
+			}
 		} else {
 			// 输出到文件，始终使用 JSON 格式
 			encoder = zapcore.NewJSONEncoder(encoderConfig)
@@ -173,14 +302,10 @@ func InitLogger(cfg *LogConfig, serviceName string) error {
 					basePath = path[:len(path)-4]
 				}
 				
-				wrapWriter := NewWrapWriterLogs(
-					basePath,
-					cfg.Rotation.MaxSize,
-					cfg.Rotation.MaxAge,
-					cfg.Rotation.MaxBackups,
-					cfg.Rotation.Compress,
-					cfg.Rotation.LocalTime,
-				)
+				wrapWriter, err := NewWrapWriterLogsFromConfig(basePath, cfg.Rotation)
+				if err != nil {
+					return err
+				}
 				writeSyncer = zapcore.AddSync(wrapWriter)
 			} else {
 				// 不使用日志切割，直接写入文件
@@ -192,7 +317,7 @@ func InitLogger(cfg *LogConfig, serviceName string) error {
 			}
 		}
 
-		// 创建 Core
+		// 创建 Core，所有 Core 共享同一个 AtomicLevel
 		core := zapcore.NewCore(encoder, writeSyncer, level)
 		cores = append(cores, core)
 	}
@@ -205,15 +330,39 @@ func InitLogger(cfg *LogConfig, serviceName string) error {
 		core = zapcore.NewTee(cores...)
 	}
 
+	// 敏感字段脱敏
+	core = newRedactCore(core, cfg.RedactKeys)
+
 	// 创建 Logger (不设置 CallerSkip，让各个函数自行调整)
 	Logger = zap.New(core, zap.AddCaller())
 
 	// 添加服务名称字段
 	Logger = Logger.With(zap.String("service", serviceName))
 
+	// 添加配置中的静态字段
+	if fields := staticFields(cfg.Fields); len(fields) > 0 {
+		Logger = Logger.With(fields...)
+	}
+
 	return nil
 }
 
+// SetLevel 修改日志级别，对 stdout 和文件输出立即生效，无需重启服务
+// text: debug, info, warn, error 等，非法值返回错误且不修改当前级别
+func SetLevel(text string) error {
+	var l zapcore.Level
+	if err := l.UnmarshalText([]byte(text)); err != nil {
+		return fmt.Errorf("invalid log level %q: %w", text, err)
+	}
+	level.SetLevel(l)
+	return nil
+}
+
+// GetLevel 返回当前日志级别
+func GetLevel() string {
+	return level.Level().String()
+}
+
 // MustInitLogger 初始化日志,失败则panic
 func MustInitLogger(cfg *LogConfig, serviceName string) {
 	if err := InitLogger(cfg, serviceName); err != nil {
@@ -0,0 +1,263 @@
+package log
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func TestSetLevel_TakesEffectImmediately(t *testing.T) {
+	prev := Logger
+	t.Cleanup(func() { Logger = prev })
+
+	path := filepath.Join(t.TempDir(), "app.log")
+	if err := InitLogger(&LogConfig{Level: "info", OutputPaths: []string{path}}, "test"); err != nil {
+		t.Fatalf("init logger: %v", err)
+	}
+	t.Cleanup(func() { _ = SetLevel("info") })
+
+	Debug("before switch")
+	if err := SetLevel("debug"); err != nil {
+		t.Fatalf("set level: %v", err)
+	}
+	if got := GetLevel(); got != "debug" {
+		t.Fatalf("want level debug, got %s", got)
+	}
+	Debug("after switch")
+	_ = Sync()
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read log file: %v", err)
+	}
+	if strings.Contains(string(content), "before switch") {
+		t.Fatal("debug log written before switching level")
+	}
+	if !strings.Contains(string(content), "after switch") {
+		t.Fatal("debug log not written after switching level")
+	}
+}
+
+func TestSetLevel_RejectsInvalidLevel(t *testing.T) {
+	if err := SetLevel("warn"); err != nil {
+		t.Fatalf("set level: %v", err)
+	}
+	t.Cleanup(func() { _ = SetLevel("info") })
+
+	if err := SetLevel("verbose"); err == nil {
+		t.Fatal("want error for invalid level")
+	}
+	if got := GetLevel(); got != "warn" {
+		t.Fatalf("want level unchanged after invalid value, got %s", got)
+	}
+}
+
+func TestWrapWriterLogs_ConcurrentWritesAcrossDayChange(t *testing.T) {
+	const (
+		goroutines = 100
+		perWorker  = 50
+	)
+
+	base := filepath.Join(t.TempDir(), "app")
+	w := NewWrapWriterLogs(base, 0, 0, 0, false, false)
+	t.Cleanup(func() { _ = w.Close() })
+
+	// 模拟的时钟，写入过程中切换到下一天
+	day1 := time.Date(2025, 1, 1, 23, 59, 59, 0, time.UTC)
+	var offset atomic.Int64
+	w.now = func() time.Time { return day1.Add(time.Duration(offset.Load())) }
+
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			<-start
+			for j := 0; j < perWorker; j++ {
+				if j == perWorker/2 && worker == goroutines/2 {
+					offset.Store(int64(time.Second))
+				}
+				if _, err := fmt.Fprintf(w, "worker %d line %d\n", worker, j); err != nil {
+					t.Errorf("write: %v", err)
+					return
+				}
+			}
+		}(i)
+	}
+	close(start)
+	wg.Wait()
+
+	var lines int
+	for _, day := range []string{"20250101", "20250102"} {
+		content, err := os.ReadFile(periodFilename(base, day))
+		if err != nil {
+			t.Fatalf("read log file for %s: %v", day, err)
+		}
+		lines += bytes.Count(content, []byte("\n"))
+	}
+	if lines != goroutines*perWorker {
+		t.Fatalf("want %d lines across both files, got %d", goroutines*perWorker, lines)
+	}
+}
+
+func TestWrapWriterLogs_HourlyGranularity(t *testing.T) {
+	base := filepath.Join(t.TempDir(), "app")
+	w, err := NewWrapWriterLogsFromConfig(base, &RotationConfig{Granularity: GranularityHour})
+	if err != nil {
+		t.Fatalf("new writer: %v", err)
+	}
+	t.Cleanup(func() { _ = w.Close() })
+
+	hour1 := time.Date(2025, 1, 1, 9, 59, 59, 0, time.UTC)
+	now := hour1
+	w.now = func() time.Time { return now }
+
+	if _, err := w.Write([]byte("first\n")); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	now = hour1.Add(time.Second)
+	if _, err := w.Write([]byte("second\n")); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	for period, want := range map[string]string{"2025010109": "first\n", "2025010110": "second\n"} {
+		content, err := os.ReadFile(periodFilename(base, period))
+		if err != nil {
+			t.Fatalf("read log file for %s: %v", period, err)
+		}
+		if string(content) != want {
+			t.Fatalf("want %q in %s, got %q", want, period, content)
+		}
+	}
+}
+
+func TestWrapWriterLogs_NoneGranularity(t *testing.T) {
+	base := filepath.Join(t.TempDir(), "app")
+	w, err := NewWrapWriterLogsFromConfig(base, &RotationConfig{Granularity: GranularityNone})
+	if err != nil {
+		t.Fatalf("new writer: %v", err)
+	}
+	t.Cleanup(func() { _ = w.Close() })
+
+	day1 := time.Date(2025, 1, 1, 23, 59, 59, 0, time.UTC)
+	now := day1
+	w.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		if _, err := w.Write([]byte("line\n")); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+		now = day1.Add(time.Hour)
+	}
+
+	// 不带时间后缀，跨天后仍写入同一个文件
+	content, err := os.ReadFile(base + ".log")
+	if err != nil {
+		t.Fatalf("read log file: %v", err)
+	}
+	if got := bytes.Count(content, []byte("\n")); got != 2 {
+		t.Fatalf("want 2 lines in %s.log, got %d", base, got)
+	}
+}
+
+func TestNewWrapWriterLogsFromConfig_InvalidGranularity(t *testing.T) {
+	if _, err := NewWrapWriterLogsFromConfig(filepath.Join(t.TempDir(), "app"), &RotationConfig{Granularity: "minute"}); err == nil {
+		t.Fatal("want error for invalid granularity")
+	}
+}
+
+func TestInitLogger_StaticFields(t *testing.T) {
+	prev, prevStdout := Logger, os.Stdout
+	t.Cleanup(func() { Logger, os.Stdout = prev, prevStdout })
+
+	dir := t.TempDir()
+	stdout, err := os.Create(filepath.Join(dir, "stdout"))
+	if err != nil {
+		t.Fatalf("create stdout file: %v", err)
+	}
+	defer stdout.Close()
+	os.Stdout = stdout
+
+	path := filepath.Join(dir, "app.log")
+	cfg := &LogConfig{
+		Level:               "info",
+		OutputPaths:         []string{"stdout", path},
+		EnableConsoleWriter: true,
+		Fields: map[string]string{
+			"env":     "prod",
+			"region":  "cn-east-1",
+			"service": "spoofed",
+			"level":   "spoofed",
+		},
+	}
+	if err := InitLogger(cfg, "test"); err != nil {
+		t.Fatalf("init logger: %v", err)
+	}
+	Info("hello")
+	_ = Sync()
+
+	jsonLog, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read log file: %v", err)
+	}
+	for _, want := range []string{`"env":"prod"`, `"region":"cn-east-1"`, `"service":"test"`, `"level":"info"`} {
+		if !strings.Contains(string(jsonLog), want) {
+			t.Fatalf("want %s in json log, got %s", want, jsonLog)
+		}
+	}
+	if strings.Contains(string(jsonLog), "spoofed") {
+		t.Fatalf("reserved keys should be ignored, got %s", jsonLog)
+	}
+
+	consoleLog, err := os.ReadFile(stdout.Name())
+	if err != nil {
+		t.Fatalf("read console log: %v", err)
+	}
+	for _, want := range []string{`"env": "prod"`, `"region": "cn-east-1"`} {
+		if !strings.Contains(string(consoleLog), want) {
+			t.Fatalf("want %s in console log, got %s", want, consoleLog)
+		}
+	}
+	if strings.Contains(string(consoleLog), "spoofed") {
+		t.Fatalf("reserved keys should be ignored, got %s", consoleLog)
+	}
+}
+
+func TestInitLogger_RedactKeys(t *testing.T) {
+	prev := Logger
+	t.Cleanup(func() { Logger = prev })
+
+	path := filepath.Join(t.TempDir(), "app.log")
+	cfg := &LogConfig{Level: "info", OutputPaths: []string{path}, RedactKeys: []string{"Password", "token"}}
+	if err := InitLogger(cfg, "test"); err != nil {
+		t.Fatalf("init logger: %v", err)
+	}
+
+	Info("login", zap.String("password", "hunter2"), zap.String("username", "alice"))
+	Logger.With(zap.String("TOKEN", "secret-token")).Info("with token")
+	_ = Sync()
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read log file: %v", err)
+	}
+	for _, want := range []string{`"password":"***"`, `"username":"alice"`, `"TOKEN":"***"`} {
+		if !strings.Contains(string(content), want) {
+			t.Fatalf("want %s in log, got %s", want, content)
+		}
+	}
+	for _, secret := range []string{"hunter2", "secret-token"} {
+		if strings.Contains(string(content), secret) {
+			t.Fatalf("secret %q leaked into log: %s", secret, content)
+		}
+	}
+}
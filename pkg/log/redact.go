@@ -0,0 +1,68 @@
+package log
+
+import (
+	"strings"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// redactedValue 敏感字段脱敏后的值
+const redactedValue = "***"
+
+// redactCore 在编码前替换敏感字段的值
+// 只匹配顶层字段的键（忽略大小写），zap.Any 传入的结构体内部字段不会被检查
+type redactCore struct {
+	zapcore.Core
+	keys map[string]struct{}
+}
+
+// newRedactCore 包装 core，keys 为空时直接返回 core
+func newRedactCore(core zapcore.Core, keys []string) zapcore.Core {
+	if len(keys) == 0 {
+		return core
+	}
+
+	set := make(map[string]struct{}, len(keys))
+	for _, key := range keys {
+		set[strings.ToLower(key)] = struct{}{}
+	}
+	return &redactCore{Core: core, keys: set}
+}
+
+// With 实现 zapcore.Core，Logger.With 附加的字段同样脱敏
+func (c *redactCore) With(fields []zapcore.Field) zapcore.Core {
+	return &redactCore{Core: c.Core.With(c.redact(fields)), keys: c.keys}
+}
+
+// Check 实现 zapcore.Core，由 redactCore 而不是内部 Core 写入日志
+func (c *redactCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+// Write 实现 zapcore.Core
+func (c *redactCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	return c.Core.Write(ent, c.redact(fields))
+}
+
+// redact 返回脱敏后的字段，没有敏感字段时返回原切片
+func (c *redactCore) redact(fields []zapcore.Field) []zapcore.Field {
+	var redacted []zapcore.Field
+	for i, field := range fields {
+		if _, ok := c.keys[strings.ToLower(field.Key)]; !ok {
+			continue
+		}
+		if redacted == nil {
+			// 不修改调用方的切片
+			redacted = append([]zapcore.Field(nil), fields...)
+		}
+		redacted[i] = zap.String(field.Key, redactedValue)
+	}
+	if redacted == nil {
+		return fields
+	}
+	return redacted
+}
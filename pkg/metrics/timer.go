@@ -0,0 +1,95 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/alfredchaos/demo/pkg/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+// StopFunc 停止计时，记录指标并返回耗时
+type StopFunc func() time.Duration
+
+// timerOptions 计时器配置
+type timerOptions struct {
+	logCtx context.Context
+}
+
+// TimerOption 计时器配置选项
+type TimerOption func(*timerOptions)
+
+// WithLog 停止计时时通过 log.WithContext(ctx) 以 Debug 级别记录 duration_ms
+func WithLog(ctx context.Context) TimerOption {
+	return func(o *timerOptions) {
+		o.logCtx = ctx
+	}
+}
+
+// Timers 代码块耗时计时器，按名称记录到同一个 Prometheus 直方图
+type Timers struct {
+	histogram *prometheus.HistogramVec
+}
+
+// NewTimers 创建计时器并注册 operation_duration_seconds{name} 直方图
+// 重复注册时复用已注册的实例
+func NewTimers(reg prometheus.Registerer) *Timers {
+	vec := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "operation_duration_seconds",
+		Help:    "Duration of timed code blocks such as repository and downstream calls, in seconds.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"name"})
+
+	if err := reg.Register(vec); err != nil {
+		var are prometheus.AlreadyRegisteredError
+		if errors.As(err, &are) {
+			if existing, ok := are.ExistingCollector.(*prometheus.HistogramVec); ok {
+				vec = existing
+			}
+		} else {
+			log.Warn("failed to register operation duration metrics", zap.Error(err))
+		}
+	}
+
+	return &Timers{histogram: vec}
+}
+
+// Timer 开始计时，调用返回的 StopFunc 结束计时
+//
+//	defer t.Timer("user_repo.get_by_id", metrics.WithLog(ctx))()
+func (t *Timers) Timer(name string, opts ...TimerOption) StopFunc {
+	o := &timerOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	start := time.Now()
+	return func() time.Duration {
+		elapsed := time.Since(start)
+		t.histogram.WithLabelValues(name).Observe(elapsed.Seconds())
+
+		if o.logCtx != nil && log.Logger != nil {
+			log.WithContext(o.logCtx).Debug("operation timed",
+				zap.String("operation", name),
+				log.DurationMs(elapsed.Milliseconds()),
+			)
+		}
+		return elapsed
+	}
+}
+
+var (
+	defaultTimers     *Timers
+	defaultTimersOnce sync.Once
+)
+
+// Timer 使用注册到 prometheus.DefaultRegisterer 的默认计时器开始计时
+func Timer(name string, opts ...TimerOption) StopFunc {
+	defaultTimersOnce.Do(func() {
+		defaultTimers = NewTimers(prometheus.DefaultRegisterer)
+	})
+	return defaultTimers.Timer(name, opts...)
+}
@@ -0,0 +1,71 @@
+package metrics
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alfredchaos/demo/pkg/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestTimerObservesElapsedTime(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	prev := log.Logger
+	log.Logger = zap.New(core)
+	t.Cleanup(func() { log.Logger = prev })
+
+	reg := prometheus.NewRegistry()
+	timers := NewTimers(reg)
+
+	stop := timers.Timer("user_repo.get_by_id", WithLog(context.Background()))
+	time.Sleep(20 * time.Millisecond)
+	elapsed := stop()
+
+	if elapsed < 20*time.Millisecond {
+		t.Fatalf("elapsed = %v, want >= 20ms", elapsed)
+	}
+
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatalf("gather: %v", err)
+	}
+	if len(families) != 1 || families[0].GetName() != "operation_duration_seconds" {
+		t.Fatalf("unexpected metric families: %v", families)
+	}
+	metric := families[0].GetMetric()[0]
+	if got := metric.GetLabel()[0].GetValue(); got != "user_repo.get_by_id" {
+		t.Fatalf("name label = %q", got)
+	}
+	h := metric.GetHistogram()
+	if h.GetSampleCount() != 1 {
+		t.Fatalf("sample count = %d, want 1", h.GetSampleCount())
+	}
+	if sum := h.GetSampleSum(); sum < 0.02 || sum > 1 {
+		t.Fatalf("sample sum = %vs, want between 0.02s and 1s", sum)
+	}
+
+	entries := logs.FilterMessage("operation timed").All()
+	if len(entries) != 1 {
+		t.Fatalf("want 1 log entry, got %d", len(entries))
+	}
+	if ms := entries[0].ContextMap()["duration_ms"].(int64); ms < 20 {
+		t.Fatalf("duration_ms = %d, want >= 20", ms)
+	}
+}
+
+func TestTimerWithoutLogOption(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	prev := log.Logger
+	log.Logger = zap.New(core)
+	t.Cleanup(func() { log.Logger = prev })
+
+	NewTimers(prometheus.NewRegistry()).Timer("noop")()
+
+	if logs.Len() != 0 {
+		t.Fatalf("want no logs, got %d", logs.Len())
+	}
+}
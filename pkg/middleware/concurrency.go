@@ -0,0 +1,105 @@
+package middleware
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/alfredchaos/demo/pkg/log"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// rejectLogInterval 拒绝日志的最小间隔，过载时每个间隔只记录一条汇总日志
+const rejectLogInterval = 10 * time.Second
+
+// ConcurrencyLimiter 服务端全局并发请求（流）数量限制
+// 一元请求与流式请求共享同一额度，超过上限时立即返回 ResourceExhausted 而不是排队等待
+type ConcurrencyLimiter struct {
+	slots chan struct{}
+
+	// 过载时每个请求都记录日志会放大压力，按 rejectLogInterval 汇总记录被拒绝的请求数
+	rejected  atomic.Int64
+	lastLogAt atomic.Int64 // 上次记录拒绝日志的时间（UnixNano）
+	now       func() time.Time
+}
+
+// NewConcurrencyLimiter 创建并发限制器，max 为同时处理的最大请求数
+func NewConcurrencyLimiter(max int) *ConcurrencyLimiter {
+	return &ConcurrencyLimiter{slots: make(chan struct{}, max), now: time.Now}
+}
+
+// acquire 尝试占用一个额度，已满时返回 false
+func (l *ConcurrencyLimiter) acquire() bool {
+	select {
+	case l.slots <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+// release 释放额度
+func (l *ConcurrencyLimiter) release() {
+	<-l.slots
+}
+
+// InFlight 当前正在处理的请求数
+func (l *ConcurrencyLimiter) InFlight() int {
+	return len(l.slots)
+}
+
+// UnaryServerConcurrencyLimit gRPC 一元拦截器 - 并发请求数限制
+// 应放在拦截器链最前面，使被拒绝的请求不再经过其他拦截器
+func UnaryServerConcurrencyLimit(limiter *ConcurrencyLimiter) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		if !limiter.acquire() {
+			return nil, limiter.reject(info.FullMethod)
+		}
+		defer limiter.release()
+
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerConcurrencyLimit gRPC 流拦截器 - 并发流数量限制
+// 流在整个生命周期内占用额度
+func StreamServerConcurrencyLimit(limiter *ConcurrencyLimiter) grpc.StreamServerInterceptor {
+	return func(
+		srv interface{},
+		ss grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) error {
+		if !limiter.acquire() {
+			return limiter.reject(info.FullMethod)
+		}
+		defer limiter.release()
+
+		return handler(srv, ss)
+	}
+}
+
+// reject 返回超过并发上限的错误
+// 日志按 rejectLogInterval 限速，rejected 字段为距上次记录以来被拒绝的请求数，method 为触发记录的请求
+func (l *ConcurrencyLimiter) reject(method string) error {
+	max := cap(l.slots)
+	l.rejected.Add(1)
+
+	now := l.now().UnixNano()
+	last := l.lastLogAt.Load()
+	if now-last >= int64(rejectLogInterval) && l.lastLogAt.CompareAndSwap(last, now) {
+		log.Warn("grpc requests rejected: too many concurrent requests",
+			zap.String("method", method),
+			zap.Int64("rejected", l.rejected.Swap(0)),
+			zap.Int("max_in_flight", max))
+	}
+	return status.Errorf(codes.ResourceExhausted, "too many concurrent requests (max %d)", max)
+}
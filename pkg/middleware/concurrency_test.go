@@ -0,0 +1,125 @@
+package middleware
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alfredchaos/demo/pkg/log"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestUnaryServerConcurrencyLimit_RejectsBeyondLimit(t *testing.T) {
+	prev := log.Logger
+	log.Logger = zap.NewNop()
+	t.Cleanup(func() { log.Logger = prev })
+	limiter := NewConcurrencyLimiter(2)
+	interceptor := UnaryServerConcurrencyLimit(limiter)
+	info := &grpc.UnaryServerInfo{FullMethod: "/svc/Method"}
+
+	// 占满两个额度的请求在 release 关闭前一直处理中
+	release := make(chan struct{})
+	started := make(chan struct{}, 2)
+	done := make(chan error, 2)
+	blocking := func(ctx context.Context, req interface{}) (interface{}, error) {
+		started <- struct{}{}
+		<-release
+		return nil, nil
+	}
+	for i := 0; i < 2; i++ {
+		go func() {
+			_, err := interceptor(context.Background(), nil, info, blocking)
+			done <- err
+		}()
+	}
+	<-started
+	<-started
+
+	ok := func(ctx context.Context, req interface{}) (interface{}, error) { return "ok", nil }
+	_, err := interceptor(context.Background(), nil, info, ok)
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("want ResourceExhausted beyond limit, got %v", err)
+	}
+
+	close(release)
+	for i := 0; i < 2; i++ {
+		if err := <-done; err != nil {
+			t.Fatalf("in-flight request failed: %v", err)
+		}
+	}
+	if n := limiter.InFlight(); n != 0 {
+		t.Fatalf("want all slots released, got %d in flight", n)
+	}
+	if resp, err := interceptor(context.Background(), nil, info, ok); err != nil || resp != "ok" {
+		t.Fatalf("want request accepted after release, got %v, %v", resp, err)
+	}
+}
+
+func TestStreamServerConcurrencyLimit_SharesLimitWithUnary(t *testing.T) {
+	prev := log.Logger
+	log.Logger = zap.NewNop()
+	t.Cleanup(func() { log.Logger = prev })
+	limiter := NewConcurrencyLimiter(1)
+	unary := UnaryServerConcurrencyLimit(limiter)
+	stream := StreamServerConcurrencyLimit(limiter)
+
+	// 流处理期间占用唯一的额度，一元请求被拒绝
+	var unaryErr error
+	handler := func(srv interface{}, ss grpc.ServerStream) error {
+		_, unaryErr = unary(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/svc/Unary"},
+			func(ctx context.Context, req interface{}) (interface{}, error) { return nil, nil })
+		return nil
+	}
+	if err := stream(nil, nil, &grpc.StreamServerInfo{FullMethod: "/svc/Stream"}, handler); err != nil {
+		t.Fatalf("stream failed: %v", err)
+	}
+	if status.Code(unaryErr) != codes.ResourceExhausted {
+		t.Fatalf("want unary rejected while stream is open, got %v", unaryErr)
+	}
+
+	// 流结束后额度释放，新的流可以进入
+	if err := stream(nil, nil, &grpc.StreamServerInfo{FullMethod: "/svc/Stream"},
+		func(srv interface{}, ss grpc.ServerStream) error { return nil }); err != nil {
+		t.Fatalf("want stream accepted after release, got %v", err)
+	}
+}
+
+func TestConcurrencyLimiter_RateLimitsRejectLogs(t *testing.T) {
+	core, logs := observer.New(zapcore.WarnLevel)
+	prev := log.Logger
+	log.Logger = zap.New(core)
+	t.Cleanup(func() { log.Logger = prev })
+
+	now := time.Date(2025, 11, 10, 9, 0, 0, 0, time.UTC)
+	limiter := NewConcurrencyLimiter(0)
+	limiter.now = func() time.Time { return now }
+	interceptor := UnaryServerConcurrencyLimit(limiter)
+	info := &grpc.UnaryServerInfo{FullMethod: "/svc/Method"}
+	ok := func(ctx context.Context, req interface{}) (interface{}, error) { return nil, nil }
+
+	// 同一间隔内的拒绝只记录第一条
+	for i := 0; i < 5; i++ {
+		if _, err := interceptor(context.Background(), nil, info, ok); status.Code(err) != codes.ResourceExhausted {
+			t.Fatalf("want ResourceExhausted, got %v", err)
+		}
+	}
+	if n := logs.Len(); n != 1 {
+		t.Fatalf("want 1 reject log within interval, got %d", n)
+	}
+
+	// 间隔过后记录一条汇总，包含期间被拒绝的请求数
+	now = now.Add(rejectLogInterval)
+	interceptor(context.Background(), nil, info, ok)
+	entries := logs.All()
+	if len(entries) != 2 {
+		t.Fatalf("want 2 reject logs after interval, got %d", len(entries))
+	}
+	if got := entries[1].ContextMap()["rejected"]; got != int64(5) {
+		t.Fatalf("want 5 rejected since last log, got %v", got)
+	}
+}
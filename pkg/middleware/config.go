@@ -0,0 +1,132 @@
+package middleware
+
+import (
+	"time"
+
+	"github.com/alfredchaos/demo/pkg/log"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"google.golang.org/grpc"
+)
+
+// Config 服务端拦截器配置
+// 各服务从配置文件读取后交给 GRPCServerBuilder 组装拦截器链，部署时无需改代码即可开关拦截器；
+// 未配置的拦截器保持默认（启用），StartTime 始终启用；Timeout 与 RateLimit 默认不启用
+// 不包含认证：服务目前只在内网接收网关和其他服务的调用，还没有服务间凭证方案（JWT/mTLS），
+// 没有可开关的认证拦截器；引入凭证方案时再增加对应的拦截器和开关
+type Config struct {
+	Recovery       Toggle          `yaml:"recovery" mapstructure:"recovery"`               // Panic 恢复
+	Tracing        Toggle          `yaml:"tracing" mapstructure:"tracing"`                 // 追踪 ID 提取
+	DeadlineBudget Toggle          `yaml:"deadline_budget" mapstructure:"deadline_budget"` // 时间预算
+	Timeout        TimeoutConfig   `yaml:"timeout" mapstructure:"timeout"`                 // 服务端超时（默认不启用）
+	SizeMetrics    Toggle          `yaml:"size_metrics" mapstructure:"size_metrics"`       // 消息大小指标
+	Logging        LoggingConfig   `yaml:"logging" mapstructure:"logging"`                 // 请求日志
+	Validation     Toggle          `yaml:"validation" mapstructure:"validation"`           // 请求校验（请求实现 Validator 时生效）
+	RateLimit      RateLimitConfig `yaml:"rate_limit" mapstructure:"rate_limit"`           // 请求速率限制（默认不启用）
+}
+
+// Toggle 拦截器开关，Enabled 未配置时视为启用
+type Toggle struct {
+	Enabled *bool `yaml:"enabled" mapstructure:"enabled"` // 是否启用，默认 true
+}
+
+// On 判断拦截器是否启用
+func (t Toggle) On() bool {
+	return t.Enabled == nil || *t.Enabled
+}
+
+// TimeoutConfig 服务端超时配置
+type TimeoutConfig struct {
+	Duration time.Duration `yaml:"duration" mapstructure:"duration"` // 单个请求的最长处理时间，0 表示不启用
+}
+
+// LoggingConfig 日志拦截器配置
+type LoggingConfig struct {
+	Toggle        `yaml:",inline" mapstructure:",squash"`
+	SlowThreshold time.Duration     `yaml:"slow_threshold" mapstructure:"slow_threshold"` // 慢请求阈值，0 时使用默认值 1s
+	MethodLevels  map[string]string `yaml:"method_levels" mapstructure:"method_levels"`   // 按方法覆盖成功请求的日志级别（服务名/方法名或方法名 -> debug/info/warn/error），键中不能含 "."（viper 的键分隔符）
+}
+
+// options 转换为日志拦截器选项，无法解析的级别记录告警后忽略，该方法沿用默认级别
+func (c LoggingConfig) options() []LoggingOption {
+	var opts []LoggingOption
+	if c.SlowThreshold > 0 {
+		opts = append(opts, WithSlowThreshold(c.SlowThreshold))
+	}
+	if len(c.MethodLevels) > 0 {
+		levels := make(map[string]zapcore.Level, len(c.MethodLevels))
+		for method, text := range c.MethodLevels {
+			var level zapcore.Level
+			if err := level.UnmarshalText([]byte(text)); err != nil {
+				log.Warn("ignoring invalid method log level",
+					zap.String("method", method), zap.String("level", text), zap.Error(err))
+				continue
+			}
+			levels[method] = level
+		}
+		opts = append(opts, WithMethodLevels(levels))
+	}
+	return opts
+}
+
+// RateLimitConfig 请求速率限制配置
+type RateLimitConfig struct {
+	Rate  float64 `yaml:"rate" mapstructure:"rate"`   // 每秒允许的请求数（一元请求与建立流共享），0 表示不启用
+	Burst int     `yaml:"burst" mapstructure:"burst"` // 允许的突发请求数，0 时取 rate 向上取整
+}
+
+// chainEntry 拦截器链中的一项
+type chainEntry struct {
+	name   string
+	unary  grpc.UnaryServerInterceptor
+	stream grpc.StreamServerInterceptor
+}
+
+// chain 按固定顺序组装已启用的拦截器
+// 顺序：StartTime -> Recovery -> Tracing -> DeadlineBudget -> Timeout -> SizeMetrics -> Logging -> RateLimit -> Validation
+// 限流和校验位于日志之后，被拒绝的请求同样会记录日志
+func (c Config) chain() []chainEntry {
+	entries := []chainEntry{
+		{"start_time", UnaryServerStartTime(), StreamServerStartTime()},
+	}
+	if c.Recovery.On() {
+		entries = append(entries, chainEntry{"recovery", UnaryServerRecovery(), StreamServerRecovery()})
+	}
+	if c.Tracing.On() {
+		entries = append(entries, chainEntry{"tracing", UnaryServerTracing(), StreamServerTracing()})
+	}
+	if c.DeadlineBudget.On() {
+		entries = append(entries, chainEntry{"deadline_budget", UnaryServerDeadlineBudget(), StreamServerDeadlineBudget()})
+	}
+	if c.Timeout.Duration > 0 {
+		entries = append(entries, chainEntry{"timeout", UnaryServerTimeout(c.Timeout.Duration), StreamServerTimeout(c.Timeout.Duration)})
+	}
+	if c.SizeMetrics.On() {
+		entries = append(entries, chainEntry{"size_metrics", UnaryServerSizeMetrics(), StreamServerSizeMetrics()})
+	}
+	if c.Logging.On() {
+		opts := c.Logging.options()
+		entries = append(entries, chainEntry{"logging", UnaryServerLogging(opts...), StreamServerLogging(opts...)})
+	}
+	if c.RateLimit.Rate > 0 {
+		limiter := NewRateLimiter(c.RateLimit.Rate, c.RateLimit.Burst)
+		entries = append(entries, chainEntry{"rate_limit", UnaryServerRateLimit(limiter), StreamServerRateLimit(limiter)})
+	}
+	if c.Validation.On() {
+		entries = append(entries, chainEntry{"validation", UnaryServerValidation(), StreamServerValidation()})
+	}
+	return entries
+}
+
+// Interceptors 返回已启用的一元拦截器和流拦截器（按执行顺序）
+// 两条链由同一次组装得到，一元请求与流式请求共享限流额度
+func (c Config) Interceptors() ([]grpc.UnaryServerInterceptor, []grpc.StreamServerInterceptor) {
+	entries := c.chain()
+	unary := make([]grpc.UnaryServerInterceptor, 0, len(entries))
+	stream := make([]grpc.StreamServerInterceptor, 0, len(entries))
+	for _, e := range entries {
+		unary = append(unary, e.unary)
+		stream = append(stream, e.stream)
+	}
+	return unary, stream
+}
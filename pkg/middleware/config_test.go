@@ -0,0 +1,199 @@
+package middleware
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alfredchaos/demo/pkg/log"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"google.golang.org/grpc"
+)
+
+// chainNames 返回拦截器链中各项的名称
+func chainNames(c Config) []string {
+	var names []string
+	for _, e := range c.chain() {
+		names = append(names, e.name)
+	}
+	return names
+}
+
+func TestConfig_ChainIncludesOnlyEnabledInOrder(t *testing.T) {
+	off := false
+
+	cases := []struct {
+		name string
+		cfg  Config
+		want []string
+	}{
+		{
+			name: "defaults",
+			cfg:  Config{},
+			want: []string{"start_time", "recovery", "tracing", "deadline_budget", "size_metrics", "logging", "validation"},
+		},
+		{
+			name: "timeout enabled",
+			cfg:  Config{Timeout: TimeoutConfig{Duration: 5 * time.Second}},
+			want: []string{"start_time", "recovery", "tracing", "deadline_budget", "timeout", "size_metrics", "logging", "validation"},
+		},
+		{
+			name: "rate limit enabled",
+			cfg:  Config{RateLimit: RateLimitConfig{Rate: 100}},
+			want: []string{"start_time", "recovery", "tracing", "deadline_budget", "size_metrics", "logging", "rate_limit", "validation"},
+		},
+		{
+			name: "tracing, logging and validation disabled",
+			cfg: Config{
+				Tracing:    Toggle{Enabled: &off},
+				Logging:    LoggingConfig{Toggle: Toggle{Enabled: &off}},
+				Validation: Toggle{Enabled: &off},
+			},
+			want: []string{"start_time", "recovery", "deadline_budget", "size_metrics"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := chainNames(c.cfg); !reflect.DeepEqual(got, c.want) {
+				t.Fatalf("want chain %v, got %v", c.want, got)
+			}
+			unary, stream := c.cfg.Interceptors()
+			if got := len(unary); got != len(c.want) {
+				t.Fatalf("want %d unary interceptors, got %d", len(c.want), got)
+			}
+			if got := len(stream); got != len(c.want) {
+				t.Fatalf("want %d stream interceptors, got %d", len(c.want), got)
+			}
+		})
+	}
+}
+
+func TestConfig_DecodesFromYAML(t *testing.T) {
+	v := viper.New()
+	v.SetConfigType("yaml")
+	err := v.ReadConfig(strings.NewReader(`
+middleware:
+  size_metrics:
+    enabled: false
+  timeout:
+    duration: 3s
+  logging:
+    enabled: true
+    slow_threshold: 500ms
+  rate_limit:
+    rate: 50
+    burst: 100
+`))
+	if err != nil {
+		t.Fatalf("read config: %v", err)
+	}
+
+	var cfg struct {
+		Middleware Config `mapstructure:"middleware"`
+	}
+	if err := v.Unmarshal(&cfg); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+
+	want := []string{"start_time", "recovery", "tracing", "deadline_budget", "timeout", "logging", "rate_limit", "validation"}
+	if got := chainNames(cfg.Middleware); !reflect.DeepEqual(got, want) {
+		t.Fatalf("want chain %v, got %v", want, got)
+	}
+	if cfg.Middleware.Logging.SlowThreshold != 500*time.Millisecond {
+		t.Fatalf("want slow threshold 500ms, got %v", cfg.Middleware.Logging.SlowThreshold)
+	}
+	if cfg.Middleware.RateLimit != (RateLimitConfig{Rate: 50, Burst: 100}) {
+		t.Fatalf("unexpected rate limit config: %+v", cfg.Middleware.RateLimit)
+	}
+}
+
+func TestConfig_StreamLoggingHonoursSlowThreshold(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	prev := log.Logger
+	log.Logger = zap.New(core)
+	t.Cleanup(func() { log.Logger = prev })
+
+	cfg := Config{Logging: LoggingConfig{SlowThreshold: 10 * time.Millisecond}}
+	var stream grpc.StreamServerInterceptor
+	for _, e := range cfg.chain() {
+		if e.name == "logging" {
+			stream = e.stream
+		}
+	}
+
+	info := &grpc.StreamServerInfo{FullMethod: "/user.v1.UserService/Watch", IsServerStream: true}
+	_ = stream(nil, &fakeServerStream{}, info, func(srv interface{}, ss grpc.ServerStream) error {
+		time.Sleep(20 * time.Millisecond)
+		return nil
+	})
+	_ = stream(nil, &fakeServerStream{}, info, func(srv interface{}, ss grpc.ServerStream) error { return nil })
+
+	entries := logs.All()
+	if len(entries) != 2 {
+		t.Fatalf("want 2 log entries, got %d", len(entries))
+	}
+	if entries[0].Level != zapcore.WarnLevel || entries[0].ContextMap()["slow_threshold"] != 10*time.Millisecond {
+		t.Fatalf("want slow stream logged at warn with configured threshold, got %v %v", entries[0].Level, entries[0].ContextMap())
+	}
+	if entries[1].Level != zapcore.InfoLevel {
+		t.Fatalf("want fast stream logged at info, got %v", entries[1].Level)
+	}
+}
+
+func TestConfig_MethodLevelsFromYAML(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	prev := log.Logger
+	log.Logger = zap.New(core)
+	t.Cleanup(func() { log.Logger = prev })
+
+	v := viper.New()
+	v.SetConfigType("yaml")
+	err := v.ReadConfig(strings.NewReader(`
+middleware:
+  logging:
+    method_levels:
+      Health/Check: debug
+      ListUsers: debug
+      SayHello: loud
+`))
+	if err != nil {
+		t.Fatalf("read config: %v", err)
+	}
+	var cfg struct {
+		Middleware Config `mapstructure:"middleware"`
+	}
+	if err := v.Unmarshal(&cfg); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+
+	var entry chainEntry
+	for _, e := range cfg.Middleware.chain() {
+		if e.name == "logging" {
+			entry = e
+		}
+	}
+
+	// 无法解析的级别记录告警，该方法沿用 info
+	warnings := logs.TakeAll()
+	if len(warnings) != 1 || warnings[0].Level != zapcore.WarnLevel || warnings[0].ContextMap()["level"] != "loud" {
+		t.Fatalf("want 1 warning for the invalid level, got %v", warnings)
+	}
+
+	ok := func(ctx context.Context, req interface{}) (interface{}, error) { return "ok", nil }
+	for _, method := range []string{"/grpc.health.v1.Health/Check", "/user.v1.UserService/SayHello"} {
+		_, _ = entry.unary(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: method}, ok)
+	}
+	_ = entry.stream(nil, &fakeServerStream{}, &grpc.StreamServerInfo{FullMethod: "/user.v1.UserService/ListUsers", IsServerStream: true},
+		func(srv interface{}, ss grpc.ServerStream) error { return nil })
+
+	entries := logs.All()
+	if len(entries) != 1 || entries[0].ContextMap()["method"] != "/user.v1.UserService/SayHello" {
+		t.Fatalf("want only SayHello logged at info, got %v", entries)
+	}
+}
@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"context"
+
+	"github.com/alfredchaos/demo/pkg/reqctx"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// UnaryServerDeadlineBudget gRPC 一元拦截器 - 时间预算
+// 从metadata中读取上游剩余的时间预算，收紧当前请求的截止时间
+func UnaryServerDeadlineBudget() grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		ctx, cancel := withIncomingBudget(ctx)
+		defer cancel()
+
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerDeadlineBudget gRPC 流拦截器 - 时间预算
+func StreamServerDeadlineBudget() grpc.StreamServerInterceptor {
+	return func(
+		srv interface{},
+		ss grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) error {
+		ctx, cancel := withIncomingBudget(ss.Context())
+		defer cancel()
+
+		return handler(srv, &contextServerStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// withIncomingBudget 根据metadata中的时间预算设置截止时间
+// context.WithTimeout 只会收紧截止时间，不会延长 gRPC 自身传递的截止时间
+func withIncomingBudget(ctx context.Context) (context.Context, context.CancelFunc) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ctx, func() {}
+	}
+
+	values := md.Get(reqctx.DeadlineBudgetKey)
+	if len(values) == 0 {
+		return ctx, func() {}
+	}
+
+	budget, ok := reqctx.ParseBudget(values[0])
+	if !ok {
+		return ctx, func() {}
+	}
+
+	return context.WithTimeout(ctx, budget)
+}
+
+// contextServerStream 替换了上下文的服务端流
+type contextServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context 返回替换后的上下文
+func (s *contextServerStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alfredchaos/demo/pkg/reqctx"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestUnaryServerDeadlineBudget_TightensDeadline(t *testing.T) {
+	upstream, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	upstreamDeadline, _ := upstream.Deadline()
+
+	ctx := metadata.NewIncomingContext(upstream, metadata.Pairs(reqctx.DeadlineBudgetKey, "200"))
+
+	var got time.Time
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		got, _ = ctx.Deadline()
+		return nil, nil
+	}
+
+	if _, err := UnaryServerDeadlineBudget()(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/svc/Method"}, handler); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !got.Before(upstreamDeadline) {
+		t.Fatalf("want deadline %v before upstream %v", got, upstreamDeadline)
+	}
+	if remaining := time.Until(got); remaining > 200*time.Millisecond {
+		t.Fatalf("want at most 200ms budget, got %v", remaining)
+	}
+}
+
+func TestUnaryServerDeadlineBudget_NeverExtendsDeadline(t *testing.T) {
+	upstream, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	upstreamDeadline, _ := upstream.Deadline()
+
+	ctx := metadata.NewIncomingContext(upstream, metadata.Pairs(reqctx.DeadlineBudgetKey, "5000"))
+
+	var got time.Time
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		got, _ = ctx.Deadline()
+		return nil, nil
+	}
+
+	if _, err := UnaryServerDeadlineBudget()(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/svc/Method"}, handler); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !got.Equal(upstreamDeadline) {
+		t.Fatalf("want upstream deadline %v kept, got %v", upstreamDeadline, got)
+	}
+}
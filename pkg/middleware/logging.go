@@ -2,16 +2,133 @@ package middleware
 
 import (
 	"context"
+	"strings"
 	"time"
 
 	"github.com/alfredchaos/demo/pkg/log"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
 )
 
+// defaultSlowThreshold 默认的慢请求阈值
+const defaultSlowThreshold = time.Second
+
+// loggingOptions 日志拦截器配置
+type loggingOptions struct {
+	// methodLevels 按方法覆盖成功请求的日志级别
+	// 键可以是完整方法名（/user.v1.UserService/SayHello）、服务名/方法名（UserService/SayHello）或方法名（SayHello）
+	// 统一转为小写，匹配时不区分大小写（viper 读取配置时会把 map 的键转为小写）
+	methodLevels map[string]zapcore.Level
+	// slowThreshold 慢请求阈值，超过时以 warn 级别记录
+	slowThreshold time.Duration
+}
+
+// LoggingOption 日志拦截器配置选项
+type LoggingOption func(*loggingOptions)
+
+// WithMethodLevel 为指定方法设置成功请求的日志级别
+// 例如将高频的健康检查降为 debug 级别以减少日志噪音
+func WithMethodLevel(method string, level zapcore.Level) LoggingOption {
+	return func(o *loggingOptions) {
+		o.methodLevels[strings.ToLower(method)] = level
+	}
+}
+
+// WithMethodLevels 批量设置方法日志级别
+func WithMethodLevels(levels map[string]zapcore.Level) LoggingOption {
+	return func(o *loggingOptions) {
+		for method, level := range levels {
+			o.methodLevels[strings.ToLower(method)] = level
+		}
+	}
+}
+
+// WithSlowThreshold 设置慢请求阈值，耗时超过阈值的成功请求（流）以 warn 级别记录并带上 is_slow 字段
+// 小于等于 0 时保持默认值（1s）
+func WithSlowThreshold(threshold time.Duration) LoggingOption {
+	return func(o *loggingOptions) {
+		if threshold > 0 {
+			o.slowThreshold = threshold
+		}
+	}
+}
+
+// newLoggingOptions 创建日志拦截器配置
+func newLoggingOptions(opts ...LoggingOption) *loggingOptions {
+	o := &loggingOptions{
+		methodLevels:  make(map[string]zapcore.Level),
+		slowThreshold: defaultSlowThreshold,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// levelFor 获取方法成功请求的日志级别，未配置时默认为 info
+// 依次匹配完整方法名、服务名/方法名（Health/Check）和方法名
+func (o *loggingOptions) levelFor(fullMethod string) zapcore.Level {
+	fullMethod = strings.ToLower(fullMethod)
+	if level, ok := o.methodLevels[fullMethod]; ok {
+		return level
+	}
+	if idx := strings.LastIndex(fullMethod, "."); idx >= 0 {
+		if level, ok := o.methodLevels[fullMethod[idx+1:]]; ok {
+			return level
+		}
+	}
+	if idx := strings.LastIndex(fullMethod, "/"); idx >= 0 {
+		if level, ok := o.methodLevels[fullMethod[idx+1:]]; ok {
+			return level
+		}
+	}
+	return zapcore.InfoLevel
+}
+
+// slowFields 慢请求的附加日志字段
+func (o *loggingOptions) slowFields() []zap.Field {
+	return []zap.Field{
+		zap.Bool("is_slow", true),
+		zap.Duration("slow_threshold", o.slowThreshold),
+	}
+}
+
+// logAtLevel 按指定级别记录日志
+// 跳过本函数所在的栈帧，caller 指向调用 logAtLevel 的拦截器
+func logAtLevel(logger *zap.Logger, level zapcore.Level, msg string, fields ...zap.Field) {
+	if ce := logger.WithOptions(zap.AddCallerSkip(1)).Check(level, msg); ce != nil {
+		ce.Write(fields...)
+	}
+}
+
+// callFields 构造调用方相关的日志字段：方法、gRPC 状态码、对端地址和认证类型
+// trace_id、user_id 等上下文字段由 log.WithContext 提供
+func callFields(ctx context.Context, method string, err error) []zap.Field {
+	fields := []zap.Field{
+		zap.String("method", method),
+		zap.String("code", status.Code(err).String()),
+	}
+
+	if p, ok := peer.FromContext(ctx); ok {
+		if p.Addr != nil {
+			fields = append(fields, zap.String("peer", p.Addr.String()))
+		}
+		if p.AuthInfo != nil {
+			fields = append(fields, zap.String("auth_type", p.AuthInfo.AuthType()))
+		}
+	}
+
+	return fields
+}
+
 // UnaryServerLogging gRPC 一元拦截器 - 日志记录
-// 记录每个gRPC请求的详细信息
-func UnaryServerLogging() grpc.UnaryServerInterceptor {
+// 记录每个gRPC请求的详细信息，失败请求始终以 error 级别记录
+func UnaryServerLogging(opts ...LoggingOption) grpc.UnaryServerInterceptor {
+	o := newLoggingOptions(opts...)
+
 	return func(
 		ctx context.Context,
 		req interface{},
@@ -27,21 +144,19 @@ func UnaryServerLogging() grpc.UnaryServerInterceptor {
 		// 计算耗时
 		latency := time.Since(startTime)
 
-		// 提取 trace ID
-		traceID := GetTraceID(ctx)
-
 		// 记录日志
-		fields := []zap.Field{
-			zap.String("method", info.FullMethod),
-			zap.String("X-Trace-ID", traceID),
-			zap.Duration("latency", latency),
-		}
+		logger := log.WithContext(ctx)
+		fields := append(callFields(ctx, info.FullMethod, err), zap.Duration("latency", latency))
 
-		if err != nil {
+		switch {
+		case err != nil:
 			fields = append(fields, zap.Error(err))
-			log.Error("gRPC request error", fields...)
-		} else {
-			log.Info("gRPC request", fields...)
+			logger.Error("gRPC request error", fields...)
+		case latency > o.slowThreshold:
+			fields = append(fields, o.slowFields()...)
+			logger.Warn("gRPC slow request", fields...)
+		default:
+			logAtLevel(logger, o.levelFor(info.FullMethod), "gRPC request", fields...)
 		}
 
 		return resp, err
@@ -49,8 +164,10 @@ func UnaryServerLogging() grpc.UnaryServerInterceptor {
 }
 
 // StreamServerLogging gRPC 流拦截器 - 日志记录
-// 记录流式gRPC请求的信息
-func StreamServerLogging() grpc.StreamServerInterceptor {
+// 记录流式gRPC请求的信息，失败请求始终以 error 级别记录
+func StreamServerLogging(opts ...LoggingOption) grpc.StreamServerInterceptor {
+	o := newLoggingOptions(opts...)
+
 	return func(
 		srv interface{},
 		ss grpc.ServerStream,
@@ -66,24 +183,24 @@ func StreamServerLogging() grpc.StreamServerInterceptor {
 		// 计算耗时
 		latency := time.Since(startTime)
 
-		// 提取 trace ID
-		ctx := ss.Context()
-		traceID := GetTraceID(ctx)
-
 		// 记录日志
-		fields := []zap.Field{
-			zap.String("method", info.FullMethod),
-			zap.String("trace_id", traceID),
+		ctx := ss.Context()
+		logger := log.WithContext(ctx)
+		fields := append(callFields(ctx, info.FullMethod, err),
 			zap.Duration("latency", latency),
 			zap.Bool("is_client_stream", info.IsClientStream),
 			zap.Bool("is_server_stream", info.IsServerStream),
-		}
+		)
 
-		if err != nil {
+		switch {
+		case err != nil:
 			fields = append(fields, zap.Error(err))
-			log.Error("gRPC stream error", fields...)
-		} else {
-			log.Info("gRPC stream", fields...)
+			logger.Error("gRPC stream error", fields...)
+		case latency > o.slowThreshold:
+			fields = append(fields, o.slowFields()...)
+			logger.Warn("gRPC slow stream", fields...)
+		default:
+			logAtLevel(logger, o.levelFor(info.FullMethod), "gRPC stream", fields...)
 		}
 
 		return err
@@ -0,0 +1,142 @@
+package middleware
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alfredchaos/demo/pkg/log"
+	"github.com/alfredchaos/demo/pkg/reqctx"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+func TestUnaryServerLogging_MethodLevelOverride(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	prev := log.Logger
+	log.Logger = zap.New(core)
+	t.Cleanup(func() { log.Logger = prev })
+
+	interceptor := UnaryServerLogging(WithMethodLevel("Check", zapcore.DebugLevel))
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "ok", nil
+	}
+
+	methods := []string{"/grpc.health.v1.Health/Check", "/user.v1.UserService/SayHello"}
+	for _, method := range methods {
+		if _, err := interceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: method}, handler); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	entries := logs.All()
+	if len(entries) != 1 {
+		t.Fatalf("want 1 info log, got %d", len(entries))
+	}
+	if got := entries[0].ContextMap()["method"]; got != "/user.v1.UserService/SayHello" {
+		t.Fatalf("want SayHello logged at info, got %v", got)
+	}
+}
+
+func TestUnaryServerLogging_CallerIsInterceptor(t *testing.T) {
+	prev := log.Logger
+	t.Cleanup(func() { log.Logger = prev })
+	core, logs := observer.New(zapcore.InfoLevel)
+	log.Logger = zap.New(core, zap.AddCaller())
+
+	unary := UnaryServerLogging()
+	_, _ = unary(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/user.v1.UserService/SayHello"},
+		func(ctx context.Context, req interface{}) (interface{}, error) { return "ok", nil })
+
+	stream := StreamServerLogging()
+	_ = stream(nil, &fakeServerStream{}, &grpc.StreamServerInfo{FullMethod: "/user.v1.UserService/Watch"},
+		func(srv interface{}, ss grpc.ServerStream) error { return nil })
+
+	entries := logs.All()
+	if len(entries) != 2 {
+		t.Fatalf("want 2 log entries, got %d", len(entries))
+	}
+	for i, want := range []string{"UnaryServerLogging", "StreamServerLogging"} {
+		caller := entries[i].Caller
+		if !caller.Defined || !strings.Contains(caller.Function, want) || strings.Contains(caller.Function, "logAtLevel") {
+			t.Errorf("want caller inside %s, got %s (%s)", want, caller.Function, caller.TrimmedPath())
+		}
+	}
+}
+
+func TestUnaryServerLogging_IncludesPeerUserAndCode(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	prev := log.Logger
+	log.Logger = zap.New(core)
+	t.Cleanup(func() { log.Logger = prev })
+
+	ctx := peer.NewContext(context.Background(), &peer.Peer{
+		Addr: &net.TCPAddr{IP: net.ParseIP("10.0.0.7"), Port: 53211},
+	})
+	ctx = reqctx.WithUserID(ctx, "u-42")
+
+	interceptor := UnaryServerLogging()
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, status.Error(codes.PermissionDenied, "forbidden")
+	}
+
+	_, _ = interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/user.v1.UserService/DeleteUser"}, handler)
+
+	entries := logs.All()
+	if len(entries) != 1 {
+		t.Fatalf("want 1 log entry, got %d", len(entries))
+	}
+	fields := entries[0].ContextMap()
+	want := map[string]string{
+		"peer":    "10.0.0.7:53211",
+		"user_id": "u-42",
+		"code":    codes.PermissionDenied.String(),
+		"method":  "/user.v1.UserService/DeleteUser",
+	}
+	for key, value := range want {
+		if fields[key] != value {
+			t.Errorf("want %s=%s, got %v", key, value, fields[key])
+		}
+	}
+}
+
+func TestUnaryServerLogging_WarnsOnSlowRequest(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	prev := log.Logger
+	log.Logger = zap.New(core)
+	t.Cleanup(func() { log.Logger = prev })
+
+	interceptor := UnaryServerLogging(WithSlowThreshold(10 * time.Millisecond))
+	slowHandler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		time.Sleep(20 * time.Millisecond)
+		return "ok", nil
+	}
+	fastHandler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "ok", nil
+	}
+
+	info := &grpc.UnaryServerInfo{FullMethod: "/user.v1.UserService/ListUsers"}
+	_, _ = interceptor(context.Background(), nil, info, slowHandler)
+	_, _ = interceptor(context.Background(), nil, info, fastHandler)
+
+	entries := logs.All()
+	if len(entries) != 2 {
+		t.Fatalf("want 2 log entries, got %d", len(entries))
+	}
+	if entries[0].Level != zapcore.WarnLevel || entries[0].ContextMap()["is_slow"] != true {
+		t.Fatalf("want slow request logged at warn with is_slow, got %v %v", entries[0].Level, entries[0].ContextMap())
+	}
+	if entries[1].Level != zapcore.InfoLevel {
+		t.Fatalf("want fast request logged at info, got %v", entries[1].Level)
+	}
+	if _, ok := entries[1].ContextMap()["is_slow"]; ok {
+		t.Fatalf("fast request should not be marked slow")
+	}
+}
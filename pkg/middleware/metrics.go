@@ -0,0 +1,157 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+
+	"github.com/alfredchaos/demo/pkg/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+)
+
+// defaultSizeBuckets 默认的消息大小分桶：64B ~ 1MB，按 4 倍递增
+var defaultSizeBuckets = prometheus.ExponentialBuckets(64, 4, 8)
+
+// sizeMetricsOptions 消息大小指标拦截器配置
+type sizeMetricsOptions struct {
+	buckets    []float64
+	registerer prometheus.Registerer
+}
+
+// SizeMetricsOption 消息大小指标拦截器配置选项
+type SizeMetricsOption func(*sizeMetricsOptions)
+
+// WithSizeBuckets 设置直方图分桶（字节）
+func WithSizeBuckets(buckets []float64) SizeMetricsOption {
+	return func(o *sizeMetricsOptions) {
+		o.buckets = buckets
+	}
+}
+
+// WithSizeRegisterer 设置指标注册器，默认为 prometheus.DefaultRegisterer
+func WithSizeRegisterer(reg prometheus.Registerer) SizeMetricsOption {
+	return func(o *sizeMetricsOptions) {
+		o.registerer = reg
+	}
+}
+
+// sizeHistograms 请求/响应消息大小直方图
+type sizeHistograms struct {
+	request  *prometheus.HistogramVec
+	response *prometheus.HistogramVec
+}
+
+// newSizeHistograms 创建并注册消息大小直方图
+// 一元与流拦截器共享同一组指标，重复注册时复用已注册的实例
+func newSizeHistograms(opts ...SizeMetricsOption) *sizeHistograms {
+	o := &sizeMetricsOptions{
+		buckets:    defaultSizeBuckets,
+		registerer: prometheus.DefaultRegisterer,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return &sizeHistograms{
+		request: registerHistogram(o.registerer, prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "grpc_server_request_size_bytes",
+			Help:    "Size of gRPC request messages received by the server, in bytes.",
+			Buckets: o.buckets,
+		}, []string{"method"})),
+		response: registerHistogram(o.registerer, prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "grpc_server_response_size_bytes",
+			Help:    "Size of gRPC response messages sent by the server, in bytes.",
+			Buckets: o.buckets,
+		}, []string{"method"})),
+	}
+}
+
+// registerHistogram 注册直方图，已注册时返回已有实例
+// 其他注册失败只记录告警，指标仍可观测但不会被导出
+func registerHistogram(reg prometheus.Registerer, vec *prometheus.HistogramVec) *prometheus.HistogramVec {
+	if err := reg.Register(vec); err != nil {
+		var are prometheus.AlreadyRegisteredError
+		if errors.As(err, &are) {
+			if existing, ok := are.ExistingCollector.(*prometheus.HistogramVec); ok {
+				return existing
+			}
+		}
+		log.Warn("failed to register grpc size metrics", zap.Error(err))
+	}
+	return vec
+}
+
+// observe 记录消息大小，非 protobuf 消息忽略
+func observe(vec *prometheus.HistogramVec, method string, msg interface{}) {
+	if m, ok := msg.(proto.Message); ok {
+		vec.WithLabelValues(method).Observe(float64(proto.Size(m)))
+	}
+}
+
+// UnaryServerSizeMetrics gRPC 一元拦截器 - 消息大小指标
+// 按方法记录请求和响应消息大小（proto.Size）的直方图，用于发现返回超大列表的接口
+func UnaryServerSizeMetrics(opts ...SizeMetricsOption) grpc.UnaryServerInterceptor {
+	metrics := newSizeHistograms(opts...)
+
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		observe(metrics.request, info.FullMethod, req)
+
+		resp, err := handler(ctx, req)
+		if err == nil {
+			observe(metrics.response, info.FullMethod, resp)
+		}
+
+		return resp, err
+	}
+}
+
+// StreamServerSizeMetrics gRPC 流拦截器 - 消息大小指标
+// 流中的每条消息分别记录
+func StreamServerSizeMetrics(opts ...SizeMetricsOption) grpc.StreamServerInterceptor {
+	metrics := newSizeHistograms(opts...)
+
+	return func(
+		srv interface{},
+		ss grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) error {
+		return handler(srv, &sizeServerStream{
+			ServerStream: ss,
+			metrics:      metrics,
+			method:       info.FullMethod,
+		})
+	}
+}
+
+// sizeServerStream 记录收发消息大小的服务端流
+type sizeServerStream struct {
+	grpc.ServerStream
+	metrics *sizeHistograms
+	method  string
+}
+
+// SendMsg 发送消息并记录大小
+func (s *sizeServerStream) SendMsg(m interface{}) error {
+	if err := s.ServerStream.SendMsg(m); err != nil {
+		return err
+	}
+	observe(s.metrics.response, s.method, m)
+	return nil
+}
+
+// RecvMsg 接收消息并记录大小
+func (s *sizeServerStream) RecvMsg(m interface{}) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	observe(s.metrics.request, s.method, m)
+	return nil
+}
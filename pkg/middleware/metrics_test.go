@@ -0,0 +1,116 @@
+package middleware
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// histogramFor 查找指定方法标签的直方图样本
+func histogramFor(t *testing.T, reg *prometheus.Registry, name, method string) *dto.Histogram {
+	t.Helper()
+
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatalf("gather: %v", err)
+	}
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == "method" && label.GetValue() == method {
+					return metric.GetHistogram()
+				}
+			}
+		}
+	}
+	t.Fatalf("no %s sample for method %s", name, method)
+	return nil
+}
+
+func TestUnaryServerSizeMetrics_ObservesMessageSizes(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	interceptor := UnaryServerSizeMetrics(WithSizeRegisterer(reg))
+
+	// StringValue 编码为 1 字节 tag + 1 字节长度 + 内容
+	req := wrapperspb.String("hello")                   // 7 字节
+	resp := wrapperspb.String(strings.Repeat("x", 100)) // 102 字节
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return resp, nil
+	}
+
+	info := &grpc.UnaryServerInfo{FullMethod: "/user.v1.UserService/ListUsers"}
+	for i := 0; i < 2; i++ {
+		if _, err := interceptor(context.Background(), req, info, handler); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	reqHist := histogramFor(t, reg, "grpc_server_request_size_bytes", info.FullMethod)
+	if reqHist.GetSampleCount() != 2 || reqHist.GetSampleSum() != 14 {
+		t.Fatalf("want 2 requests totalling 14 bytes, got %d / %v", reqHist.GetSampleCount(), reqHist.GetSampleSum())
+	}
+
+	respHist := histogramFor(t, reg, "grpc_server_response_size_bytes", info.FullMethod)
+	if respHist.GetSampleCount() != 2 || respHist.GetSampleSum() != 204 {
+		t.Fatalf("want 2 responses totalling 204 bytes, got %d / %v", respHist.GetSampleCount(), respHist.GetSampleSum())
+	}
+}
+
+// fakeServerStream 内存服务端流，收发固定消息
+type fakeServerStream struct {
+	grpc.ServerStream
+	recv *wrapperspb.StringValue
+}
+
+func (s *fakeServerStream) Context() context.Context { return context.Background() }
+
+func (s *fakeServerStream) SendMsg(m interface{}) error { return nil }
+
+func (s *fakeServerStream) RecvMsg(m interface{}) error {
+	m.(*wrapperspb.StringValue).Value = s.recv.Value
+	return nil
+}
+
+func TestStreamServerSizeMetrics_ObservesEachMessage(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	// 一元与流拦截器共用同一组指标
+	_ = UnaryServerSizeMetrics(WithSizeRegisterer(reg))
+	interceptor := StreamServerSizeMetrics(WithSizeRegisterer(reg))
+
+	handler := func(srv interface{}, ss grpc.ServerStream) error {
+		var in wrapperspb.StringValue
+		if err := ss.RecvMsg(&in); err != nil {
+			return err
+		}
+		for i := 0; i < 3; i++ {
+			if err := ss.SendMsg(wrapperspb.String("abc")); err != nil { // 5 字节
+				return err
+			}
+		}
+		return nil
+	}
+
+	stream := &fakeServerStream{recv: wrapperspb.String("hello")}
+	info := &grpc.StreamServerInfo{FullMethod: "/book.v1.BookService/Watch"}
+	if err := interceptor(nil, stream, info, handler); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	reqHist := histogramFor(t, reg, "grpc_server_request_size_bytes", info.FullMethod)
+	if reqHist.GetSampleCount() != 1 || reqHist.GetSampleSum() != 7 {
+		t.Fatalf("want 1 request of 7 bytes, got %d / %v", reqHist.GetSampleCount(), reqHist.GetSampleSum())
+	}
+
+	respHist := histogramFor(t, reg, "grpc_server_response_size_bytes", info.FullMethod)
+	if respHist.GetSampleCount() != 3 || respHist.GetSampleSum() != 15 {
+		t.Fatalf("want 3 responses totalling 15 bytes, got %d / %v", respHist.GetSampleCount(), respHist.GetSampleSum())
+	}
+}
@@ -0,0 +1,85 @@
+package middleware
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// RateLimiter 服务端请求速率限制（令牌桶）
+// 一元请求与流式请求共享同一个令牌桶，令牌不足时立即返回 ResourceExhausted 而不是排队等待
+type RateLimiter struct {
+	mu     sync.Mutex
+	rate   float64 // 每秒补充的令牌数
+	burst  float64 // 令牌桶容量
+	tokens float64
+	last   time.Time
+	now    func() time.Time
+}
+
+// NewRateLimiter 创建速率限制器，rate 为每秒允许的请求数，burst 为允许的突发请求数，<= 0 时取 rate 向上取整（至少为 1）
+func NewRateLimiter(rate float64, burst int) *RateLimiter {
+	b := float64(burst)
+	if burst <= 0 {
+		b = math.Max(1, math.Ceil(rate))
+	}
+	return &RateLimiter{rate: rate, burst: b, tokens: b, now: time.Now}
+}
+
+// Allow 尝试取走一个令牌，令牌不足时返回 false
+func (l *RateLimiter) Allow() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	if !l.last.IsZero() {
+		l.tokens = math.Min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	}
+	l.last = now
+
+	if l.tokens < 1 {
+		return false
+	}
+	l.tokens--
+	return true
+}
+
+// UnaryServerRateLimit gRPC 一元拦截器 - 请求速率限制
+func UnaryServerRateLimit(limiter *RateLimiter) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		if !limiter.Allow() {
+			return nil, errRateLimited
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerRateLimit gRPC 流拦截器 - 请求速率限制
+// 按建立流的次数计数，流内消息不受限制
+func StreamServerRateLimit(limiter *RateLimiter) grpc.StreamServerInterceptor {
+	return func(
+		srv interface{},
+		ss grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) error {
+		if !limiter.Allow() {
+			return errRateLimited
+		}
+		return handler(srv, ss)
+	}
+}
+
+// errRateLimited 超过速率限制时返回的错误
+// 拦截器位于日志拦截器之后，被拒绝的请求由日志拦截器记录，这里不单独记录
+var errRateLimited = status.Error(codes.ResourceExhausted, "rate limit exceeded")
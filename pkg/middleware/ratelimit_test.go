@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestUnaryServerRateLimit_RejectsBeyondBurstAndRefills(t *testing.T) {
+	now := time.Date(2025, 11, 10, 9, 0, 0, 0, time.UTC)
+	limiter := NewRateLimiter(2, 2)
+	limiter.now = func() time.Time { return now }
+	interceptor := UnaryServerRateLimit(limiter)
+	info := &grpc.UnaryServerInfo{FullMethod: "/svc/Method"}
+	ok := func(ctx context.Context, req interface{}) (interface{}, error) { return "ok", nil }
+
+	for i := 0; i < 2; i++ {
+		if _, err := interceptor(context.Background(), nil, info, ok); err != nil {
+			t.Fatalf("request %d within burst rejected: %v", i, err)
+		}
+	}
+	if _, err := interceptor(context.Background(), nil, info, ok); status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("want ResourceExhausted beyond burst, got %v", err)
+	}
+
+	// 每秒补充 2 个令牌，半秒后可以再接受一个请求
+	now = now.Add(500 * time.Millisecond)
+	if resp, err := interceptor(context.Background(), nil, info, ok); err != nil || resp != "ok" {
+		t.Fatalf("want request accepted after refill, got %v, %v", resp, err)
+	}
+	if _, err := interceptor(context.Background(), nil, info, ok); status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("want ResourceExhausted once refilled token is used, got %v", err)
+	}
+}
+
+func TestConfig_RateLimitSharedBetweenUnaryAndStream(t *testing.T) {
+	unary, stream := Config{RateLimit: RateLimitConfig{Rate: 1}}.Interceptors()
+	var unaryLimit grpc.UnaryServerInterceptor
+	var streamLimit grpc.StreamServerInterceptor
+	for i, name := range chainNames(Config{RateLimit: RateLimitConfig{Rate: 1}}) {
+		if name == "rate_limit" {
+			unaryLimit, streamLimit = unary[i], stream[i]
+		}
+	}
+	if unaryLimit == nil || streamLimit == nil {
+		t.Fatal("rate limit interceptors not in chain")
+	}
+
+	ok := func(ctx context.Context, req interface{}) (interface{}, error) { return nil, nil }
+	if _, err := unaryLimit(context.Background(), nil, &grpc.UnaryServerInfo{}, ok); err != nil {
+		t.Fatalf("first request rejected: %v", err)
+	}
+	err := streamLimit(nil, nil, &grpc.StreamServerInfo{}, func(srv interface{}, ss grpc.ServerStream) error { return nil })
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("want stream to share the unary limit, got %v", err)
+	}
+}
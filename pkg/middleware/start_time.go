@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"context"
+	"time"
+
+	"github.com/alfredchaos/demo/pkg/reqctx"
+	"google.golang.org/grpc"
+)
+
+// UnaryServerStartTime gRPC 一元拦截器 - 请求开始时间
+// 将请求开始时间存入上下文，下游可通过 reqctx.Elapsed 获取总耗时
+func UnaryServerStartTime() grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		return handler(reqctx.WithStartTime(ctx, time.Now()), req)
+	}
+}
+
+// StreamServerStartTime gRPC 流拦截器 - 请求开始时间
+func StreamServerStartTime() grpc.StreamServerInterceptor {
+	return func(
+		srv interface{},
+		ss grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) error {
+		ctx := reqctx.WithStartTime(ss.Context(), time.Now())
+		return handler(srv, &contextServerStream{ServerStream: ss, ctx: ctx})
+	}
+}
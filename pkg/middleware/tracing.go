@@ -3,6 +3,7 @@ package middleware
 import (
 	"context"
 
+	"github.com/alfredchaos/demo/pkg/log"
 	"github.com/alfredchaos/demo/pkg/reqctx"
 	"github.com/google/uuid"
 	"google.golang.org/grpc"
@@ -11,7 +12,7 @@ import (
 
 const (
 	// TraceIDKey 追踪ID的元数据键名
-	TraceIDKey = "X-Trace-ID"
+	TraceIDKey = reqctx.TraceIDMetadataKey
 )
 
 // UnaryServerTracing gRPC 一元拦截器 - 追踪
@@ -38,8 +39,9 @@ func UnaryServerTracing() grpc.UnaryServerInterceptor {
 			traceID = uuid.New().String()
 		}
 
-		// 将trace-id存储到上下文中
+		// 将trace-id存储到上下文中，并绑定请求级 logger 供下游复用
 		ctx = reqctx.WithTraceID(ctx, traceID)
+		ctx = log.IntoContext(ctx, log.WithContext(ctx))
 
 		// 调用实际的处理函数
 		return handler(ctx, req)
@@ -71,11 +73,12 @@ func StreamServerTracing() grpc.StreamServerInterceptor {
 			traceID = uuid.New().String()
 		}
 
-		// 将trace-id存储到上下文中
+		// 将trace-id存储到上下文中，并绑定请求级 logger 供下游复用
 		ctx = reqctx.WithTraceID(ctx, traceID)
+		ctx = log.IntoContext(ctx, log.WithContext(ctx))
 
 		// 调用实际的处理函数
-		return handler(srv, ss)
+		return handler(srv, &contextServerStream{ServerStream: ss, ctx: ctx})
 	}
 }
 
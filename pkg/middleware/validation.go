@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Validator 可自校验的请求消息
+type Validator interface {
+	Validate() error
+}
+
+// UnaryServerValidation gRPC 一元拦截器 - 请求校验
+// 请求实现 Validator 且校验失败时返回 InvalidArgument，不调用处理函数
+func UnaryServerValidation() grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		if err := validate(req); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerValidation gRPC 流拦截器 - 请求校验
+// 逐条校验客户端发送的消息，校验失败时 RecvMsg 返回 InvalidArgument
+func StreamServerValidation() grpc.StreamServerInterceptor {
+	return func(
+		srv interface{},
+		ss grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) error {
+		return handler(srv, &validatingServerStream{ServerStream: ss})
+	}
+}
+
+// validate 校验实现了 Validator 的消息
+func validate(msg interface{}) error {
+	v, ok := msg.(Validator)
+	if !ok {
+		return nil
+	}
+	if err := v.Validate(); err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	return nil
+}
+
+// validatingServerStream 接收消息后执行校验的服务端流
+type validatingServerStream struct {
+	grpc.ServerStream
+}
+
+// RecvMsg 接收并校验消息
+func (s *validatingServerStream) RecvMsg(m interface{}) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	return validate(m)
+}
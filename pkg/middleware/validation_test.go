@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// validatedRequest 校验结果固定的请求
+type validatedRequest struct {
+	err error
+}
+
+func (r validatedRequest) Validate() error { return r.err }
+
+func TestUnaryServerValidation(t *testing.T) {
+	cases := []struct {
+		name       string
+		req        interface{}
+		wantCode   codes.Code
+		wantCalled bool
+	}{
+		{"valid request", validatedRequest{}, codes.OK, true},
+		{"invalid request", validatedRequest{err: errors.New("name is required")}, codes.InvalidArgument, false},
+		{"request without validator", struct{}{}, codes.OK, true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			called := false
+			handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+				called = true
+				return "ok", nil
+			}
+
+			_, err := UnaryServerValidation()(context.Background(), c.req, &grpc.UnaryServerInfo{FullMethod: "/svc/Method"}, handler)
+			if got := status.Code(err); got != c.wantCode {
+				t.Fatalf("want code %v, got %v (%v)", c.wantCode, got, err)
+			}
+			if called != c.wantCalled {
+				t.Fatalf("want handler called=%v, got %v", c.wantCalled, called)
+			}
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package mq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ErrNacked 消息被 Broker 拒绝确认（nack）
+var ErrNacked = errors.New("message was nacked by broker")
+
+// OutgoingMessage 批量发布的单条消息
+type OutgoingMessage struct {
+	RoutingKey string                 // 路由键
+	Body       []byte                 // 消息内容
+	Headers    map[string]interface{} // 消息头，可为空
+}
+
+// PublishFailure 批量发布中单条消息的失败信息
+type PublishFailure struct {
+	Index int   // 消息在批次中的下标
+	Err   error // 失败原因
+}
+
+// PublishManyError 批量发布部分失败
+// 未出现在 Failures 中的消息均已被 Broker 确认，调用方可以只将成功的消息标记为已发送
+type PublishManyError struct {
+	Failures []PublishFailure
+}
+
+// Error 实现 error 接口
+func (e *PublishManyError) Error() string {
+	parts := make([]string, 0, len(e.Failures))
+	for _, f := range e.Failures {
+		parts = append(parts, fmt.Sprintf("#%d: %v", f.Index, f.Err))
+	}
+	return fmt.Sprintf("failed to publish %d message(s): %s", len(e.Failures), strings.Join(parts, "; "))
+}
+
+// FailedIndexes 获取失败消息的下标（升序）
+func (e *PublishManyError) FailedIndexes() []int {
+	indexes := make([]int, 0, len(e.Failures))
+	for _, f := range e.Failures {
+		indexes = append(indexes, f.Index)
+	}
+	return indexes
+}
+
+// confirmation 发布确认，*amqp.DeferredConfirmation 实现了该接口
+type confirmation interface {
+	WaitContext(ctx context.Context) (bool, error)
+}
+
+// publishFunc 发布单条消息并返回待确认的句柄
+type publishFunc func(ctx context.Context, msg OutgoingMessage) (confirmation, error)
+
+// publishBatch 先发布整批消息，再逐条等待 Broker 确认
+// 发布失败或被 nack 的消息记录到 PublishManyError 中，其余消息视为发送成功
+func publishBatch(ctx context.Context, msgs []OutgoingMessage, publish publishFunc) error {
+	var failures []PublishFailure
+	pending := make([]confirmation, len(msgs))
+
+	for i, msg := range msgs {
+		confirm, err := publish(ctx, msg)
+		if err != nil {
+			failures = append(failures, PublishFailure{Index: i, Err: err})
+			continue
+		}
+		pending[i] = confirm
+	}
+
+	for i, confirm := range pending {
+		if confirm == nil {
+			continue
+		}
+		acked, err := confirm.WaitContext(ctx)
+		switch {
+		case err != nil:
+			failures = append(failures, PublishFailure{Index: i, Err: fmt.Errorf("failed to wait for confirmation: %w", err)})
+		case !acked:
+			failures = append(failures, PublishFailure{Index: i, Err: ErrNacked})
+		}
+	}
+
+	if len(failures) == 0 {
+		return nil
+	}
+
+	// 发布阶段与确认阶段的失败按下标归并，保持升序
+	sort.Slice(failures, func(i, j int) bool { return failures[i].Index < failures[j].Index })
+	return &PublishManyError{Failures: failures}
+}
@@ -0,0 +1,70 @@
+package mq
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// fakeConfirmation 固定结果的发布确认
+type fakeConfirmation struct {
+	acked bool
+	err   error
+}
+
+func (f fakeConfirmation) WaitContext(ctx context.Context) (bool, error) {
+	return f.acked, f.err
+}
+
+func TestPublishBatchReportsPartialFailures(t *testing.T) {
+	msgs := []OutgoingMessage{
+		{RoutingKey: "user.created", Body: []byte(`{"id":1}`)},
+		{RoutingKey: "user.created", Body: []byte(`{"id":2}`)},
+		{RoutingKey: "user.created", Body: []byte(`{"id":3}`)},
+		{RoutingKey: "user.created", Body: []byte(`{"id":4}`)},
+	}
+	publishErr := errors.New("channel closed")
+
+	var published []string
+	err := publishBatch(context.Background(), msgs, func(ctx context.Context, msg OutgoingMessage) (confirmation, error) {
+		published = append(published, string(msg.Body))
+		switch string(msg.Body) {
+		case `{"id":2}`:
+			return fakeConfirmation{acked: false}, nil
+		case `{"id":3}`:
+			return nil, publishErr
+		default:
+			return fakeConfirmation{acked: true}, nil
+		}
+	})
+
+	if len(published) != len(msgs) {
+		t.Fatalf("published %d messages, want %d", len(published), len(msgs))
+	}
+
+	var batchErr *PublishManyError
+	if !errors.As(err, &batchErr) {
+		t.Fatalf("expected *PublishManyError, got %v", err)
+	}
+	if got, want := batchErr.FailedIndexes(), []int{1, 2}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("failed indexes = %v, want %v", got, want)
+	}
+	if !errors.Is(batchErr.Failures[0].Err, ErrNacked) {
+		t.Errorf("failure #1 = %v, want ErrNacked", batchErr.Failures[0].Err)
+	}
+	if !errors.Is(batchErr.Failures[1].Err, publishErr) {
+		t.Errorf("failure #2 = %v, want %v", batchErr.Failures[1].Err, publishErr)
+	}
+}
+
+func TestPublishBatchAllAcked(t *testing.T) {
+	msgs := []OutgoingMessage{{RoutingKey: "a"}, {RoutingKey: "b"}}
+
+	err := publishBatch(context.Background(), msgs, func(ctx context.Context, msg OutgoingMessage) (confirmation, error) {
+		return fakeConfirmation{acked: true}, nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
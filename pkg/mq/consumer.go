@@ -2,7 +2,15 @@ package mq
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sync"
+	"time"
+
+	"github.com/alfredchaos/demo/pkg/log"
+	"github.com/alfredchaos/demo/pkg/worker"
+	amqp "github.com/rabbitmq/amqp091-go"
+	"go.uber.org/zap"
 )
 
 // MessageHandler 消息处理函数类型
@@ -15,9 +23,74 @@ type Consumer interface {
 	Close() error
 }
 
+// defaultDrainTimeout 关闭时等待处理中消息完成的默认时长
+const defaultDrainTimeout = 10 * time.Second
+
+// DeliveryCountHeader 仲裁队列记录消息此前投递失败次数的消息头
+const DeliveryCountHeader = "x-delivery-count"
+
+// DeliveryCount 获取消息此前被投递（并被拒绝重新入队）的次数
+// 仅仲裁队列会设置该消息头，经典队列及首次投递时返回 0
+func DeliveryCount(headers amqp.Table) int64 {
+	switch v := headers[DeliveryCountHeader].(type) {
+	case int64:
+		return v
+	case int32:
+		return int64(v)
+	case int:
+		return int64(v)
+	default:
+		return 0
+	}
+}
+
+// permanentError 重试也无法成功的处理错误
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent 将处理错误标记为永久错误（如消息格式错误），默认策略下不再重新入队，直接进入死信
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// IsPermanent 判断处理错误是否为永久错误
+// 包括 Permanent 标记的错误，以及没有匹配路由（ErrNoRoute）、不支持的格式版本（ErrUnsupportedSchemaVersion）
+func IsPermanent(err error) bool {
+	var permanent *permanentError
+	return errors.As(err, &permanent) || errors.Is(err, ErrNoRoute) || errors.Is(err, ErrUnsupportedSchemaVersion)
+}
+
+// AckAction 处理失败后对消息的确认动作
+type AckAction int
+
+const (
+	// AckDefault 使用默认策略：永久错误（见 IsPermanent）直接进入死信；其余错误未达到 delivery_limit 时重新入队，达到后进入死信
+	AckDefault AckAction = iota
+	// AckAck 确认消息，不再投递（视为已处理，如已知无法处理的脏数据）
+	AckAck
+	// AckRequeue 拒绝并重新入队，忽略 delivery_limit
+	AckRequeue
+	// AckReject 拒绝且不重新入队，配置了死信交换机时进入死信队列
+	AckReject
+)
+
+// ErrorHandler 消息处理失败时的回调
+// 可用于告警、上报指标，并通过返回值决定消息的确认动作；自动确认模式下返回值不生效
+type ErrorHandler func(ctx context.Context, delivery amqp.Delivery, err error) AckAction
+
 // RabbitMQConsumer RabbitMQ 消息消费者实现
 type RabbitMQConsumer struct {
-	client *RabbitMQClient
+	client  *RabbitMQClient
+	onError ErrorHandler
+	// wg 跟踪消费循环，Close 等待其排空后返回
+	wg sync.WaitGroup
 }
 
 // NewRabbitMQConsumer 创建新的 RabbitMQ 消费者
@@ -27,19 +100,40 @@ func NewRabbitMQConsumer(client *RabbitMQClient) *RabbitMQConsumer {
 	}
 }
 
+// WithErrorHandler 设置处理失败时的回调，需在 Consume / ConsumeWithOptions 之前调用
+func (c *RabbitMQConsumer) WithErrorHandler(handler ErrorHandler) *RabbitMQConsumer {
+	c.onError = handler
+	return c
+}
+
 // Consume 开始消费消息
 // ctx: 上下文,用于控制消费者的生命周期
 // handler: 消息处理函数
+// 确认模式由配置 auto_ack 决定，默认手动确认
 func (c *RabbitMQConsumer) Consume(ctx context.Context, handler MessageHandler) error {
+	return c.ConsumeDeliveries(ctx, bodyHandler(handler))
+}
+
+// ConsumeDeliveries 开始消费消息，处理函数接收完整的投递（含 RoutingKey）
+// 适用于队列绑定了多个路由模式、需要按路由键分发的场景，可配合 Dispatcher 使用
+func (c *RabbitMQConsumer) ConsumeDeliveries(ctx context.Context, handler DeliveryHandler) error {
 	if !c.client.IsConnected() {
 		return fmt.Errorf("rabbitmq connection is closed")
 	}
-	
-	// 开始消费消息
+
+	autoAck := c.client.config.AutoAck
+
+	// 并发消费时限制未确认消息数量，避免消息堆积在本地（自动确认模式下 Broker 不按 QoS 限流）
+	if concurrency := c.client.config.Concurrency; concurrency > 1 && !autoAck {
+		if err := c.client.channel.Qos(concurrency, 0, false); err != nil {
+			return fmt.Errorf("failed to set qos: %w", err)
+		}
+	}
+
 	msgs, err := c.client.channel.Consume(
 		c.client.config.Queue, // 队列名称
 		"",                    // 消费者标签
-		false,                 // 自动确认: false表示手动确认
+		autoAck,               // 自动确认
 		false,                 // 独占
 		false,                 // no-local
 		false,                 // no-wait
@@ -48,35 +142,19 @@ func (c *RabbitMQConsumer) Consume(ctx context.Context, handler MessageHandler)
 	if err != nil {
 		return fmt.Errorf("failed to register consumer: %w", err)
 	}
-	
-	// 处理消息
-	go func() {
-		for {
-			select {
-			case <-ctx.Done():
-				// 上下文取消,停止消费
-				return
-			case msg, ok := <-msgs:
-				if !ok {
-					// 通道关闭
-					return
-				}
-				
-				// 调用处理函数
-				if err := handler(ctx, msg.Body); err != nil {
-					// 处理失败,拒绝消息并重新入队
-					msg.Nack(false, true)
-				} else {
-					// 处理成功,确认消息
-					msg.Ack(false)
-				}
-			}
-		}
-	}()
-	
+
+	c.startDispatch(ctx, msgs, handler, autoAck)
+
 	return nil
 }
 
+// bodyHandler 将只处理消息体的 MessageHandler 适配为 DeliveryHandler
+func bodyHandler(handler MessageHandler) DeliveryHandler {
+	return func(ctx context.Context, delivery amqp.Delivery) error {
+		return handler(ctx, delivery.Body)
+	}
+}
+
 // ConsumeWithOptions 使用自定义选项消费消息
 // 提供更细粒度的控制,如自动确认、预取数量等
 func (c *RabbitMQConsumer) ConsumeWithOptions(
@@ -116,34 +194,156 @@ func (c *RabbitMQConsumer) ConsumeWithOptions(
 	}
 	
 	// 处理消息
+	c.startDispatch(ctx, msgs, bodyHandler(handler), autoAck)
+	
+	return nil
+}
+
+// startDispatch 在后台启动消费循环，并纳入 Close 的等待范围
+func (c *RabbitMQConsumer) startDispatch(ctx context.Context, msgs <-chan amqp.Delivery, handler DeliveryHandler, autoAck bool) {
+	c.wg.Add(1)
 	go func() {
-		for {
-			select {
-			case <-ctx.Done():
+		defer c.wg.Done()
+		c.dispatch(ctx, msgs, handler, autoAck)
+	}()
+}
+
+// dispatch 分发消息到处理函数
+// 配置了 concurrency 时通过 worker.Pool 并发处理
+//
+// 上下文取消后进入排空阶段：不再拉取新消息，处理中的消息在 drain_timeout 内继续执行；
+// 处理函数使用独立的上下文，超过排空窗口才会被取消
+func (c *RabbitMQConsumer) dispatch(ctx context.Context, msgs <-chan amqp.Delivery, handler DeliveryHandler, autoAck bool) {
+	handlerCtx, cancelHandlers := context.WithCancel(context.WithoutCancel(ctx))
+	defer cancelHandlers()
+
+	pool := worker.NewPool(handlerCtx, c.client.config.Concurrency)
+	defer func() {
+		_ = pool.Wait()
+	}()
+
+	go c.cancelAfterDrain(ctx, handlerCtx, cancelHandlers)
+
+	for {
+		select {
+		case <-ctx.Done():
+			// 上下文取消,停止消费
+			return
+		case msg, ok := <-msgs:
+			if !ok {
+				// 通道关闭
 				return
-			case msg, ok := <-msgs:
-				if !ok {
-					return
+			}
+
+			// 已进入排空阶段时不再处理新消息，重新入队
+			if ctx.Err() != nil {
+				if !autoAck {
+					msg.Nack(false, true)
 				}
-				
-				if err := handler(ctx, msg.Body); err != nil {
-					if !autoAck {
-						msg.Nack(false, true)
-					}
-				} else {
-					if !autoAck {
-						msg.Ack(false)
-					}
+				return
+			}
+
+			// 处理失败已通过 Nack 重新入队，不再汇总到工作池的错误中
+			err := pool.Submit(func(ctx context.Context) error {
+				handleDelivery(ctx, msg, handler, c.onError, autoAck, c.client.config.DeliveryLimit)
+				return nil
+			})
+			if err != nil {
+				// 上下文取消，消息未处理，重新入队
+				if !autoAck {
+					msg.Nack(false, true)
 				}
+				return
 			}
 		}
-	}()
-	
-	return nil
+	}
+}
+
+// cancelAfterDrain 消费上下文取消后等待排空窗口，超时则取消处理中的消息
+func (c *RabbitMQConsumer) cancelAfterDrain(ctx, handlerCtx context.Context, cancelHandlers context.CancelFunc) {
+	select {
+	case <-ctx.Done():
+	case <-handlerCtx.Done():
+		return
+	}
+
+	drainTimeout := c.client.config.DrainTimeout
+	if drainTimeout <= 0 {
+		drainTimeout = defaultDrainTimeout
+	}
+
+	timer := time.NewTimer(drainTimeout)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		cancelHandlers()
+	case <-handlerCtx.Done():
+	}
+}
+
+// handleDelivery 调用处理函数并确认消息
+// 处理失败时先调用 onError（可为 nil），由其返回的动作决定如何确认；
+// 使用默认策略时永久错误直接拒绝且不重新入队，其余（临时）错误重新入队；
+// deliveryLimit > 0 时，已达到最大投递次数的消息不再重新入队，交由死信交换机处理
+func handleDelivery(ctx context.Context, msg amqp.Delivery, handler DeliveryHandler, onError ErrorHandler, autoAck bool, deliveryLimit int) {
+	if err := handler(ctx, msg); err != nil {
+		action := AckDefault
+		if onError != nil {
+			action = onError(ctx, msg, err)
+		}
+		if autoAck {
+			return
+		}
+
+		switch action {
+		case AckAck:
+			msg.Ack(false)
+			return
+		case AckRequeue:
+			msg.Nack(false, true)
+			return
+		case AckReject:
+			msg.Nack(false, false)
+			return
+		}
+
+		// 永久错误重试也不会成功，不再重新入队
+		if IsPermanent(err) {
+			log.WithContext(ctx).Warn("message failed permanently, dead-lettering",
+				zap.String("routing_key", msg.RoutingKey),
+				zap.String("schema_version", SchemaVersion(msg.Headers)),
+				zap.Error(err))
+			msg.Nack(false, false)
+			return
+		}
+
+		// 本次为第 DeliveryCount+1 次投递
+		if deliveryLimit > 0 && DeliveryCount(msg.Headers)+1 >= int64(deliveryLimit) {
+			log.WithContext(ctx).Warn("message exceeded delivery limit, dead-lettering",
+				zap.String("routing_key", msg.RoutingKey),
+				zap.Int64("delivery_count", DeliveryCount(msg.Headers)),
+				zap.Int("delivery_limit", deliveryLimit),
+				zap.Error(err))
+			msg.Nack(false, false)
+			return
+		}
+
+		// 处理失败,拒绝消息并重新入队
+		msg.Nack(false, true)
+		return
+	}
+
+	// 处理成功,确认消息
+	if !autoAck {
+		msg.Ack(false)
+	}
 }
 
-// Close 关闭消费者
+// Close 等待消费循环排空后关闭消费者
+// 调用前应先取消 Consume 的上下文，否则会一直阻塞；最长等待约 drain_timeout
 func (c *RabbitMQConsumer) Close() error {
+	c.wg.Wait()
 	// 消费者不直接关闭客户端,由客户端管理者负责
 	return nil
 }
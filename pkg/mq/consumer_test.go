@@ -0,0 +1,327 @@
+package mq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alfredchaos/demo/pkg/log"
+	amqp "github.com/rabbitmq/amqp091-go"
+	"go.uber.org/zap"
+)
+
+// useNopLogger 测试期间使用空日志，结束后恢复原日志
+func useNopLogger(t *testing.T) {
+	t.Helper()
+	original := log.Logger
+	log.Logger = zap.NewNop()
+	t.Cleanup(func() { log.Logger = original })
+}
+
+// fakeAcknowledger 记录确认结果的 Acknowledger
+type fakeAcknowledger struct {
+	mu       sync.Mutex
+	acks     []uint64
+	nacks    []uint64
+	rejected []uint64 // 未重新入队（进入死信）的消息
+}
+
+func (a *fakeAcknowledger) Ack(tag uint64, multiple bool) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.acks = append(a.acks, tag)
+	return nil
+}
+
+func (a *fakeAcknowledger) Nack(tag uint64, multiple, requeue bool) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.nacks = append(a.nacks, tag)
+	if !requeue {
+		a.rejected = append(a.rejected, tag)
+	}
+	return nil
+}
+
+func (a *fakeAcknowledger) Reject(tag uint64, requeue bool) error {
+	return a.Nack(tag, false, requeue)
+}
+
+func (a *fakeAcknowledger) counts() (acks, nacks int) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return len(a.acks), len(a.nacks)
+}
+
+// newTestConsumer 创建不连接 Broker 的消费者，只用于测试分发逻辑
+func newTestConsumer(drainTimeout time.Duration) *RabbitMQConsumer {
+	return NewRabbitMQConsumer(&RabbitMQClient{
+		config: &RabbitMQConfig{DrainTimeout: drainTimeout},
+	})
+}
+
+func TestConsumer_DrainsInFlightHandlerOnShutdown(t *testing.T) {
+	consumer := newTestConsumer(time.Second)
+	acker := &fakeAcknowledger{}
+	msgs := make(chan amqp.Delivery, 2)
+
+	started := make(chan struct{})
+	var handlerErr error
+	handler := func(ctx context.Context, d amqp.Delivery) error {
+		close(started)
+		select {
+		case <-time.After(100 * time.Millisecond):
+		case <-ctx.Done():
+			handlerErr = ctx.Err()
+		}
+		return handlerErr
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	consumer.startDispatch(ctx, msgs, handler, false)
+
+	msgs <- amqp.Delivery{Acknowledger: acker, DeliveryTag: 1}
+	<-started
+
+	// 处理中途关闭：停止拉取，第二条消息不应被处理
+	cancel()
+	msgs <- amqp.Delivery{Acknowledger: acker, DeliveryTag: 2}
+
+	if err := consumer.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	if handlerErr != nil {
+		t.Fatalf("handler was cancelled within the drain window: %v", handlerErr)
+	}
+	if acks, _ := acker.counts(); acks != 1 || acker.acks[0] != 1 {
+		t.Fatalf("want in-flight message acked, got acks=%v nacks=%v", acker.acks, acker.nacks)
+	}
+}
+
+func TestConsumer_CancelsHandlerAfterDrainTimeout(t *testing.T) {
+	consumer := newTestConsumer(50 * time.Millisecond)
+	acker := &fakeAcknowledger{}
+	msgs := make(chan amqp.Delivery, 1)
+
+	started := make(chan struct{})
+	handler := func(ctx context.Context, d amqp.Delivery) error {
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	consumer.startDispatch(ctx, msgs, handler, false)
+
+	msgs <- amqp.Delivery{Acknowledger: acker, DeliveryTag: 1}
+	<-started
+	cancel()
+
+	closed := make(chan struct{})
+	go func() {
+		_ = consumer.Close()
+		close(closed)
+	}()
+
+	select {
+	case <-closed:
+	case <-time.After(2 * time.Second):
+		t.Fatal("close did not return after the drain timeout")
+	}
+
+	if acks, nacks := acker.counts(); acks != 0 || nacks != 1 {
+		t.Fatalf("want timed-out message nacked, got acks=%d nacks=%d", acks, nacks)
+	}
+}
+
+func TestHandleDelivery_DeadLettersAfterDeliveryLimit(t *testing.T) {
+	useNopLogger(t)
+	failing := func(ctx context.Context, delivery amqp.Delivery) error { return errors.New("boom") }
+
+	cases := []struct {
+		name         string
+		headers      amqp.Table
+		limit        int
+		wantRejected bool
+	}{
+		{"first delivery requeued", nil, 3, false},
+		{"below limit requeued", amqp.Table{DeliveryCountHeader: int64(1)}, 3, false},
+		{"reaching limit dead-lettered", amqp.Table{DeliveryCountHeader: int64(2)}, 3, true},
+		{"no limit always requeued", amqp.Table{DeliveryCountHeader: int64(10)}, 0, false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ack := &fakeAcknowledger{}
+			msg := amqp.Delivery{Acknowledger: ack, DeliveryTag: 1, Headers: c.headers}
+
+			handleDelivery(context.Background(), msg, failing, nil, false, c.limit)
+
+			if _, nacks := ack.counts(); nacks != 1 {
+				t.Fatalf("want 1 nack, got %d", nacks)
+			}
+			if got := len(ack.rejected) == 1; got != c.wantRejected {
+				t.Fatalf("want dead-lettered=%v, got %v", c.wantRejected, got)
+			}
+		})
+	}
+}
+
+func TestHandleDelivery_RejectsPermanentErrorsWithoutRequeue(t *testing.T) {
+	useNopLogger(t)
+	cases := []struct {
+		name         string
+		err          error
+		wantRejected bool
+	}{
+		{"transient requeued", errors.New("connection reset"), false},
+		{"marked permanent", Permanent(errors.New("invalid payload")), true},
+		{"no route", fmt.Errorf("dispatch: %w", ErrNoRoute), true},
+		{"unsupported schema version", fmt.Errorf("decode: %w", ErrUnsupportedSchemaVersion), true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ack := &fakeAcknowledger{}
+			msg := amqp.Delivery{Acknowledger: ack, DeliveryTag: 1}
+			failing := func(ctx context.Context, delivery amqp.Delivery) error { return c.err }
+
+			// 第一次投递且不限制投递次数，只有永久错误进入死信
+			handleDelivery(context.Background(), msg, failing, nil, false, 0)
+
+			if _, nacks := ack.counts(); nacks != 1 {
+				t.Fatalf("want 1 nack, got %d", nacks)
+			}
+			if got := len(ack.rejected) == 1; got != c.wantRejected {
+				t.Fatalf("want dead-lettered=%v, got %v", c.wantRejected, got)
+			}
+		})
+	}
+}
+
+func TestHandleDelivery_ErrorHandlerDecidesAckAction(t *testing.T) {
+	useNopLogger(t)
+	handlerErr := errors.New("boom")
+	failing := func(ctx context.Context, delivery amqp.Delivery) error { return handlerErr }
+
+	cases := []struct {
+		name                string
+		action              AckAction
+		wantAcks, wantNacks int
+		wantRejected        bool
+	}{
+		// 已达到投递上限，默认策略进入死信
+		{"default applies delivery limit", AckDefault, 0, 1, true},
+		{"ack", AckAck, 1, 0, false},
+		{"requeue ignores delivery limit", AckRequeue, 0, 1, false},
+		{"reject", AckReject, 0, 1, true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ack := &fakeAcknowledger{}
+			msg := amqp.Delivery{
+				Acknowledger: ack,
+				DeliveryTag:  1,
+				RoutingKey:   "user.created",
+				Headers:      amqp.Table{DeliveryCountHeader: int64(2)},
+			}
+
+			var gotKey string
+			var gotErr error
+			onError := func(ctx context.Context, delivery amqp.Delivery, err error) AckAction {
+				gotKey, gotErr = delivery.RoutingKey, err
+				return c.action
+			}
+			handleDelivery(context.Background(), msg, failing, onError, false, 3)
+
+			if gotKey != "user.created" || !errors.Is(gotErr, handlerErr) {
+				t.Fatalf("want error handler called with delivery and error, got key=%q err=%v", gotKey, gotErr)
+			}
+			acks, nacks := ack.counts()
+			if acks != c.wantAcks || nacks != c.wantNacks {
+				t.Fatalf("want acks=%d nacks=%d, got acks=%d nacks=%d", c.wantAcks, c.wantNacks, acks, nacks)
+			}
+			if got := len(ack.rejected) == 1; got != c.wantRejected {
+				t.Fatalf("want dead-lettered=%v, got %v", c.wantRejected, got)
+			}
+		})
+	}
+}
+
+func TestConsumer_UsesConfiguredErrorHandler(t *testing.T) {
+	consumer := newTestConsumer(time.Second)
+	var calls atomic.Int32
+	consumer.WithErrorHandler(func(ctx context.Context, delivery amqp.Delivery, err error) AckAction {
+		calls.Add(1)
+		return AckAck
+	})
+
+	acker := &fakeAcknowledger{}
+	msgs := make(chan amqp.Delivery, 2)
+	msgs <- amqp.Delivery{Acknowledger: acker, DeliveryTag: 1}
+	msgs <- amqp.Delivery{Acknowledger: acker, DeliveryTag: 2, RoutingKey: "bad"}
+	close(msgs)
+
+	handler := func(ctx context.Context, delivery amqp.Delivery) error {
+		if delivery.RoutingKey == "bad" {
+			return errors.New("malformed payload")
+		}
+		return nil
+	}
+	consumer.startDispatch(context.Background(), msgs, handler, false)
+	if err := consumer.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	// 成功的消息不触发回调；失败的消息按回调返回的 AckAck 确认
+	acks, nacks := acker.counts()
+	if calls.Load() != 1 || acks != 2 || nacks != 0 {
+		t.Fatalf("want 1 callback and 2 acks, got calls=%d acks=%d nacks=%d", calls.Load(), acks, nacks)
+	}
+}
+
+func TestConsumer_FailedMessageRequeueDependsOnAutoAck(t *testing.T) {
+	failing := func(ctx context.Context, delivery amqp.Delivery) error { return errors.New("boom") }
+
+	cases := []struct {
+		name      string
+		autoAck   bool
+		wantNacks int
+	}{
+		// 至多一次：Broker 已确认，失败的消息不会重新入队
+		{"auto ack does not requeue", true, 0},
+		// 至少一次：失败的消息 Nack 重新入队
+		{"manual ack requeues", false, 1},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			consumer := NewRabbitMQConsumer(&RabbitMQClient{
+				config: &RabbitMQConfig{AutoAck: c.autoAck},
+			})
+			acker := &fakeAcknowledger{}
+			msgs := make(chan amqp.Delivery, 1)
+			msgs <- amqp.Delivery{Acknowledger: acker, DeliveryTag: 1}
+			close(msgs)
+
+			consumer.startDispatch(context.Background(), msgs, failing, consumer.client.config.AutoAck)
+			if err := consumer.Close(); err != nil {
+				t.Fatalf("close: %v", err)
+			}
+
+			acks, nacks := acker.counts()
+			if acks != 0 || nacks != c.wantNacks {
+				t.Fatalf("want acks=0 nacks=%d, got acks=%d nacks=%d", c.wantNacks, acks, nacks)
+			}
+			if len(acker.rejected) != 0 {
+				t.Fatalf("failed message should not be dead-lettered, got %v", acker.rejected)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package mq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// ErrNoRoute 没有与路由键匹配的处理函数
+var ErrNoRoute = errors.New("no handler matched routing key")
+
+// DeliveryHandler 完整投递的处理函数类型
+// 相比 MessageHandler 可以访问 RoutingKey、Headers 等投递元数据
+type DeliveryHandler func(ctx context.Context, delivery amqp.Delivery) error
+
+// route 路由规则
+type route struct {
+	pattern string
+	handler DeliveryHandler
+}
+
+// Dispatcher 按路由键分发消息的处理器
+// 模式语义与 Topic Exchange 一致：* 匹配一个单词，# 匹配零个或多个单词
+// 按注册顺序匹配，第一个匹配的处理函数生效
+type Dispatcher struct {
+	routes []route
+}
+
+// NewDispatcher 创建消息分发器
+func NewDispatcher() *Dispatcher {
+	return &Dispatcher{}
+}
+
+// Handle 注册路由模式对应的处理函数
+func (d *Dispatcher) Handle(pattern string, handler DeliveryHandler) *Dispatcher {
+	d.routes = append(d.routes, route{pattern: pattern, handler: handler})
+	return d
+}
+
+// Dispatch 将投递分发到第一个匹配的处理函数
+// 没有匹配的处理函数时返回 ErrNoRoute
+func (d *Dispatcher) Dispatch(ctx context.Context, delivery amqp.Delivery) error {
+	for _, r := range d.routes {
+		if MatchRoutingKey(r.pattern, delivery.RoutingKey) {
+			return r.handler(ctx, delivery)
+		}
+	}
+	return fmt.Errorf("%w: %s", ErrNoRoute, delivery.RoutingKey)
+}
+
+// MatchRoutingKey 判断路由键是否匹配 Topic 模式
+func MatchRoutingKey(pattern, routingKey string) bool {
+	return matchWords(strings.Split(pattern, "."), strings.Split(routingKey, "."))
+}
+
+// matchWords 逐个单词匹配，# 可以吞掉零个或多个单词
+func matchWords(pattern, words []string) bool {
+	if len(pattern) == 0 {
+		return len(words) == 0
+	}
+
+	switch pattern[0] {
+	case "#":
+		for i := 0; i <= len(words); i++ {
+			if matchWords(pattern[1:], words[i:]) {
+				return true
+			}
+		}
+		return false
+	case "*":
+		return len(words) > 0 && matchWords(pattern[1:], words[1:])
+	default:
+		return len(words) > 0 && pattern[0] == words[0] && matchWords(pattern[1:], words[1:])
+	}
+}
@@ -0,0 +1,67 @@
+package mq
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+func TestMatchRoutingKey(t *testing.T) {
+	cases := []struct {
+		pattern, key string
+		want         bool
+	}{
+		{"task.#", "task.sayhello.create", true},
+		{"task.#", "task", true},
+		{"task.#", "nice.process", false},
+		{"task.*", "task.sayhello", true},
+		{"task.*", "task.sayhello.create", false},
+		{"*.sayhello.#", "task.sayhello.completed", true},
+		{"user.created", "user.created", true},
+		{"user.created", "user.updated", false},
+		{"#", "anything.at.all", true},
+	}
+
+	for _, c := range cases {
+		if got := MatchRoutingKey(c.pattern, c.key); got != c.want {
+			t.Errorf("MatchRoutingKey(%q, %q) = %v, want %v", c.pattern, c.key, got, c.want)
+		}
+	}
+}
+
+func TestDispatcher_RoutesByRoutingKey(t *testing.T) {
+	var got []string
+	record := func(name string) DeliveryHandler {
+		return func(ctx context.Context, d amqp.Delivery) error {
+			got = append(got, name+":"+d.RoutingKey)
+			return nil
+		}
+	}
+
+	d := NewDispatcher().
+		Handle(RoutingKeyTaskSayHelloFailed, record("failed")).
+		Handle(RoutingKeyTaskPattern, record("task")).
+		Handle(RoutingKeyNicePattern, record("nice"))
+
+	for _, key := range []string{"task.sayhello.create", "nice.process", "task.sayhello.failed"} {
+		if err := d.Dispatch(context.Background(), amqp.Delivery{RoutingKey: key}); err != nil {
+			t.Fatalf("dispatch %s: %v", key, err)
+		}
+	}
+
+	want := []string{"task:task.sayhello.create", "nice:nice.process", "failed:task.sayhello.failed"}
+	if len(got) != len(want) {
+		t.Fatalf("want %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("want %v, got %v", want, got)
+		}
+	}
+
+	if err := d.Dispatch(context.Background(), amqp.Delivery{RoutingKey: "user.created"}); !errors.Is(err, ErrNoRoute) {
+		t.Fatalf("want ErrNoRoute, got %v", err)
+	}
+}
@@ -0,0 +1,80 @@
+package mqtest
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/alfredchaos/demo/pkg/mq"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// ErrNoHandler 推送消息时尚未注册处理函数
+var ErrNoHandler = errors.New("mqtest: no handler registered")
+
+// FakeConsumer 由测试主动推送消息的消费者
+// Consume / ConsumeDeliveries 只登记处理函数，Push 系列方法同步调用处理函数并返回其错误
+type FakeConsumer struct {
+	mu      sync.Mutex
+	handler mq.DeliveryHandler
+	closed  bool
+}
+
+// NewFakeConsumer 创建测试用消费者
+func NewFakeConsumer() *FakeConsumer {
+	return &FakeConsumer{}
+}
+
+// Consume 登记只关心消息体的处理函数
+func (c *FakeConsumer) Consume(ctx context.Context, handler mq.MessageHandler) error {
+	return c.ConsumeDeliveries(ctx, func(ctx context.Context, delivery amqp.Delivery) error {
+		return handler(ctx, delivery.Body)
+	})
+}
+
+// ConsumeDeliveries 登记接收完整投递的处理函数，重复调用时替换之前的处理函数
+func (c *FakeConsumer) ConsumeDeliveries(ctx context.Context, handler mq.DeliveryHandler) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return ErrClosed
+	}
+	c.handler = handler
+	return nil
+}
+
+// Push 推送一条消息体
+func (c *FakeConsumer) Push(ctx context.Context, body []byte) error {
+	return c.PushDelivery(ctx, amqp.Delivery{Body: body})
+}
+
+// PushWithRouting 推送一条带路由键的消息
+func (c *FakeConsumer) PushWithRouting(ctx context.Context, routingKey string, body []byte) error {
+	return c.PushDelivery(ctx, amqp.Delivery{RoutingKey: routingKey, Body: body})
+}
+
+// PushDelivery 推送一条完整投递，返回处理函数的错误
+func (c *FakeConsumer) PushDelivery(ctx context.Context, delivery amqp.Delivery) error {
+	c.mu.Lock()
+	handler, closed := c.handler, c.closed
+	c.mu.Unlock()
+
+	if closed {
+		return ErrClosed
+	}
+	if handler == nil {
+		return ErrNoHandler
+	}
+	return handler(ctx, delivery)
+}
+
+// Close 关闭消费者，之后的推送返回 ErrClosed
+func (c *FakeConsumer) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.closed = true
+	c.handler = nil
+	return nil
+}
+
+var _ mq.Consumer = (*FakeConsumer)(nil)
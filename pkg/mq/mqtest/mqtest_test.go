@@ -0,0 +1,138 @@
+package mqtest
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/alfredchaos/demo/pkg/mq"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+func TestFakePublisher_Records(t *testing.T) {
+	p := NewFakePublisher()
+	ctx := context.Background()
+
+	body := []byte("hello")
+	if err := p.PublishWithRouting(ctx, "task.a", body); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	body[0] = 'j' // 调用方复用缓冲区不影响已记录的消息
+	if err := p.PublishWithPriority(ctx, "task.b", []byte("urgent"), 5); err != nil {
+		t.Fatalf("publish with priority: %v", err)
+	}
+	if err := p.Publish(ctx, []byte("default")); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+
+	msgs := p.Messages()
+	if len(msgs) != 3 {
+		t.Fatalf("want 3 messages, got %d", len(msgs))
+	}
+	if msgs[0].RoutingKey != "task.a" || string(msgs[0].Body) != "hello" {
+		t.Fatalf("unexpected first message: %+v", msgs[0])
+	}
+	if msgs[1].Priority != 5 {
+		t.Fatalf("want priority 5, got %d", msgs[1].Priority)
+	}
+	if got := p.MessagesFor("task.b"); len(got) != 1 || string(got[0].Body) != "urgent" {
+		t.Fatalf("unexpected messages for task.b: %+v", got)
+	}
+
+	p.Reset()
+	if len(p.Messages()) != 0 {
+		t.Fatal("want no messages after reset")
+	}
+}
+
+func TestFakePublisher_InjectedErrors(t *testing.T) {
+	p := NewFakePublisher()
+	ctx := context.Background()
+	errBoom := errors.New("boom")
+
+	p.SetError(errBoom)
+	if err := p.Publish(ctx, []byte("x")); !errors.Is(err, errBoom) {
+		t.Fatalf("want injected error, got %v", err)
+	}
+	p.SetError(nil)
+
+	p.SetRoutingKeyError("task.bad", errBoom)
+	err := p.PublishMany(ctx, []mq.OutgoingMessage{
+		{RoutingKey: "task.ok", Body: []byte("1")},
+		{RoutingKey: "task.bad", Body: []byte("2")},
+		{RoutingKey: "task.ok", Body: []byte("3")},
+	})
+	var manyErr *mq.PublishManyError
+	if !errors.As(err, &manyErr) {
+		t.Fatalf("want *mq.PublishManyError, got %v", err)
+	}
+	if idx := manyErr.FailedIndexes(); len(idx) != 1 || idx[0] != 1 {
+		t.Fatalf("want failed index [1], got %v", idx)
+	}
+	if got := p.MessagesFor("task.ok"); len(got) != 2 {
+		t.Fatalf("want successful messages recorded, got %d", len(got))
+	}
+
+	_ = p.Close()
+	if err := p.Publish(ctx, []byte("x")); !errors.Is(err, ErrClosed) {
+		t.Fatalf("want ErrClosed after close, got %v", err)
+	}
+}
+
+func TestFakeConsumer_Push(t *testing.T) {
+	c := NewFakeConsumer()
+	ctx := context.Background()
+
+	if err := c.Push(ctx, []byte("x")); !errors.Is(err, ErrNoHandler) {
+		t.Fatalf("want ErrNoHandler, got %v", err)
+	}
+
+	var got []string
+	errReject := errors.New("reject")
+	if err := c.Consume(ctx, func(ctx context.Context, message []byte) error {
+		got = append(got, string(message))
+		if string(message) == "bad" {
+			return errReject
+		}
+		return nil
+	}); err != nil {
+		t.Fatalf("consume: %v", err)
+	}
+
+	if err := c.Push(ctx, []byte("good")); err != nil {
+		t.Fatalf("push: %v", err)
+	}
+	if err := c.Push(ctx, []byte("bad")); !errors.Is(err, errReject) {
+		t.Fatalf("want handler error, got %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("want 2 handled messages, got %v", got)
+	}
+
+	_ = c.Close()
+	if err := c.Push(ctx, []byte("x")); !errors.Is(err, ErrClosed) {
+		t.Fatalf("want ErrClosed after close, got %v", err)
+	}
+}
+
+func TestFakeConsumer_Deliveries(t *testing.T) {
+	c := NewFakeConsumer()
+	ctx := context.Background()
+
+	dispatcher := mq.NewDispatcher()
+	var routed string
+	dispatcher.Handle("task.#", func(ctx context.Context, delivery amqp.Delivery) error {
+		routed = delivery.RoutingKey
+		return nil
+	})
+	if err := c.ConsumeDeliveries(ctx, dispatcher.Dispatch); err != nil {
+		t.Fatalf("consume deliveries: %v", err)
+	}
+
+	if err := c.PushWithRouting(ctx, "task.sayhello.create", []byte("{}")); err != nil {
+		t.Fatalf("push: %v", err)
+	}
+	if routed != "task.sayhello.create" {
+		t.Fatalf("want routing key delivered, got %q", routed)
+	}
+}
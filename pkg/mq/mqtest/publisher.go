@@ -0,0 +1,149 @@
+// Package mqtest 提供消息发布者与消费者的测试替身
+// 仅用于单元测试，不连接 RabbitMQ
+package mqtest
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/alfredchaos/demo/pkg/mq"
+)
+
+// ErrClosed 发布者或消费者已关闭
+var ErrClosed = errors.New("mqtest: closed")
+
+// Message 记录的一条已发布消息
+type Message struct {
+	RoutingKey string                 // 路由键，Publish 发布的消息为空
+	Body       []byte                 // 消息内容
+	Headers    map[string]interface{} // 消息头，仅 PublishMany 会设置
+	Priority   uint8                  // 优先级，仅 PublishWithPriority 会设置
+}
+
+// FakePublisher 记录所有已发布消息的发布者
+// 同时满足 mq.Publisher 及各服务 messaging.Publisher 接口，并发安全；
+// 通过 SetError / SetRoutingKeyError 注入发布错误，失败的消息不会被记录
+type FakePublisher struct {
+	mu            sync.Mutex
+	messages      []Message
+	err           error
+	routingKeyErr map[string]error
+	closed        bool
+}
+
+// NewFakePublisher 创建测试用发布者
+func NewFakePublisher() *FakePublisher {
+	return &FakePublisher{routingKeyErr: make(map[string]error)}
+}
+
+// SetError 设置后续所有发布返回的错误，传入 nil 恢复正常
+func (p *FakePublisher) SetError(err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.err = err
+}
+
+// SetRoutingKeyError 设置发布到指定路由键时返回的错误，传入 nil 恢复正常
+func (p *FakePublisher) SetRoutingKeyError(routingKey string, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if err == nil {
+		delete(p.routingKeyErr, routingKey)
+		return
+	}
+	p.routingKeyErr[routingKey] = err
+}
+
+// Publish 记录发布到默认路由键的消息
+func (p *FakePublisher) Publish(ctx context.Context, message []byte) error {
+	return p.record(Message{Body: message})
+}
+
+// PublishWithRouting 记录发布到指定路由键的消息
+func (p *FakePublisher) PublishWithRouting(ctx context.Context, routingKey string, message []byte) error {
+	return p.record(Message{RoutingKey: routingKey, Body: message})
+}
+
+// PublishWithPriority 记录带优先级的消息
+func (p *FakePublisher) PublishWithPriority(ctx context.Context, routingKey string, message []byte, priority uint8) error {
+	return p.record(Message{RoutingKey: routingKey, Body: message, Priority: priority})
+}
+
+// PublishMany 逐条记录批量消息
+// 与真实实现一致，部分失败时返回 *mq.PublishManyError，成功的消息照常记录
+func (p *FakePublisher) PublishMany(ctx context.Context, msgs []mq.OutgoingMessage) error {
+	var failures []mq.PublishFailure
+	for i, msg := range msgs {
+		err := p.record(Message{RoutingKey: msg.RoutingKey, Body: msg.Body, Headers: msg.Headers})
+		if err != nil {
+			failures = append(failures, mq.PublishFailure{Index: i, Err: err})
+		}
+	}
+	if len(failures) > 0 {
+		return &mq.PublishManyError{Failures: failures}
+	}
+	return nil
+}
+
+// Close 关闭发布者，之后的发布返回 ErrClosed
+func (p *FakePublisher) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.closed = true
+	return nil
+}
+
+// Messages 返回已发布消息的副本，按发布顺序排列
+func (p *FakePublisher) Messages() []Message {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	out := make([]Message, len(p.messages))
+	copy(out, p.messages)
+	return out
+}
+
+// MessagesFor 返回发布到指定路由键的消息
+func (p *FakePublisher) MessagesFor(routingKey string) []Message {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var out []Message
+	for _, msg := range p.messages {
+		if msg.RoutingKey == routingKey {
+			out = append(out, msg)
+		}
+	}
+	return out
+}
+
+// Reset 清空已记录的消息和注入的错误
+func (p *FakePublisher) Reset() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.messages = nil
+	p.err = nil
+	p.routingKeyErr = make(map[string]error)
+	p.closed = false
+}
+
+// record 检查注入的错误后记录消息，消息体复制一份避免调用方复用缓冲区
+func (p *FakePublisher) record(msg Message) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return ErrClosed
+	}
+	if p.err != nil {
+		return p.err
+	}
+	if err, ok := p.routingKeyErr[msg.RoutingKey]; ok {
+		return err
+	}
+
+	msg.Body = append([]byte(nil), msg.Body...)
+	p.messages = append(p.messages, msg)
+	return nil
+}
+
+var _ mq.Publisher = (*FakePublisher)(nil)
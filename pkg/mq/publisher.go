@@ -11,6 +11,7 @@ import (
 // 使用接口定义发布者行为,便于测试和替换实现
 type Publisher interface {
 	Publish(ctx context.Context, message []byte) error
+	PublishMany(ctx context.Context, msgs []OutgoingMessage) error
 	Close() error
 }
 
@@ -43,6 +44,7 @@ func (p *RabbitMQPublisher) Publish(ctx context.Context, message []byte) error {
 		false,                      // immediate: 如果为true,当消息无法立即投递给消费者时会返回错误
 		amqp.Publishing{
 			ContentType:  "application/json",
+			Headers:      withSchemaVersion(nil, p.client.config.SchemaVersion), // 格式版本，供消费者选择解码器
 			Body:         message,
 			DeliveryMode: amqp.Persistent, // 持久化消息
 		},
@@ -82,6 +84,7 @@ func (p *RabbitMQPublisher) PublishWithOptions(
 		false,
 		amqp.Publishing{
 			ContentType:  contentType,
+			Headers:      withSchemaVersion(nil, p.client.config.SchemaVersion),
 			Body:         message,
 			DeliveryMode: deliveryMode,
 		},
@@ -94,6 +97,80 @@ func (p *RabbitMQPublisher) PublishWithOptions(
 	return nil
 }
 
+// PublishWithPriority 以指定优先级发布消息到配置的交换机
+// 优先级越高越先被投递，超过队列 x-max-priority 的值按最大优先级处理；
+// 仅当目标队列声明时设置了 MaxPriority（x-max-priority）才生效，否则按普通 FIFO 投递
+func (p *RabbitMQPublisher) PublishWithPriority(ctx context.Context, routingKey string, message []byte, priority uint8) error {
+	if !p.client.IsConnected() {
+		return fmt.Errorf("rabbitmq connection is closed")
+	}
+
+	err := p.client.channel.PublishWithContext(
+		ctx,
+		p.client.config.Exchange,
+		routingKey,
+		false,
+		false,
+		amqp.Publishing{
+			ContentType:  "application/json",
+			Headers:      withSchemaVersion(nil, p.client.config.SchemaVersion),
+			Body:         message,
+			DeliveryMode: amqp.Persistent,
+			Priority:     priority,
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to publish message: %w", err)
+	}
+
+	return nil
+}
+
+// PublishMany 在一个确认批次中发布多条消息
+// 使用独立的 confirm 模式通道，先发布整批消息再等待 Broker 确认；
+// 部分消息失败时返回 *PublishManyError，其中列出失败消息的下标
+func (p *RabbitMQPublisher) PublishMany(ctx context.Context, msgs []OutgoingMessage) error {
+	if len(msgs) == 0 {
+		return nil
+	}
+	if !p.client.IsConnected() {
+		return fmt.Errorf("rabbitmq connection is closed")
+	}
+
+	// 使用独立通道开启 confirm 模式，避免影响共享通道上的其他发布
+	channel, err := p.client.conn.Channel()
+	if err != nil {
+		return fmt.Errorf("failed to open channel: %w", err)
+	}
+	defer channel.Close()
+
+	if err := channel.Confirm(false); err != nil {
+		return fmt.Errorf("failed to enable publisher confirms: %w", err)
+	}
+
+	exchange := p.client.config.Exchange
+	schemaVersion := p.client.config.SchemaVersion
+	return publishBatch(ctx, msgs, func(ctx context.Context, msg OutgoingMessage) (confirmation, error) {
+		confirm, err := channel.PublishWithDeferredConfirmWithContext(
+			ctx,
+			exchange,
+			msg.RoutingKey,
+			false,
+			false,
+			amqp.Publishing{
+				ContentType:  "application/json",
+				Headers:      withSchemaVersion(amqp.Table(msg.Headers), schemaVersion),
+				Body:         msg.Body,
+				DeliveryMode: amqp.Persistent,
+			},
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to publish message: %w", err)
+		}
+		return confirm, nil
+	})
+}
+
 // Close 关闭发布者
 func (p *RabbitMQPublisher) Close() error {
 	// 发布者不直接关闭客户端,由客户端管理者负责
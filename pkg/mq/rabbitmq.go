@@ -1,21 +1,169 @@
 package mq
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"time"
 
 	amqp "github.com/rabbitmq/amqp091-go"
 )
 
 // RabbitMQConfig RabbitMQ 配置
 type RabbitMQConfig struct {
-	Enabled      bool   `yaml:"enabled" mapstructure:"enabled"`             // 是否启用 RabbitMQ
-	URL          string `yaml:"url" mapstructure:"url"`                     // RabbitMQ 连接 URL
-	Exchange     string `yaml:"exchange" mapstructure:"exchange"`           // 交换机名称
-	ExchangeType string `yaml:"exchange_type" mapstructure:"exchange_type"` // 交换机类型: direct, topic, fanout
-	Queue        string `yaml:"queue" mapstructure:"queue"`                 // 队列名称
-	RoutingKey   string `yaml:"routing_key" mapstructure:"routing_key"`     // 路由键
-	Durable      bool   `yaml:"durable" mapstructure:"durable"`             // 是否持久化
-	AutoDelete   bool   `yaml:"auto_delete" mapstructure:"auto_delete"`     // 是否自动删除
+	Enabled      bool     `yaml:"enabled" mapstructure:"enabled"`             // 是否启用 RabbitMQ
+	URL          string   `yaml:"url" mapstructure:"url"`                     // RabbitMQ 连接 URL
+	Exchange     string   `yaml:"exchange" mapstructure:"exchange"`           // 交换机名称
+	ExchangeType string   `yaml:"exchange_type" mapstructure:"exchange_type"` // 交换机类型: direct, topic, fanout
+	Queue        string   `yaml:"queue" mapstructure:"queue"`                 // 队列名称
+	RoutingKey   string   `yaml:"routing_key" mapstructure:"routing_key"`     // 路由键
+	BindingKeys  []string `yaml:"binding_keys" mapstructure:"binding_keys"`   // 额外绑定到队列的路由模式（除 routing_key 外）
+	Durable      bool     `yaml:"durable" mapstructure:"durable"`             // 是否持久化
+	AutoDelete   bool     `yaml:"auto_delete" mapstructure:"auto_delete"`     // 是否自动删除
+	Concurrency  int      `yaml:"concurrency" mapstructure:"concurrency"`     // 消费者并发处理数，默认 1（按顺序处理）
+	MaxPriority  uint8    `yaml:"max_priority" mapstructure:"max_priority"`   // 队列最大优先级（x-max-priority，建议 1-10），0 表示不启用优先级
+	QueueType    string   `yaml:"queue_type" mapstructure:"queue_type"`       // 队列类型: classic, quorum，默认 classic；quorum 队列必须持久化且不能自动删除
+
+	DeliveryLimit      int    `yaml:"delivery_limit" mapstructure:"delivery_limit"`             // 处理失败的最大投递次数，达到后不再重新入队（进入死信交换机），0 表示不限制
+	DeadLetterExchange string `yaml:"dead_letter_exchange" mapstructure:"dead_letter_exchange"` // 死信交换机（x-dead-letter-exchange），超过投递次数或永久失败的消息转发到此；配置后自动声明为 fanout 交换机
+	DeadLetterQueue    string `yaml:"dead_letter_queue" mapstructure:"dead_letter_queue"`       // 绑定到死信交换机的队列，默认 <queue>.dlq，保存死信供排查与重放
+
+	// 队列容量限制，防止消费者卡住时队列无限增长耗尽 Broker 内存；0 表示不限制
+	MessageTTL     time.Duration `yaml:"message_ttl" mapstructure:"message_ttl"`           // 消息存活时间（x-message-ttl），过期消息被丢弃或进入死信
+	MaxLength      int64         `yaml:"max_length" mapstructure:"max_length"`             // 队列最大消息数（x-max-length）
+	MaxLengthBytes int64         `yaml:"max_length_bytes" mapstructure:"max_length_bytes"` // 队列最大消息体总字节数（x-max-length-bytes）
+	Overflow       string        `yaml:"overflow" mapstructure:"overflow"`                 // 超出长度限制时的策略: drop-head（丢弃最旧消息，默认）, reject-publish（拒绝新消息）
+
+	DrainTimeout time.Duration `yaml:"drain_timeout" mapstructure:"drain_timeout"` // 关闭时等待处理中消息完成的时长，默认10s
+
+	// AutoAck 消费时是否由 Broker 自动确认（Consume/ConsumeDeliveries 生效），默认 false
+	// false: 至少一次（at-least-once），处理成功后手动 Ack，失败 Nack 重新入队，处理函数需幂等
+	// true: 至多一次（at-most-once），投递即确认，处理失败或进程崩溃时消息丢失，适合可丢弃的通知类消息；delivery_limit 不再生效
+	AutoAck bool `yaml:"auto_ack" mapstructure:"auto_ack"`
+
+	SchemaVersion string `yaml:"schema_version" mapstructure:"schema_version"` // 发布消息时写入 schema_version 消息头的格式版本，为空时不写入
+}
+
+// bindingKeys 获取队列需要绑定的全部路由键（去重）
+func (c *RabbitMQConfig) bindingKeys() []string {
+	keys := make([]string, 0, len(c.BindingKeys)+1)
+	seen := make(map[string]bool, len(c.BindingKeys)+1)
+	for _, key := range append([]string{c.RoutingKey}, c.BindingKeys...) {
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// 队列溢出策略
+const (
+	OverflowDropHead      = "drop-head"      // 丢弃队首（最旧）的消息
+	OverflowRejectPublish = "reject-publish" // 拒绝新发布的消息，开启 confirm 的发布者会收到 nack
+)
+
+// 队列类型
+const (
+	QueueTypeClassic = "classic" // 经典队列
+	QueueTypeQuorum  = "quorum"  // 仲裁队列，基于 Raft 复制，可靠性更高
+)
+
+// Validate 校验配置组合是否合法
+// 在连接 Broker 之前调用，避免声明时才因参数冲突失败
+func (c *RabbitMQConfig) Validate() error {
+	switch c.QueueType {
+	case "", QueueTypeClassic:
+	case QueueTypeQuorum:
+		if c.Queue != "" && !c.Durable {
+			return fmt.Errorf("quorum queue %s must be durable", c.Queue)
+		}
+		if c.Queue != "" && c.AutoDelete {
+			return fmt.Errorf("quorum queue %s cannot be auto-deleted", c.Queue)
+		}
+	default:
+		return fmt.Errorf("unsupported queue type: %s", c.QueueType)
+	}
+	if c.DeliveryLimit < 0 {
+		return fmt.Errorf("delivery limit cannot be negative: %d", c.DeliveryLimit)
+	}
+	if c.MessageTTL < 0 || c.MaxLength < 0 || c.MaxLengthBytes < 0 {
+		return fmt.Errorf("message ttl and max length cannot be negative")
+	}
+	switch c.Overflow {
+	case "", OverflowDropHead, OverflowRejectPublish:
+	default:
+		return fmt.Errorf("unsupported overflow policy: %s", c.Overflow)
+	}
+	return nil
+}
+
+// queueArguments 队列声明的额外参数
+// 优先级只对声明时带 x-max-priority 的 classic/quorum 队列生效；已存在的队列参数不同会导致声明失败，需要删除重建
+func (c *RabbitMQConfig) queueArguments() amqp.Table {
+	args := amqp.Table{}
+	if c.MaxPriority > 0 {
+		args["x-max-priority"] = int32(c.MaxPriority)
+	}
+	if c.QueueType != "" {
+		args["x-queue-type"] = c.QueueType
+	}
+	if c.DeadLetterExchange != "" {
+		args["x-dead-letter-exchange"] = c.DeadLetterExchange
+	}
+	if c.MessageTTL > 0 {
+		args["x-message-ttl"] = c.MessageTTL.Milliseconds()
+	}
+	if c.MaxLength > 0 {
+		args["x-max-length"] = c.MaxLength
+	}
+	if c.MaxLengthBytes > 0 {
+		args["x-max-length-bytes"] = c.MaxLengthBytes
+	}
+	if c.Overflow != "" {
+		args["x-overflow"] = c.Overflow
+	}
+	if len(args) == 0 {
+		return nil
+	}
+	return args
+}
+
+// deadLetterQueue 获取死信队列名称，未配置死信交换机时返回空
+func (c *RabbitMQConfig) deadLetterQueue() string {
+	if c.DeadLetterExchange == "" {
+		return ""
+	}
+	if c.DeadLetterQueue != "" {
+		return c.DeadLetterQueue
+	}
+	if c.Queue == "" {
+		return ""
+	}
+	return c.Queue + ".dlq"
+}
+
+// declareDeadLetter 声明死信交换机与死信队列并绑定
+// 死信交换机使用 fanout 类型，死信保留原路由键，不需要逐个绑定；队列始终持久化，避免 Broker 重启丢失死信
+func declareDeadLetter(channel *amqp.Channel, cfg *RabbitMQConfig) error {
+	if cfg.DeadLetterExchange == "" {
+		return nil
+	}
+	if err := channel.ExchangeDeclare(cfg.DeadLetterExchange, amqp.ExchangeFanout, true, false, false, false, nil); err != nil {
+		return fmt.Errorf("failed to declare dead letter exchange: %w", err)
+	}
+	queue := cfg.deadLetterQueue()
+	if queue == "" {
+		return nil
+	}
+	if _, err := channel.QueueDeclare(queue, true, false, false, false, nil); err != nil {
+		return fmt.Errorf("failed to declare dead letter queue: %w", err)
+	}
+	if err := channel.QueueBind(queue, "", cfg.DeadLetterExchange, false, nil); err != nil {
+		return fmt.Errorf("failed to bind dead letter queue: %w", err)
+	}
+	return nil
 }
 
 // RabbitMQClient RabbitMQ 客户端封装
@@ -28,6 +176,10 @@ type RabbitMQClient struct {
 // NewRabbitMQClient 创建新的 RabbitMQ 客户端
 // 使用工厂模式创建客户端实例
 func NewRabbitMQClient(cfg *RabbitMQConfig) (*RabbitMQClient, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid rabbitmq config: %w", err)
+	}
+
 	// 连接到 RabbitMQ
 	conn, err := amqp.Dial(cfg.URL)
 	if err != nil {
@@ -59,35 +211,49 @@ func NewRabbitMQClient(cfg *RabbitMQConfig) (*RabbitMQClient, error) {
 		}
 	}
 	
+	// 声明死信交换机与死信队列，需在业务队列之前声明
+	if err = declareDeadLetter(channel, cfg); err != nil {
+		channel.Close()
+		conn.Close()
+		return nil, err
+	}
+
 	// 声明队列
 	if cfg.Queue != "" {
 		_, err = channel.QueueDeclare(
-			cfg.Queue,      // 队列名称
-			cfg.Durable,    // 是否持久化
-			cfg.AutoDelete, // 是否自动删除
-			false,          // 是否独占
-			false,          // 是否等待服务器确认
-			nil,            // 额外参数
+			cfg.Queue,            // 队列名称
+			cfg.Durable,          // 是否持久化
+			cfg.AutoDelete,       // 是否自动删除
+			false,                // 是否独占
+			false,                // 是否等待服务器确认
+			cfg.queueArguments(), // 额外参数（优先级等）
 		)
 		if err != nil {
 			channel.Close()
 			conn.Close()
+			var amqpErr *amqp.Error
+			if errors.As(err, &amqpErr) && amqpErr.Code == amqp.PreconditionFailed {
+				// 已存在的队列参数（队列类型、优先级、容量限制等）与配置不一致，RabbitMQ 不支持原地修改
+				return nil, fmt.Errorf("failed to declare queue %s: existing queue has different arguments (e.g. classic vs quorum), delete or migrate it first: %w", cfg.Queue, err)
+			}
 			return nil, fmt.Errorf("failed to declare queue: %w", err)
 		}
 		
 		// 绑定队列到交换机
 		if cfg.Exchange != "" {
-			err = channel.QueueBind(
-				cfg.Queue,      // 队列名称
-				cfg.RoutingKey, // 路由键
-				cfg.Exchange,   // 交换机名称
-				false,          // 是否等待服务器确认
-				nil,            // 额外参数
-			)
-			if err != nil {
-				channel.Close()
-				conn.Close()
-				return nil, fmt.Errorf("failed to bind queue: %w", err)
+			for _, key := range cfg.bindingKeys() {
+				err = channel.QueueBind(
+					cfg.Queue,    // 队列名称
+					key,          // 路由键
+					cfg.Exchange, // 交换机名称
+					false,        // 是否等待服务器确认
+					nil,          // 额外参数
+				)
+				if err != nil {
+					channel.Close()
+					conn.Close()
+					return nil, fmt.Errorf("failed to bind queue with key %s: %w", key, err)
+				}
 			}
 		}
 	}
@@ -127,6 +293,18 @@ func (r *RabbitMQClient) IsConnected() bool {
 	return r.conn != nil && !r.conn.IsClosed()
 }
 
+// PingContext 检查连接是否正常
+// AMQP 连接由心跳维持，这里只检查连接状态，不产生网络往返
+func (r *RabbitMQClient) PingContext(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if !r.IsConnected() {
+		return fmt.Errorf("rabbitmq connection is closed")
+	}
+	return nil
+}
+
 // MustNewRabbitMQClient 创建 RabbitMQ 客户端,失败则 panic
 // 适用于服务启动阶段
 func MustNewRabbitMQClient(cfg *RabbitMQConfig) *RabbitMQClient {
@@ -0,0 +1,141 @@
+package mq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+func TestQueueArguments_Quorum(t *testing.T) {
+	cfg := &RabbitMQConfig{
+		Queue:              "nice_service_queue",
+		Durable:            true,
+		QueueType:          QueueTypeQuorum,
+		DeadLetterExchange: "microservice_events.dlx",
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+
+	args := cfg.queueArguments()
+	if args["x-queue-type"] != QueueTypeQuorum {
+		t.Fatalf("want x-queue-type=quorum, got %#v", args["x-queue-type"])
+	}
+	if args["x-dead-letter-exchange"] != "microservice_events.dlx" {
+		t.Fatalf("want x-dead-letter-exchange set, got %#v", args["x-dead-letter-exchange"])
+	}
+}
+
+func TestDeadLetterQueue(t *testing.T) {
+	cases := []struct {
+		name string
+		cfg  RabbitMQConfig
+		want string
+	}{
+		{"no dead letter exchange", RabbitMQConfig{Queue: "nice_service_queue"}, ""},
+		{"default name", RabbitMQConfig{Queue: "nice_service_queue", DeadLetterExchange: "dlx"}, "nice_service_queue.dlq"},
+		{"configured name", RabbitMQConfig{Queue: "nice_service_queue", DeadLetterExchange: "dlx", DeadLetterQueue: "failed"}, "failed"},
+		{"publisher without queue", RabbitMQConfig{DeadLetterExchange: "dlx"}, ""},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := c.cfg.deadLetterQueue(); got != c.want {
+				t.Fatalf("want %q, got %q", c.want, got)
+			}
+		})
+	}
+}
+
+func TestValidate_RejectsInvalidQuorumConfig(t *testing.T) {
+	cases := []struct {
+		name string
+		cfg  RabbitMQConfig
+	}{
+		{"not durable", RabbitMQConfig{Queue: "q", QueueType: QueueTypeQuorum}},
+		{"auto delete", RabbitMQConfig{Queue: "q", QueueType: QueueTypeQuorum, Durable: true, AutoDelete: true}},
+		{"unknown type", RabbitMQConfig{Queue: "q", QueueType: "stream-ish", Durable: true}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if err := c.cfg.Validate(); err == nil {
+				t.Fatal("want validation error")
+			}
+			if _, err := NewRabbitMQClient(&c.cfg); err == nil {
+				t.Fatal("want NewRabbitMQClient to reject config before dialing")
+			}
+		})
+	}
+}
+
+func TestQueueArguments_LengthLimits(t *testing.T) {
+	cfg := &RabbitMQConfig{
+		MessageTTL:     time.Hour,
+		MaxLength:      10000,
+		MaxLengthBytes: 64 << 20,
+		Overflow:       OverflowRejectPublish,
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+
+	args := cfg.queueArguments()
+	want := amqp.Table{
+		"x-message-ttl":      int64(3600000),
+		"x-max-length":       int64(10000),
+		"x-max-length-bytes": int64(64 << 20),
+		"x-overflow":         OverflowRejectPublish,
+	}
+	for k, v := range want {
+		if args[k] != v {
+			t.Errorf("want %s=%#v, got %#v", k, v, args[k])
+		}
+	}
+
+	if err := (&RabbitMQConfig{Overflow: "drop-tail"}).Validate(); err == nil {
+		t.Error("want unknown overflow policy rejected")
+	}
+}
+
+// TestMaxLength_RejectsPublishBeyondLimit 需要真实的 RabbitMQ，设置 RABBITMQ_TEST_URL 后运行
+func TestMaxLength_RejectsPublishBeyondLimit(t *testing.T) {
+	url := os.Getenv("RABBITMQ_TEST_URL")
+	if url == "" {
+		t.Skip("RABBITMQ_TEST_URL not set")
+	}
+
+	queue := fmt.Sprintf("max_length_test_%d", time.Now().UnixNano())
+	client, err := NewRabbitMQClient(&RabbitMQConfig{
+		URL:        url,
+		Queue:      queue,
+		AutoDelete: true,
+		MaxLength:  3,
+		Overflow:   OverflowRejectPublish,
+	})
+	if err != nil {
+		t.Fatalf("NewRabbitMQClient() error = %v", err)
+	}
+	defer client.Close()
+	defer client.GetChannel().QueueDelete(queue, false, false, false)
+
+	// 使用 confirm 模式批量发布，第 N+1 条会被 Broker nack
+	msgs := make([]OutgoingMessage, 4)
+	for i := range msgs {
+		msgs[i] = OutgoingMessage{RoutingKey: queue, Body: []byte(fmt.Sprint(i))}
+	}
+	err = NewRabbitMQPublisher(client).PublishMany(context.Background(), msgs)
+
+	var batchErr *PublishManyError
+	if !errors.As(err, &batchErr) {
+		t.Fatalf("want *PublishManyError, got %v", err)
+	}
+	if got := batchErr.FailedIndexes(); len(got) != 1 || got[0] != 3 {
+		t.Fatalf("want only message 3 rejected, got %v", got)
+	}
+}
@@ -0,0 +1,124 @@
+package mq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// defaultRPCTimeout 未指定超时时等待回复的默认时长
+const defaultRPCTimeout = 10 * time.Second
+
+var (
+	// ErrRPCTimeout 在超时时间内没有收到对应的回复
+	ErrRPCTimeout = errors.New("rpc reply timeout")
+	// ErrNoReplyTo 请求消息没有 ReplyTo，无法回复
+	ErrNoReplyTo = errors.New("request has no reply-to queue")
+)
+
+// rpcChannel RPC 请求所需的通道操作，*amqp.Channel 实现该接口
+type rpcChannel interface {
+	QueueDeclare(name string, durable, autoDelete, exclusive, noWait bool, args amqp.Table) (amqp.Queue, error)
+	Consume(queue, consumer string, autoAck, exclusive, noLocal, noWait bool, args amqp.Table) (<-chan amqp.Delivery, error)
+	PublishWithContext(ctx context.Context, exchange, key string, mandatory, immediate bool, msg amqp.Publishing) error
+}
+
+// PublishRPC 以请求/回复方式发布消息并等待回复
+// 每次调用使用独立通道声明临时的独占回复队列，设置 ReplyTo 和 CorrelationId 后发布，
+// 只接受 CorrelationId 匹配的回复；超过 timeout（<= 0 时默认10s）或 ctx 取消时返回错误
+func (p *RabbitMQPublisher) PublishRPC(ctx context.Context, routingKey string, message []byte, timeout time.Duration) ([]byte, error) {
+	if !p.client.IsConnected() {
+		return nil, fmt.Errorf("rabbitmq connection is closed")
+	}
+
+	// 独立通道关闭后临时回复队列随之删除
+	channel, err := p.client.conn.Channel()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open channel: %w", err)
+	}
+	defer channel.Close()
+
+	return publishRPC(ctx, channel, p.client.config.Exchange, routingKey, message, timeout, p.client.config.SchemaVersion)
+}
+
+// Reply 回复 RPC 请求
+// 通过默认交换机发送到请求的 ReplyTo 队列，并带上请求的 CorrelationId
+func (p *RabbitMQPublisher) Reply(ctx context.Context, request amqp.Delivery, message []byte) error {
+	if !p.client.IsConnected() {
+		return fmt.Errorf("rabbitmq connection is closed")
+	}
+	return reply(ctx, p.client.channel, request, message, p.client.config.SchemaVersion)
+}
+
+// publishRPC 发布请求并等待对应的回复
+// schemaVersion 非空时写入 schema_version 消息头，与普通发布一致
+func publishRPC(ctx context.Context, ch rpcChannel, exchange, routingKey string, message []byte, timeout time.Duration, schemaVersion string) ([]byte, error) {
+	if timeout <= 0 {
+		timeout = defaultRPCTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	// 服务端命名的临时队列：独占、自动删除
+	queue, err := ch.QueueDeclare("", false, true, true, false, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to declare reply queue: %w", err)
+	}
+
+	replies, err := ch.Consume(queue.Name, "", true, true, false, false, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to consume reply queue: %w", err)
+	}
+
+	correlationID := uuid.New().String()
+	err = ch.PublishWithContext(ctx, exchange, routingKey, false, false, amqp.Publishing{
+		ContentType:   "application/json",
+		CorrelationId: correlationID,
+		ReplyTo:       queue.Name,
+		Headers:       withSchemaVersion(nil, schemaVersion), // 格式版本，供服务端选择解码器
+		Body:          message,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to publish rpc request: %w", err)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return nil, fmt.Errorf("%w: %s", ErrRPCTimeout, routingKey)
+			}
+			return nil, ctx.Err()
+		case delivery, ok := <-replies:
+			if !ok {
+				return nil, fmt.Errorf("reply queue closed before reply received")
+			}
+			// 忽略迟到或不属于本次请求的回复
+			if delivery.CorrelationId == correlationID {
+				return delivery.Body, nil
+			}
+		}
+	}
+}
+
+// reply 发送 RPC 回复，schemaVersion 非空时写入 schema_version 消息头
+func reply(ctx context.Context, ch rpcChannel, request amqp.Delivery, message []byte, schemaVersion string) error {
+	if request.ReplyTo == "" {
+		return ErrNoReplyTo
+	}
+
+	err := ch.PublishWithContext(ctx, "", request.ReplyTo, false, false, amqp.Publishing{
+		ContentType:   "application/json",
+		CorrelationId: request.CorrelationId,
+		Headers:       withSchemaVersion(nil, schemaVersion),
+		Body:          message,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to publish rpc reply: %w", err)
+	}
+	return nil
+}
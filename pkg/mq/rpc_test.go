@@ -0,0 +1,136 @@
+package mq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// fakeBroker 内存中的 Broker：默认交换机按队列名投递，其余发布进入 requests
+type fakeBroker struct {
+	mu       sync.Mutex
+	queues   map[string]chan amqp.Delivery
+	requests chan amqp.Delivery
+}
+
+func newFakeBroker() *fakeBroker {
+	return &fakeBroker{
+		queues:   make(map[string]chan amqp.Delivery),
+		requests: make(chan amqp.Delivery, 10),
+	}
+}
+
+func (b *fakeBroker) QueueDeclare(name string, durable, autoDelete, exclusive, noWait bool, args amqp.Table) (amqp.Queue, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if name == "" {
+		name = fmt.Sprintf("amq.gen-%d", len(b.queues))
+	}
+	b.queues[name] = make(chan amqp.Delivery, 10)
+	return amqp.Queue{Name: name}, nil
+}
+
+func (b *fakeBroker) Consume(queue, consumer string, autoAck, exclusive, noLocal, noWait bool, args amqp.Table) (<-chan amqp.Delivery, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.queues[queue], nil
+}
+
+func (b *fakeBroker) PublishWithContext(ctx context.Context, exchange, key string, mandatory, immediate bool, msg amqp.Publishing) error {
+	delivery := amqp.Delivery{
+		Exchange:      exchange,
+		RoutingKey:    key,
+		CorrelationId: msg.CorrelationId,
+		ReplyTo:       msg.ReplyTo,
+		Headers:       msg.Headers,
+		Body:          msg.Body,
+	}
+	if exchange != "" {
+		b.requests <- delivery
+		return nil
+	}
+
+	b.mu.Lock()
+	queue, ok := b.queues[key]
+	b.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("no queue %s", key)
+	}
+	queue <- delivery
+	return nil
+}
+
+func TestPublishRPC_RoundTripMatchesCorrelationID(t *testing.T) {
+	broker := newFakeBroker()
+
+	// 服务端：先发送一条不相关的回复，再回复真正的请求
+	go func() {
+		request := <-broker.requests
+		stale := request
+		stale.CorrelationId = "someone-else"
+		_ = reply(context.Background(), broker, stale, []byte(`"stale"`), "")
+		_ = reply(context.Background(), broker, request, []byte(`"pong:`+string(request.Body)+`"`), "")
+	}()
+
+	got, err := publishRPC(context.Background(), broker, "demo_exchange", "rpc.ping", []byte("ping"), time.Second, "")
+	if err != nil {
+		t.Fatalf("publishRPC() error = %v", err)
+	}
+	if string(got) != `"pong:ping"` {
+		t.Fatalf("want correlated reply, got %s", got)
+	}
+}
+
+func TestPublishRPC_TimesOutWithoutReply(t *testing.T) {
+	broker := newFakeBroker()
+
+	start := time.Now()
+	_, err := publishRPC(context.Background(), broker, "demo_exchange", "rpc.ping", []byte("ping"), 50*time.Millisecond, "")
+	if !errors.Is(err, ErrRPCTimeout) {
+		t.Fatalf("want ErrRPCTimeout, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("want timeout honoured, took %v", elapsed)
+	}
+
+	request := <-broker.requests
+	if request.ReplyTo == "" || request.CorrelationId == "" {
+		t.Fatalf("want ReplyTo and CorrelationId set, got %+v", request)
+	}
+}
+
+func TestReply_RequiresReplyTo(t *testing.T) {
+	if err := reply(context.Background(), newFakeBroker(), amqp.Delivery{}, nil, ""); !errors.Is(err, ErrNoReplyTo) {
+		t.Fatalf("want ErrNoReplyTo, got %v", err)
+	}
+}
+
+func TestPublishRPC_SetsSchemaVersion(t *testing.T) {
+	broker := newFakeBroker()
+
+	go func() {
+		request := <-broker.requests
+		if got := SchemaVersion(request.Headers); got != "2" {
+			t.Errorf("want request schema version 2, got %s", got)
+		}
+		_ = reply(context.Background(), broker, request, []byte(`"pong"`), "2")
+	}()
+
+	if _, err := publishRPC(context.Background(), broker, "demo_exchange", "rpc.ping", []byte("ping"), time.Second, "2"); err != nil {
+		t.Fatalf("publishRPC() error = %v", err)
+	}
+
+	queue, _ := broker.QueueDeclare("", false, true, true, false, nil)
+	replies, _ := broker.Consume(queue.Name, "", true, true, false, false, nil)
+	if err := reply(context.Background(), broker, amqp.Delivery{ReplyTo: queue.Name}, []byte(`"pong"`), "2"); err != nil {
+		t.Fatalf("reply() error = %v", err)
+	}
+	if got := SchemaVersion((<-replies).Headers); got != "2" {
+		t.Fatalf("want reply schema version 2, got %s", got)
+	}
+}
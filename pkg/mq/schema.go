@@ -0,0 +1,84 @@
+package mq
+
+import (
+	"errors"
+	"fmt"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// SchemaVersionHeader 消息体格式版本的消息头
+const SchemaVersionHeader = "schema_version"
+
+// DefaultSchemaVersion 未携带版本头时假定的版本
+// 引入版本头之前发布的消息均为 v1 格式
+const DefaultSchemaVersion = "1"
+
+// ErrUnsupportedSchemaVersion 消费者不支持消息的格式版本
+var ErrUnsupportedSchemaVersion = errors.New("unsupported message schema version")
+
+// SchemaVersion 读取投递的格式版本，缺失时返回 DefaultSchemaVersion
+// 兼容其他客户端以整数形式写入的版本头
+func SchemaVersion(headers amqp.Table) string {
+	value, ok := headers[SchemaVersionHeader]
+	if !ok || value == nil {
+		return DefaultSchemaVersion
+	}
+	if version := fmt.Sprint(value); version != "" {
+		return version
+	}
+	return DefaultSchemaVersion
+}
+
+// withSchemaVersion 在消息头中写入格式版本
+// version 为空或调用方已显式设置版本时保持原样；返回新的 Table，不修改入参
+func withSchemaVersion(headers amqp.Table, version string) amqp.Table {
+	if version == "" {
+		return headers
+	}
+	if _, ok := headers[SchemaVersionHeader]; ok {
+		return headers
+	}
+
+	merged := make(amqp.Table, len(headers)+1)
+	for k, v := range headers {
+		merged[k] = v
+	}
+	merged[SchemaVersionHeader] = version
+	return merged
+}
+
+// VersionedDecoder 按格式版本分发的消息解码器
+// 滚动升级期间新旧格式的消息会同时存在，每个版本注册一个解码函数，统一解码为最新的内存结构
+type VersionedDecoder[T any] struct {
+	decoders map[string]func(body []byte) (*T, error)
+}
+
+// NewVersionedDecoder 创建按版本分发的解码器
+func NewVersionedDecoder[T any]() *VersionedDecoder[T] {
+	return &VersionedDecoder[T]{
+		decoders: make(map[string]func(body []byte) (*T, error)),
+	}
+}
+
+// Register 注册指定版本的解码函数
+func (d *VersionedDecoder[T]) Register(version string, decode func(body []byte) (*T, error)) *VersionedDecoder[T] {
+	d.decoders[version] = decode
+	return d
+}
+
+// Decode 根据投递的格式版本解码消息体
+// 版本未注册时返回 ErrUnsupportedSchemaVersion，消息体无法解码时返回 Permanent 错误，两者都不应重新入队
+func (d *VersionedDecoder[T]) Decode(delivery amqp.Delivery) (*T, error) {
+	version := SchemaVersion(delivery.Headers)
+	decode, ok := d.decoders[version]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedSchemaVersion, version)
+	}
+
+	msg, err := decode(delivery.Body)
+	if err != nil {
+		return nil, Permanent(fmt.Errorf("failed to decode schema version %s: %w", version, err))
+	}
+	return msg, nil
+}
@@ -0,0 +1,69 @@
+package netutil
+
+import (
+	"fmt"
+	"net/netip"
+	"strings"
+)
+
+// IPAllowList 来源 IP 白名单
+// 条目支持单个 IP（10.0.0.1）和 CIDR（10.0.0.0/8）；空白名单允许所有来源
+type IPAllowList struct {
+	prefixes []netip.Prefix
+}
+
+// ParseIPAllowList 解析白名单条目，任一条目无效时返回错误
+func ParseIPAllowList(entries []string) (*IPAllowList, error) {
+	prefixes := make([]netip.Prefix, 0, len(entries))
+	for _, entry := range entries {
+		prefix, err := parseIPPrefix(entry)
+		if err != nil {
+			return nil, err
+		}
+		prefixes = append(prefixes, prefix)
+	}
+	return &IPAllowList{prefixes: prefixes}, nil
+}
+
+// Empty 白名单是否为空（不做限制）
+func (l *IPAllowList) Empty() bool {
+	return len(l.prefixes) == 0
+}
+
+// Allows 判断来源 IP 是否在白名单内，无法解析的 IP 只在白名单为空时允许
+func (l *IPAllowList) Allows(ip string) bool {
+	if l.Empty() {
+		return true
+	}
+
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, prefix := range l.prefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseIPPrefix 解析白名单条目，单个 IP 视为只包含自身的网段
+func parseIPPrefix(entry string) (netip.Prefix, error) {
+	entry = strings.TrimSpace(entry)
+	if strings.Contains(entry, "/") {
+		prefix, err := netip.ParsePrefix(entry)
+		if err != nil {
+			return netip.Prefix{}, fmt.Errorf("invalid ip allow list entry %q: %w", entry, err)
+		}
+		return prefix.Masked(), nil
+	}
+
+	addr, err := netip.ParseAddr(entry)
+	if err != nil {
+		return netip.Prefix{}, fmt.Errorf("invalid ip allow list entry %q: %w", entry, err)
+	}
+	addr = addr.Unmap()
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
@@ -0,0 +1,57 @@
+// Package netutil 提供网络监听相关的辅助工具
+package netutil
+
+import (
+	"net"
+	"sync"
+
+	"github.com/alfredchaos/demo/pkg/log"
+	"go.uber.org/zap"
+)
+
+// LimitListener 限制同时保持的连接数
+// 达到上限后新连接被接受后立即关闭（而不是停止 Accept 让其在内核队列中堆积），
+// 客户端可以尽快失败并重试其他实例；已有连接关闭后释放额度
+func LimitListener(l net.Listener, max int) net.Listener {
+	return &limitListener{Listener: l, slots: make(chan struct{}, max)}
+}
+
+// limitListener 连接数受限的监听器
+type limitListener struct {
+	net.Listener
+	slots chan struct{}
+}
+
+// Accept 接受连接，超过上限的连接直接关闭并继续等待下一个连接
+func (l *limitListener) Accept() (net.Conn, error) {
+	for {
+		conn, err := l.Listener.Accept()
+		if err != nil {
+			return nil, err
+		}
+
+		select {
+		case l.slots <- struct{}{}:
+			return &limitConn{Conn: conn, release: func() { <-l.slots }}, nil
+		default:
+			log.Warn("connection refused: too many connections",
+				zap.String("remote_addr", conn.RemoteAddr().String()),
+				zap.Int("max_connections", cap(l.slots)))
+			_ = conn.Close()
+		}
+	}
+}
+
+// limitConn 关闭时释放额度的连接
+type limitConn struct {
+	net.Conn
+	releaseOnce sync.Once
+	release     func()
+}
+
+// Close 关闭连接并释放额度，重复关闭只释放一次
+func (c *limitConn) Close() error {
+	err := c.Conn.Close()
+	c.releaseOnce.Do(c.release)
+	return err
+}
@@ -0,0 +1,166 @@
+package pagination
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	apperrors "github.com/alfredchaos/demo/pkg/errors"
+)
+
+// ErrInvalidCursor 游标格式错误、签名校验失败或缺少排序键，映射为参数错误
+var ErrInvalidCursor = apperrors.NewCoded(apperrors.ErrInvalidParams, "invalid pagination cursor")
+
+// cursorSeparator 载荷与签名之间的分隔符（base64url 字符集中不包含 '.'）
+const cursorSeparator = "."
+
+// Codec 分页游标编解码器
+// 游标为 base64url(JSON)，启用 HMAC 后追加 ".base64url(签名)" 用于防篡改
+type Codec struct {
+	secret []byte
+}
+
+// Option 编解码器配置选项
+type Option func(*Codec)
+
+// WithHMAC 启用 HMAC-SHA256 签名，解码时拒绝签名不匹配的游标
+func WithHMAC(secret []byte) Option {
+	return func(c *Codec) {
+		c.secret = secret
+	}
+}
+
+// NewCodec 创建游标编解码器
+func NewCodec(opts ...Option) *Codec {
+	c := &Codec{}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Encode 将排序键编码为不透明的游标
+func (c *Codec) Encode(fields map[string]interface{}) (string, error) {
+	payload, err := json.Marshal(fields)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal cursor: %w", err)
+	}
+
+	token := base64.RawURLEncoding.EncodeToString(payload)
+	if len(c.secret) > 0 {
+		token += cursorSeparator + base64.RawURLEncoding.EncodeToString(c.sign(payload))
+	}
+	return token, nil
+}
+
+// Decode 解析游标并返回排序键
+// 数值以 json.Number 返回，避免大整数丢失精度
+func (c *Codec) Decode(token string) (map[string]interface{}, error) {
+	encoded, signature, signed := strings.Cut(token, cursorSeparator)
+	if len(c.secret) > 0 && !signed {
+		return nil, fmt.Errorf("%w: missing signature", ErrInvalidCursor)
+	}
+	if len(c.secret) == 0 && signed {
+		return nil, fmt.Errorf("%w: unexpected signature", ErrInvalidCursor)
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+
+	if signed {
+		mac, err := base64.RawURLEncoding.DecodeString(signature)
+		if err != nil || !hmac.Equal(mac, c.sign(payload)) {
+			return nil, fmt.Errorf("%w: signature mismatch", ErrInvalidCursor)
+		}
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(payload))
+	decoder.UseNumber()
+
+	var fields map[string]interface{}
+	if err := decoder.Decode(&fields); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+	if fields == nil {
+		return nil, fmt.Errorf("%w: empty payload", ErrInvalidCursor)
+	}
+	return fields, nil
+}
+
+// sign 计算载荷的 HMAC-SHA256 签名
+func (c *Codec) sign(payload []byte) []byte {
+	mac := hmac.New(sha256.New, c.secret)
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
+
+var (
+	defaultCodec   = NewCodec()
+	defaultCodecMu sync.RWMutex
+)
+
+// SetDefaultCodec 设置包级默认编解码器
+// 应在服务启动阶段调用，例如配置 HMAC 密钥后替换默认实现
+func SetDefaultCodec(c *Codec) {
+	defaultCodecMu.Lock()
+	defer defaultCodecMu.Unlock()
+	defaultCodec = c
+}
+
+// getDefaultCodec 获取包级默认编解码器
+func getDefaultCodec() *Codec {
+	defaultCodecMu.RLock()
+	defer defaultCodecMu.RUnlock()
+	return defaultCodec
+}
+
+// EncodeCursor 使用默认编解码器编码游标
+// fields 只应包含可 JSON 序列化的排序键；序列化失败时返回错误，而不是会被当作第一页的空游标
+func EncodeCursor(fields map[string]interface{}) (string, error) {
+	return getDefaultCodec().Encode(fields)
+}
+
+// DecodeCursor 使用默认编解码器解码游标
+func DecodeCursor(token string) (map[string]interface{}, error) {
+	return getDefaultCodec().Decode(token)
+}
+
+// 按 (created_at, id) 倒序键集分页时游标中的排序键
+const (
+	createdAtKey = "created_at"
+	idKey        = "id"
+)
+
+// EncodeCreatedAtCursor 编码按 (created_at, id) 倒序分页的游标
+func EncodeCreatedAtCursor(createdAt time.Time, id string) (string, error) {
+	return EncodeCursor(map[string]interface{}{
+		createdAtKey: createdAt.Format(time.RFC3339Nano),
+		idKey:        id,
+	})
+}
+
+// DecodeCreatedAtCursor 解析 EncodeCreatedAtCursor 生成的游标
+// 排序键缺失或格式错误时同样返回包装了 ErrInvalidCursor 的错误
+func DecodeCreatedAtCursor(token string) (time.Time, string, error) {
+	fields, err := DecodeCursor(token)
+	if err != nil {
+		return time.Time{}, "", err
+	}
+
+	rawCreatedAt, _ := fields[createdAtKey].(string)
+	id, _ := fields[idKey].(string)
+	createdAt, err := time.Parse(time.RFC3339Nano, rawCreatedAt)
+	if err != nil || id == "" {
+		return time.Time{}, "", fmt.Errorf("%w: missing sort keys", ErrInvalidCursor)
+	}
+
+	return createdAt, id, nil
+}
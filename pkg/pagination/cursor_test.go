@@ -0,0 +1,125 @@
+package pagination
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	apperrors "github.com/alfredchaos/demo/pkg/errors"
+)
+
+func TestCursor_RoundTrip(t *testing.T) {
+	fields := map[string]interface{}{
+		"created_at": "2024-01-01T00:00:00Z",
+		"id":         "u1",
+		"seq":        int64(9007199254740993),
+	}
+
+	for name, codec := range map[string]*Codec{
+		"plain":  NewCodec(),
+		"signed": NewCodec(WithHMAC([]byte("secret"))),
+	} {
+		t.Run(name, func(t *testing.T) {
+			token, err := codec.Encode(fields)
+			if err != nil {
+				t.Fatalf("encode: %v", err)
+			}
+
+			got, err := codec.Decode(token)
+			if err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if got["created_at"] != fields["created_at"] || got["id"] != fields["id"] {
+				t.Fatalf("unexpected fields: %v", got)
+			}
+			// 大整数不应丢失精度
+			if got["seq"] != json.Number("9007199254740993") {
+				t.Fatalf("want exact seq, got %v", got["seq"])
+			}
+		})
+	}
+}
+
+func TestCursor_RejectsTamperedTokens(t *testing.T) {
+	codec := NewCodec(WithHMAC([]byte("secret")))
+	token, err := codec.Encode(map[string]interface{}{"id": "u1"})
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	forged, _ := NewCodec().Encode(map[string]interface{}{"id": "u2"})
+	otherKey, _ := NewCodec(WithHMAC([]byte("other"))).Encode(map[string]interface{}{"id": "u1"})
+
+	tests := map[string]string{
+		"forged payload":    forged + token[len(forged):],
+		"missing signature": forged,
+		"wrong key":         otherKey,
+		"garbage":           "!!!",
+		"empty":             "",
+	}
+
+	for name, tampered := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := codec.Decode(tampered); !errors.Is(err, ErrInvalidCursor) {
+				t.Fatalf("want ErrInvalidCursor, got %v", err)
+			}
+		})
+	}
+}
+
+func TestCursor_DefaultCodec(t *testing.T) {
+	token, err := EncodeCursor(map[string]interface{}{"id": "u1"})
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+
+	got, err := DecodeCursor(token)
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if got["id"] != "u1" {
+		t.Fatalf("unexpected fields: %v", got)
+	}
+}
+
+func TestEncodeCursor_ReturnsMarshalError(t *testing.T) {
+	// 序列化失败不能返回空游标，否则调用方会把它当作“没有下一页”或第一页
+	token, err := EncodeCursor(map[string]interface{}{"id": make(chan int)})
+	if err == nil || token != "" {
+		t.Fatalf("want marshal error, got %q, %v", token, err)
+	}
+}
+
+func TestCreatedAtCursor(t *testing.T) {
+	createdAt := time.Date(2024, 1, 1, 8, 30, 0, 123456789, time.UTC)
+
+	token, err := EncodeCreatedAtCursor(createdAt, "b1")
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	gotCreatedAt, gotID, err := DecodeCreatedAtCursor(token)
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if !gotCreatedAt.Equal(createdAt) || gotID != "b1" {
+		t.Fatalf("unexpected sort keys: %v %q", gotCreatedAt, gotID)
+	}
+
+	// 格式合法但缺少排序键的游标同样是参数错误
+	for name, fields := range map[string]map[string]interface{}{
+		"missing id":         {"created_at": createdAt.Format(time.RFC3339Nano)},
+		"missing created_at": {"id": "b1"},
+		"bad created_at":     {"created_at": "yesterday", "id": "b1"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			token, err := EncodeCursor(fields)
+			if err != nil {
+				t.Fatalf("encode: %v", err)
+			}
+			_, _, err = DecodeCreatedAtCursor(token)
+			if !errors.Is(err, ErrInvalidCursor) || apperrors.CodeOf(err) != apperrors.ErrInvalidParams {
+				t.Fatalf("want ErrInvalidCursor with ErrInvalidParams code, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package pagination
+
+import (
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// DefaultPageSize 未指定页大小时的默认值
+	DefaultPageSize = 20
+	// MaxPageSize 单页最大条数，超过时截断
+	MaxPageSize = 100
+)
+
+// PageRequest 带分页参数的 gRPC 列表请求
+// protoc 为 page_size / page_token 字段生成的 Getter 即满足该接口
+type PageRequest interface {
+	GetPageSize() int32
+	GetPageToken() string
+}
+
+// NormalizePageRequest 统一校验 gRPC 列表请求的分页参数
+// 页大小为 0 时取 DefaultPageSize，超过 MaxPageSize 时截断，为负数时返回 InvalidArgument；
+// 游标使用默认编解码器校验，格式错误或签名不匹配时返回 InvalidArgument
+func NormalizePageRequest(req PageRequest) (limit int, cursor string, err error) {
+	pageSize := req.GetPageSize()
+	switch {
+	case pageSize < 0:
+		return 0, "", status.Errorf(codes.InvalidArgument, "page_size must not be negative, got %d", pageSize)
+	case pageSize == 0:
+		limit = DefaultPageSize
+	case pageSize > MaxPageSize:
+		limit = MaxPageSize
+	default:
+		limit = int(pageSize)
+	}
+
+	cursor = req.GetPageToken()
+	if cursor != "" {
+		if _, err := DecodeCursor(cursor); err != nil {
+			return 0, "", status.Error(codes.InvalidArgument, "invalid page_token")
+		}
+	}
+
+	return limit, cursor, nil
+}
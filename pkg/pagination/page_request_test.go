@@ -0,0 +1,62 @@
+package pagination
+
+import (
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// pageRequest 模拟 protoc 生成的列表请求
+type pageRequest struct {
+	pageSize  int32
+	pageToken string
+}
+
+func (r pageRequest) GetPageSize() int32   { return r.pageSize }
+func (r pageRequest) GetPageToken() string { return r.pageToken }
+
+func TestNormalizePageRequest_ClampsPageSize(t *testing.T) {
+	cases := []struct {
+		pageSize int32
+		want     int
+	}{
+		{0, DefaultPageSize},
+		{1, 1},
+		{50, 50},
+		{MaxPageSize, MaxPageSize},
+		{MaxPageSize + 1, MaxPageSize},
+		{10000, MaxPageSize},
+	}
+
+	for _, c := range cases {
+		limit, _, err := NormalizePageRequest(pageRequest{pageSize: c.pageSize})
+		if err != nil {
+			t.Fatalf("page_size=%d: unexpected error %v", c.pageSize, err)
+		}
+		if limit != c.want {
+			t.Errorf("page_size=%d: want limit %d, got %d", c.pageSize, c.want, limit)
+		}
+	}
+}
+
+func TestNormalizePageRequest_ValidatesCursor(t *testing.T) {
+	valid, err := EncodeCursor(map[string]interface{}{"id": "u1"})
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	_, cursor, err := NormalizePageRequest(pageRequest{pageToken: valid})
+	if err != nil || cursor != valid {
+		t.Fatalf("want valid cursor passed through, got %q, %v", cursor, err)
+	}
+
+	for _, req := range []pageRequest{
+		{pageToken: "not-a-cursor!"},
+		{pageToken: "bnVsbA"}, // base64("null")
+		{pageSize: -1},
+	} {
+		if _, _, err := NormalizePageRequest(req); status.Code(err) != codes.InvalidArgument {
+			t.Errorf("%+v: want InvalidArgument, got %v", req, err)
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package pagination
+
+import "strings"
+
+// SortField 排序字段，供仓储层拼接 ORDER BY
+// Field 必须在进入仓储层之前经过白名单校验，仓储层不会再做转义
+type SortField struct {
+	Field string // 排序列名
+	Desc  bool   // 是否降序
+}
+
+// String 返回 "field ASC" / "field DESC" 形式的排序子句
+func (s SortField) String() string {
+	if s.Desc {
+		return s.Field + " DESC"
+	}
+	return s.Field + " ASC"
+}
+
+// OrderClause 将多个排序字段拼接为 ORDER BY 子句（不含 ORDER BY 关键字）
+func OrderClause(fields []SortField) string {
+	parts := make([]string, 0, len(fields))
+	for _, f := range fields {
+		parts = append(parts, f.String())
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Filters 列表过滤条件，字段名到可选值的映射
+// 同一字段的多个值之间为 OR 关系（IN），不同字段之间为 AND 关系
+type Filters map[string][]string
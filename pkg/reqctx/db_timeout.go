@@ -0,0 +1,21 @@
+package reqctx
+
+import (
+	"context"
+	"time"
+)
+
+// DBTimeoutKey 数据库查询超时在 context 中的键
+const DBTimeoutKey contextKey = "db_timeout"
+
+// WithDBTimeout 为本次调用设置数据库查询超时，覆盖客户端配置的默认超时
+// 只能在上游截止时间内生效：ctx 剩余时间更短时以 ctx 为准
+func WithDBTimeout(ctx context.Context, timeout time.Duration) context.Context {
+	return context.WithValue(ctx, DBTimeoutKey, timeout)
+}
+
+// GetDBTimeout 从 context 中获取数据库查询超时
+func GetDBTimeout(ctx context.Context) (time.Duration, bool) {
+	timeout, ok := ctx.Value(DBTimeoutKey).(time.Duration)
+	return timeout, ok
+}
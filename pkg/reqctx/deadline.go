@@ -0,0 +1,47 @@
+package reqctx
+
+import (
+	"context"
+	"strconv"
+	"time"
+)
+
+// DeadlineBudgetKey 剩余时间预算（毫秒）在 HTTP 头和 gRPC metadata 中的键
+// 每一跳读取上游预算并扣除余量后传给下游，保证整条调用链不超过客户端的总截止时间
+const DeadlineBudgetKey = "X-Deadline-Budget-Ms"
+
+// RemainingBudget 计算 ctx 剩余的时间预算并扣除 headroom
+// ctx 没有截止时间时返回 false；预算耗尽时返回值 <= 0
+func RemainingBudget(ctx context.Context, headroom time.Duration) (time.Duration, bool) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return 0, false
+	}
+	return time.Until(deadline) - headroom, true
+}
+
+// WithDownstreamDeadline 为下一跳创建截止时间：上游截止时间扣除 headroom，为本跳处理响应留出余量，
+// 避免下游截止时间与上游相同而总是先在上游超时
+// 基于上游截止时间计算，不受 time.Now 漂移影响；ctx 没有截止时间时原样返回
+func WithDownstreamDeadline(ctx context.Context, headroom time.Duration) (context.Context, context.CancelFunc) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return ctx, func() {}
+	}
+	return context.WithDeadline(ctx, deadline.Add(-headroom))
+}
+
+// FormatBudget 将时间预算格式化为毫秒字符串
+func FormatBudget(budget time.Duration) string {
+	return strconv.FormatInt(budget.Milliseconds(), 10)
+}
+
+// ParseBudget 解析毫秒字符串形式的时间预算
+// 格式非法或预算为负数时返回 false
+func ParseBudget(value string) (time.Duration, bool) {
+	ms, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || ms < 0 {
+		return 0, false
+	}
+	return time.Duration(ms) * time.Millisecond, true
+}
@@ -0,0 +1,51 @@
+package reqctx
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestWithDownstreamDeadline_SubtractsHeadroom(t *testing.T) {
+	upstream, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	upstreamDeadline, _ := upstream.Deadline()
+
+	headroom := 100 * time.Millisecond
+	ctx, cancelDownstream := WithDownstreamDeadline(upstream, headroom)
+	defer cancelDownstream()
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		t.Fatal("want downstream deadline")
+	}
+	if gap := upstreamDeadline.Sub(deadline); gap != headroom {
+		t.Fatalf("want downstream deadline %v earlier than upstream, got %v", headroom, gap)
+	}
+}
+
+func TestWithDownstreamDeadline_ShrinksEachHop(t *testing.T) {
+	gateway, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	gatewayDeadline, _ := gateway.Deadline()
+
+	headroom := 50 * time.Millisecond
+	userCtx, cancelUser := WithDownstreamDeadline(gateway, headroom)
+	defer cancelUser()
+	bookCtx, cancelBook := WithDownstreamDeadline(userCtx, headroom)
+	defer cancelBook()
+
+	bookDeadline, _ := bookCtx.Deadline()
+	if gap := gatewayDeadline.Sub(bookDeadline); gap != 2*headroom {
+		t.Fatalf("want two hops to reserve %v, got %v", 2*headroom, gap)
+	}
+}
+
+func TestWithDownstreamDeadline_NoDeadline(t *testing.T) {
+	ctx, cancel := WithDownstreamDeadline(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	if _, ok := ctx.Deadline(); ok {
+		t.Fatal("want no deadline when upstream has none")
+	}
+}
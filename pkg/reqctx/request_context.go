@@ -16,6 +16,10 @@ const (
 	RequestInfoKey contextKey = "request_info"
 )
 
+// TraceIDMetadataKey trace_id 在 gRPC metadata 中的键
+// 网关将 X-Request-ID 作为 trace_id，经客户端拦截器写入该键，服务端拦截器读回
+const TraceIDMetadataKey = "x-trace-id"
+
 // RequestInfo 请求信息结构体
 type RequestInfo struct {
 	Method   string
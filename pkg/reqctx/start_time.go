@@ -0,0 +1,30 @@
+package reqctx
+
+import (
+	"context"
+	"time"
+)
+
+// StartTimeKey 请求开始时间在 context 中的键
+const StartTimeKey contextKey = "start_time"
+
+// WithStartTime 将请求开始时间存储到 context
+func WithStartTime(ctx context.Context, start time.Time) context.Context {
+	return context.WithValue(ctx, StartTimeKey, start)
+}
+
+// GetStartTime 从 context 中获取请求开始时间
+func GetStartTime(ctx context.Context) (time.Time, bool) {
+	start, ok := ctx.Value(StartTimeKey).(time.Time)
+	return start, ok
+}
+
+// Elapsed 获取请求从开始到现在的耗时
+// context 中没有开始时间时返回 0
+func Elapsed(ctx context.Context) time.Duration {
+	start, ok := GetStartTime(ctx)
+	if !ok {
+		return 0
+	}
+	return time.Since(start)
+}
@@ -0,0 +1,23 @@
+package reqctx
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestElapsed(t *testing.T) {
+	ctx := WithStartTime(context.Background(), time.Now())
+	time.Sleep(20 * time.Millisecond)
+
+	elapsed := Elapsed(ctx)
+	if elapsed < 20*time.Millisecond || elapsed > time.Second {
+		t.Fatalf("Elapsed = %v, want between 20ms and 1s", elapsed)
+	}
+}
+
+func TestElapsedWithoutStartTime(t *testing.T) {
+	if elapsed := Elapsed(context.Background()); elapsed != 0 {
+		t.Fatalf("Elapsed = %v, want 0", elapsed)
+	}
+}
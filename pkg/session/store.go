@@ -0,0 +1,77 @@
+package session
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/alfredchaos/demo/pkg/cache"
+)
+
+// ErrNotFound 会话不存在、已过期或已销毁
+var ErrNotFound = errors.New("session not found")
+
+// keyPrefix 会话在 Redis 中的键前缀
+const keyPrefix = "session:"
+
+// Store 基于 Redis 的服务端会话存储
+// 会话 ID 为随机生成的不透明令牌，Redis 中只保存会话 ID 到用户 ID 的映射
+type Store struct {
+	client *cache.RedisClient
+}
+
+// NewStore 创建会话存储
+func NewStore(client *cache.RedisClient) *Store {
+	return &Store{client: client}
+}
+
+// Create 为用户创建会话并返回会话 ID
+// ttl 受 RedisConfig 的 max_ttl 上限约束，为 0 时使用 default_ttl
+func (s *Store) Create(ctx context.Context, userID string, ttl time.Duration) (string, error) {
+	sessionID, err := newSessionID()
+	if err != nil {
+		return "", err
+	}
+
+	if err := s.client.Set(ctx, keyPrefix+sessionID, userID, ttl); err != nil {
+		return "", fmt.Errorf("failed to create session: %w", err)
+	}
+	return sessionID, nil
+}
+
+// Get 获取会话对应的用户 ID
+// 会话不存在或已过期时返回 ErrNotFound
+func (s *Store) Get(ctx context.Context, sessionID string) (string, error) {
+	if sessionID == "" {
+		return "", ErrNotFound
+	}
+
+	userID, found, err := s.client.GetOrNil(ctx, keyPrefix+sessionID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get session: %w", err)
+	}
+	if !found {
+		return "", ErrNotFound
+	}
+	return userID, nil
+}
+
+// Destroy 销毁会话，会话不存在时为空操作
+func (s *Store) Destroy(ctx context.Context, sessionID string) error {
+	if err := s.client.Del(ctx, keyPrefix+sessionID); err != nil {
+		return fmt.Errorf("failed to destroy session: %w", err)
+	}
+	return nil
+}
+
+// newSessionID 生成 256 位随机会话 ID
+func newSessionID() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate session id: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
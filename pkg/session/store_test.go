@@ -0,0 +1,96 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alfredchaos/demo/pkg/cache"
+	"github.com/alicebob/miniredis/v2"
+)
+
+func newTestStore(t *testing.T) (*Store, *miniredis.Miniredis) {
+	t.Helper()
+
+	mr := miniredis.RunT(t)
+	rc, err := cache.NewRedisClient(&cache.RedisConfig{Addr: mr.Addr()})
+	if err != nil {
+		t.Fatalf("new redis client: %v", err)
+	}
+	t.Cleanup(func() { _ = rc.Close() })
+
+	return NewStore(rc), mr
+}
+
+func TestStore_CreateGet(t *testing.T) {
+	store, _ := newTestStore(t)
+	ctx := context.Background()
+
+	id1, err := store.Create(ctx, "user-1", time.Hour)
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	id2, err := store.Create(ctx, "user-1", time.Hour)
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if id1 == id2 {
+		t.Fatal("want distinct session ids")
+	}
+
+	userID, err := store.Get(ctx, id1)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if userID != "user-1" {
+		t.Fatalf("want user-1, got %q", userID)
+	}
+}
+
+func TestStore_Expire(t *testing.T) {
+	store, mr := newTestStore(t)
+	ctx := context.Background()
+
+	id, err := store.Create(ctx, "user-1", time.Minute)
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	mr.FastForward(2 * time.Minute)
+
+	if _, err := store.Get(ctx, id); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("want ErrNotFound after expiry, got %v", err)
+	}
+}
+
+func TestStore_Destroy(t *testing.T) {
+	store, _ := newTestStore(t)
+	ctx := context.Background()
+
+	id, err := store.Create(ctx, "user-1", time.Hour)
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if err := store.Destroy(ctx, id); err != nil {
+		t.Fatalf("destroy: %v", err)
+	}
+
+	if _, err := store.Get(ctx, id); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("want ErrNotFound after destroy, got %v", err)
+	}
+	// 重复销毁为空操作
+	if err := store.Destroy(ctx, id); err != nil {
+		t.Fatalf("destroy twice: %v", err)
+	}
+}
+
+func TestStore_GetUnknown(t *testing.T) {
+	store, _ := newTestStore(t)
+
+	for _, id := range []string{"", "unknown"} {
+		if _, err := store.Get(context.Background(), id); !errors.Is(err, ErrNotFound) {
+			t.Fatalf("get %q: want ErrNotFound, got %v", id, err)
+		}
+	}
+}
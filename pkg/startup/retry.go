@@ -0,0 +1,73 @@
+// Package startup 提供服务启动阶段的辅助工具
+package startup
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/alfredchaos/demo/pkg/log"
+	"go.uber.org/zap"
+)
+
+const (
+	// defaultMaxAttempts 默认最大尝试次数（含首次）
+	defaultMaxAttempts = 5
+	// defaultInitialBackoff 默认首次重试前的等待时间
+	defaultInitialBackoff = 500 * time.Millisecond
+	// defaultMaxBackoff 默认单次等待上限
+	defaultMaxBackoff = 5 * time.Second
+)
+
+// RetryPolicy 启动阶段连接依赖的重试策略
+// 编排启动（如 docker compose）时依赖往往晚几秒就绪，重试可以避免服务因此直接退出
+type RetryPolicy struct {
+	MaxAttempts    int           `yaml:"max_attempts" mapstructure:"max_attempts"`       // 最大尝试次数（含首次），默认 5，1 表示不重试
+	InitialBackoff time.Duration `yaml:"initial_backoff" mapstructure:"initial_backoff"` // 首次重试前的等待时间，之后每次翻倍，默认 500ms
+	MaxBackoff     time.Duration `yaml:"max_backoff" mapstructure:"max_backoff"`         // 单次等待上限，默认 5s
+}
+
+// withDefaults 填充未配置的字段
+func (p RetryPolicy) withDefaults() RetryPolicy {
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = defaultMaxAttempts
+	}
+	if p.InitialBackoff <= 0 {
+		p.InitialBackoff = defaultInitialBackoff
+	}
+	if p.MaxBackoff <= 0 {
+		p.MaxBackoff = defaultMaxBackoff
+	}
+	return p
+}
+
+// RetryConnect 以指数退避重试 connectFn，直到成功或达到最大尝试次数
+// name 为依赖名称，仅用于日志和错误信息
+func RetryConnect(name string, connectFn func() error, policy RetryPolicy) error {
+	policy = policy.withDefaults()
+	backoff := policy.InitialBackoff
+
+	var err error
+	for attempt := 1; ; attempt++ {
+		if err = connectFn(); err == nil {
+			if attempt > 1 {
+				log.Info("dependency connected after retry", zap.String("dependency", name), zap.Int("attempt", attempt))
+			}
+			return nil
+		}
+		if attempt >= policy.MaxAttempts {
+			return fmt.Errorf("failed to connect %s after %d attempts: %w", name, attempt, err)
+		}
+
+		log.Warn("dependency not ready, retrying",
+			zap.String("dependency", name),
+			zap.Int("attempt", attempt),
+			zap.Duration("backoff", backoff),
+			zap.Error(err))
+		time.Sleep(backoff)
+
+		backoff *= 2
+		if backoff > policy.MaxBackoff {
+			backoff = policy.MaxBackoff
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package startup
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alfredchaos/demo/pkg/log"
+	"go.uber.org/zap"
+)
+
+// useNopLogger 测试期间使用空日志，结束后恢复原日志
+func useNopLogger(t *testing.T) {
+	t.Helper()
+	original := log.Logger
+	log.Logger = zap.NewNop()
+	t.Cleanup(func() { log.Logger = original })
+}
+
+// fastPolicy 测试用的短退避策略
+var fastPolicy = RetryPolicy{MaxAttempts: 4, InitialBackoff: time.Millisecond, MaxBackoff: 2 * time.Millisecond}
+
+var errNotReady = errors.New("connection refused")
+
+func TestRetryConnect_SucceedsOnceDependencyIsUp(t *testing.T) {
+	useNopLogger(t)
+	// 前两次依赖尚未就绪，第三次成功
+	attempts := 0
+	err := RetryConnect("redis", func() error {
+		attempts++
+		if attempts < 3 {
+			return errNotReady
+		}
+		return nil
+	}, fastPolicy)
+
+	if err != nil {
+		t.Fatalf("want success after retries, got %v", err)
+	}
+	if attempts != 3 {
+		t.Fatalf("want 3 attempts, got %d", attempts)
+	}
+}
+
+func TestRetryConnect_FailsAfterMaxAttempts(t *testing.T) {
+	useNopLogger(t)
+	attempts := 0
+	err := RetryConnect("rabbitmq", func() error {
+		attempts++
+		return errNotReady
+	}, fastPolicy)
+
+	if !errors.Is(err, errNotReady) {
+		t.Fatalf("want last error wrapped, got %v", err)
+	}
+	if attempts != fastPolicy.MaxAttempts {
+		t.Fatalf("want %d attempts, got %d", fastPolicy.MaxAttempts, attempts)
+	}
+}
@@ -0,0 +1,130 @@
+// Package taskresult 保存异步任务的执行结果，供网关查询或长轮询等待
+package taskresult
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/alfredchaos/demo/pkg/cache"
+	"github.com/alfredchaos/demo/pkg/codec"
+)
+
+// keyPrefix 任务结果缓存键前缀
+const keyPrefix = "task:result:"
+
+// DefaultTTL 任务结果默认保留时间
+const DefaultTTL = 10 * time.Minute
+
+// 任务状态
+const (
+	StatusSucceeded = "succeeded" // 执行成功
+	StatusFailed    = "failed"    // 执行失败
+)
+
+// Wait 轮询退避参数，每次未命中后间隔翻倍，直到上限
+var (
+	waitInitialInterval = 10 * time.Millisecond
+	waitMaxInterval     = 500 * time.Millisecond
+)
+
+// Result 任务执行结果
+type Result struct {
+	TaskID     string          `json:"task_id"`
+	Status     string          `json:"status"`           // 任务状态，见 Status* 常量
+	Output     json.RawMessage `json:"output,omitempty"` // 任务输出，由任务类型决定结构
+	Error      string          `json:"error,omitempty"`  // 失败原因
+	FinishedAt time.Time       `json:"finished_at"`      // 完成时间
+}
+
+// TaskResultStore 基于 Redis 的任务结果存储
+type TaskResultStore struct {
+	client *cache.RedisClient
+	ttl    time.Duration
+}
+
+// NewTaskResultStore 创建任务结果存储，ttl <= 0 时使用 DefaultTTL
+func NewTaskResultStore(client *cache.RedisClient, ttl time.Duration) *TaskResultStore {
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+	return &TaskResultStore{
+		client: client,
+		ttl:    ttl,
+	}
+}
+
+// buildKey 构建任务结果缓存键
+func buildKey(taskID string) string {
+	return keyPrefix + taskID
+}
+
+// Save 保存任务结果，已有结果会被覆盖
+func (s *TaskResultStore) Save(ctx context.Context, result *Result) error {
+	if result == nil || result.TaskID == "" {
+		return fmt.Errorf("task id is required")
+	}
+
+	data, err := codec.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("failed to marshal task result: %w", err)
+	}
+	if err := s.client.Set(ctx, buildKey(result.TaskID), data, s.ttl); err != nil {
+		return fmt.Errorf("failed to save task result: %w", err)
+	}
+	return nil
+}
+
+// Get 获取任务结果，ok 为 false 表示结果尚未写入或已过期
+func (s *TaskResultStore) Get(ctx context.Context, taskID string) (*Result, bool, error) {
+	value, found, err := s.client.GetOrNil(ctx, buildKey(taskID))
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get task result: %w", err)
+	}
+	if !found {
+		return nil, false, nil
+	}
+
+	var result Result
+	if err := json.Unmarshal([]byte(value), &result); err != nil {
+		return nil, false, fmt.Errorf("failed to unmarshal task result: %w", err)
+	}
+	return &result, true, nil
+}
+
+// Wait 等待任务结果写入，最长等待 timeout
+// 以指数退避轮询，结果写入后最迟在一个轮询间隔（上限 500ms）内返回；
+// 超时返回 ok 为 false 且 err 为 nil，ctx 取消时返回 ctx.Err()；timeout <= 0 时只查询一次
+func (s *TaskResultStore) Wait(ctx context.Context, taskID string, timeout time.Duration) (*Result, bool, error) {
+	deadline := time.Now().Add(timeout)
+	interval := waitInitialInterval
+
+	for {
+		result, ok, err := s.Get(ctx, taskID)
+		if err != nil || ok {
+			return result, ok, err
+		}
+
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return nil, false, nil
+		}
+		if interval > remaining {
+			interval = remaining
+		}
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, false, ctx.Err()
+		case <-timer.C:
+		}
+
+		interval *= 2
+		if interval > waitMaxInterval {
+			interval = waitMaxInterval
+		}
+	}
+}
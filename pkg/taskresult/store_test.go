@@ -0,0 +1,96 @@
+package taskresult
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alfredchaos/demo/pkg/cache"
+	"github.com/alicebob/miniredis/v2"
+)
+
+func newTestStore(t *testing.T) *TaskResultStore {
+	t.Helper()
+
+	mr := miniredis.RunT(t)
+	rc, err := cache.NewRedisClient(&cache.RedisConfig{Addr: mr.Addr()})
+	if err != nil {
+		t.Fatalf("new redis client: %v", err)
+	}
+	t.Cleanup(func() { rc.Close() })
+
+	return NewTaskResultStore(rc, time.Minute)
+}
+
+func TestTaskResultStore_SaveAndGet(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	if _, ok, err := store.Get(ctx, "t1"); ok || err != nil {
+		t.Fatalf("want missing result, got ok=%v err=%v", ok, err)
+	}
+
+	want := &Result{TaskID: "t1", Status: StatusSucceeded, Output: []byte(`{"n":1}`)}
+	if err := store.Save(ctx, want); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	got, ok, err := store.Get(ctx, "t1")
+	if err != nil || !ok {
+		t.Fatalf("want result, got ok=%v err=%v", ok, err)
+	}
+	if got.Status != StatusSucceeded || string(got.Output) != `{"n":1}` {
+		t.Fatalf("unexpected result: %+v", got)
+	}
+}
+
+func TestTaskResultStore_WaitReturnsPromptlyWhenResultWritten(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		_ = store.Save(ctx, &Result{TaskID: "t1", Status: StatusSucceeded})
+	}()
+
+	start := time.Now()
+	result, ok, err := store.Wait(ctx, "t1", 5*time.Second)
+	if err != nil || !ok {
+		t.Fatalf("want result, got ok=%v err=%v", ok, err)
+	}
+	if result.TaskID != "t1" {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	// 结果写入后最迟一个轮询间隔内返回
+	if elapsed := time.Since(start); elapsed > 50*time.Millisecond+waitMaxInterval {
+		t.Fatalf("wait returned too late: %s", elapsed)
+	}
+}
+
+func TestTaskResultStore_WaitTimesOut(t *testing.T) {
+	store := newTestStore(t)
+
+	start := time.Now()
+	result, ok, err := store.Wait(context.Background(), "missing", 100*time.Millisecond)
+	if err != nil || ok || result != nil {
+		t.Fatalf("want timeout without error, got result=%v ok=%v err=%v", result, ok, err)
+	}
+	if elapsed := time.Since(start); elapsed < 100*time.Millisecond || elapsed > time.Second {
+		t.Fatalf("want wait to last about the timeout, got %s", elapsed)
+	}
+}
+
+func TestTaskResultStore_WaitStopsOnCancel(t *testing.T) {
+	store := newTestStore(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	start := time.Now()
+	_, ok, err := store.Wait(ctx, "missing", 5*time.Second)
+	if ok || !errors.Is(err, context.Canceled) {
+		t.Fatalf("want context.Canceled, got ok=%v err=%v", ok, err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("wait did not stop on cancel: %s", elapsed)
+	}
+}
@@ -0,0 +1,125 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"sync"
+)
+
+// ErrPoolClosed 向已关闭的工作池提交任务
+var ErrPoolClosed = errors.New("worker pool is closed")
+
+// Job 工作池任务
+// ctx 为工作池的上下文，取消时正在执行的任务应尽快返回
+type Job func(ctx context.Context) error
+
+// Pool 有界工作池
+// 固定数量的 worker 并发执行任务，收集所有任务返回的错误
+//
+// 上下文取消时优雅排空：已开始的任务继续执行完毕（通过 ctx 感知取消），
+// 尚未开始的任务被丢弃，Submit 返回 ctx.Err()
+type Pool struct {
+	ctx  context.Context
+	jobs chan Job
+	wg   sync.WaitGroup
+
+	// closeMu 保护 closed，Submit 持有读锁发送任务，避免向已关闭的通道发送
+	closeMu sync.RWMutex
+	closed  bool
+
+	mu   sync.Mutex
+	errs []error
+}
+
+// NewPool 创建并启动工作池
+// workers: 并发 worker 数量，小于 1 时按 1 处理
+func NewPool(ctx context.Context, workers int) *Pool {
+	if workers < 1 {
+		workers = 1
+	}
+
+	p := &Pool{
+		ctx:  ctx,
+		jobs: make(chan Job),
+	}
+
+	p.wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go p.work()
+	}
+
+	return p
+}
+
+// Submit 提交任务
+// 所有 worker 都忙碌时阻塞，直到有 worker 空闲或上下文被取消
+func (p *Pool) Submit(job Job) error {
+	p.closeMu.RLock()
+	defer p.closeMu.RUnlock()
+	if p.closed {
+		return ErrPoolClosed
+	}
+
+	// 上下文已取消时不再接收任务，避免 select 随机选中 jobs 分支
+	if err := p.ctx.Err(); err != nil {
+		return err
+	}
+
+	select {
+	case p.jobs <- job:
+		return nil
+	case <-p.ctx.Done():
+		return p.ctx.Err()
+	}
+}
+
+// Wait 停止接收任务，等待所有 worker 退出并返回聚合后的错误
+// 调用后不能再提交任务；没有任务失败时返回 nil
+func (p *Pool) Wait() error {
+	p.closeMu.Lock()
+	if !p.closed {
+		p.closed = true
+		close(p.jobs)
+	}
+	p.closeMu.Unlock()
+
+	p.wg.Wait()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return errors.Join(p.errs...)
+}
+
+// work worker 主循环
+func (p *Pool) work() {
+	defer p.wg.Done()
+
+	for {
+		select {
+		case job, ok := <-p.jobs:
+			if !ok {
+				return
+			}
+			if err := p.run(job); err != nil {
+				p.mu.Lock()
+				p.errs = append(p.errs, err)
+				p.mu.Unlock()
+			}
+		case <-p.ctx.Done():
+			return
+		}
+	}
+}
+
+// run 执行单个任务，将 panic 转换为错误，避免单个任务拖垮整个工作池
+func (p *Pool) run(job Job) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("worker job panic: %v\n%s", r, debug.Stack())
+		}
+	}()
+
+	return job(p.ctx)
+}
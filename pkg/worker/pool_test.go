@@ -0,0 +1,117 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPool_BoundedConcurrency(t *testing.T) {
+	pool := NewPool(context.Background(), 3)
+
+	var running, peak, done int32
+	for i := 0; i < 20; i++ {
+		err := pool.Submit(func(ctx context.Context) error {
+			n := atomic.AddInt32(&running, 1)
+			for {
+				old := atomic.LoadInt32(&peak)
+				if n <= old || atomic.CompareAndSwapInt32(&peak, old, n) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			atomic.AddInt32(&running, -1)
+			atomic.AddInt32(&done, 1)
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("submit: %v", err)
+		}
+	}
+
+	if err := pool.Wait(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if peak > 3 {
+		t.Fatalf("want at most 3 concurrent jobs, got %d", peak)
+	}
+	if done != 20 {
+		t.Fatalf("want 20 jobs done, got %d", done)
+	}
+}
+
+func TestPool_AggregatesErrors(t *testing.T) {
+	pool := NewPool(context.Background(), 2)
+
+	errA := errors.New("job a failed")
+	errB := errors.New("job b failed")
+	jobs := []Job{
+		func(ctx context.Context) error { return errA },
+		func(ctx context.Context) error { return nil },
+		func(ctx context.Context) error { return errB },
+		func(ctx context.Context) error { panic("boom") },
+	}
+	for _, job := range jobs {
+		if err := pool.Submit(job); err != nil {
+			t.Fatalf("submit: %v", err)
+		}
+	}
+
+	err := pool.Wait()
+	if !errors.Is(err, errA) || !errors.Is(err, errB) {
+		t.Fatalf("want both job errors aggregated, got %v", err)
+	}
+	if joined, ok := err.(interface{ Unwrap() []error }); !ok || len(joined.Unwrap()) != 3 {
+		t.Fatalf("want 3 errors including recovered panic, got %v", err)
+	}
+
+	if err := pool.Submit(func(ctx context.Context) error { return nil }); !errors.Is(err, ErrPoolClosed) {
+		t.Fatalf("want ErrPoolClosed after Wait, got %v", err)
+	}
+}
+
+func TestPool_CancelMidFlight(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	pool := NewPool(ctx, 2)
+
+	started := make(chan struct{}, 2)
+	var finished int32
+	for i := 0; i < 2; i++ {
+		err := pool.Submit(func(ctx context.Context) error {
+			started <- struct{}{}
+			<-ctx.Done()
+			atomic.AddInt32(&finished, 1)
+			return ctx.Err()
+		})
+		if err != nil {
+			t.Fatalf("submit: %v", err)
+		}
+	}
+	<-started
+	<-started
+
+	// 所有 worker 忙碌时 Submit 阻塞，取消后返回 ctx.Err()
+	submitErr := make(chan error, 1)
+	go func() {
+		submitErr <- pool.Submit(func(ctx context.Context) error {
+			t.Error("job submitted after cancel must not run")
+			return nil
+		})
+	}()
+
+	cancel()
+
+	if err := <-submitErr; !errors.Is(err, context.Canceled) {
+		t.Fatalf("want context.Canceled from blocked Submit, got %v", err)
+	}
+
+	err := pool.Wait()
+	if finished != 2 {
+		t.Fatalf("want in-flight jobs drained, got %d finished", finished)
+	}
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("want in-flight job errors aggregated, got %v", err)
+	}
+}